@@ -0,0 +1,362 @@
+package boltkv
+
+import (
+	"context"
+	"sort"
+
+	"rafal.dev/objects/types"
+)
+
+// DB is the subset of a bbolt database used by the backend, e.g.
+// an adapter over *bbolt.DB, which passes *bbolt.Tx wrapped in a Tx
+// to the functions.
+type DB interface {
+	View(fn func(Tx) error) error
+	Update(fn func(Tx) error) error
+}
+
+// Tx is the subset of a bbolt transaction used by the backend.
+// Bucket gives a nil interface for a missing bucket.
+type Tx interface {
+	Bucket(name []byte) Bucket
+	CreateBucketIfNotExists(name []byte) (Bucket, error)
+}
+
+// Bucket is the subset of a bbolt bucket used by the backend. Bucket
+// gives a nil interface for a missing bucket; ForEach passes a nil
+// value for nested buckets, as bbolt does.
+type Bucket interface {
+	Bucket(name []byte) Bucket
+	CreateBucketIfNotExists(name []byte) (Bucket, error)
+	DeleteBucket(name []byte) error
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	ForEach(fn func(k, v []byte) error) error
+}
+
+// Tree is a tree stored in a top-level bucket, where nested buckets
+// are maps and key/value pairs are leaves. Values of leaves are
+// read as []byte.
+type Tree struct {
+	db   DB
+	root []byte
+	key  types.Key
+}
+
+var (
+	_ types.Interface  = (*Tree)(nil)
+	_ types.SafeReader = (*Tree)(nil)
+	_ types.SafeLister = (*Tree)(nil)
+	_ types.SafeWriter = (*Tree)(nil)
+)
+
+// New gives a tree stored in the root bucket, which is created on
+// the first write.
+func New(db DB, root string) *Tree {
+	return &Tree{
+		db:   db,
+		root: []byte(root),
+	}
+}
+
+func (t *Tree) Type() types.Type {
+	return types.TypeMap
+}
+
+func (t *Tree) Get(ctx context.Context, key string) (any, bool) {
+	v, err := t.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (t *Tree) SafeGet(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	var v any
+
+	err := t.db.View(func(tx Tx) error {
+		b := t.bucket(tx)
+		if b == nil {
+			return types.ErrNotFound
+		}
+
+		if b.Bucket([]byte(key)) != nil {
+			v = t.child(key)
+			return nil
+		}
+
+		p := b.Get([]byte(key))
+		if p == nil {
+			return types.ErrNotFound
+		}
+
+		// Values are valid only during the transaction.
+		v = append([]byte(nil), p...)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	return v, nil
+}
+
+func (t *Tree) List(ctx context.Context) []string {
+	keys, _ := t.SafeList(ctx)
+	return keys
+}
+
+func (t *Tree) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	var keys []string
+
+	err := t.db.View(func(tx Tx) error {
+		b := t.bucket(tx)
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (t *Tree) Del(ctx context.Context, key string) bool {
+	return t.SafeDel(ctx, key) == nil
+}
+
+func (t *Tree) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := t.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (t *Tree) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := t.SafePut(ctx, key, hint)
+	return w
+}
+
+func (t *Tree) SafeDel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return keyError("Del", key, err)
+	}
+
+	err := t.db.Update(func(tx Tx) error {
+		b := t.bucket(tx)
+		if b == nil {
+			return types.ErrNotFound
+		}
+
+		ok, err := del(b, []byte(key))
+		if err == nil && !ok {
+			err = types.ErrNotFound
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return keyError("Del", key, err)
+	}
+
+	return nil
+}
+
+// SafeSet replaces the value under the key, which is a string or
+// []byte leaf or a Reader, stored as nested buckets in a single
+// transaction.
+func (t *Tree) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	var previous bool
+
+	err := t.db.Update(func(tx Tx) error {
+		b, err := t.createBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		if previous, err = del(b, []byte(key)); err != nil {
+			return err
+		}
+
+		return put(ctx, b, key, value)
+	})
+
+	if err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	return previous, nil
+}
+
+// SafePut creates the bucket under the key, together with any missing
+// parent buckets, replacing a leaf. Slices are stored as buckets too.
+func (t *Tree) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	err := t.db.Update(func(tx Tx) error {
+		b, err := t.createBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		if b.Bucket([]byte(key)) == nil && b.Get([]byte(key)) != nil {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		_, err = b.CreateBucketIfNotExists([]byte(key))
+		return err
+	})
+
+	if err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	return t.child(key), nil
+}
+
+func (t *Tree) Ping(ctx context.Context) error {
+	return types.Ping(ctx, t.db)
+}
+
+func (t *Tree) Close(ctx context.Context) error {
+	return types.Close(ctx, t.db)
+}
+
+func (t *Tree) Unwrap() any {
+	return t.db
+}
+
+// bucket gives the bucket of the tree, or nil if it does not exist.
+func (t *Tree) bucket(tx Tx) Bucket {
+	b := tx.Bucket(t.root)
+
+	for _, k := range t.key {
+		if b == nil {
+			return nil
+		}
+
+		b = b.Bucket([]byte(k))
+	}
+
+	return b
+}
+
+// createBucket gives the bucket of the tree, creating missing ones.
+func (t *Tree) createBucket(tx Tx) (Bucket, error) {
+	b, err := tx.CreateBucketIfNotExists(t.root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range t.key {
+		if b.Bucket([]byte(k)) == nil && b.Get([]byte(k)) != nil {
+			if err := b.Delete([]byte(k)); err != nil {
+				return nil, err
+			}
+		}
+
+		if b, err = b.CreateBucketIfNotExists([]byte(k)); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func (t *Tree) child(key string) *Tree {
+	return &Tree{
+		db:   t.db,
+		root: t.root,
+		key:  append(t.key.Copy(), key),
+	}
+}
+
+func del(b Bucket, key []byte) (bool, error) {
+	if b.Bucket(key) != nil {
+		return true, b.DeleteBucket(key)
+	}
+
+	if b.Get(key) != nil {
+		return true, b.Delete(key)
+	}
+
+	return false, nil
+}
+
+func put(ctx context.Context, b Bucket, key string, value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return b.Put([]byte(key), v)
+	case string:
+		return b.Put([]byte(key), []byte(v))
+	case types.Reader:
+		nested, err := b.CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		keys, err := types.List(ctx, v)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			u, err := types.PrefixedReader{R: v}.SafeGet(ctx, k)
+			if err != nil {
+				return err
+			}
+
+			if err := put(ctx, nested, k, u); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return &types.Error{
+			Op:   "Set",
+			Key:  []string{key},
+			Got:  value,
+			Want: []byte(nil),
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+}
+
+func keyError(op, key string, err error) error {
+	return &types.Error{
+		Op:  op,
+		Key: []string{key},
+		Err: err,
+	}
+}
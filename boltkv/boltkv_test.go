@@ -0,0 +1,167 @@
+package boltkv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/boltkv"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// bucket is an in-memory bucket, which values are []byte leaves or
+// nested buckets.
+type bucket map[string]any
+
+type db struct {
+	root bucket
+}
+
+func (db *db) View(fn func(boltkv.Tx) error) error {
+	return fn(db.root)
+}
+
+func (db *db) Update(fn func(boltkv.Tx) error) error {
+	snapshot := db.root.clone()
+
+	if err := fn(db.root); err != nil {
+		db.root = snapshot
+		return err
+	}
+
+	return nil
+}
+
+func (b bucket) Bucket(name []byte) boltkv.Bucket {
+	if nested, ok := b[string(name)].(bucket); ok {
+		return nested
+	}
+	return nil
+}
+
+func (b bucket) CreateBucketIfNotExists(name []byte) (boltkv.Bucket, error) {
+	switch v := b[string(name)].(type) {
+	case bucket:
+		return v, nil
+	case nil:
+		nested := make(bucket)
+		b[string(name)] = nested
+		return nested, nil
+	default:
+		return nil, errors.New("incompatible value")
+	}
+}
+
+func (b bucket) DeleteBucket(name []byte) error {
+	if _, ok := b[string(name)].(bucket); !ok {
+		return errors.New("bucket not found")
+	}
+	delete(b, string(name))
+	return nil
+}
+
+func (b bucket) Get(key []byte) []byte {
+	p, _ := b[string(key)].([]byte)
+	return p
+}
+
+func (b bucket) Put(key, value []byte) error {
+	if _, ok := b[string(key)].(bucket); ok {
+		return errors.New("incompatible value")
+	}
+	b[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+func (b bucket) Delete(key []byte) error {
+	if _, ok := b[string(key)].(bucket); ok {
+		return errors.New("incompatible value")
+	}
+	delete(b, string(key))
+	return nil
+}
+
+func (b bucket) ForEach(fn func(k, v []byte) error) error {
+	for k, v := range b {
+		p, _ := v.([]byte)
+		if err := fn([]byte(k), p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b bucket) clone() bucket {
+	c := make(bucket, len(b))
+
+	for k, v := range b {
+		if nested, ok := v.(bucket); ok {
+			v = nested.clone()
+		}
+		c[k] = v
+	}
+
+	return c
+}
+
+func TestTree(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		db   = &db{root: make(bucket)}
+		tree = boltkv.New(db, "config")
+	)
+
+	if _, err := objects.Set(ctx, tree, types.Map{"host": "localhost", "tls": types.Map{"cert": "a.pem"}}, "db"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	want := bucket{
+		"config": bucket{
+			"db": bucket{
+				"host": []byte("localhost"),
+				"tls": bucket{
+					"cert": []byte("a.pem"),
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, db.root); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, err := objects.Set(ctx, tree, types.Map{"host": "db.local", "port": 5432}, "db"); !errors.Is(err, types.ErrUnexpectedType) {
+		t.Fatalf("Set()=%+v, want %v", err, types.ErrUnexpectedType)
+	}
+
+	if diff := cmp.Diff(want, db.root); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, err := objects.Put(ctx, tree, types.TypeMap, "db", "host", "primary"); err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	keys, err := objects.List(ctx, tree, "db")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"host", "tls"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, ok := db.root["config"].(bucket)["db"].(bucket)["host"].(bucket)["primary"].(bucket); !ok {
+		t.Fatalf("got %v, want db.host.primary bucket", db.root)
+	}
+}
+
+func TestTreeConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(t *testing.T) types.Interface {
+		return boltkv.New(&db{root: make(bucket)}, "objects")
+	})
+}
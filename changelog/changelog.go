@@ -0,0 +1,169 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+
+	"rafal.dev/objects/types"
+)
+
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// Producer publishes messages to a topic, e.g. a thin adapter
+// over a Kafka client.
+type Producer interface {
+	Produce(ctx context.Context, msgs ...Message) error
+}
+
+type Event struct {
+	ID    string    `json:"id,omitempty"`
+	Op    string    `json:"op"`
+	Key   types.Key `json:"key"`
+	Value any       `json:"value,omitempty"`
+}
+
+type Writer struct {
+	W        types.Writer
+	Producer Producer
+	Topic    string
+	Key      types.Key
+}
+
+var (
+	_ types.Writer     = (*Writer)(nil)
+	_ types.SafeWriter = (*Writer)(nil)
+)
+
+func New(w types.Writer, p Producer, topic string) *Writer {
+	return &Writer{
+		W:        w,
+		Producer: p,
+		Topic:    topic,
+	}
+}
+
+func (w *Writer) Del(ctx context.Context, key string) bool {
+	return w.SafeDel(ctx, key) == nil
+}
+
+func (w *Writer) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := w.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (w *Writer) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	v, _ := w.SafePut(ctx, key, hint)
+	return v
+}
+
+func (w *Writer) SafeDel(ctx context.Context, key string) error {
+	if sw, ok := w.W.(types.SafeWriter); ok {
+		if err := sw.SafeDel(ctx, key); err != nil {
+			return err
+		}
+	} else if !w.W.Del(ctx, key) {
+		return &types.Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: types.ErrNotFound,
+		}
+	}
+
+	return w.publish(ctx, "Del", key, nil)
+}
+
+func (w *Writer) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	var (
+		ok  bool
+		err error
+	)
+
+	if sw, isSafe := w.W.(types.SafeWriter); isSafe {
+		if ok, err = sw.SafeSet(ctx, key, value); err != nil {
+			return false, err
+		}
+	} else {
+		ok = w.W.Set(ctx, key, value)
+	}
+
+	return ok, w.publish(ctx, "Set", key, value)
+}
+
+func (w *Writer) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	var (
+		v   types.Writer
+		err error
+	)
+
+	if sw, ok := w.W.(types.SafeWriter); ok {
+		if v, err = sw.SafePut(ctx, key, hint); err != nil {
+			return nil, err
+		}
+	} else {
+		v = w.W.Put(ctx, key, hint)
+	}
+
+	if err := w.publish(ctx, "Put", key, hint); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		W:        v,
+		Producer: w.Producer,
+		Topic:    w.Topic,
+		Key:      append(w.Key.Copy(), key),
+	}, nil
+}
+
+func (w *Writer) publish(ctx context.Context, op, key string, value any) error {
+	id, _ := types.IdempotencyKey(ctx)
+
+	ev := Event{
+		ID:    id,
+		Op:    op,
+		Key:   append(w.Key.Copy(), key),
+		Value: value,
+	}
+
+	p, err := json.Marshal(ev)
+	if err != nil {
+		return &types.Error{
+			Op:  op,
+			Key: []string{key},
+			Got: value,
+			Err: err,
+		}
+	}
+
+	msg := Message{
+		Topic: w.Topic,
+		Key:   []byte(ev.Key.String()),
+		Value: p,
+	}
+
+	if err := w.Producer.Produce(ctx, msg); err != nil {
+		return &types.Error{
+			Op:  op,
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (w *Writer) Ping(ctx context.Context) error {
+	return types.Ping(ctx, w.W, w.Producer)
+}
+
+func (w *Writer) Close(ctx context.Context) error {
+	return types.Close(ctx, w.W, w.Producer)
+}
+
+func (w *Writer) Unwrap() any {
+	return w.W
+}
@@ -0,0 +1,56 @@
+package changelog_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/changelog"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type producer []changelog.Message
+
+func (p *producer) Produce(_ context.Context, msgs ...changelog.Message) error {
+	*p = append(*p, msgs...)
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	var (
+		p   producer
+		m   = make(types.Map)
+		w   = changelog.New(m, &p, "objects")
+		ctx = context.Background()
+	)
+
+	sub, err := w.SafePut(ctx, "db", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	if _, err := sub.(types.SafeWriter).SafeSet(ctx, "host", "localhost"); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if err := w.SafeDel(ctx, "db"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	var got []string
+
+	for _, msg := range p {
+		got = append(got, msg.Topic+" "+string(msg.Key)+" "+string(msg.Value))
+	}
+
+	want := []string{
+		`objects db {"op":"Put","key":["db"],"value":"Map"}`,
+		`objects db.host {"op":"Set","key":["db","host"],"value":"localhost"}`,
+		`objects db {"op":"Del","key":["db"]}`,
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
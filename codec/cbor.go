@@ -0,0 +1,177 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+type CBOROptions struct {
+	// IntKeys encodes map keys, which are decimal integers, as CBOR
+	// integers, as they were decoded.
+	IntKeys bool
+}
+
+var DefaultCBOROptions = &CBOROptions{
+	IntKeys: true,
+}
+
+var (
+	cborEnc, _ = cbor.CanonicalEncOptions().EncMode()
+	cborDec, _ = cbor.DecOptions{IntDec: cbor.IntDecConvertSigned}.DecMode()
+)
+
+var CBOR Codec = codecFn{
+	marshal: func(v any) ([]byte, error) {
+		if r, ok := v.(objects.Reader); ok {
+			var buf bytes.Buffer
+			err := EncodeCBOR(context.Background(), &buf, r, DefaultCBOROptions)
+			return buf.Bytes(), err
+		}
+		return cborEnc.Marshal(v)
+	},
+	unmarshal: cborDec.Unmarshal,
+}
+
+// EncodeCBOR encodes the tree of r as canonical CBOR to w. Slices are
+// encoded as arrays, other readers as maps and []byte leaves as byte
+// strings. Tagged values decoded by DecodeCBOR are encoded back with
+// their tags.
+func EncodeCBOR(ctx context.Context, w io.Writer, r objects.Reader, opts *CBOROptions) error {
+	if opts == nil {
+		opts = DefaultCBOROptions
+	}
+
+	v, err := cborValue(ctx, r, opts, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := cborEnc.NewEncoder(w).Encode(v); err != nil {
+		return &objects.Error{
+			Op:  "Encode",
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func cborValue(ctx context.Context, v any, opts *CBOROptions, key objects.Key) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r, ok := v.(objects.Reader)
+	if !ok {
+		return v, nil
+	}
+
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return nil, &objects.Error{
+			Op:  "Encode",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	var (
+		s []any
+		m map[any]any
+	)
+
+	if r.Type() != objects.TypeSlice {
+		m = make(map[any]any, len(keys))
+	}
+
+	for _, k := range keys {
+		v, err := objects.Get(ctx, r, k)
+		if errors.Is(err, objects.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if v, err = cborValue(ctx, v, opts, append(key.Copy(), k)); err != nil {
+			return nil, err
+		}
+
+		if m == nil {
+			s = append(s, v)
+			continue
+		}
+
+		if n, err := strconv.ParseInt(k, 10, 64); opts.IntKeys && err == nil && strconv.FormatInt(n, 10) == k {
+			m[n] = v
+		} else {
+			m[k] = v
+		}
+	}
+
+	if m != nil {
+		return m, nil
+	}
+
+	if s == nil {
+		s = []any{}
+	}
+
+	return s, nil
+}
+
+// DecodeCBOR decodes a CBOR document from r into a tree of maps and
+// slices. Map keys are converted to strings, so integer keys become
+// their decimal representation. Unknown tagged values are kept as
+// cbor.Tag leaves. The top-level value must be a map or an array.
+func DecodeCBOR(r io.Reader) (objects.Interface, error) {
+	var v any
+
+	if err := cborDec.NewDecoder(r).Decode(&v); err != nil {
+		return nil, &objects.Error{
+			Op:  "Decode",
+			Err: err,
+		}
+	}
+
+	iface, ok := fromCBOR(v).(objects.Interface)
+	if !ok {
+		return nil, &objects.Error{
+			Op:   "Decode",
+			Got:  v,
+			Want: "map or array",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	return iface, nil
+}
+
+func fromCBOR(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(types.Map, len(v))
+		for k, v := range v {
+			m[fmt.Sprint(k)] = fromCBOR(v)
+		}
+		return m
+	case []any:
+		s := make(types.Slice, len(v))
+		for i, v := range v {
+			s[i] = fromCBOR(v)
+		}
+		return &s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,82 @@
+package codec_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/codec"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCBOR(t *testing.T) {
+	var ctx = context.Background()
+
+	// A sensor reading keyed by integers, as sent by constrained devices.
+	p, err := cbor.Marshal(map[any]any{
+		1: "sensor-1",
+		2: []any{21.5, 22},
+		3: cbor.Tag{Number: 1000, Content: "custom"},
+		4: []byte{0, 1},
+	})
+	if err != nil {
+		t.Fatalf("Marshal()=%+v", err)
+	}
+
+	iface, err := codec.DecodeCBOR(bytes.NewReader(p))
+	if err != nil {
+		t.Fatalf("DecodeCBOR()=%+v", err)
+	}
+
+	gets := []struct {
+		keys []string
+		want any
+	}{
+		{[]string{"1"}, "sensor-1"},
+		{[]string{"2", "1"}, int64(22)},
+		{[]string{"3"}, cbor.Tag{Number: 1000, Content: "custom"}},
+		{[]string{"4"}, []byte{0, 1}},
+	}
+
+	for _, get := range gets {
+		v, err := objects.Get(ctx, iface, get.keys...)
+		if err != nil {
+			t.Fatalf("Get(%v)=%+v", get.keys, err)
+		}
+
+		if !cmp.Equal(v, get.want) {
+			t.Fatalf("Get(%v): got %#v, want %#v", get.keys, v, get.want)
+		}
+	}
+
+	if _, err := objects.Set(ctx, iface, "sensor-2", "1"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := codec.EncodeCBOR(ctx, &buf, iface, nil); err != nil {
+		t.Fatalf("EncodeCBOR()=%+v", err)
+	}
+
+	var got map[any]any
+
+	if err := codec.CBOR.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal()=%+v", err)
+	}
+
+	want := map[any]any{
+		int64(1): "sensor-2",
+		int64(2): []any{21.5, int64(22)},
+		int64(3): cbor.Tag{Number: 1000, Content: "custom"},
+		int64(4): []byte{0, 1},
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
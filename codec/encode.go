@@ -0,0 +1,209 @@
+package codec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+type JSONOptions struct {
+	Prefix   string
+	Indent   string
+	SortKeys bool
+
+	// Redact, when non-nil, is called with every leaf value and
+	// the value it returns is encoded instead.
+	Redact func(key objects.Key, value any) any
+
+	// MaxDepth and MaxBytes limit the depth of the encoded tree
+	// and the size of the output. They are not limited if 0.
+	MaxDepth int
+	MaxBytes int64
+}
+
+var DefaultJSONOptions = &JSONOptions{
+	Indent:   "\t",
+	SortKeys: true,
+}
+
+// EncodeJSON streams the tree of r as JSON to w, without buffering
+// the whole output. Slices are encoded as arrays, other readers as
+// objects.
+func EncodeJSON(ctx context.Context, w io.Writer, r objects.Reader, opts *JSONOptions) error {
+	if opts == nil {
+		opts = DefaultJSONOptions
+	}
+
+	e := &encoder{
+		w:    bufio.NewWriter(w),
+		opts: opts,
+	}
+
+	if err := e.encode(ctx, r, nil); err != nil {
+		return err
+	}
+
+	if e.opts.Indent != "" {
+		e.write("\n")
+	}
+
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+type encoder struct {
+	w    *bufio.Writer
+	opts *JSONOptions
+	n    int64
+	err  error
+}
+
+func (e *encoder) encode(ctx context.Context, v any, key objects.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r, ok := v.(objects.Reader)
+	if !ok {
+		return e.leaf(v, key)
+	}
+
+	if e.opts.MaxDepth != 0 && len(key) >= e.opts.MaxDepth {
+		return &objects.Error{
+			Op:   "Encode",
+			Key:  key,
+			Got:  len(key),
+			Want: e.opts.MaxDepth,
+			Err:  objects.ErrTooLarge,
+		}
+	}
+
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return &objects.Error{
+			Op:  "Encode",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	var (
+		array       = r.Type() == objects.TypeSlice
+		open, close = "{", "}"
+	)
+
+	if array {
+		open, close = "[", "]"
+	} else if e.opts.SortKeys {
+		sort.Strings(keys)
+	}
+
+	e.write(open)
+
+	var n int
+
+	for _, k := range keys {
+		v, err := objects.Get(ctx, r, k)
+		if errors.Is(err, objects.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if n != 0 {
+			e.write(",")
+		}
+
+		n++
+
+		e.newline(len(key) + 1)
+
+		if !array {
+			p, _ := json.Marshal(k)
+			e.write(string(p))
+			e.write(":")
+
+			if e.opts.Indent != "" {
+				e.write(" ")
+			}
+		}
+
+		if err := e.encode(ctx, v, append(key.Copy(), k)); err != nil {
+			return err
+		}
+	}
+
+	if n != 0 {
+		e.newline(len(key))
+	}
+
+	e.write(close)
+
+	return e.err
+}
+
+func (e *encoder) leaf(v any, key objects.Key) error {
+	if e.opts.Redact != nil {
+		v = e.opts.Redact(key, v)
+	}
+
+	var (
+		p   []byte
+		err error
+	)
+
+	if e.opts.Indent != "" {
+		p, err = json.MarshalIndent(v, e.opts.Prefix+strings.Repeat(e.opts.Indent, len(key)), e.opts.Indent)
+	} else {
+		p, err = json.Marshal(v)
+	}
+
+	if err != nil {
+		return &objects.Error{
+			Op:  "Encode",
+			Key: key,
+			Got: v,
+			Err: err,
+		}
+	}
+
+	e.write(string(p))
+
+	return e.err
+}
+
+func (e *encoder) newline(depth int) {
+	if e.opts.Indent != "" {
+		e.write("\n" + e.opts.Prefix + strings.Repeat(e.opts.Indent, depth))
+	}
+}
+
+func (e *encoder) write(s string) {
+	if e.err != nil {
+		return
+	}
+
+	if e.n += int64(len(s)); e.opts.MaxBytes != 0 && e.n > e.opts.MaxBytes {
+		e.err = &objects.Error{
+			Op:   "Encode",
+			Got:  e.n,
+			Want: e.opts.MaxBytes,
+			Err:  objects.ErrTooLarge,
+		}
+		return
+	}
+
+	_, e.err = e.w.WriteString(s)
+}
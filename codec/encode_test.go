@@ -0,0 +1,123 @@
+package codec_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTree() types.Map {
+	return types.Map{
+		"server": types.Map{
+			"host":     "localhost",
+			"port":     8080,
+			"password": "secret",
+		},
+		"backends": &types.Slice{
+			types.Map{"url": "http://a"},
+			types.Map{"url": "http://b"},
+		},
+		"empty": types.Map{},
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		buf  bytes.Buffer
+		opts = &codec.JSONOptions{
+			Indent:   "  ",
+			SortKeys: true,
+			Redact: func(key objects.Key, v any) any {
+				if key.Base() == "password" {
+					return "***"
+				}
+				return v
+			},
+		}
+		want = map[string]any{
+			"server": map[string]any{
+				"host":     "localhost",
+				"port":     8080,
+				"password": "***",
+			},
+			"backends": []any{
+				map[string]any{"url": "http://a"},
+				map[string]any{"url": "http://b"},
+			},
+			"empty": map[string]any{},
+		}
+	)
+
+	if err := codec.EncodeJSON(ctx, &buf, newTree(), opts); err != nil {
+		t.Fatalf("EncodeJSON()=%+v", err)
+	}
+
+	p, err := json.MarshalIndent(want, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent()=%+v", err)
+	}
+
+	if got, want := buf.String(), string(p)+"\n"; got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestEncodeJSONLimits(t *testing.T) {
+	var ctx = context.Background()
+
+	for _, opts := range []*codec.JSONOptions{
+		{MaxDepth: 1},
+		{MaxBytes: 16},
+	} {
+		var buf bytes.Buffer
+
+		if err := codec.EncodeJSON(ctx, &buf, newTree(), opts); !errors.Is(err, objects.ErrTooLarge) {
+			t.Fatalf("got %+v, want %+v", err, objects.ErrTooLarge)
+		}
+	}
+}
+
+// failMap is a map, which backend fails to be read.
+type failMap struct {
+	types.Map
+	err error
+}
+
+func (m failMap) SafeGet(context.Context, string) (any, error) {
+	return nil, m.err
+}
+
+func TestEncodeReadError(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		want = errors.New("backend failed")
+		r    = failMap{Map: types.Map{"key": "value"}, err: want}
+	)
+
+	encoders := map[string]func(*bytes.Buffer) error{
+		"JSON": func(buf *bytes.Buffer) error {
+			return codec.EncodeJSON(ctx, buf, r, nil)
+		},
+		"Msgpack": func(buf *bytes.Buffer) error {
+			return codec.EncodeMsgpack(ctx, buf, r)
+		},
+		"CBOR": func(buf *bytes.Buffer) error {
+			return codec.EncodeCBOR(ctx, buf, r, nil)
+		},
+	}
+
+	for name, encode := range encoders {
+		if err := encode(new(bytes.Buffer)); !errors.Is(err, want) {
+			t.Fatalf("%s: got %+v, want %+v", name, err, want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"rafal.dev/objects"
+)
+
+var JSON Codec = codecFn{
+	marshal: func(v any) ([]byte, error) {
+		if r, ok := v.(objects.Reader); ok {
+			var buf bytes.Buffer
+			err := EncodeJSON(context.Background(), &buf, r, DefaultJSONOptions)
+			return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), err
+		}
+		return json.MarshalIndent(v, "", "\t")
+	},
+	unmarshal: json.Unmarshal,
+}
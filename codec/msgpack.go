@@ -0,0 +1,199 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+var Msgpack Codec = codecFn{
+	marshal: func(v any) ([]byte, error) {
+		if r, ok := v.(objects.Reader); ok {
+			var buf bytes.Buffer
+			err := EncodeMsgpack(context.Background(), &buf, r)
+			return buf.Bytes(), err
+		}
+		return msgpack.Marshal(v)
+	},
+	unmarshal: msgpack.Unmarshal,
+}
+
+// EncodeMsgpack streams the tree of r as MessagePack to w. Slices
+// are encoded as arrays, other readers as maps with sorted keys and
+// []byte leaves as binary values.
+func EncodeMsgpack(ctx context.Context, w io.Writer, r objects.Reader) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetSortMapKeys(true)
+
+	return encodeMsgpack(ctx, enc, r, nil)
+}
+
+func encodeMsgpack(ctx context.Context, enc *msgpack.Encoder, v any, key objects.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r, ok := v.(objects.Reader)
+	if !ok {
+		if err := enc.Encode(v); err != nil {
+			return &objects.Error{
+				Op:  "Encode",
+				Key: key,
+				Got: v,
+				Err: err,
+			}
+		}
+		return nil
+	}
+
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return &objects.Error{
+			Op:  "Encode",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	array := r.Type() == objects.TypeSlice
+
+	if !array {
+		sort.Strings(keys)
+	}
+
+	var (
+		ks []string
+		vs []any
+	)
+
+	for _, k := range keys {
+		v, err := objects.Get(ctx, r, k)
+		if errors.Is(err, objects.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ks = append(ks, k)
+		vs = append(vs, v)
+	}
+
+	if array {
+		err = enc.EncodeArrayLen(len(vs))
+	} else {
+		err = enc.EncodeMapLen(len(vs))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for i, v := range vs {
+		if !array {
+			if err := enc.EncodeString(ks[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := encodeMsgpack(ctx, enc, v, append(key.Copy(), ks[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeMsgpack decodes a MessagePack document from r directly into
+// a tree of maps and slices. Integers are decoded as int64, unless
+// they overflow it, floats as float64 and binary values as []byte.
+// The top-level value must be a map or an array.
+func DecodeMsgpack(r io.Reader) (objects.Interface, error) {
+	v, err := decodeMsgpack(msgpack.NewDecoder(r))
+	if err != nil {
+		return nil, &objects.Error{
+			Op:  "Decode",
+			Err: err,
+		}
+	}
+
+	iface, ok := v.(objects.Interface)
+	if !ok {
+		return nil, &objects.Error{
+			Op:   "Decode",
+			Got:  v,
+			Want: "map or array",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	return iface, nil
+}
+
+func decodeMsgpack(dec *msgpack.Decoder) (any, error) {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		n, err := dec.DecodeMapLen()
+		if err != nil {
+			return nil, err
+		}
+
+		m := make(types.Map, n)
+
+		for i := 0; i < n; i++ {
+			k, err := dec.DecodeInterfaceLoose()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := decodeMsgpack(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			m[fmt.Sprint(k)] = v
+		}
+
+		return m, nil
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		n, err := dec.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+
+		s := make(types.Slice, n)
+
+		for i := range s {
+			if s[i], err = decodeMsgpack(dec); err != nil {
+				return nil, err
+			}
+		}
+
+		return &s, nil
+	case c == msgpcode.Bin8 || c == msgpcode.Bin16 || c == msgpcode.Bin32:
+		return dec.DecodeBytes()
+	}
+
+	v, err := dec.DecodeInterfaceLoose()
+	if n, ok := v.(uint64); ok && n <= math.MaxInt64 {
+		return int64(n), err
+	}
+
+	return v, err
+}
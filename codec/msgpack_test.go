@@ -0,0 +1,69 @@
+package codec_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMsgpack(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		buf  bytes.Buffer
+		tree = newTree()
+	)
+
+	tree["blob"] = []byte{0, 1, 2, 255}
+
+	if err := codec.EncodeMsgpack(ctx, &buf, tree); err != nil {
+		t.Fatalf("EncodeMsgpack()=%+v", err)
+	}
+
+	iface, err := codec.DecodeMsgpack(&buf)
+	if err != nil {
+		t.Fatalf("DecodeMsgpack()=%+v", err)
+	}
+
+	want := types.Map{
+		"server": types.Map{
+			"host":     "localhost",
+			"port":     int64(8080),
+			"password": "secret",
+		},
+		"backends": &types.Slice{
+			types.Map{"url": "http://a"},
+			types.Map{"url": "http://b"},
+		},
+		"empty": types.Map{},
+		"blob":  []byte{0, 1, 2, 255},
+	}
+
+	if !cmp.Equal(iface, objects.Interface(want)) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(iface, objects.Interface(want)))
+	}
+
+	p, err := codec.Msgpack.Marshal(tree)
+	if err != nil {
+		t.Fatalf("Marshal()=%+v", err)
+	}
+
+	var v map[string]any
+
+	if err := codec.Msgpack.Unmarshal(p, &v); err != nil {
+		t.Fatalf("Unmarshal()=%+v", err)
+	}
+
+	if got, ok := v["blob"].([]byte); !ok || !cmp.Equal(got, []byte{0, 1, 2, 255}) {
+		t.Fatalf("got %#v, want []byte", v["blob"])
+	}
+
+	if _, err := codec.DecodeMsgpack(bytes.NewReader([]byte{0x01})); err == nil {
+		t.Fatal("expected DecodeMsgpack of a scalar to fail")
+	}
+}
@@ -0,0 +1,134 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/types"
+)
+
+type Options struct {
+	// Env exports leaves of the tree into the process environment,
+	// see objects.ExportEnv.
+	Env       bool
+	EnvPrefix string
+
+	// Codec, when set, renders the tree into a temporary file,
+	// which path is passed to the process by setting the FileEnv
+	// variable and by replacing every FileArg argument.
+	Codec   codec.Codec
+	Pattern string
+	FileEnv string
+	FileArg string
+}
+
+var DefaultOptions = &Options{
+	Env:     true,
+	Pattern: "config-*",
+}
+
+// Prepare configures cmd with the tree read from r. The returned
+// func removes the temporary files and must be called once the
+// process exits.
+func Prepare(ctx context.Context, cmd *exec.Cmd, r objects.Reader, opts *Options) (cleanup func() error, err error) {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	cleanup = func() error { return nil }
+
+	if opts.Env {
+		env, err := objects.ExportEnv(ctx, r, opts.EnvPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+
+		cmd.Env = append(cmd.Env, env...)
+	}
+
+	if opts.Codec != nil {
+		path, err := writeFile(ctx, r, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		cleanup = func() error { return os.Remove(path) }
+
+		if opts.FileEnv != "" {
+			if cmd.Env == nil {
+				cmd.Env = os.Environ()
+			}
+
+			cmd.Env = append(cmd.Env, opts.FileEnv+"="+path)
+		}
+
+		if opts.FileArg != "" {
+			for i, arg := range cmd.Args {
+				if arg == opts.FileArg {
+					cmd.Args[i] = path
+				}
+			}
+		}
+	}
+
+	return cleanup, nil
+}
+
+// Run runs cmd configured with Prepare and cleans up afterwards.
+func Run(ctx context.Context, cmd *exec.Cmd, r objects.Reader, opts *Options) error {
+	cleanup, err := Prepare(ctx, cmd, r, opts)
+	if err != nil {
+		return err
+	}
+
+	return types.Close(ctx, cmd.Run(), closerFunc(cleanup))
+}
+
+func writeFile(ctx context.Context, r objects.Reader, opts *Options) (string, error) {
+	var m = make(types.Map)
+
+	if err := objects.Copy(ctx, m, r); err != nil {
+		return "", err
+	}
+
+	p, err := opts.Codec.Marshal(m)
+	if err != nil {
+		return "", &objects.Error{
+			Op:  "Marshal",
+			Got: m,
+			Err: err,
+		}
+	}
+
+	f, err := os.CreateTemp("", opts.Pattern)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := f.Write(p); err != nil {
+		return "", types.Close(ctx, err, f, removeFunc(f.Name()))
+	}
+
+	if err := f.Close(); err != nil {
+		return "", types.Close(ctx, err, removeFunc(f.Name()))
+	}
+
+	return f.Name(), nil
+}
+
+type closerFunc func() error
+
+func (fn closerFunc) Close() error {
+	return fn()
+}
+
+func removeFunc(path string) closerFunc {
+	return func() error { return os.Remove(path) }
+}
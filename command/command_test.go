@@ -0,0 +1,59 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/command"
+	"rafal.dev/objects/types"
+)
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	var (
+		buf  bytes.Buffer
+		path string
+		cmd  = exec.Command("sh", "-c", `echo "$APP_DB__HOST"; echo "$1"; cat "$CONFIG"`, "sh", "{config}")
+		m    = types.Map{"db": types.Map{"host": "localhost"}}
+		opts = &command.Options{
+			Env:       true,
+			EnvPrefix: "APP_",
+			Codec:     codec.JSON,
+			Pattern:   "config-*.json",
+			FileEnv:   "CONFIG",
+			FileArg:   "{config}",
+		}
+	)
+
+	cmd.Stdout = &buf
+
+	if err := command.Run(context.Background(), cmd, m, opts); err != nil {
+		t.Fatalf("Run()=%+v", err)
+	}
+
+	lines := strings.SplitN(buf.String(), "\n", 3)
+
+	if got, want := lines[0], "localhost"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if path = lines[1]; !strings.HasSuffix(path, ".json") {
+		t.Fatalf("got %q, want a .json file", path)
+	}
+
+	if got, want := lines[2], "{\n\t\"db\": {\n\t\t\"host\": \"localhost\"\n\t}\n}"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("got %+v, want %s removed", err, path)
+	}
+}
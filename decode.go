@@ -0,0 +1,494 @@
+package objects
+
+import (
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+var DefaultDecodeOptions = &DecodeOptions{
+	Options: DefaultOptions,
+}
+
+type DecodeOptions struct {
+	*Options
+
+	// ErrorOnUnused reports keys present in the reader, which
+	// have no corresponding field in the destination struct.
+	ErrorOnUnused bool
+
+	// Required lists dot-separated paths of keys, which must be
+	// present in the reader, see Require.
+	Required []string
+}
+
+var (
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType    = reflect.TypeOf(time.Duration(0))
+	bytesType       = reflect.TypeOf([]byte(nil))
+)
+
+// Decode decodes the tree of r into the value pointed to by v.
+// All errors are reported, each with the full path of its key.
+// Decoded values are checked with `validate` tags of struct fields
+// and with Validate of Validators.
+func Decode(ctx context.Context, r Reader, v any, opts *DecodeOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &Error{
+			Op:   "Decode",
+			Got:  v,
+			Want: "non-nil pointer",
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	d := newDecoder(opts)
+	d.require(ctx, r)
+	d.decode(ctx, r, rv, nil)
+	d.positions(ctx, r)
+
+	return d.errs.Err()
+}
+
+// DecodeAll decodes subtrees of r into multiple values, each pointed
+// to by the value of its key, e.g. "server" or "db.primary".
+// Missing subtrees are skipped and errors of all values are reported.
+func DecodeAll(ctx context.Context, r Reader, vs map[string]any, opts *DecodeOptions) error {
+	var (
+		d    = newDecoder(opts)
+		keys = make([]string, 0, len(vs))
+	)
+
+	d.require(ctx, r)
+
+	for k := range vs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var (
+			key = Key(strings.Split(k, "."))
+			rv  = reflect.ValueOf(vs[k])
+		)
+
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			d.errs = append(d.errs, &Error{
+				Op:   "Decode",
+				Key:  key,
+				Got:  vs[k],
+				Want: "non-nil pointer",
+				Err:  ErrUnexpectedType,
+			})
+			continue
+		}
+
+		v, err := PrefixedReader{Key: key.Dir(), R: r}.SafeGet(ctx, key.Base())
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			d.errs = append(d.errs, err)
+			continue
+		}
+
+		d.decode(ctx, tryMake(v), rv, key)
+	}
+
+	d.positions(ctx, r)
+
+	return d.errs.Err()
+}
+
+type decoder struct {
+	opts *DecodeOptions
+	errs types.Errors
+}
+
+// newDecoder creates a decoder, which uses DefaultDecodeOptions if
+// opts is nil and DefaultOptions if opts has no Options.
+func newDecoder(opts *DecodeOptions) *decoder {
+	if opts == nil {
+		opts = DefaultDecodeOptions
+	}
+
+	if opts.Options == nil || opts.StructField == nil {
+		o := *opts
+		o.Options = DefaultOptions
+		opts = &o
+	}
+
+	return &decoder{opts: opts}
+}
+
+func (d *decoder) decode(ctx context.Context, src any, dst reflect.Value, key Key) {
+	n := len(d.errs)
+
+	defer func() {
+		if len(d.errs) == n {
+			d.validate(dst, key)
+		}
+	}()
+
+	if reflect.PtrTo(dst.Type()).Implements(textUnmarshaler) {
+		if s, ok := src.(string); ok {
+			if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+				d.error(key, src, dst, err)
+			}
+			return
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		d.decode(ctx, src, dst.Elem(), key)
+		return
+	case reflect.Interface:
+		if dst.NumMethod() == 0 {
+			if v := d.plain(ctx, src, key); v != nil {
+				dst.Set(reflect.ValueOf(v))
+			}
+			return
+		}
+	}
+
+	r, ok := src.(Reader)
+	if !ok {
+		d.scalar(src, dst, key)
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		d.structure(ctx, r, dst, key)
+	case reflect.Map:
+		d.mapping(ctx, r, dst, key)
+	case reflect.Slice, reflect.Array:
+		d.slice(ctx, r, dst, key)
+	default:
+		d.error(key, src, dst, ErrUnexpectedType)
+	}
+}
+
+func (d *decoder) structure(ctx context.Context, r Reader, dst reflect.Value, key Key) {
+	var (
+		used = make(map[string]struct{})
+		keys = d.list(ctx, r, key)
+	)
+
+	d.fields(ctx, r, dst, key, used)
+
+	if !d.opts.ErrorOnUnused {
+		return
+	}
+
+	for _, k := range keys {
+		if _, ok := used[k]; !ok {
+			d.errs = append(d.errs, &Error{
+				Op:  "Decode",
+				Key: append(key.Copy(), k),
+				Err: ErrUnused,
+			})
+		}
+	}
+}
+
+func (d *decoder) fields(ctx context.Context, r Reader, dst reflect.Value, key Key, used map[string]struct{}) {
+	t := dst.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f    = t.Field(i)
+			name = d.opts.StructField(f)
+		)
+
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == f.Name && f.Type.Kind() == reflect.Struct {
+			d.fields(ctx, r, dst.Field(i), key, used)
+			continue
+		}
+
+		used[name] = struct{}{}
+
+		k := append(key.Copy(), name)
+
+		v, err := types.PrefixedReader{R: r}.SafeGet(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			d.rules(f, dst.Field(i), k, false)
+			continue
+		}
+
+		if err != nil {
+			d.errs = append(d.errs, err)
+			continue
+		}
+
+		n := len(d.errs)
+
+		d.decode(ctx, tryMake(v), dst.Field(i), k)
+
+		if len(d.errs) == n {
+			d.rules(f, dst.Field(i), k, true)
+		}
+	}
+}
+
+func (d *decoder) mapping(ctx context.Context, r Reader, dst reflect.Value, key Key) {
+	t := dst.Type()
+
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(t))
+	}
+
+	for _, k := range d.list(ctx, r, key) {
+		v, ok := r.Get(ctx, k)
+		if !ok {
+			continue
+		}
+
+		mk, err := (&Map{v: dst}).key("Decode", k)
+		if err != nil {
+			d.error(append(key.Copy(), k), k, reflect.New(t.Key()).Elem(), ErrUnexpectedType)
+			continue
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		d.decode(ctx, tryMake(v), elem, append(key.Copy(), k))
+		dst.SetMapIndex(mk, elem)
+	}
+}
+
+func (d *decoder) slice(ctx context.Context, r Reader, dst reflect.Value, key Key) {
+	n := len(d.list(ctx, r, key))
+
+	if dst.Kind() == reflect.Slice {
+		dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+	} else if n > dst.Len() {
+		d.error(key, r, dst, ErrOutOfBounds)
+		n = dst.Len()
+	}
+
+	for i := 0; i < n; i++ {
+		k := strconv.Itoa(i)
+
+		v, ok := r.Get(ctx, k)
+		if !ok {
+			continue
+		}
+
+		d.decode(ctx, tryMake(v), dst.Index(i), append(key.Copy(), k))
+	}
+}
+
+func (d *decoder) scalar(src any, dst reflect.Value, key Key) {
+	if src == nil {
+		return
+	}
+
+	var (
+		v = reflect.ValueOf(src)
+		t = dst.Type()
+	)
+
+	if s, ok := text(src); ok && t.Kind() != reflect.String {
+		var err error
+		if v, err = parse(s, t); err != nil {
+			d.error(key, src, dst, err)
+			return
+		}
+	}
+
+	switch {
+	case v.Type().AssignableTo(t):
+		dst.Set(v)
+	case convertible(v, t):
+		dst.Set(v.Convert(t))
+	default:
+		d.error(key, src, dst, ErrUnexpectedType)
+	}
+}
+
+func (d *decoder) plain(ctx context.Context, src any, key Key) any {
+	r, ok := src.(Reader)
+	if !ok {
+		return src
+	}
+
+	if r.Type() == TypeSlice {
+		var s []any
+		d.decode(ctx, r, reflect.ValueOf(&s).Elem(), key)
+		return s
+	}
+
+	var m map[string]any
+	d.decode(ctx, r, reflect.ValueOf(&m).Elem(), key)
+	return m
+}
+
+func (d *decoder) list(ctx context.Context, r Reader, key Key) []string {
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		d.errs = append(d.errs, &Error{
+			Op:  "Decode",
+			Key: key,
+			Err: err,
+		})
+	}
+	return keys
+}
+
+func (d *decoder) require(ctx context.Context, r Reader) {
+	if err := Require(ctx, r, d.opts.Required...); err != nil {
+		d.errs = append(d.errs, err.(Errors)...)
+	}
+}
+
+// positions sets source positions of errors, if r knows them.
+func (d *decoder) positions(ctx context.Context, r Reader) {
+	for _, err := range d.errs {
+		if e, ok := err.(*Error); ok && len(e.Key) != 0 {
+			e.Position, _ = types.PositionOf(ctx, r, e.Key)
+		}
+	}
+}
+
+func (d *decoder) error(key Key, src any, dst reflect.Value, err error) {
+	d.errs = append(d.errs, &Error{
+		Op:   "Decode",
+		Key:  key,
+		Got:  src,
+		Want: dst.Type().String(),
+		Err:  err,
+	})
+}
+
+// text gives the string of src, if it is a string or a number kept
+// in its text form, e.g. a json.Number.
+func text(src any) (string, bool) {
+	switch s := src.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	default:
+		return "", false
+	}
+}
+
+func parse(s string, t reflect.Type) (reflect.Value, error) {
+	switch k := t.Kind(); {
+	case t == bytesType:
+		p, err := base64.StdEncoding.DecodeString(s)
+		return reflect.ValueOf(p), err
+	case t == durationType:
+		d, err := time.ParseDuration(s)
+		return reflect.ValueOf(d), err
+	case k == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		return reflect.ValueOf(b), err
+	case k >= reflect.Int && k <= reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, t.Bits())
+		return reflect.ValueOf(n), err
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, t.Bits())
+		return reflect.ValueOf(n), err
+	case k == reflect.Float32 || k == reflect.Float64:
+		n, err := strconv.ParseFloat(s, t.Bits())
+		return reflect.ValueOf(n), err
+	default:
+		return reflect.ValueOf(s), nil
+	}
+}
+
+func convertible(v reflect.Value, t reflect.Type) bool {
+	if !v.Type().ConvertibleTo(t) {
+		return false
+	}
+
+	switch vk, tk := v.Kind(), t.Kind(); {
+	case number(vk) && number(tk):
+		return fits(v, t)
+	case vk == tk:
+		return true
+	default:
+		return false
+	}
+}
+
+// fits reports whether the number v converted to t keeps its value,
+// i.e. it is in range of t and is not truncated.
+func fits(v reflect.Value, t reflect.Type) bool {
+	var (
+		z  = reflect.New(t).Elem()
+		tk = t.Kind()
+	)
+
+	switch vk := v.Kind(); {
+	case signed(vk):
+		n := v.Int()
+
+		switch {
+		case signed(tk):
+			return !z.OverflowInt(n)
+		case unsigned(tk):
+			return n >= 0 && !z.OverflowUint(uint64(n))
+		default:
+			return !z.OverflowFloat(float64(n))
+		}
+	case unsigned(vk):
+		n := v.Uint()
+
+		switch {
+		case signed(tk):
+			return n <= math.MaxInt64 && !z.OverflowInt(int64(n))
+		case unsigned(tk):
+			return !z.OverflowUint(n)
+		default:
+			return !z.OverflowFloat(float64(n))
+		}
+	default:
+		f := v.Float()
+
+		switch {
+		case !signed(tk) && !unsigned(tk):
+			return !z.OverflowFloat(f)
+		case f != math.Trunc(f):
+			return false
+		case signed(tk):
+			return f >= math.MinInt64 && f < math.MaxInt64 && !z.OverflowInt(int64(f))
+		default:
+			return f >= 0 && f < math.MaxUint64 && !z.OverflowUint(uint64(f))
+		}
+	}
+}
+
+func signed(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func unsigned(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func number(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
@@ -0,0 +1,252 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Server struct {
+	Host    string        `json:"host"`
+	Port    int           `json:"port"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+type Config struct {
+	Server   Server            `json:"server"`
+	Backends []string          `json:"backends"`
+	Labels   map[string]string `json:"labels"`
+	Extra    any               `json:"extra"`
+}
+
+func TestDecode(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{
+				"host":    "localhost",
+				"port":    float64(8080),
+				"timeout": "30s",
+			},
+			"backends": &types.Slice{"a", "b"},
+			"labels": types.Map{
+				"env": "prod",
+			},
+			"extra": types.Map{
+				"list": &types.Slice{1, 2},
+			},
+		}
+		want = Config{
+			Server: Server{
+				Host:    "localhost",
+				Port:    8080,
+				Timeout: 30 * time.Second,
+			},
+			Backends: []string{"a", "b"},
+			Labels:   map[string]string{"env": "prod"},
+			Extra:    map[string]any{"list": []any{1, 2}},
+		}
+		got Config
+	)
+
+	if err := objects.Decode(ctx, m, &got, nil); err != nil {
+		t.Fatalf("Decode()=%+v", err)
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestDecodeErrorOnUnused(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{
+				"host": "localhost",
+				"prot": 8080,
+			},
+			"backend": &types.Slice{"a"},
+		}
+		opts = &objects.DecodeOptions{
+			Options:       objects.DefaultOptions,
+			ErrorOnUnused: true,
+		}
+		cfg Config
+	)
+
+	err := objects.Decode(ctx, m, &cfg, opts)
+	if !errors.Is(err, objects.ErrUnused) {
+		t.Fatalf("got %+v, want %+v", err, objects.ErrUnused)
+	}
+
+	var got []string
+
+	for _, err := range err.(objects.Errors) {
+		got = append(got, types.Key(err.(*objects.Error).Key).String())
+	}
+
+	if want := []string{"server.prot", "backend"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if cfg.Server.Host != "localhost" {
+		t.Fatalf("got %q, want %q", cfg.Server.Host, "localhost")
+	}
+}
+
+func TestDecodeZeroOptions(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{"host": "localhost", "port": 8080},
+		}
+		cfg Config
+	)
+
+	if err := objects.Decode(ctx, m, &cfg, &objects.DecodeOptions{}); err != nil {
+		t.Fatalf("Decode()=%+v", err)
+	}
+
+	if want := (Server{Host: "localhost", Port: 8080}); cfg.Server != want {
+		t.Fatalf("got %+v, want %+v", cfg.Server, want)
+	}
+}
+
+func TestDecodeOverflow(t *testing.T) {
+	type Limits struct {
+		U16 uint16  `json:"u16"`
+		U8  uint8   `json:"u8"`
+		I8  int8    `json:"i8"`
+		F32 float32 `json:"f32"`
+	}
+
+	ctx := context.Background()
+
+	cases := map[string]any{
+		"u16": 70000,
+		"u8":  -1,
+		"i8":  200.0,
+		"f32": 1e300,
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(t *testing.T) {
+			var l Limits
+
+			err := objects.Decode(ctx, types.Map{k: v}, &l, nil)
+			if !errors.Is(err, objects.ErrUnexpectedType) {
+				t.Fatalf("Decode()=%+v, want %v", err, objects.ErrUnexpectedType)
+			}
+
+			_, err = objects.MakeStruct(&l, nil).SafeSet(ctx, k, v)
+			if !errors.Is(err, objects.ErrUnexpectedType) {
+				t.Fatalf("SafeSet()=%+v, want %v", err, objects.ErrUnexpectedType)
+			}
+
+			if l != (Limits{}) {
+				t.Fatalf("got %+v, want zero value", l)
+			}
+		})
+	}
+
+	var l Limits
+
+	if err := objects.Decode(ctx, types.Map{"u16": 65535, "u8": uint64(255), "i8": -128.0}, &l, nil); err != nil {
+		t.Fatalf("Decode()=%+v", err)
+	}
+
+	if want := (Limits{U16: 65535, U8: 255, I8: -128}); l != want {
+		t.Fatalf("got %+v, want %+v", l, want)
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{
+				"host": "localhost",
+				"port": "8080",
+			},
+			"db": types.Map{
+				"primary": types.Map{
+					"host": "db",
+					"port": "abc",
+				},
+			},
+		}
+		srv, db Server
+		missing Config
+	)
+
+	err := objects.DecodeAll(ctx, m, map[string]any{
+		"server":     &srv,
+		"db.primary": &db,
+		"missing":    &missing,
+	}, nil)
+
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("got %+v, want %+v", err, strconv.ErrSyntax)
+	}
+
+	if want := (Server{Host: "localhost", Port: 8080}); srv != want {
+		t.Fatalf("got %+v, want %+v", srv, want)
+	}
+
+	if want := (Server{Host: "db"}); db != want {
+		t.Fatalf("got %+v, want %+v", db, want)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"raw":    []byte{0, 1, 2, 255},
+			"base64": "AAEC/w==",
+			"text":   "not base64!",
+			"int":    1,
+		}
+		want = []byte{0, 1, 2, 255}
+	)
+
+	for _, key := range []string{"raw", "base64"} {
+		p, err := objects.Bytes(ctx, m, key)
+		if err != nil {
+			t.Fatalf("Bytes(%q)=%+v", key, err)
+		}
+
+		if !cmp.Equal(p, want) {
+			t.Fatalf("got != want:\n%s", cmp.Diff(p, want))
+		}
+	}
+
+	if _, err := objects.Bytes(ctx, m, "text"); err == nil {
+		t.Fatal("expected Bytes to fail on non-base64 string")
+	}
+
+	if _, err := objects.Bytes(ctx, m, "int"); !errors.Is(err, objects.ErrUnexpectedType) {
+		t.Fatalf("got %+v, want %+v", err, objects.ErrUnexpectedType)
+	}
+
+	var v struct {
+		Raw    []byte `json:"raw"`
+		Base64 []byte `json:"base64"`
+	}
+
+	if err := objects.Decode(ctx, m, &v, &objects.DecodeOptions{Options: objects.DefaultOptions}); err != nil {
+		t.Fatalf("Decode()=%+v", err)
+	}
+
+	if !cmp.Equal(v.Raw, want) || !cmp.Equal(v.Base64, want) {
+		t.Fatalf("got %v, %v, want %v", v.Raw, v.Base64, want)
+	}
+}
@@ -0,0 +1,96 @@
+package objects
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+type DumpOptions struct {
+	// MaxValueLen truncates string and []byte leaves longer than
+	// it, see Truncate. Leaves are not truncated if it is 0.
+	MaxValueLen int
+
+	// Layers annotates each leaf with the layer, which supplies it,
+	// and its source position, see Origin.
+	Layers []Layer
+}
+
+var DefaultDumpOptions = &DumpOptions{
+	MaxValueLen: 256,
+}
+
+// Dump writes leaves of r to w, one "key.path: value" line per leaf.
+func Dump(ctx context.Context, w io.Writer, r Reader, opts *DumpOptions) error {
+	if opts == nil {
+		opts = DefaultDumpOptions
+	}
+
+	it := Walk(r)
+
+	for it.Next(ctx) {
+		if !it.Leaf() {
+			continue
+		}
+
+		v := it.Value()
+
+		if opts.MaxValueLen != 0 {
+			v = Truncate(v, opts.MaxValueLen)
+		}
+
+		var suffix string
+
+		if opts.Layers != nil {
+			s, err := origin(ctx, opts.Layers, it.Key())
+			if err != nil {
+				return err
+			}
+
+			if s != "" {
+				suffix = " (" + s + ")"
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %v%s\n", it.Key(), v, suffix); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// Truncate shortens string and []byte values longer than n bytes,
+// appending their length and a hash of the whole content, e.g.
+// "abc... (len=1024, sha256=ba7816bf8f01)". Strings are not cut
+// in the middle of a UTF-8 encoded rune. Other values are returned
+// unchanged.
+func Truncate(v any, n int) any {
+	var p []byte
+
+	switch v := v.(type) {
+	case string:
+		if len(v) <= n {
+			return v
+		}
+		p = []byte(v)
+
+		for n > 0 && !utf8.RuneStart(p[n]) {
+			n--
+		}
+	case []byte:
+		if len(v) <= n {
+			return v
+		}
+		p = v
+	default:
+		return v
+	}
+
+	sum := sha256.Sum256(p)
+
+	return fmt.Sprintf("%s... (len=%d, sha256=%s)", p[:n], len(p), hex.EncodeToString(sum[:6]))
+}
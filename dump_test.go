@@ -0,0 +1,117 @@
+package objects_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDump(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		golden = filepath.Join("testdata", "abcd.flat.yaml.golden")
+	)
+
+	if err := objects.Dump(context.Background(), &buf, objects.Make(newX()), nil); err != nil {
+		t.Fatalf("Dump()=%+v", err)
+	}
+
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("WriteFile()=%+v", err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("ReadFile()=%+v", err)
+	}
+
+	if got := buf.String(); !cmp.Equal(got, string(want)) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, string(want)))
+	}
+}
+
+func TestDumpTruncate(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		m   = types.Map{
+			"blob": []byte(strings.Repeat("x", 100)),
+			"name": "abcd",
+		}
+		opts = &objects.DumpOptions{MaxValueLen: 4}
+		want = "blob: xxxx... (len=100, sha256=09ecb6ebc8bc)\nname: abcd\n"
+	)
+
+	if err := objects.Dump(context.Background(), &buf, m, opts); err != nil {
+		t.Fatalf("Dump()=%+v", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	s := objects.Truncate("zażółć", 3).(string)
+
+	if want := "za... (len=10, "; !strings.HasPrefix(s, want) {
+		t.Fatalf("got %q, want %q prefix", s, want)
+	}
+
+	if !utf8.ValidString(s) {
+		t.Fatalf("got invalid UTF-8 %q", s)
+	}
+}
+
+type positioned struct {
+	types.Map
+}
+
+func (positioned) Position(key types.Key) (types.Position, bool) {
+	return types.Position{File: "config.yaml", Line: len(key), Column: 1}, true
+}
+
+func TestDumpLayers(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		ctx    = context.Background()
+		flags  = types.Map{"port": "9090"}
+		env    = types.Map{"db": types.Map{"host": "db.local"}}
+		file   = positioned{types.Map{"port": "8080", "db": types.Map{"host": "localhost", "name": "app"}}}
+		layers = []objects.Layer{{"flag", flags}, {"env", env}, {"file", file}}
+		merged = types.Map{"port": "9090", "db": types.Map{"host": "db.local", "name": "app"}}
+		want   = "db.host: db.local (env)\ndb.name: app (file config.yaml:2:1)\nport: 9090 (flag)\n"
+	)
+
+	l, err := objects.Origin(ctx, layers, "db", "host")
+	if err != nil {
+		t.Fatalf("Origin()=%+v", err)
+	}
+
+	if l.Name != "env" {
+		t.Fatalf("got %q, want %q", l.Name, "env")
+	}
+
+	if _, err := objects.Origin(ctx, layers, "db", "user"); !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Origin()=%+v, want %v", err, objects.ErrNotFound)
+	}
+
+	if err := objects.Dump(ctx, &buf, merged, &objects.DumpOptions{Layers: layers}); err != nil {
+		t.Fatalf("Dump()=%+v", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,221 @@
+package objects
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ExportEnv flattens leaves of r into KEY=VALUE pairs suitable for
+// exec.Cmd.Env. A variable name is the prefix followed by the uppercased
+// key segments joined with "__", where characters not allowed in
+// variable names are replaced with "_". Values are passed verbatim
+// to the process, thus they are not shell-quoted; a value containing
+// a NUL byte, which cannot be represented, is an error.
+func ExportEnv(ctx context.Context, r Reader, prefix string) ([]string, error) {
+	var (
+		env []string
+		it  = Walk(r)
+	)
+
+	for it.Next(ctx) {
+		if !it.Leaf() {
+			continue
+		}
+
+		var (
+			key   = it.Key()
+			value = envValue(it.Value())
+		)
+
+		if strings.IndexByte(value, 0) != -1 {
+			return nil, &Error{
+				Op:  "ExportEnv",
+				Key: key,
+				Got: it.Value(),
+				Err: fmt.Errorf("value contains NUL byte"),
+			}
+		}
+
+		env = append(env, prefix+envName(key)+"="+value)
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(env)
+
+	return env, nil
+}
+
+func envName(key Key) string {
+	var parts = make([]string, 0, len(key))
+
+	for _, k := range key {
+		parts = append(parts, strings.Map(envRune, strings.ToUpper(k)))
+	}
+
+	return strings.Join(parts, "__")
+}
+
+func envRune(r rune) rune {
+	switch {
+	case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+		return r
+	default:
+		return '_'
+	}
+}
+
+func envValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type EnvOptions struct {
+	// Prefix is stripped from names of variables; variables
+	// without it are ignored.
+	Prefix string
+
+	// Separator splits variable names into key segments.
+	Separator string
+
+	// Environ gives variables as KEY=VALUE pairs; os.Environ is
+	// used if nil.
+	Environ func() []string
+}
+
+var DefaultEnvOptions = &EnvOptions{
+	Separator: "_",
+}
+
+// EnvReader reads environment variables as a tree, e.g. with "APP_"
+// prefix and "_" separator the APP_DB_HOST variable is read as the
+// db.host key. Key segments are lowercase. A segment, which is both
+// a variable and a prefix of other ones, is read as a subtree.
+type EnvReader struct {
+	vars map[string]string
+	sep  string
+	name string
+}
+
+var (
+	_ Reader     = (*EnvReader)(nil)
+	_ SafeReader = (*EnvReader)(nil)
+	_ SafeLister = (*EnvReader)(nil)
+)
+
+// ReadEnv creates a reader of environment variables, which are
+// read once on creation.
+func ReadEnv(opts *EnvOptions) *EnvReader {
+	if opts == nil {
+		opts = DefaultEnvOptions
+	}
+
+	var (
+		environ = opts.Environ
+		sep     = opts.Separator
+		vars    = make(map[string]string)
+	)
+
+	if environ == nil {
+		environ = os.Environ
+	}
+
+	if sep == "" {
+		sep = DefaultEnvOptions.Separator
+	}
+
+	for _, kv := range environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, opts.Prefix) {
+			continue
+		}
+
+		if name = strings.TrimPrefix(name, opts.Prefix); name != "" {
+			vars[strings.ToLower(name)] = value
+		}
+	}
+
+	return &EnvReader{
+		vars: vars,
+		sep:  sep,
+	}
+}
+
+func (e *EnvReader) Type() Type {
+	return TypeMap
+}
+
+func (e *EnvReader) Get(ctx context.Context, key string) (any, bool) {
+	v, err := e.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (e *EnvReader) SafeGet(ctx context.Context, key string) (any, error) {
+	name := e.name + strings.ToLower(key)
+
+	if key != "" {
+		for k := range e.vars {
+			if strings.HasPrefix(k, name+e.sep) {
+				return &EnvReader{
+					vars: e.vars,
+					sep:  e.sep,
+					name: name + e.sep,
+				}, nil
+			}
+		}
+
+		if v, ok := e.vars[name]; ok {
+			return v, nil
+		}
+	}
+
+	return nil, &Error{
+		Op:  "Get",
+		Key: []string{key},
+		Err: ErrNotFound,
+	}
+}
+
+func (e *EnvReader) List(ctx context.Context) []string {
+	keys, _ := e.SafeList(ctx)
+	return keys
+}
+
+func (e *EnvReader) SafeList(ctx context.Context) ([]string, error) {
+	var (
+		keys []string
+		seen = make(map[string]struct{})
+	)
+
+	for k := range e.vars {
+		if !strings.HasPrefix(k, e.name) {
+			continue
+		}
+
+		k, _, _ = strings.Cut(k[len(e.name):], e.sep)
+
+		if _, ok := seen[k]; !ok && k != "" {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
@@ -0,0 +1,94 @@
+package objects_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExportEnv(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"db": types.Map{
+				"host":      "localhost",
+				"port":      5432,
+				"read-only": true,
+			},
+			"hosts":   types.Slice{"a", "b"},
+			"message": "hello \"world\"\nbye",
+		}
+	)
+
+	got, err := objects.ExportEnv(ctx, m, "APP_")
+	if err != nil {
+		t.Fatalf("ExportEnv()=%+v", err)
+	}
+
+	want := []string{
+		"APP_DB__HOST=localhost",
+		"APP_DB__PORT=5432",
+		"APP_DB__READ_ONLY=true",
+		"APP_HOSTS__0=a",
+		"APP_HOSTS__1=b",
+		"APP_MESSAGE=hello \"world\"\nbye",
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if _, err := objects.ExportEnv(ctx, types.Map{"nul": "a\x00b"}, ""); err == nil {
+		t.Fatal("expected ExportEnv() to fail")
+	}
+}
+
+func TestReadEnv(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		opts = &objects.EnvOptions{
+			Prefix:    "APP_",
+			Separator: "_",
+			Environ: func() []string {
+				return []string{
+					"APP_DB_HOST=localhost",
+					"APP_DB_PORT=5432",
+					"APP_DEBUG=true",
+					"APP_=ignored",
+					"HOME=/root",
+				}
+			},
+		}
+		env = objects.ReadEnv(opts)
+	)
+
+	got := make(types.Map)
+
+	if err := objects.Copy(ctx, got, env); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	want := types.Map{
+		"db": types.Map{
+			"host": "localhost",
+			"port": "5432",
+		},
+		"debug": "true",
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, err := objects.Get(ctx, env, "db", "host"); err != nil || v != "localhost" {
+		t.Fatalf("Get()=%v, %+v", v, err)
+	}
+
+	if _, err := objects.Get(ctx, env, "home"); err == nil {
+		t.Fatal("expected Get() to fail")
+	}
+}
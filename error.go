@@ -8,8 +8,17 @@ var (
 	ErrNotFound       = types.ErrNotFound
 	ErrNotDone        = types.ErrNotDone
 	ErrUnexpectedType = types.ErrUnexpectedType
+	ErrClosed         = types.ErrClosed
+	ErrQueueFull      = types.ErrQueueFull
+	ErrConflict       = types.ErrConflict
+	ErrReadOnly       = types.ErrReadOnly
+	ErrUnused         = types.ErrUnused
+	ErrTooLarge       = types.ErrTooLarge
+	ErrQuotaExceeded  = types.ErrQuotaExceeded
+	ErrInvalid        = types.ErrInvalid
 )
 
 type (
-	Error = types.Error
+	Error  = types.Error
+	Errors = types.Errors
 )
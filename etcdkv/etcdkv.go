@@ -0,0 +1,120 @@
+package etcdkv
+
+import (
+	"context"
+	"strings"
+
+	"rafal.dev/objects/kv"
+	"rafal.dev/objects/types"
+)
+
+const Sep = "/"
+
+// KV is the subset of an etcd client used by the backend, e.g. an
+// adapter over clientv3.KV. Get with prefix set is a range request
+// over the prefix (clientv3.WithPrefix); Put gives the previous
+// key-value (clientv3.WithPrevKV) or nil if there was none; Delete
+// gives the number of deleted keys.
+type KV interface {
+	Get(ctx context.Context, key string, prefix bool) ([]*KeyValue, error)
+	Put(ctx context.Context, key string, value []byte) (prev *KeyValue, err error)
+	Delete(ctx context.Context, key string) (deleted int64, err error)
+}
+
+type KeyValue struct {
+	Key         string
+	Value       []byte
+	ModRevision int64
+}
+
+type store struct {
+	kv     KV
+	prefix string
+}
+
+var _ kv.Store = store{}
+
+// New gives a tree of keys stored under the prefix, e.g. "/app/".
+// Errors of the client are kept as causes of the errors given
+// by the tree.
+func New(c KV, prefix string) *kv.Tree {
+	return kv.New(store{kv: c, prefix: prefix}, Sep)
+}
+
+func (s store) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	kvs, err := s.kv.Get(ctx, s.prefix+key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kvs) == 0 {
+		return nil, types.ErrNotFound
+	}
+
+	return kvs[0].Value, nil
+}
+
+func (s store) Set(ctx context.Context, key string, value []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	prev, err := s.kv.Put(ctx, s.prefix+key, value)
+	if err != nil {
+		return false, err
+	}
+
+	return prev != nil, nil
+}
+
+func (s store) Del(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	n, err := s.kv.Delete(ctx, s.prefix+key)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	kvs, err := s.kv.Get(ctx, s.prefix+prefix, true)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(kvs))
+
+	for _, kv := range kvs {
+		keys = append(keys, strings.TrimPrefix(kv.Key, s.prefix))
+	}
+
+	return keys, nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	return types.Ping(ctx, s.kv)
+}
+
+func (s store) Close(ctx context.Context) error {
+	return types.Close(ctx, s.kv)
+}
+
+func (s store) Unwrap() any {
+	return s.kv
+}
@@ -0,0 +1,128 @@
+package etcdkv_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/etcdkv"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type client struct {
+	kvs map[string][]byte
+	err error
+}
+
+func (c *client) Get(_ context.Context, key string, prefix bool) ([]*etcdkv.KeyValue, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	var kvs []*etcdkv.KeyValue
+
+	for k, v := range c.kvs {
+		if k == key || (prefix && strings.HasPrefix(k, key)) {
+			kvs = append(kvs, &etcdkv.KeyValue{Key: k, Value: v})
+		}
+	}
+
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+	return kvs, nil
+}
+
+func (c *client) Put(_ context.Context, key string, value []byte) (*etcdkv.KeyValue, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	var prev *etcdkv.KeyValue
+
+	if v, ok := c.kvs[key]; ok {
+		prev = &etcdkv.KeyValue{Key: key, Value: v}
+	}
+
+	c.kvs[key] = value
+
+	return prev, nil
+}
+
+func (c *client) Delete(_ context.Context, key string) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	if _, ok := c.kvs[key]; !ok {
+		return 0, nil
+	}
+
+	delete(c.kvs, key)
+
+	return 1, nil
+}
+
+func TestNew(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = &client{kvs: map[string][]byte{"/other/key": []byte("x")}}
+		tr  = etcdkv.New(c, "/app/")
+		src = types.Map{
+			"db": types.Map{
+				"host": "localhost",
+			},
+			"debug": "true",
+		}
+	)
+
+	if err := objects.Copy(ctx, tr, src); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	want := map[string][]byte{
+		"/app/db/host": []byte("localhost"),
+		"/app/debug":   []byte("true"),
+		"/other/key":   []byte("x"),
+	}
+
+	if !cmp.Equal(c.kvs, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(c.kvs, want))
+	}
+
+	keys, err := objects.List(ctx, tr)
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"db", "debug"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	unavailable := errors.New("etcdserver: leader changed")
+	c.err = unavailable
+
+	if _, err := objects.Get(ctx, tr, "debug"); !errors.Is(err, unavailable) {
+		t.Fatalf("Get()=%+v, want %v", err, unavailable)
+	}
+
+	c.err = nil
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := objects.Set(ctx, tr, "false", "debug"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Set()=%+v, want %v", err, context.Canceled)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(*testing.T) types.Interface {
+		return etcdkv.New(&client{kvs: make(map[string][]byte)}, "/app/")
+	})
+}
@@ -0,0 +1,128 @@
+// Package experiment assigns units, like users or requests, to variants
+// of experiments configured in a tree.
+//
+// Assignments are deterministic: a unit gets the same variant of an
+// experiment in every service reading the same configuration.
+package experiment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+
+	"rafal.dev/objects"
+)
+
+// Experiment is the configuration of an experiment, e.g.
+//
+//	checkout:
+//	  salt: v2
+//	  variants:
+//	    - name: control
+//	      weight: 90
+//	    - name: one-click
+//	      weight: 10
+type Experiment struct {
+	// Salt changes the assignment of all units, e.g. when restarting
+	// the experiment.
+	Salt     string    `json:"salt"`
+	Variants []Variant `json:"variants"`
+}
+
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// Exposure is an assignment of a unit to a variant.
+type Exposure struct {
+	Experiment string
+	Unit       string
+	Variant    string
+}
+
+// Bucket hashes the key and the unit id into one of n buckets.
+func Bucket(key, unit string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	sum := sha256.Sum256([]byte(key + "\x00" + unit))
+
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(n))
+}
+
+// Assign gives the variant of the experiment of the key for the unit,
+// with chances proportional to weights of the variants. It is false
+// if no variant has a positive weight.
+func (e *Experiment) Assign(key, unit string) (Variant, bool) {
+	var total int
+
+	for _, v := range e.Variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+
+	if total == 0 {
+		return Variant{}, false
+	}
+
+	n := Bucket(key+"\x00"+e.Salt, unit, total)
+
+	for _, v := range e.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+
+		if n < v.Weight {
+			return v, true
+		}
+
+		n -= v.Weight
+	}
+
+	return Variant{}, false
+}
+
+// Assigner assigns units to variants of experiments read from a tree.
+type Assigner struct {
+	// R holds experiments under their keys.
+	R objects.Reader
+
+	// Expose, when non-nil, is called with each assignment, e.g. to
+	// log exposures for analysis.
+	Expose func(context.Context, Exposure)
+}
+
+// Assign gives the name of the variant of the experiment under the
+// dot-separated key for the unit. Experiments are read on each call,
+// so changes of the tree take effect right away.
+func (a *Assigner) Assign(ctx context.Context, key, unit string) (string, error) {
+	var e Experiment
+
+	if err := objects.DecodeAll(ctx, a.R, map[string]any{key: &e}, nil); err != nil {
+		return "", err
+	}
+
+	v, ok := e.Assign(key, unit)
+	if !ok {
+		return "", &objects.Error{
+			Op:  "Assign",
+			Key: strings.Split(key, "."),
+			Got: unit,
+			Err: objects.ErrNotFound,
+		}
+	}
+
+	if a.Expose != nil {
+		a.Expose(ctx, Exposure{
+			Experiment: key,
+			Unit:       unit,
+			Variant:    v.Name,
+		})
+	}
+
+	return v.Name, nil
+}
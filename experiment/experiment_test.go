@@ -0,0 +1,94 @@
+package experiment_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/experiment"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAssigner(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"experiments": types.Map{
+				"checkout": types.Map{
+					"salt": "v2",
+					"variants": &types.Slice{
+						types.Map{"name": "control", "weight": 90},
+						types.Map{"name": "one-click", "weight": 10},
+					},
+				},
+			},
+		}
+		exposures []experiment.Exposure
+		a         = &experiment.Assigner{
+			R: m,
+			Expose: func(_ context.Context, e experiment.Exposure) {
+				exposures = append(exposures, e)
+			},
+		}
+		counts = make(map[string]int)
+	)
+
+	for i := 0; i < 1000; i++ {
+		unit := "user-" + strconv.Itoa(i)
+
+		v, err := a.Assign(ctx, "experiments.checkout", unit)
+		if err != nil {
+			t.Fatalf("Assign()=%+v", err)
+		}
+
+		again, err := a.Assign(ctx, "experiments.checkout", unit)
+		if err != nil {
+			t.Fatalf("Assign()=%+v", err)
+		}
+
+		if v != again {
+			t.Fatalf("got %q, then %q for %s", v, again, unit)
+		}
+
+		counts[v]++
+	}
+
+	if n := counts["one-click"]; n < 50 || n > 150 {
+		t.Fatalf("got %d one-click assignments, want about 100", n)
+	}
+
+	if n := counts["control"] + counts["one-click"]; n != 1000 {
+		t.Fatalf("got %d assignments, want 1000", n)
+	}
+
+	want := experiment.Exposure{Experiment: "experiments.checkout", Unit: "user-0", Variant: exposures[0].Variant}
+
+	if diff := cmp.Diff(want, exposures[0]); diff != "" || len(exposures) != 2000 {
+		t.Fatalf("got %d exposures, diff (-want, +got):\n%s", len(exposures), diff)
+	}
+
+	if _, err := a.Assign(ctx, "experiments.missing", "user-0"); !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Assign()=%+v, want %v", err, objects.ErrNotFound)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	if got, want := experiment.Bucket("checkout", "user-1", 100), experiment.Bucket("checkout", "user-1", 100); got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+
+	var differ bool
+
+	for i := 0; i < 10 && !differ; i++ {
+		unit := strconv.Itoa(i)
+		differ = experiment.Bucket("a", unit, 1000) != experiment.Bucket("b", unit, 1000)
+	}
+
+	if !differ {
+		t.Fatal("want buckets to depend on the key")
+	}
+}
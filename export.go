@@ -0,0 +1,146 @@
+package objects
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+type ExportOptions struct {
+	// Layers annotates each leaf with a comment naming the layer,
+	// which supplies it, and its source position, see Origin.
+	Layers []Layer
+
+	// Expand, when non-nil, is called with every string leaf, e.g.
+	// os.ExpandEnv, and the string it returns is exported instead.
+	Expand func(string) string
+
+	// Redact, when non-nil, is called with every leaf, after Expand,
+	// and the value it returns is exported instead.
+	Redact func(key Key, value any) any
+}
+
+var DefaultExportOptions = &ExportOptions{
+	Redact: Redact,
+}
+
+// Redacted replaces values of secrets in exports.
+const Redacted = "REDACTED"
+
+var secrets = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api_key", "private"}
+
+// Redact replaces leaves, which keys name a secret, like "password"
+// or "api_key", with Redacted.
+func Redact(key Key, value any) any {
+	base := strings.ToLower(key.Base())
+
+	for _, s := range secrets {
+		if strings.Contains(base, s) {
+			return Redacted
+		}
+	}
+
+	return value
+}
+
+// Export writes the effective configuration read from r to w, as
+// "key.path: value" lines with values encoded as JSON, which makes
+// the output valid YAML. Keys, which are not plain YAML scalars,
+// are quoted as JSON strings as well. It is meant for support bundles and bug
+// reports, so values are not truncated.
+func Export(ctx context.Context, w io.Writer, r Reader, opts *ExportOptions) error {
+	if opts == nil {
+		opts = DefaultExportOptions
+	}
+
+	it := Walk(r)
+
+	for it.Next(ctx) {
+		if !it.Leaf() {
+			continue
+		}
+
+		var (
+			key = it.Key()
+			v   = it.Value()
+		)
+
+		if s, ok := v.(string); ok && opts.Expand != nil {
+			v = opts.Expand(s)
+		}
+
+		if opts.Redact != nil {
+			v = opts.Redact(key, v)
+		}
+
+		p, err := json.Marshal(v)
+		if err != nil {
+			return &Error{
+				Op:  "Export",
+				Key: key,
+				Got: v,
+				Err: err,
+			}
+		}
+
+		var comment string
+
+		if opts.Layers != nil {
+			s, err := origin(ctx, opts.Layers, key)
+			if err != nil {
+				return err
+			}
+
+			if s != "" {
+				comment = " # " + s
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s%s\n", exportKey(key), p, comment); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// exportKey gives the key as a YAML mapping key, quoting it unless
+// it is made of letters, digits and "_-./" only.
+func exportKey(key Key) string {
+	s := key.String()
+
+	plain := s != "" && s[0] != '-'
+
+	for _, r := range s {
+		if !plain {
+			break
+		}
+
+		plain = unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-./", r)
+	}
+
+	if plain {
+		return s
+	}
+
+	p, _ := json.Marshal(s)
+
+	return string(p)
+}
+
+// ExportFile writes the export of r to the file, which is readable
+// by its owner only.
+func ExportFile(ctx context.Context, path string, r Reader, opts *ExportOptions) error {
+	var buf bytes.Buffer
+
+	if err := Export(ctx, &buf, r, opts); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
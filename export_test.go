@@ -0,0 +1,102 @@
+package objects_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExport(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		path   = filepath.Join(t.TempDir(), "config.yaml")
+		env    = types.Map{"db": types.Map{"password": "hunter2"}}
+		file   = positioned{types.Map{"db": types.Map{"host": "${HOST}", "port": 5432}}}
+		layers = []objects.Layer{{"env", env}, {"file", file}}
+		merged = types.Map{"db": types.Map{"host": "${HOST}", "password": "hunter2", "port": 5432}}
+		opts   = &objects.ExportOptions{
+			Layers: layers,
+			Expand: func(s string) string {
+				return strings.ReplaceAll(s, "${HOST}", "db.local")
+			},
+			Redact: objects.Redact,
+		}
+		want = `db.host: "db.local" # file config.yaml:2:1
+db.password: "REDACTED" # env
+db.port: 5432 # file config.yaml:2:1
+`
+	)
+
+	if err := objects.ExportFile(ctx, path, merged, opts); err != nil {
+		t.Fatalf("ExportFile()=%+v", err)
+	}
+
+	p, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile()=%+v", err)
+	}
+
+	if got := string(p); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for _, key := range []objects.Key{{"db", "Password"}, {"aws", "secret_access_key"}, {"github", "token"}} {
+		if got := objects.Redact(key, "value"); got != objects.Redacted {
+			t.Errorf("Redact(%q)=%v, want %v", key, got, objects.Redacted)
+		}
+	}
+
+	if got := objects.Redact(objects.Key{"db", "host"}, "localhost"); got != "localhost" {
+		t.Errorf("Redact()=%v, want localhost", got)
+	}
+}
+
+func TestExportQuotedKeys(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		buf  strings.Builder
+		tree = types.Map{
+			"plain":  types.Map{"a-b_c/d": 1},
+			"colon":  types.Map{"a: b": 2},
+			"hash":   types.Map{"#x": 3},
+			"quote":  types.Map{`"q"`: 4},
+			"-dash":  5,
+			"spaced": types.Map{" ": 6},
+		}
+		want = `"-dash": 5
+"colon.a: b": 2
+"hash.#x": 3
+plain.a-b_c/d: 1
+"quote.\"q\"": 4
+"spaced. ": 6
+`
+	)
+
+	if err := objects.Export(ctx, &buf, tree, &objects.ExportOptions{}); err != nil {
+		t.Fatalf("Export()=%+v", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	var m map[string]any
+
+	if err := yaml.Unmarshal([]byte(buf.String()), &m); err != nil {
+		t.Fatalf("Unmarshal()=%+v", err)
+	}
+
+	if m[`quote."q"`] != 4 || m["spaced. "] != 6 {
+		t.Fatalf("got %#v", m)
+	}
+}
@@ -0,0 +1,163 @@
+package objects
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+type FlagOptions struct {
+	// All reads every defined flag; otherwise only flags set on
+	// the command line are read, so their defaults do not override
+	// lower layers of configuration.
+	All bool
+}
+
+var DefaultFlagOptions = &FlagOptions{}
+
+// FlagSet reads flags of a parsed flag.FlagSet as a flat tree keyed
+// by flag names. Values of flags implementing flag.Getter, like the
+// ones defined by the flag package, are typed; other ones are read
+// as strings. Setting a key calls Set on its flag.
+type FlagSet struct {
+	fs   *flag.FlagSet
+	opts *FlagOptions
+}
+
+var (
+	_ Reader     = (*FlagSet)(nil)
+	_ SafeReader = (*FlagSet)(nil)
+	_ SafeLister = (*FlagSet)(nil)
+	_ Writer     = (*FlagSet)(nil)
+	_ SafeWriter = (*FlagSet)(nil)
+)
+
+func Flags(fs *flag.FlagSet, opts *FlagOptions) *FlagSet {
+	if opts == nil {
+		opts = DefaultFlagOptions
+	}
+
+	return &FlagSet{
+		fs:   fs,
+		opts: opts,
+	}
+}
+
+func (f *FlagSet) Type() Type {
+	return TypeMap
+}
+
+func (f *FlagSet) Get(ctx context.Context, key string) (any, bool) {
+	v, err := f.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (f *FlagSet) SafeGet(ctx context.Context, key string) (any, error) {
+	fl := f.lookup(key)
+	if fl == nil {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	if g, ok := fl.Value.(flag.Getter); ok {
+		return g.Get(), nil
+	}
+
+	return fl.Value.String(), nil
+}
+
+func (f *FlagSet) List(ctx context.Context) []string {
+	keys, _ := f.SafeList(ctx)
+	return keys
+}
+
+// SafeList lists flag names in lexicographical order.
+func (f *FlagSet) SafeList(ctx context.Context) ([]string, error) {
+	var keys []string
+
+	fn := func(fl *flag.Flag) {
+		keys = append(keys, fl.Name)
+	}
+
+	if f.opts.All {
+		f.fs.VisitAll(fn)
+	} else {
+		f.fs.Visit(fn)
+	}
+
+	return keys, nil
+}
+
+func (f *FlagSet) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := f.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (f *FlagSet) Del(ctx context.Context, key string) bool {
+	return f.SafeDel(ctx, key) == nil
+}
+
+func (f *FlagSet) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := f.SafePut(ctx, key, hint)
+	return w
+}
+
+// SafeSet sets the flag to the string form of value. A flag set in
+// this way is read even if All is false.
+func (f *FlagSet) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if f.fs.Lookup(key) == nil {
+		return false, &Error{
+			Op:  "Set",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	if err := f.fs.Set(key, fmt.Sprint(value)); err != nil {
+		return false, &Error{
+			Op:  "Set",
+			Key: []string{key},
+			Got: value,
+			Err: err,
+		}
+	}
+
+	return true, nil
+}
+
+// SafeDel fails, as flags cannot be undefined.
+func (f *FlagSet) SafeDel(ctx context.Context, key string) error {
+	return &Error{
+		Op:  "Del",
+		Key: []string{key},
+		Err: ErrReadOnly,
+	}
+}
+
+// SafePut fails, as flags have no children.
+func (f *FlagSet) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	return nil, &Error{
+		Op:   "Put",
+		Key:  []string{key},
+		Got:  hint,
+		Want: "leaf",
+		Err:  ErrUnexpectedType,
+	}
+}
+
+func (f *FlagSet) lookup(name string) (fl *flag.Flag) {
+	if f.opts.All {
+		return f.fs.Lookup(name)
+	}
+
+	f.fs.Visit(func(v *flag.Flag) {
+		if v.Name == name {
+			fl = v
+		}
+	})
+
+	return fl
+}
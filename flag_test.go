@@ -0,0 +1,69 @@
+package objects_test
+
+import (
+	"context"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFlags(t *testing.T) {
+	var (
+		ctx = context.Background()
+		fs  = flag.NewFlagSet("test", flag.ContinueOnError)
+	)
+
+	fs.SetOutput(io.Discard)
+	fs.String("host", "localhost", "")
+	fs.Int("port", 8080, "")
+	fs.Bool("debug", false, "")
+	fs.Duration("timeout", time.Second, "")
+
+	if err := fs.Parse([]string{"-port", "9090", "-debug"}); err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	got := types.Map{"host": "example.com", "port": 80}
+
+	if err := objects.Copy(ctx, got, objects.Flags(fs, nil)); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	want := types.Map{
+		"host":  "example.com",
+		"port":  9090,
+		"debug": true,
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	all := objects.Flags(fs, &objects.FlagOptions{All: true})
+
+	if v, err := objects.Get(ctx, all, "timeout"); err != nil || v != time.Second {
+		t.Fatalf("Get()=%v, %+v", v, err)
+	}
+
+	if _, err := objects.Set(ctx, all, "2s", "timeout"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if v, err := objects.Get(ctx, objects.Flags(fs, nil), "timeout"); err != nil || v != 2*time.Second {
+		t.Fatalf("Get()=%v, %+v", v, err)
+	}
+
+	if _, err := objects.Set(ctx, all, "x", "port"); err == nil {
+		t.Fatal("expected Set() to fail")
+	}
+
+	if err := objects.Del(ctx, all, "host"); err == nil {
+		t.Fatal("expected Del() to fail")
+	}
+}
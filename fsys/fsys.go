@@ -0,0 +1,158 @@
+// Package fsys exposes an fs.FS as a tree of objects.
+//
+// Directories are read as nested readers keyed by entry names and
+// files as leaves. Files with an extension of a configured codec are
+// decoded, other ones are read as []byte.
+package fsys
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/types"
+)
+
+type Options struct {
+	// Codecs decode files by their extension, e.g. ".json".
+	Codecs map[string]codec.Codec
+}
+
+var DefaultOptions = &Options{
+	Codecs: map[string]codec.Codec{
+		".json": codec.JSON,
+	},
+}
+
+// Dir is a reader of a directory.
+type Dir struct {
+	fsys fs.FS
+	dir  string
+	opts *Options
+}
+
+var (
+	_ objects.Reader     = (*Dir)(nil)
+	_ objects.SafeReader = (*Dir)(nil)
+	_ objects.SafeLister = (*Dir)(nil)
+)
+
+// Read creates a reader of the root directory of fsys.
+func Read(fsys fs.FS, opts *Options) *Dir {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	return &Dir{
+		fsys: fsys,
+		dir:  ".",
+		opts: opts,
+	}
+}
+
+func (d *Dir) Type() objects.Type {
+	return objects.TypeMap
+}
+
+func (d *Dir) Get(ctx context.Context, key string) (any, bool) {
+	v, err := d.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+// SafeGet gives a *Dir for a directory and the content of a file,
+// decoded if there is a codec for its extension.
+func (d *Dir) SafeGet(ctx context.Context, key string) (any, error) {
+	name := path.Join(d.dir, key)
+
+	if !fs.ValidPath(name) || key == "" || key == "." || key == ".." {
+		return nil, &objects.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: objects.ErrNotFound,
+		}
+	}
+
+	fi, err := fs.Stat(d.fsys, name)
+	if err != nil {
+		return nil, d.error(key, err)
+	}
+
+	if fi.IsDir() {
+		return &Dir{
+			fsys: d.fsys,
+			dir:  name,
+			opts: d.opts,
+		}, nil
+	}
+
+	p, err := fs.ReadFile(d.fsys, name)
+	if err != nil {
+		return nil, d.error(key, err)
+	}
+
+	c, ok := d.opts.Codecs[path.Ext(key)]
+	if !ok {
+		return p, nil
+	}
+
+	var v any
+
+	if err := c.Unmarshal(p, &v); err != nil {
+		return nil, &objects.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Got: name,
+			Err: err,
+		}
+	}
+
+	if r := types.Make(v); r != nil {
+		return r, nil
+	}
+
+	return v, nil
+}
+
+func (d *Dir) List(ctx context.Context) []string {
+	keys, _ := d.SafeList(ctx)
+	return keys
+}
+
+// SafeList lists names of the directory entries in lexicographical
+// order.
+func (d *Dir) SafeList(ctx context.Context) ([]string, error) {
+	entries, err := fs.ReadDir(d.fsys, d.dir)
+	if err != nil {
+		return nil, &objects.Error{
+			Op:  "List",
+			Got: d.dir,
+			Err: err,
+		}
+	}
+
+	keys := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		keys = append(keys, e.Name())
+	}
+
+	return keys, nil
+}
+
+func (d *Dir) error(key string, err error) error {
+	e := &objects.Error{
+		Op:  "Get",
+		Key: []string{key},
+		Got: path.Join(d.dir, key),
+		Err: err,
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		e.Err = objects.ErrNotFound
+	}
+
+	return e
+}
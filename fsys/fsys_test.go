@@ -0,0 +1,68 @@
+package fsys_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/fsys"
+)
+
+func TestRead(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = fstest.MapFS{
+			"config/app.json":     {Data: []byte(`{"db":{"host":"localhost","port":5432}}`)},
+			"config/motd.txt":     {Data: []byte("hello")},
+			"assets/img/logo.svg": {Data: []byte("<svg/>")},
+			"broken.json":         {Data: []byte("{")},
+		}
+		r = fsys.Read(m, nil)
+	)
+
+	keys, err := objects.List(ctx, r)
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"assets", "broken.json", "config"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	cases := []struct {
+		key  []string
+		want any
+	}{
+		{[]string{"config", "app.json", "db", "host"}, "localhost"},
+		{[]string{"config", "app.json", "db", "port"}, 5432.0},
+		{[]string{"config", "motd.txt"}, []byte("hello")},
+		{[]string{"assets", "img", "logo.svg"}, []byte("<svg/>")},
+	}
+
+	for _, cas := range cases {
+		got, err := objects.Get(ctx, r, cas.key...)
+		if err != nil {
+			t.Fatalf("Get(%q)=%+v", cas.key, err)
+		}
+
+		if diff := cmp.Diff(cas.want, got); diff != "" {
+			t.Fatalf("Get(%q): got != want (-want, +got):\n%s", cas.key, diff)
+		}
+	}
+
+	if _, err := objects.Get(ctx, r, "config", "missing"); !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, objects.ErrNotFound)
+	}
+
+	if _, err := objects.Get(ctx, r, "..", "etc"); !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, objects.ErrNotFound)
+	}
+
+	if _, err := objects.Get(ctx, r, "broken.json"); err == nil {
+		t.Fatal("expected Get() to fail")
+	}
+}
@@ -0,0 +1,295 @@
+// Package gc reclaims subtrees of stored trees, which are expired,
+// tombstoned or no longer referenced, according to retention rules.
+package gc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// Rule selects keys to reclaim among the ones matching a dot-separated
+// path, which segments may be "*" wildcards, e.g. "sessions.*".
+type Rule struct {
+	ID string
+
+	op     string
+	path   types.Key
+	field  types.Key
+	maxAge time.Duration
+	refs   types.Key
+}
+
+// Expired reclaims subtrees matching path, which timestamp under
+// the dot-separated field is older than maxAge. Timestamps are
+// time.Time values or RFC 3339 strings; subtrees without a timestamp
+// are kept.
+func Expired(id, path, field string, maxAge time.Duration) Rule {
+	return Rule{ID: id, op: "Expired", path: strings.Split(path, "."), field: strings.Split(field, "."), maxAge: maxAge}
+}
+
+// Tombstoned reclaims tombstones left by types.SoftDeleted under
+// keys matching path, which were deleted more than maxAge ago.
+func Tombstoned(id, path string, maxAge time.Duration) Rule {
+	return Rule{ID: id, op: "Tombstoned", path: strings.Split(path, "."), maxAge: maxAge}
+}
+
+// Unreferenced reclaims keys matching path, which names are not
+// the value of any leaf matching the refs path, e.g. blobs no entry
+// of an index refers to:
+//
+//	Unreferenced("blobs", "blobs.*", "index.*.blob")
+func Unreferenced(id, path, refs string) Rule {
+	return Rule{ID: id, op: "Unreferenced", path: strings.Split(path, "."), refs: strings.Split(refs, ".")}
+}
+
+// Reclaimed is a key deleted, or to be deleted for a dry run.
+type Reclaimed struct {
+	Rule string
+	Op   string
+	Key  types.Key
+}
+
+// Report sums up a collection.
+type Report struct {
+	Scanned   int // keys matching paths of the rules
+	Batches   int
+	Reclaimed []Reclaimed
+}
+
+type Options struct {
+	// DryRun gives the report without deleting anything.
+	DryRun bool
+
+	// BatchSize is the maximum number of keys deleted at once and
+	// Interval is the pause between batches, which limits the load
+	// put on the store.
+	BatchSize int
+	Interval  time.Duration
+
+	// Clock tells the age of keys and times the pauses. It defaults
+	// to types.SystemClock when nil.
+	Clock types.Clock
+}
+
+var DefaultOptions = &Options{
+	BatchSize: 100,
+	Interval:  100 * time.Millisecond,
+}
+
+// Collect reclaims the keys of the tree selected by the rules. The
+// report covers the keys reclaimed until the first failure, if any.
+func Collect(ctx context.Context, iface types.Interface, rules []Rule, opts *Options) (*Report, error) {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	var (
+		clock  = opts.Clock
+		report = new(Report)
+	)
+
+	if clock == nil {
+		clock = types.SystemClock
+	}
+
+	var garbage []Reclaimed
+
+	for _, rule := range rules {
+		matches, err := match(ctx, iface, rule.path)
+		if err != nil {
+			return report, ruleError(rule, rule.path, err)
+		}
+
+		report.Scanned += len(matches)
+
+		keep, err := rule.keep(ctx, iface)
+		if err != nil {
+			return report, ruleError(rule, rule.refs, err)
+		}
+
+		for _, m := range matches {
+			ok, err := rule.reclaim(ctx, m, keep, clock.Now())
+			if err != nil {
+				return report, ruleError(rule, m.key, err)
+			}
+
+			if ok {
+				garbage = append(garbage, Reclaimed{Rule: rule.ID, Op: rule.op, Key: m.key})
+			}
+		}
+	}
+
+	if opts.DryRun {
+		report.Reclaimed = garbage
+		return report, nil
+	}
+
+	size := opts.BatchSize
+	if size <= 0 {
+		size = len(garbage)
+	}
+
+	for len(garbage) != 0 {
+		if report.Batches != 0 {
+			if err := sleep(ctx, clock, opts.Interval); err != nil {
+				return report, err
+			}
+		}
+
+		n := size
+		if n > len(garbage) {
+			n = len(garbage)
+		}
+
+		for _, r := range garbage[:n] {
+			if err := del(ctx, iface, r.Key); err != nil {
+				return report, &types.Error{
+					Op:  "GC",
+					Key: r.Key,
+					Got: r.Rule,
+					Err: err,
+				}
+			}
+
+			report.Reclaimed = append(report.Reclaimed, r)
+		}
+
+		garbage = garbage[n:]
+		report.Batches++
+	}
+
+	return report, nil
+}
+
+// keep gives the referenced names for an Unreferenced rule.
+func (r Rule) keep(ctx context.Context, root types.Reader) (map[string]struct{}, error) {
+	if r.op != "Unreferenced" {
+		return nil, nil
+	}
+
+	refs, err := match(ctx, root, r.refs)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]struct{}, len(refs))
+
+	for _, m := range refs {
+		keep[fmt.Sprint(m.value)] = struct{}{}
+	}
+
+	return keep, nil
+}
+
+func (r Rule) reclaim(ctx context.Context, m matched, keep map[string]struct{}, now time.Time) (bool, error) {
+	switch r.op {
+	case "Expired":
+		sub, ok := m.value.(types.Reader)
+		if !ok {
+			return false, nil
+		}
+
+		v, err := types.PrefixedReader{Key: r.field.Dir(), R: sub}.SafeGet(ctx, r.field.Base())
+		if errors.Is(err, types.ErrNotFound) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		t, err := instant(v)
+		if err != nil {
+			return false, err
+		}
+
+		return now.Sub(t) > r.maxAge, nil
+	case "Tombstoned":
+		var t *types.Tombstone
+
+		switch v := m.value.(type) {
+		case *types.Tombstone:
+			t = v
+		case types.Tombstone:
+			t = &v
+		default:
+			return false, nil
+		}
+
+		return now.Sub(t.DeletedAt) > r.maxAge, nil
+	case "Unreferenced":
+		_, ok := keep[m.key.Base()]
+		return !ok, nil
+	default:
+		return false, nil
+	}
+}
+
+type matched struct {
+	key   types.Key
+	value any
+}
+
+// match finds keys of r matching the pattern.
+func match(ctx context.Context, r types.Reader, pattern types.Key) ([]matched, error) {
+	var all []matched
+
+	err := types.Match(ctx, r, pattern, nil, func(key types.Key, v any) error {
+		all = append(all, matched{key: key, value: v})
+		return nil
+	})
+
+	return all, err
+}
+
+func del(ctx context.Context, iface types.Interface, key types.Key) error {
+	err := objects.Del(ctx, iface, key...)
+	if errors.Is(err, types.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func sleep(ctx context.Context, clock types.Clock, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	done := make(chan struct{})
+	t := clock.AfterFunc(d, func() { close(done) })
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		t.Stop()
+		return ctx.Err()
+	}
+}
+
+func instant(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339, v)
+	default:
+		return time.Time{}, types.ErrUnexpectedType
+	}
+}
+
+func ruleError(rule Rule, key types.Key, err error) error {
+	return &types.Error{
+		Op:  "GC",
+		Key: key,
+		Got: rule.ID,
+		Err: err,
+	}
+}
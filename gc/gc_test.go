@@ -0,0 +1,97 @@
+package gc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/gc"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var now = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func newTree() types.Map {
+	return types.Map{
+		"sessions": types.Map{
+			"old":   types.Map{"updated": "2026-09-01T00:00:00Z"},
+			"fresh": types.Map{"updated": now.Add(-time.Hour)},
+			"bare":  types.Map{},
+		},
+		"users": types.Map{
+			"alice": "active",
+			"bob":   &types.Tombstone{Value: "active", DeletedAt: now.Add(-48 * time.Hour)},
+			"carol": &types.Tombstone{Value: "active", DeletedAt: now.Add(-time.Minute)},
+		},
+		"blobs": types.Map{
+			"b1": []byte("x"),
+			"b2": []byte("y"),
+			"b3": []byte("z"),
+		},
+		"index": types.Map{
+			"readme": types.Map{"blob": "b1"},
+			"logo":   types.Map{"blob": "b3"},
+		},
+	}
+}
+
+var rules = []gc.Rule{
+	gc.Expired("sessions", "sessions.*", "updated", 24*time.Hour),
+	gc.Tombstoned("users", "users.*", 24*time.Hour),
+	gc.Unreferenced("blobs", "blobs.*", "index.*.blob"),
+}
+
+func TestCollect(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		tree = newTree()
+		opts = &gc.Options{
+			BatchSize: 2,
+			Clock:     objectstest.NewClock(now),
+		}
+	)
+
+	dry := *opts
+	dry.DryRun = true
+
+	report, err := gc.Collect(ctx, tree, rules, &dry)
+	if err != nil {
+		t.Fatalf("Collect()=%+v", err)
+	}
+
+	want := []gc.Reclaimed{
+		{Rule: "sessions", Op: "Expired", Key: types.Key{"sessions", "old"}},
+		{Rule: "users", Op: "Tombstoned", Key: types.Key{"users", "bob"}},
+		{Rule: "blobs", Op: "Unreferenced", Key: types.Key{"blobs", "b2"}},
+	}
+
+	if diff := cmp.Diff(want, report.Reclaimed); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(newTree(), tree); diff != "" {
+		t.Fatalf("dry run modified the tree (-want, +got):\n%s", diff)
+	}
+
+	report, err = gc.Collect(ctx, tree, rules, opts)
+	if err != nil {
+		t.Fatalf("Collect()=%+v", err)
+	}
+
+	if report.Scanned != 9 || report.Batches != 2 || len(report.Reclaimed) != 3 {
+		t.Fatalf("got %+v, want 9 scanned and 3 reclaimed in 2 batches", report)
+	}
+
+	for _, r := range want {
+		if _, ok := tree[r.Key[0]].(types.Map)[r.Key[1]]; ok {
+			t.Errorf("%v was not reclaimed", r.Key)
+		}
+	}
+
+	if _, ok := tree["sessions"].(types.Map)["bare"]; !ok {
+		t.Errorf("session without a timestamp was reclaimed")
+	}
+}
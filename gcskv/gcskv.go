@@ -0,0 +1,102 @@
+// Package gcskv exposes objects of a Google Cloud Storage bucket as
+// a tree, with the semantics of the s3kv package.
+package gcskv
+
+import (
+	"context"
+	"errors"
+
+	"rafal.dev/objects/s3kv"
+	"rafal.dev/objects/types"
+)
+
+type Object = s3kv.Object
+
+// Query selects objects, which names start with Prefix; objects
+// with Delimiter after the prefix are listed as prefixes.
+type Query struct {
+	Prefix    string
+	Delimiter string
+}
+
+// ObjectAttrs is a listed object, or a prefix, in which case only
+// Prefix is set, as for storage.ObjectAttrs.
+type ObjectAttrs struct {
+	Name   string
+	Prefix string
+}
+
+// Client is the subset of a GCS client used by the backend, e.g.
+// an adapter over cloud.google.com/go/storage. Read is expected to
+// return an error wrapping types.ErrNotFound for missing objects;
+// Objects gives all the objects matching the query, e.g. draining
+// an ObjectIterator.
+type Client interface {
+	Read(ctx context.Context, bucket, name string) (*Object, error)
+	Write(ctx context.Context, bucket, name string, obj *Object) error
+	Delete(ctx context.Context, bucket, name string) error
+	Objects(ctx context.Context, bucket string, q *Query) ([]*ObjectAttrs, error)
+}
+
+type client struct {
+	c Client
+}
+
+var _ s3kv.Client = client{}
+
+// New gives a tree of objects of the bucket, which names start with
+// the prefix, see s3kv.New.
+func New(c Client, bucket, prefix string, opts *s3kv.Options) *s3kv.Tree {
+	return s3kv.New(client{c: c}, bucket, prefix, opts)
+}
+
+func (c client) GetObject(ctx context.Context, bucket, key string) (*Object, error) {
+	return c.c.Read(ctx, bucket, key)
+}
+
+func (c client) PutObject(ctx context.Context, bucket, key string, obj *Object) error {
+	return c.c.Write(ctx, bucket, key, obj)
+}
+
+func (c client) DeleteObject(ctx context.Context, bucket, key string) error {
+	if err := c.c.Delete(ctx, bucket, key); err != nil && !errors.Is(err, types.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ListObjectsV2 lists all the objects at once, as there is a single
+// page of Objects.
+func (c client) ListObjectsV2(ctx context.Context, in *s3kv.ListInput) (*s3kv.ListOutput, error) {
+	attrs, err := c.c.Objects(ctx, in.Bucket, &Query{
+		Prefix:    in.Prefix,
+		Delimiter: in.Delimiter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &s3kv.ListOutput{}
+
+	for _, a := range attrs {
+		if a.Name == "" {
+			out.CommonPrefixes = append(out.CommonPrefixes, a.Prefix)
+		} else {
+			out.Keys = append(out.Keys, a.Name)
+		}
+	}
+
+	return out, nil
+}
+
+func (c client) Ping(ctx context.Context) error {
+	return types.Ping(ctx, c.c)
+}
+
+func (c client) Close(ctx context.Context) error {
+	return types.Close(ctx, c.c)
+}
+
+func (c client) Unwrap() any {
+	return c.c
+}
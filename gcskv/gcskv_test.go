@@ -0,0 +1,114 @@
+package gcskv_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/gcskv"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type client struct {
+	objs map[string]*gcskv.Object
+}
+
+func (c *client) Read(_ context.Context, _, name string) (*gcskv.Object, error) {
+	obj, ok := c.objs[name]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return obj, nil
+}
+
+func (c *client) Write(_ context.Context, _, name string, obj *gcskv.Object) error {
+	c.objs[name] = obj
+	return nil
+}
+
+func (c *client) Delete(_ context.Context, _, name string) error {
+	if _, ok := c.objs[name]; !ok {
+		return types.ErrNotFound
+	}
+	delete(c.objs, name)
+	return nil
+}
+
+func (c *client) Objects(_ context.Context, _ string, q *gcskv.Query) ([]*gcskv.ObjectAttrs, error) {
+	var (
+		attrs []*gcskv.ObjectAttrs
+		seen  = make(map[string]bool)
+	)
+
+	for name := range c.objs {
+		if !strings.HasPrefix(name, q.Prefix) {
+			continue
+		}
+
+		if i := strings.Index(name[len(q.Prefix):], q.Delimiter); q.Delimiter != "" && i != -1 {
+			p := name[:len(q.Prefix)+i+1]
+			if !seen[p] {
+				seen[p] = true
+				attrs = append(attrs, &gcskv.ObjectAttrs{Prefix: p})
+			}
+			continue
+		}
+
+		attrs = append(attrs, &gcskv.ObjectAttrs{Name: name})
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name+attrs[i].Prefix < attrs[j].Name+attrs[j].Prefix
+	})
+
+	return attrs, nil
+}
+
+func TestTree(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = &client{objs: map[string]*gcskv.Object{
+			"app/db/host":         {Body: []byte("localhost")},
+			"app/features.json":   {Body: []byte(`{"beta":true}`), ContentType: "application/json"},
+			"app/certs/old/a.pem": {Body: []byte("a")},
+		}}
+		tree = gcskv.New(c, "bucket", "app/", nil)
+	)
+
+	keys, err := types.List(ctx, tree)
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"certs", "db", "features.json"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	beta, err := objects.Get(ctx, tree, "features.json", "beta")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if beta != true {
+		t.Fatalf("got %v, want true", beta)
+	}
+
+	if err := objects.Del(ctx, tree, "certs"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if _, ok := c.objs["app/certs/old/a.pem"]; ok {
+		t.Fatal("want app/certs/old/a.pem deleted")
+	}
+}
+
+func TestTreeConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(t *testing.T) types.Interface {
+		return gcskv.New(&client{objs: make(map[string]*gcskv.Object)}, "bucket", "", nil)
+	})
+}
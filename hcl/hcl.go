@@ -0,0 +1,593 @@
+// Package hcl exposes HCL files, like Terraform and Nomad
+// configurations, as trees of objects.
+//
+// Attributes of a body are its leaves and blocks are keyed by their
+// type and then by each of their labels, e.g. resource.aws_instance.web.
+// Repeated blocks with the same labels are exposed as slices.
+// Writes modify the file in place, preserving its comments and
+// formatting.
+package hcl
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"rafal.dev/objects"
+)
+
+// File is a parsed HCL file.
+type File struct {
+	*Body
+	f *hclwrite.File
+}
+
+// Body is a body of a file or a block.
+type Body struct {
+	b *hclwrite.Body
+}
+
+// Blocks are blocks of a body of the same type, which labels start
+// with the given ones. They are exposed as a map keyed by the next
+// label, or as a slice when all their labels are matched.
+type Blocks struct {
+	b      *hclwrite.Body
+	typ    string
+	labels []string
+	slice  bool
+}
+
+var (
+	_ objects.Interface  = (*Body)(nil)
+	_ objects.SafeReader = (*Body)(nil)
+	_ objects.SafeLister = (*Body)(nil)
+	_ objects.SafeWriter = (*Body)(nil)
+	_ objects.Interface  = (*Blocks)(nil)
+	_ objects.SafeReader = (*Blocks)(nil)
+	_ objects.SafeLister = (*Blocks)(nil)
+	_ objects.SafeWriter = (*Blocks)(nil)
+)
+
+// Parse parses the HCL file p.
+func Parse(p []byte) (*File, error) {
+	f, diags := hclwrite.ParseConfig(p, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: diags,
+		}
+	}
+
+	return &File{
+		Body: &Body{b: f.Body()},
+		f:    f,
+	}, nil
+}
+
+// Bytes serializes the file back to HCL.
+func (f *File) Bytes() []byte {
+	return f.f.Bytes()
+}
+
+func (b *Body) Type() objects.Type {
+	return objects.TypeMap
+}
+
+func (b *Body) Get(ctx context.Context, key string) (any, bool) {
+	v, err := b.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+// SafeGet gives the value of an attribute or the blocks of the given
+// type. Attributes which cannot be evaluated without a context, like
+// references to variables, are given as their source text.
+func (b *Body) SafeGet(ctx context.Context, key string) (any, error) {
+	if a := b.b.GetAttribute(key); a != nil {
+		return value(a), nil
+	}
+
+	if bs := (&Blocks{b: b.b, typ: key}); len(bs.blocks()) != 0 {
+		return bs.value(), nil
+	}
+
+	return nil, &objects.Error{
+		Op:  "Get",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+// SafeList lists attributes sorted by name, followed by block types
+// in order of their appearance.
+func (b *Body) SafeList(ctx context.Context) ([]string, error) {
+	var (
+		keys []string
+		seen = make(map[string]struct{})
+	)
+
+	for name := range b.b.Attributes() {
+		keys = append(keys, name)
+	}
+
+	sort.Strings(keys)
+
+	for _, blk := range b.b.Blocks() {
+		if _, ok := seen[blk.Type()]; !ok {
+			seen[blk.Type()] = struct{}{}
+			keys = append(keys, blk.Type())
+		}
+	}
+
+	return keys, nil
+}
+
+func (b *Body) List(ctx context.Context) []string {
+	keys, _ := b.SafeList(ctx)
+	return keys
+}
+
+func (b *Body) Del(ctx context.Context, key string) bool {
+	return b.SafeDel(ctx, key) == nil
+}
+
+func (b *Body) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := b.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (b *Body) Put(ctx context.Context, key string, hint objects.Type) objects.Writer {
+	w, _ := b.SafePut(ctx, key, hint)
+	return w
+}
+
+// SafeDel removes an attribute or all blocks of the given type.
+func (b *Body) SafeDel(ctx context.Context, key string) error {
+	if b.b.GetAttribute(key) != nil {
+		b.b.RemoveAttribute(key)
+		return nil
+	}
+
+	if bs := (&Blocks{b: b.b, typ: key}); bs.remove() {
+		return nil
+	}
+
+	return &objects.Error{
+		Op:  "Del",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+// SafeSet sets an attribute. Readers are written as a block,
+// unless an attribute of the given name already exists.
+func (b *Body) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if r, ok := value.(objects.Reader); ok && b.b.GetAttribute(key) == nil {
+		bs := &Blocks{b: b.b, typ: key}
+		ok := bs.remove()
+
+		return ok, objects.Copy(ctx, &Body{b: b.b.AppendNewBlock(key, nil).Body()}, r)
+	}
+
+	v, err := convert(ctx, value)
+	if err != nil {
+		return false, &objects.Error{
+			Op:  "Set",
+			Key: []string{key},
+			Got: value,
+			Err: err,
+		}
+	}
+
+	ok := b.b.GetAttribute(key) != nil
+	b.b.SetAttributeValue(key, v)
+
+	return ok, nil
+}
+
+// SafePut gives a writer of the blocks of the given type, appending
+// a new unlabeled block if there are none.
+func (b *Body) SafePut(ctx context.Context, key string, hint objects.Type) (objects.Writer, error) {
+	if b.b.GetAttribute(key) != nil {
+		return nil, &objects.Error{
+			Op:   "Put",
+			Key:  []string{key},
+			Got:  "attribute",
+			Want: "block",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	bs := &Blocks{b: b.b, typ: key, slice: hint == objects.TypeSlice}
+
+	if len(bs.blocks()) != 0 || bs.slice {
+		return bs.value().(objects.Writer), nil
+	}
+
+	return &Body{b: b.b.AppendNewBlock(key, nil).Body()}, nil
+}
+
+func (bs *Blocks) Type() objects.Type {
+	if bs.isSlice() {
+		return objects.TypeSlice
+	}
+	return objects.TypeMap
+}
+
+func (bs *Blocks) Get(ctx context.Context, key string) (any, bool) {
+	v, err := bs.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (bs *Blocks) SafeGet(ctx context.Context, key string) (any, error) {
+	if bs.isSlice() {
+		blk, err := bs.index("Get", key)
+		if err != nil {
+			return nil, err
+		}
+		return &Body{b: blk.Body()}, nil
+	}
+
+	if sub := bs.sub(key); len(sub.blocks()) != 0 {
+		return sub.value(), nil
+	}
+
+	return nil, &objects.Error{
+		Op:  "Get",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+func (bs *Blocks) List(ctx context.Context) []string {
+	keys, _ := bs.SafeList(ctx)
+	return keys
+}
+
+func (bs *Blocks) SafeList(ctx context.Context) ([]string, error) {
+	var (
+		keys []string
+		seen = make(map[string]struct{})
+		n    = len(bs.labels)
+	)
+
+	for i, blk := range bs.blocks() {
+		if bs.isSlice() {
+			keys = append(keys, strconv.Itoa(i))
+			continue
+		}
+
+		if label := blk.Labels()[n]; !has(seen, label) {
+			seen[label] = struct{}{}
+			keys = append(keys, label)
+		}
+	}
+
+	return keys, nil
+}
+
+func (bs *Blocks) Del(ctx context.Context, key string) bool {
+	return bs.SafeDel(ctx, key) == nil
+}
+
+func (bs *Blocks) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := bs.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (bs *Blocks) Put(ctx context.Context, key string, hint objects.Type) objects.Writer {
+	w, _ := bs.SafePut(ctx, key, hint)
+	return w
+}
+
+func (bs *Blocks) SafeDel(ctx context.Context, key string) error {
+	if bs.isSlice() {
+		blk, err := bs.index("Del", key)
+		if err != nil {
+			return err
+		}
+		bs.b.RemoveBlock(blk)
+		return nil
+	}
+
+	if bs.sub(key).remove() {
+		return nil
+	}
+
+	return &objects.Error{
+		Op:  "Del",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+// SafeSet replaces the block under the key with a block of the
+// value, which must be a Reader.
+func (bs *Blocks) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	r, ok := value.(objects.Reader)
+	if !ok {
+		return false, &objects.Error{
+			Op:   "Set",
+			Key:  []string{key},
+			Got:  value,
+			Want: "block",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	var blk *hclwrite.Block
+
+	switch {
+	case bs.isSlice() && key == "-":
+		blk = bs.b.AppendNewBlock(bs.typ, bs.labels)
+	case bs.isSlice():
+		old, err := bs.index("Set", key)
+		if err != nil {
+			return false, err
+		}
+		old.Body().Clear()
+		blk, ok = old, true
+	default:
+		ok = bs.sub(key).remove()
+		blk = bs.b.AppendNewBlock(bs.typ, append(clone(bs.labels), key))
+	}
+
+	return ok, objects.Copy(ctx, &Body{b: blk.Body()}, r)
+}
+
+// SafePut gives a writer of the block under the key, appending
+// a new block if it does not exist.
+func (bs *Blocks) SafePut(ctx context.Context, key string, hint objects.Type) (objects.Writer, error) {
+	if bs.isSlice() {
+		if key == "-" {
+			return &Body{b: bs.b.AppendNewBlock(bs.typ, bs.labels).Body()}, nil
+		}
+
+		blk, err := bs.index("Put", key)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Body{b: blk.Body()}, nil
+	}
+
+	if sub := bs.sub(key); len(sub.blocks()) != 0 || len(sub.labels) < bs.depth() {
+		return sub.value().(objects.Writer), nil
+	}
+
+	return &Body{b: bs.b.AppendNewBlock(bs.typ, append(clone(bs.labels), key)).Body()}, nil
+}
+
+func (bs *Blocks) blocks() []*hclwrite.Block {
+	var blocks []*hclwrite.Block
+
+	for _, blk := range bs.b.Blocks() {
+		if blk.Type() == bs.typ && hasPrefix(blk.Labels(), bs.labels) {
+			blocks = append(blocks, blk)
+		}
+	}
+
+	return blocks
+}
+
+// depth gives the largest number of labels of the blocks of the same
+// type, so new blocks get as many labels as their siblings.
+func (bs *Blocks) depth() int {
+	var n int
+
+	for _, blk := range bs.b.Blocks() {
+		if blk.Type() == bs.typ && len(blk.Labels()) > n {
+			n = len(blk.Labels())
+		}
+	}
+
+	return n
+}
+
+// isSlice tells whether any of the blocks has all of its labels
+// matched.
+func (bs *Blocks) isSlice() bool {
+	blocks := bs.blocks()
+
+	if len(blocks) == 0 {
+		return bs.slice
+	}
+
+	for _, blk := range blocks {
+		if len(blk.Labels()) == len(bs.labels) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// value gives the body of a single fully matched block, or bs.
+func (bs *Blocks) value() any {
+	if blocks := bs.blocks(); len(blocks) == 1 && len(blocks[0].Labels()) == len(bs.labels) && !bs.slice {
+		return &Body{b: blocks[0].Body()}
+	}
+	return bs
+}
+
+func (bs *Blocks) sub(label string) *Blocks {
+	return &Blocks{
+		b:      bs.b,
+		typ:    bs.typ,
+		labels: append(clone(bs.labels), label),
+	}
+}
+
+func (bs *Blocks) remove() bool {
+	blocks := bs.blocks()
+
+	for _, blk := range blocks {
+		bs.b.RemoveBlock(blk)
+	}
+
+	return len(blocks) != 0
+}
+
+func (bs *Blocks) index(op, key string) (*hclwrite.Block, error) {
+	var (
+		blocks = bs.blocks()
+		i, err = strconv.Atoi(key)
+	)
+
+	if err != nil || i < 0 || i >= len(blocks) {
+		return nil, &objects.Error{
+			Op:   op,
+			Key:  []string{key},
+			Got:  key,
+			Want: len(blocks),
+			Err:  objects.ErrOutOfBounds,
+		}
+	}
+
+	return blocks[i], nil
+}
+
+func value(a *hclwrite.Attribute) any {
+	src := a.Expr().BuildTokens(nil).Bytes()
+
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return strings.TrimSpace(string(src))
+	}
+
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return strings.TrimSpace(string(src))
+	}
+
+	return fromCty(v)
+}
+
+func fromCty(v cty.Value) any {
+	if !v.IsKnown() || v.IsNull() {
+		return nil
+	}
+
+	switch t := v.Type(); {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Bool:
+		return v.True()
+	case t == cty.Number:
+		f := v.AsBigFloat()
+		if n, acc := f.Int64(); acc == big.Exact {
+			return n
+		}
+		n, _ := f.Float64()
+		return n
+	case t.IsListType() || t.IsSetType() || t.IsTupleType():
+		var s []any
+		for it := v.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			s = append(s, fromCty(v))
+		}
+		return s
+	case t.IsMapType() || t.IsObjectType():
+		m := make(map[string]any)
+		for it := v.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			m[k.AsString()] = fromCty(v)
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+func convert(ctx context.Context, value any) (cty.Value, error) {
+	if r, ok := value.(objects.Reader); ok {
+		var v any
+		if err := objects.Decode(ctx, r, &v, nil); err != nil {
+			return cty.NilVal, err
+		}
+		value = v
+	}
+
+	return toCty(value)
+}
+
+func toCty(value any) (cty.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case string:
+		return cty.StringVal(v), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case []any:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		vs := make([]cty.Value, len(v))
+		for i, v := range v {
+			x, err := toCty(v)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vs[i] = x
+		}
+		return cty.TupleVal(vs), nil
+	case map[string]any:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+		vs := make(map[string]cty.Value, len(v))
+		for k, v := range v {
+			x, err := toCty(v)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vs[k] = x
+		}
+		return cty.ObjectVal(vs), nil
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cty.NumberIntVal(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cty.NumberUIntVal(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cty.NumberFloatVal(v.Float()), nil
+	case reflect.String:
+		return cty.StringVal(v.String()), nil
+	case reflect.Bool:
+		return cty.BoolVal(v.Bool()), nil
+	}
+
+	return cty.NilVal, fmt.Errorf("%w: %T", objects.ErrUnexpectedType, value)
+}
+
+func hasPrefix(labels, prefix []string) bool {
+	if len(labels) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if labels[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func has(m map[string]struct{}, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func clone(s []string) []string {
+	return append([]string(nil), s...)
+}
@@ -0,0 +1,138 @@
+package hcl_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/hcl"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const config = `# Web servers
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = "t2.micro"
+  count         = 2
+  subnet_id     = var.subnet # from variables
+
+  ebs_block_device {
+    device_name = "/dev/sdb"
+  }
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+  }
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+}
+`
+
+func TestFile(t *testing.T) {
+	var ctx = context.Background()
+
+	f, err := hcl.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if got, want := mustList(t, f, "resource", "aws_instance"), []string{"web", "db"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	gets := []struct {
+		keys []string
+		want any
+	}{
+		{[]string{"resource", "aws_instance", "web", "ami"}, "ami-123"},
+		{[]string{"resource", "aws_instance", "web", "count"}, int64(2)},
+		{[]string{"resource", "aws_instance", "web", "subnet_id"}, "var.subnet"},
+		{[]string{"resource", "aws_instance", "web", "ebs_block_device", "1", "device_name"}, "/dev/sdc"},
+		{[]string{"resource", "aws_instance", "db", "ami"}, "ami-456"},
+	}
+
+	for _, get := range gets {
+		v, err := objects.Get(ctx, f, get.keys...)
+		if err != nil {
+			t.Fatalf("Get(%v)=%+v", get.keys, err)
+		}
+
+		if !cmp.Equal(v, get.want) {
+			t.Fatalf("Get(%v): got %#v, want %#v", get.keys, v, get.want)
+		}
+	}
+
+	sets := []struct {
+		value any
+		keys  []string
+	}{
+		{"t3.large", []string{"resource", "aws_instance", "web", "instance_type"}},
+		{map[string]any{"Name": "db"}, []string{"resource", "aws_instance", "db", "tags"}},
+		{types.Map{"device_name": "/dev/sdd"}, []string{"resource", "aws_instance", "web", "ebs_block_device", "-"}},
+	}
+
+	w, err := objects.Put(ctx, f, objects.TypeMap, "resource", "aws_s3_bucket", "logs")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, w, "logs", "bucket"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	for _, set := range sets {
+		if _, err := objects.Set(ctx, f, set.value, set.keys...); err != nil {
+			t.Fatalf("Set(%v)=%+v", set.keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, f, "resource", "aws_instance", "web", "ebs_block_device", "0"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	want := `# Web servers
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = "t3.large"
+  count         = 2
+  subnet_id     = var.subnet # from variables
+
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+  }
+  ebs_block_device {
+    device_name = "/dev/sdd"
+  }
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+  tags = {
+    Name = "db"
+  }
+}
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`
+
+	if got := string(f.Bytes()); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func mustList(t *testing.T, r objects.Reader, keys ...string) []string {
+	t.Helper()
+
+	list, err := objects.List(context.Background(), r, keys...)
+	if err != nil {
+		t.Fatalf("List(%v)=%+v", keys, err)
+	}
+
+	return list
+}
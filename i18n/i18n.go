@@ -0,0 +1,134 @@
+// Package i18n reads translated messages from a tree of message
+// bundles, one per locale.
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rafal.dev/objects"
+)
+
+type localeKey struct{}
+
+// WithLocale sets the locale of messages read with ctx, e.g. "de-AT".
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// Locale gives the locale set with WithLocale.
+func Locale(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// Fallbacks gives the chain of locales a message of the locale is
+// looked up in, from the most specific one to the default, e.g.
+// de-AT, de and en for the en default.
+func Fallbacks(locale, def string) []string {
+	var chain []string
+
+	for locale = strings.ReplaceAll(locale, "_", "-"); locale != ""; {
+		chain = append(chain, locale)
+
+		i := strings.LastIndexByte(locale, '-')
+		if i == -1 {
+			break
+		}
+
+		locale = locale[:i]
+	}
+
+	if def != "" && !contains(chain, def) {
+		chain = append(chain, def)
+	}
+
+	return chain
+}
+
+// Bundle is a tree of message bundles under their locales, e.g.
+//
+//	en:
+//	  errors:
+//	    not_found: Not found
+//	de:
+//	  errors:
+//	    not_found: Nicht gefunden
+type Bundle struct {
+	R objects.Reader
+
+	// Default is the locale of messages missing in the requested one
+	// and its parents, and the locale used when ctx has none.
+	Default string
+}
+
+// Get gives the message under the dot-separated key in the locale
+// of ctx, falling back along the chain of Fallbacks.
+func (b *Bundle) Get(ctx context.Context, key string) (string, error) {
+	locale, ok := Locale(ctx)
+	if !ok {
+		locale = b.Default
+	}
+
+	keys := strings.Split(key, ".")
+
+	for _, l := range Fallbacks(locale, b.Default) {
+		v, err := objects.Get(ctx, b.R, append([]string{l}, keys...)...)
+		if errors.Is(err, objects.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return message(v, keys)
+	}
+
+	return "", &objects.Error{
+		Op:  "Get",
+		Key: keys,
+		Got: locale,
+		Err: objects.ErrNotFound,
+	}
+}
+
+// Translate is like Get, but gives the key itself for missing
+// messages, so that untranslated strings remain readable.
+func (b *Bundle) Translate(ctx context.Context, key string) string {
+	msg, err := b.Get(ctx, key)
+	if err != nil {
+		return key
+	}
+	return msg
+}
+
+func message(v any, keys []string) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		return "", &objects.Error{
+			Op:   "Get",
+			Key:  keys,
+			Got:  v,
+			Want: "",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
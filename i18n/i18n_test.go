@@ -0,0 +1,83 @@
+package i18n_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/i18n"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBundle(t *testing.T) {
+	var (
+		ctx = context.Background()
+		b   = &i18n.Bundle{
+			R: types.Map{
+				"en": types.Map{
+					"errors": types.Map{
+						"not_found": "Not found",
+						"forbidden": "Forbidden",
+						"conflict":  "Conflict",
+					},
+				},
+				"de": types.Map{
+					"errors": types.Map{
+						"not_found": "Nicht gefunden",
+						"forbidden": "Verboten",
+					},
+				},
+				"de-AT": types.Map{
+					"errors": types.Map{
+						"not_found": "Nix gfundn",
+					},
+				},
+			},
+			Default: "en",
+		}
+	)
+
+	cases := []struct {
+		locale string
+		key    string
+		want   string
+	}{
+		{"de-AT", "errors.not_found", "Nix gfundn"},
+		{"de_AT", "errors.forbidden", "Verboten"},
+		{"de-AT", "errors.conflict", "Conflict"},
+		{"de", "errors.not_found", "Nicht gefunden"},
+		{"fr", "errors.not_found", "Not found"},
+		{"", "errors.forbidden", "Forbidden"},
+	}
+
+	for _, cas := range cases {
+		got, err := b.Get(i18n.WithLocale(ctx, cas.locale), cas.key)
+		if err != nil {
+			t.Fatalf("Get()=%+v", err)
+		}
+
+		if got != cas.want {
+			t.Errorf("%s %s: got %q, want %q", cas.locale, cas.key, got, cas.want)
+		}
+	}
+
+	if _, err := b.Get(ctx, "errors.missing"); !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, objects.ErrNotFound)
+	}
+
+	if got := b.Translate(ctx, "errors.missing"); got != "errors.missing" {
+		t.Fatalf("got %q, want errors.missing", got)
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	got := i18n.Fallbacks("zh-Hant-TW", "en")
+	want := []string{"zh-Hant-TW", "zh-Hant", "zh", "en"}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
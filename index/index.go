@@ -0,0 +1,243 @@
+// Package index maintains inverted indexes of leaf values of a tree,
+// so that keys holding a value are found without scanning the tree.
+package index
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// Index is a set of named inverted indexes of a tree.
+type Index struct {
+	r types.Reader
+
+	mu      sync.RWMutex
+	indexes map[string]*index
+}
+
+type index struct {
+	path    types.Key
+	byValue map[string]map[string]types.Key // keys by values and IDs
+	byKey   map[string]string               // values by IDs of keys
+}
+
+func New(r types.Reader) *Index {
+	return &Index{
+		r:       r,
+		indexes: make(map[string]*index),
+	}
+}
+
+func newIndex(path types.Key) *index {
+	return &index{
+		path:    path,
+		byValue: make(map[string]map[string]types.Key),
+		byKey:   make(map[string]string),
+	}
+}
+
+// Add indexes values of leaves matching the dot-separated path, which
+// segments may be "*" wildcards, e.g. "services.*.owner", under the
+// name. Values are compared by their fmt.Sprint format.
+func (ix *Index) Add(ctx context.Context, name, path string) error {
+	idx := newIndex(strings.Split(path, "."))
+
+	if err := idx.scan(ctx, ix.r, idx.path, nil); err != nil {
+		return &types.Error{
+			Op:  "Index",
+			Key: idx.path,
+			Got: name,
+			Err: err,
+		}
+	}
+
+	ix.mu.Lock()
+	ix.indexes[name] = idx
+	ix.mu.Unlock()
+
+	return nil
+}
+
+// FindByValue gives keys of leaves indexed under the name, which
+// hold the value, in order.
+func (ix *Index) FindByValue(ctx context.Context, name string, value any) ([]types.Key, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	idx, ok := ix.indexes[name]
+	if !ok {
+		return nil, &types.Error{
+			Op:  "FindByValue",
+			Got: name,
+			Err: types.ErrNotFound,
+		}
+	}
+
+	var keys []types.Key
+
+	for _, k := range idx.byValue[fmt.Sprint(value)] {
+		keys = append(keys, k.Copy())
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Compare(keys[j]) < 0
+	})
+
+	return keys, nil
+}
+
+// Watch keeps the indexes up to date with writes to the tree, which
+// must be a types.Watcher, until ctx is done. Each event rescans the
+// indexed keys under its key; keys, which fail to be read, are not
+// indexed until they are written again.
+func (ix *Index) Watch(ctx context.Context) error {
+	var w types.Watcher
+
+	if !types.As(ix.r, &w) {
+		return &types.Error{
+			Op:   "Watch",
+			Got:  ix.r,
+			Want: w,
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+
+	events := w.Watch(ctx, "")
+
+	go func() {
+		for ev := range events {
+			ix.update(ctx, ev.Key)
+		}
+	}()
+
+	return nil
+}
+
+// update rescans keys of the indexes under the key. The tree is read
+// before the indexes are locked, so that lookups are not held back
+// by the store.
+func (ix *Index) update(ctx context.Context, key types.Key) {
+	ix.mu.RLock()
+	indexes := make(map[string]*index, len(ix.indexes))
+	for name, idx := range ix.indexes {
+		indexes[name] = idx
+	}
+	ix.mu.RUnlock()
+
+	for name, idx := range indexes {
+		n := len(key)
+		if n > len(idx.path) {
+			n = len(idx.path)
+		}
+
+		if !matches(idx.path[:n], key[:n]) {
+			continue
+		}
+
+		var (
+			prefix = key[:n].Copy()
+			fresh  = newIndex(idx.path)
+		)
+
+		if n == 0 {
+			_ = fresh.scan(ctx, ix.r, idx.path, nil)
+		} else if v, err := objects.Get(ctx, ix.r, prefix...); err == nil {
+			if n == len(idx.path) {
+				fresh.add(prefix, v)
+			} else if r, ok := v.(types.Reader); ok {
+				_ = fresh.scan(ctx, r, idx.path[n:], prefix)
+			}
+		}
+
+		ix.mu.Lock()
+		if ix.indexes[name] == idx {
+			idx.remove(prefix)
+			idx.merge(fresh)
+		}
+		ix.mu.Unlock()
+	}
+}
+
+// scan indexes leaves of r matching the pattern, relative to the key.
+func (idx *index) scan(ctx context.Context, r types.Reader, pattern, key types.Key) error {
+	return types.Match(ctx, r, pattern, key, func(k types.Key, v any) error {
+		idx.add(k, v)
+		return nil
+	})
+}
+
+func (idx *index) add(key types.Key, v any) {
+	if _, ok := v.(types.Reader); ok {
+		return
+	}
+
+	s := fmt.Sprint(v)
+
+	if idx.byValue[s] == nil {
+		idx.byValue[s] = make(map[string]types.Key)
+	}
+
+	id := key.ID()
+
+	idx.byValue[s][id] = key
+	idx.byKey[id] = s
+}
+
+func (idx *index) merge(other *index) {
+	for s, keys := range other.byValue {
+		if idx.byValue[s] == nil {
+			idx.byValue[s] = make(map[string]types.Key, len(keys))
+		}
+
+		for id, key := range keys {
+			idx.byValue[s][id] = key
+			idx.byKey[id] = s
+		}
+	}
+}
+
+// remove drops the keys under the prefix from the index.
+func (idx *index) remove(prefix types.Key) {
+	p := prefix.ID()
+
+	if len(prefix) == len(idx.path) {
+		idx.drop(p)
+		return
+	}
+
+	// The ID of a key under the prefix starts with the prefix's ID.
+	for id := range idx.byKey {
+		if strings.HasPrefix(id, p) {
+			idx.drop(id)
+		}
+	}
+}
+
+func (idx *index) drop(id string) {
+	s, ok := idx.byKey[id]
+	if !ok {
+		return
+	}
+
+	delete(idx.byKey, id)
+	delete(idx.byValue[s], id)
+
+	if len(idx.byValue[s]) == 0 {
+		delete(idx.byValue, s)
+	}
+}
+
+func matches(pattern, key types.Key) bool {
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != key[i] {
+			return false
+		}
+	}
+	return true
+}
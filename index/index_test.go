@@ -0,0 +1,116 @@
+package index_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/index"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIndex(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tree := types.Observe(types.Map{
+		"services": types.Map{
+			"api":    types.Map{"owner": "team-x", "port": 8080},
+			"web":    types.Map{"owner": "team-y"},
+			"worker": types.Map{"owner": "team-x"},
+		},
+	})
+
+	ix := index.New(tree)
+
+	if err := ix.Add(ctx, "owner", "services.*.owner"); err != nil {
+		t.Fatalf("Add()=%+v", err)
+	}
+
+	find := func(want ...types.Key) {
+		t.Helper()
+
+		var (
+			got      []types.Key
+			err      error
+			deadline = time.Now().Add(5 * time.Second)
+		)
+
+		for time.Now().Before(deadline) {
+			if got, err = ix.FindByValue(ctx, "owner", "team-x"); err != nil {
+				t.Fatalf("FindByValue()=%+v", err)
+			}
+
+			if cmp.Equal(want, got) {
+				return
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		t.Fatalf("got != want (-want, +got):\n%s", cmp.Diff(want, got))
+	}
+
+	find(
+		types.Key{"services", "api", "owner"},
+		types.Key{"services", "worker", "owner"},
+	)
+
+	if err := ix.Watch(ctx); err != nil {
+		t.Fatalf("Watch()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, tree, "team-x", "services", "web", "owner"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, tree, "services", "api"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, tree, types.Map{"owner": "team-x"}, "services", "db"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	find(
+		types.Key{"services", "db", "owner"},
+		types.Key{"services", "web", "owner"},
+		types.Key{"services", "worker", "owner"},
+	)
+
+	if _, err := ix.FindByValue(ctx, "port", 8080); err == nil {
+		t.Fatal("FindByValue() of an unknown index succeeded")
+	}
+
+	if err := index.New(types.Map{}).Watch(ctx); err == nil {
+		t.Fatal("Watch() of a non-watcher succeeded")
+	}
+}
+
+func TestIndexDottedKeys(t *testing.T) {
+	var (
+		ctx = context.Background()
+		ix  = index.New(types.Map{
+			"a.b": types.Map{"c": "team-x"},
+			"a":   types.Map{"b.c": "team-x"},
+		})
+	)
+
+	if err := ix.Add(ctx, "owner", "*.*"); err != nil {
+		t.Fatalf("Add()=%+v", err)
+	}
+
+	got, err := ix.FindByValue(ctx, "owner", "team-x")
+	if err != nil {
+		t.Fatalf("FindByValue()=%+v", err)
+	}
+
+	want := []types.Key{{"a", "b.c"}, {"a.b", "c"}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,356 @@
+// Package ini exposes INI and .properties files as trees of objects.
+//
+// Sections become nested maps and keys their leaves; keys preceding
+// the first section, as in .properties files, are leaves of the root.
+// Values are edited in place, so comments, ordering and formatting of
+// untouched lines are preserved when the document is serialized back.
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"rafal.dev/objects"
+)
+
+// Document is a parsed INI or .properties file.
+type Document struct {
+	*Section
+
+	// File is the name of the source file reported in positions.
+	File string
+
+	lines []*line
+}
+
+// Section is a section of a document; the root section, with an
+// empty name, holds the keys preceding the first section header.
+type Section struct {
+	d    *Document
+	name string
+}
+
+type line struct {
+	text    string
+	section string
+	header  bool
+	key     string
+	head    string // text preceding the value
+	value   string
+}
+
+var (
+	_ objects.Interface  = (*Section)(nil)
+	_ objects.SafeReader = (*Section)(nil)
+	_ objects.SafeLister = (*Section)(nil)
+	_ objects.SafeWriter = (*Section)(nil)
+	_ objects.Provenance = (*Section)(nil)
+)
+
+// Parse parses the INI or .properties file p. Keys are separated
+// from values with "=" or ":"; lines starting with "#", ";" or "!"
+// are comments.
+func Parse(p []byte) (*Document, error) {
+	var (
+		d       = &Document{}
+		s       = bufio.NewScanner(bytes.NewReader(p))
+		section string
+		n       int
+	)
+
+	d.Section = &Section{d: d}
+
+	for s.Scan() {
+		n++
+
+		var (
+			text    = s.Text()
+			trimmed = strings.TrimSpace(text)
+			l       = &line{text: text, section: section}
+		)
+
+		switch {
+		case trimmed == "" || strings.ContainsAny(trimmed[:1], "#;!"):
+		case strings.HasPrefix(trimmed, "["):
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, &objects.Error{
+					Op:  "Parse",
+					Got: text,
+					Err: fmt.Errorf("line %d: unterminated section header", n),
+				}
+			}
+
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			l.section, l.header = section, true
+		default:
+			i := strings.IndexAny(text, "=:")
+			if i == -1 {
+				return nil, &objects.Error{
+					Op:  "Parse",
+					Got: text,
+					Err: fmt.Errorf("line %d: missing separator", n),
+				}
+			}
+
+			j := i + 1
+			for j < len(text) && (text[j] == ' ' || text[j] == '\t') {
+				j++
+			}
+
+			l.key = strings.TrimSpace(text[:i])
+			l.head = text[:j]
+			l.value = strings.TrimSpace(text[j:])
+		}
+
+		d.lines = append(d.lines, l)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: err,
+		}
+	}
+
+	return d, nil
+}
+
+// Bytes serializes the document back.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+
+	for _, l := range d.lines {
+		buf.WriteString(l.text)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+// Position gives the position of a key or of a section header in the
+// document, as it is serialized by Bytes.
+func (s *Section) Position(key objects.Key) (objects.Position, bool) {
+	var i = -1
+
+	switch {
+	case len(key) == 1:
+		if i = s.d.key(s.name, key[0]); i == -1 && s.name == "" {
+			i = s.d.header(key[0])
+		}
+	case len(key) == 2 && s.name == "":
+		i = s.d.key(key[0], key[1])
+	}
+
+	if i == -1 {
+		return objects.Position{}, false
+	}
+
+	l := s.d.lines[i]
+
+	return objects.Position{
+		File:   s.d.File,
+		Line:   i + 1,
+		Column: len(l.text) - len(strings.TrimLeft(l.text, " \t")) + 1,
+	}, true
+}
+
+func (s *Section) Type() objects.Type {
+	return objects.TypeMap
+}
+
+func (s *Section) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Section) SafeGet(ctx context.Context, key string) (any, error) {
+	if i := s.d.key(s.name, key); i != -1 {
+		return s.d.lines[i].value, nil
+	}
+
+	if s.name == "" && s.d.header(key) != -1 {
+		return &Section{d: s.d, name: key}, nil
+	}
+
+	return nil, &objects.Error{
+		Op:  "Get",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+func (s *Section) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Section) SafeList(ctx context.Context) ([]string, error) {
+	var (
+		keys []string
+		seen = make(map[string]struct{})
+	)
+
+	for _, l := range s.d.lines {
+		var k string
+
+		switch {
+		case l.key != "" && l.section == s.name:
+			k = l.key
+		case l.header && s.name == "":
+			k = l.section
+		default:
+			continue
+		}
+
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *Section) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Section) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Section) Put(ctx context.Context, key string, hint objects.Type) objects.Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+// SafeDel removes the key or, in the root section, a whole section.
+func (s *Section) SafeDel(ctx context.Context, key string) error {
+	if i := s.d.key(s.name, key); i != -1 {
+		s.d.lines = append(s.d.lines[:i], s.d.lines[i+1:]...)
+		return nil
+	}
+
+	if s.name == "" && s.d.header(key) != -1 {
+		var lines []*line
+
+		for _, l := range s.d.lines {
+			if l.section != key {
+				lines = append(lines, l)
+			}
+		}
+
+		s.d.lines = lines
+
+		return nil
+	}
+
+	return &objects.Error{
+		Op:  "Del",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+// SafeSet sets the value in place, or appends the key at the end
+// of the section. Readers set in the root section become sections.
+func (s *Section) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if r, ok := value.(objects.Reader); ok {
+		ok := s.d.header(key) != -1
+
+		w, err := s.SafePut(ctx, key, objects.TypeMap)
+		if err != nil {
+			return false, err
+		}
+
+		return ok, objects.Copy(ctx, w, r)
+	}
+
+	v := fmt.Sprint(value)
+
+	if i := s.d.key(s.name, key); i != -1 {
+		l := s.d.lines[i]
+		l.value = v
+		l.text = l.head + v
+		return true, nil
+	}
+
+	l := &line{
+		section: s.name,
+		key:     key,
+		head:    key + " = ",
+		value:   v,
+	}
+
+	l.text = l.head + v
+
+	s.d.insert(s.name, l)
+
+	return false, nil
+}
+
+// SafePut gives the section of the given name, appending it to
+// the document if missing. Sections cannot be nested.
+func (s *Section) SafePut(ctx context.Context, key string, hint objects.Type) (objects.Writer, error) {
+	if s.name != "" || hint == objects.TypeSlice {
+		return nil, &objects.Error{
+			Op:   "Put",
+			Key:  []string{key},
+			Got:  hint,
+			Want: objects.TypeMap,
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	if s.d.header(key) == -1 {
+		if n := len(s.d.lines); n != 0 && strings.TrimSpace(s.d.lines[n-1].text) != "" {
+			s.d.lines = append(s.d.lines, &line{section: s.d.lines[n-1].section})
+		}
+
+		s.d.lines = append(s.d.lines, &line{
+			text:    "[" + key + "]",
+			section: key,
+			header:  true,
+		})
+	}
+
+	return &Section{d: s.d, name: key}, nil
+}
+
+func (d *Document) key(section, key string) int {
+	for i, l := range d.lines {
+		if l.key == key && l.section == section {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Document) header(section string) int {
+	for i, l := range d.lines {
+		if l.header && l.section == section {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert adds l after the last key of the section, or after its
+// header if it has no keys.
+func (d *Document) insert(section string, l *line) {
+	i := -1
+
+	for j, x := range d.lines {
+		if x.section == section && (x.key != "" || x.header) {
+			i = j
+		}
+	}
+
+	i++
+
+	d.lines = append(d.lines[:i], append([]*line{l}, d.lines[i:]...)...)
+}
@@ -0,0 +1,123 @@
+package ini_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/ini"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const config = `; global settings
+name = example
+
+[server]
+host = localhost
+port: 8080
+
+# database
+[db]
+url=postgres://db
+`
+
+func TestDocument(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := ini.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if got, want := doc.List(ctx), []string{"name", "server", "db"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, err := objects.Get(ctx, doc, "server", "port"); err != nil || v != "8080" {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+
+	sets := []struct {
+		value any
+		keys  []string
+	}{
+		{9090, []string{"server", "port"}},
+		{true, []string{"server", "tls"}},
+		{"postgres://replica", []string{"db", "url"}},
+		{types.Map{"level": "debug"}, []string{"log"}},
+	}
+
+	for _, set := range sets {
+		if _, err := objects.Set(ctx, doc, set.value, set.keys...); err != nil {
+			t.Fatalf("Set(%v)=%+v", set.keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, doc, "name"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if _, err := objects.Put(ctx, doc, objects.TypeMap, "server", "nested"); err == nil {
+		t.Fatal("expected Put of a nested section to fail")
+	}
+
+	want := `; global settings
+
+[server]
+host = localhost
+port: 9090
+tls = true
+
+# database
+[db]
+url=postgres://replica
+
+[log]
+level = debug
+`
+
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestProperties(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := ini.Parse([]byte("! comment\napp.name=example\napp.port = 8080\n"))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if got, want := doc.List(ctx), []string{"app.name", "app.port"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if _, err := ini.Parse([]byte("[broken\n")); err == nil {
+		t.Fatal("expected Parse of an unterminated header to fail")
+	}
+}
+
+func TestPosition(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := ini.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	doc.File = "app.ini"
+
+	for keys, want := range map[string]string{
+		"name":        "app.ini:2:1",
+		"db":          "app.ini:9:1",
+		"server.port": "app.ini:6:1",
+	} {
+		if pos, ok := objects.PositionOf(ctx, doc, strings.Split(keys, ".")...); !ok || pos.String() != want {
+			t.Fatalf("PositionOf(%s)=%v, %t, want %s", keys, pos, ok, want)
+		}
+	}
+}
@@ -6,18 +6,57 @@ type (
 	Type          = types.Type
 	Reader        = types.Reader
 	SafeReader    = types.SafeReader
+	SafeLister    = types.SafeLister
 	ListerTo      = types.ListerTo
+	ValueLister   = types.ValueLister
+	KV            = types.KV
+	LegacyReader  = types.LegacyReader
 	Writer        = types.Writer
 	SafeWriter    = types.SafeWriter
+	Watcher       = types.Watcher
+	Event         = types.Event
+	Locker        = types.Locker
+	Pinger        = types.Pinger
+	Closer        = types.Closer
+	Unwrapper     = types.Unwrapper
 	Interface     = types.Interface
 	SafeInterface = types.SafeInterface
 	Iter          = types.Iter
+	Tx            = types.Tx
+	Transactor    = types.Transactor
+	BatchReader   = types.BatchReader
+	BatchWriter   = types.BatchWriter
+	Entry         = types.Entry
+	Result        = types.Result
+)
+
+type (
+	Capability = types.Capability
+	Position   = types.Position
+	Provenance = types.Provenance
+)
+
+const (
+	CapSafeReader  = types.CapSafeReader
+	CapSafeLister  = types.CapSafeLister
+	CapListerTo    = types.CapListerTo
+	CapWriter      = types.CapWriter
+	CapSafeWriter  = types.CapSafeWriter
+	CapWatcher     = types.CapWatcher
+	CapLocker      = types.CapLocker
+	CapPinger      = types.CapPinger
+	CapCloser      = types.CapCloser
+	CapValueLister = types.CapValueLister
+	CapTransactor  = types.CapTransactor
+	CapBatchReader = types.CapBatchReader
+	CapBatchWriter = types.CapBatchWriter
 )
 
 const (
 	TypeMap    = types.TypeMap
 	TypeSlice  = types.TypeSlice
 	TypeStruct = types.TypeStruct
+	TypeBytes  = types.TypeBytes
 )
 
 type (
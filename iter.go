@@ -3,11 +3,13 @@ package objects
 import (
 	"context"
 	"errors"
+
+	"rafal.dev/objects/types"
 )
 
 func Walk(r Reader) Iter {
 	return &iter{
-		queue: newQueue(context.TODO(), r),
+		root: r,
 	}
 }
 
@@ -25,11 +27,26 @@ type elm struct {
 	leaf   bool
 }
 
-func newQueue(ctx context.Context, r Reader) []elm {
-	return []elm{{parent: r, left: r.List(ctx)}}
+func push(ctx context.Context, queue []elm, r Reader, key Key) ([]elm, error) {
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return nil, &Error{
+			Op:  "List",
+			Key: key,
+			Got: r,
+			Err: err,
+		}
+	}
+
+	if len(keys) != 0 {
+		queue = append(queue, elm{parent: r, key: key, left: keys})
+	}
+
+	return queue, nil
 }
 
 type iter struct {
+	root  Reader
 	it    elm
 	queue []elm
 	done  bool
@@ -39,6 +56,20 @@ type iter struct {
 var _ Iter = (*iter)(nil)
 
 func (it *iter) Next(ctx context.Context) bool {
+	if it.done {
+		return false
+	}
+
+	if it.err = ctx.Err(); it.err != nil {
+		it.done = true
+		return false
+	}
+
+	if it.root != nil {
+		it.queue, it.err = push(ctx, nil, it.root, nil)
+		it.root = nil
+	}
+
 	if it.err != nil || len(it.queue) == 0 {
 		it.done = true
 		return false
@@ -64,7 +95,10 @@ func (it *iter) Next(ctx context.Context) bool {
 	}
 
 	if r, ok := it.it.v.(Reader); ok {
-		it.queue = append(it.queue, elm{parent: r, key: it.it.key, left: r.List(ctx)})
+		if it.queue, it.err = push(ctx, it.queue, r, it.it.key); it.err != nil {
+			it.done = true
+			return false
+		}
 	} else {
 		it.it.leaf = true
 	}
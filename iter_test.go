@@ -2,10 +2,12 @@ package objects_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"rafal.dev/objects"
 	"rafal.dev/objects/internal/misc"
+	"rafal.dev/objects/types"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -54,3 +56,36 @@ func TestIter(t *testing.T) {
 		})
 	}
 }
+
+func TestIterContext(t *testing.T) {
+	var (
+		it          = objects.Walk(objects.Make(newX()))
+		ctx, cancel = context.WithCancel(context.Background())
+		n           int
+	)
+
+	defer cancel()
+
+	for it.Next(ctx) {
+		if n++; n == 3 {
+			cancel()
+		}
+	}
+
+	if err := it.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %+v, want %+v", err, context.Canceled)
+	}
+
+	if n != 3 {
+		t.Fatalf("got %d, want %d", n, 3)
+	}
+
+	it = objects.Walk(types.Map{"empty": types.Map{}})
+
+	for it.Next(context.Background()) {
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err()=%+v", err)
+	}
+}
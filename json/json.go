@@ -0,0 +1,127 @@
+// Package json parses JSON documents into trees of objects
+// and serializes trees back to JSON.
+package json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/types"
+)
+
+// Parse parses the JSON document p into an Interface. The top-level
+// value of the document must be an object or an array.
+//
+// Numbers are kept as json.Number, so that they are serialized back
+// as they were, e.g. integers too large for float64 keep their digits.
+func Parse(p []byte) (objects.Interface, error) {
+	var v any
+
+	if err := unmarshal(p, &v); err != nil {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: err,
+		}
+	}
+
+	iface, ok := convert(v).(objects.Interface)
+	if !ok {
+		return nil, &objects.Error{
+			Op:   "Parse",
+			Got:  v,
+			Want: "object or array",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	return iface, nil
+}
+
+// Marshal serializes the tree of r into a compact JSON document.
+func Marshal(ctx context.Context, r objects.Reader) ([]byte, error) {
+	return marshal(ctx, r, &codec.JSONOptions{})
+}
+
+// MarshalIndent is like Marshal, but indents the document.
+func MarshalIndent(ctx context.Context, r objects.Reader, prefix, indent string) ([]byte, error) {
+	p, err := marshal(ctx, r, &codec.JSONOptions{
+		Prefix: prefix,
+		Indent: indent,
+	})
+
+	return bytes.TrimSuffix(p, []byte("\n")), err
+}
+
+// Get reads the value under the keys of the JSON document p.
+func Get(ctx context.Context, p []byte, keys ...string) (any, error) {
+	iface, err := Parse(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return objects.Get(ctx, iface, keys...)
+}
+
+// Set writes the value under the keys of the JSON document p
+// and returns the updated document.
+func Set(ctx context.Context, p []byte, value any, keys ...string) ([]byte, error) {
+	iface, err := Parse(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := objects.Set(ctx, iface, value, keys...); err != nil {
+		return nil, err
+	}
+
+	return Marshal(ctx, iface)
+}
+
+func unmarshal(p []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid data after top-level value")
+	}
+
+	return nil
+}
+
+func marshal(ctx context.Context, r objects.Reader, opts *codec.JSONOptions) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := codec.EncodeJSON(ctx, &buf, r, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func convert(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(types.Map, len(v))
+		for k, v := range v {
+			m[k] = convert(v)
+		}
+		return m
+	case []any:
+		s := make(types.Slice, len(v))
+		for i, v := range v {
+			s[i] = convert(v)
+		}
+		return &s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,126 @@
+package json_test
+
+import (
+	"context"
+	stdjson "encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/json"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	var ctx = context.Background()
+
+	p, err := os.ReadFile(filepath.Join("..", "testdata", "ansible-facts.json"))
+	if err != nil {
+		t.Fatalf("ReadFile()=%+v", err)
+	}
+
+	iface, err := json.Parse(p)
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	q, err := json.Marshal(ctx, iface)
+	if err != nil {
+		t.Fatalf("Marshal()=%+v", err)
+	}
+
+	var want, got any
+
+	if err := stdjson.Unmarshal(p, &want); err != nil {
+		t.Fatalf("Unmarshal()=%+v", err)
+	}
+
+	if err := stdjson.Unmarshal(q, &got); err != nil {
+		t.Fatalf("Unmarshal()=%+v", err)
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, err := objects.Get(ctx, iface, "ansible_apparmor", "status"); err != nil || v != "disabled" {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+
+	if _, err := json.Parse([]byte(`"string"`)); err == nil {
+		t.Fatal("expected Parse of a scalar document to fail")
+	}
+}
+
+func TestSet(t *testing.T) {
+	var (
+		ctx = context.Background()
+		doc = []byte(`{"server":{"host":"localhost","ports":[80]}}`)
+	)
+
+	doc, err := json.Set(ctx, doc, 443, "server", "ports", "-")
+	if err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	doc, err = json.Set(ctx, doc, "example.com", "server", "host")
+	if err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if got, want := string(doc), `{"server":{"host":"example.com","ports":[80,443]}}`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	v, err := json.Get(ctx, doc, "server", "ports", "1")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if want := stdjson.Number("443"); v != want {
+		t.Fatalf("got %#v, want %#v", v, want)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	var (
+		ctx = context.Background()
+		doc = []byte(`{"id":12345678901234567890,"ratio":0.1,"port":8080}`)
+	)
+
+	got, err := json.Set(ctx, doc, "api", "name")
+	if err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	want := `{"id":12345678901234567890,"name":"api","port":8080,"ratio":0.1}`
+
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	iface, err := json.Parse(doc)
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	var v struct {
+		ID    uint64  `json:"id"`
+		Ratio float64 `json:"ratio"`
+		Port  int     `json:"port"`
+	}
+
+	if err := objects.Decode(ctx, iface, &v, nil); err != nil {
+		t.Fatalf("Decode()=%+v", err)
+	}
+
+	if v.ID != 12345678901234567890 || v.Ratio != 0.1 || v.Port != 8080 {
+		t.Fatalf("got %+v", v)
+	}
+
+	if _, err := json.Parse([]byte(`{} {}`)); err == nil {
+		t.Fatal("expected Parse of trailing data to fail")
+	}
+}
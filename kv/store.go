@@ -0,0 +1,31 @@
+package kv
+
+import "context"
+
+// Store is a flat key-value store, whose keys are paths joined
+// with a separator. Get and Del are expected to return an error
+// wrapping types.ErrNotFound for missing keys.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) (previous bool, err error)
+	Del(ctx context.Context, key string) error
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// BatchStore is implemented by stores able to get or set many keys
+// in one round trip. GetMany gives an error wrapping
+// types.ErrNotFound for each missing key.
+type BatchStore interface {
+	GetMany(ctx context.Context, keys []string) ([][]byte, []error)
+	SetMany(ctx context.Context, keys []string, values [][]byte) []error
+}
+
+type Watcher interface {
+	Watch(ctx context.Context, prefix string) (<-chan Change, error)
+}
+
+type Change struct {
+	Key   string
+	Value []byte
+	Del   bool
+}
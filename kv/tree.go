@@ -0,0 +1,473 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects/types"
+)
+
+type Tree struct {
+	Store Store
+	Sep   string
+	Key   types.Key
+}
+
+var (
+	_ types.SafeInterface = (*Tree)(nil)
+	_ types.ListerTo      = (*Tree)(nil)
+	_ types.SafeLister    = (*Tree)(nil)
+	_ types.Watcher       = (*Tree)(nil)
+	_ types.BatchReader   = (*Tree)(nil)
+	_ types.BatchWriter   = (*Tree)(nil)
+)
+
+func New(s Store, sep string) *Tree {
+	return &Tree{
+		Store: s,
+		Sep:   sep,
+	}
+}
+
+func (t *Tree) Type() types.Type {
+	return types.TypeMap
+}
+
+func (t *Tree) Get(ctx context.Context, key string) (any, bool) {
+	v, err := t.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (t *Tree) SafeGet(ctx context.Context, key string) (any, error) {
+	var path = t.path(key)
+
+	switch p, err := t.Store.Get(ctx, path); {
+	case err == nil:
+		return p, nil
+	case !errors.Is(err, types.ErrNotFound):
+		return nil, &types.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	switch keys, err := t.Store.Keys(ctx, path+t.Sep); {
+	case err != nil:
+		return nil, &types.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: err,
+		}
+	case len(keys) == 0:
+		return nil, &types.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: types.ErrNotFound,
+		}
+	default:
+		return t.child(key), nil
+	}
+}
+
+func (t *Tree) List(ctx context.Context) []string {
+	var keys []string
+	t.ListTo(ctx, &keys)
+	return keys
+}
+
+func (t *Tree) SafeList(ctx context.Context) ([]string, error) {
+	var keys []string
+
+	if err := t.listTo(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (t *Tree) ListTo(ctx context.Context, keys *[]string) {
+	_ = t.listTo(ctx, keys)
+}
+
+func (t *Tree) listTo(ctx context.Context, keys *[]string) error {
+	var prefix = t.prefix()
+
+	all, err := t.Store.Keys(ctx, prefix)
+	if err != nil {
+		return &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	var (
+		n    = len(*keys)
+		seen = make(map[string]struct{})
+	)
+
+	for _, k := range all {
+		k = strings.TrimPrefix(k, prefix)
+
+		if i := strings.Index(k, t.Sep); i != -1 {
+			k = k[:i]
+		}
+
+		if _, ok := seen[k]; ok || k == "" {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		*keys = append(*keys, k)
+	}
+
+	sort.Strings((*keys)[n:])
+
+	return nil
+}
+
+func (t *Tree) Del(ctx context.Context, key string) bool {
+	return t.SafeDel(ctx, key) == nil
+}
+
+func (t *Tree) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := t.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (t *Tree) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := t.SafePut(ctx, key, hint)
+	return w
+}
+
+func (t *Tree) SafeDel(ctx context.Context, key string) error {
+	var (
+		path  = t.path(key)
+		found bool
+	)
+
+	switch err := t.Store.Del(ctx, path); {
+	case err == nil:
+		found = true
+	case !errors.Is(err, types.ErrNotFound):
+		return &types.Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	keys, err := t.Store.Keys(ctx, path+t.Sep)
+	if err != nil {
+		return &types.Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	for _, k := range keys {
+		if err := t.Store.Del(ctx, k); err != nil && !errors.Is(err, types.ErrNotFound) {
+			return &types.Error{
+				Op:  "Del",
+				Key: []string{key},
+				Got: k,
+				Err: err,
+			}
+		}
+
+		found = true
+	}
+
+	if !found {
+		return &types.Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: types.ErrNotFound,
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	var p []byte
+
+	switch v := value.(type) {
+	case []byte:
+		p = v
+	case string:
+		p = []byte(v)
+	default:
+		return false, &types.Error{
+			Op:   "Set",
+			Key:  []string{key},
+			Got:  value,
+			Want: []byte(nil),
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+
+	ok, err := t.Store.Set(ctx, t.path(key), p)
+	if err != nil {
+		return false, &types.Error{
+			Op:  "Set",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	return ok, nil
+}
+
+func (t *Tree) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	return t.child(key), nil
+}
+
+// GetMany gets the keys with a single GetMany of the store, if it is
+// a BatchStore, falling back to SafeGet for subtrees.
+func (t *Tree) GetMany(ctx context.Context, keys []types.Key) []types.Result {
+	var (
+		res   = make([]types.Result, len(keys))
+		paths = make([]string, len(keys))
+	)
+
+	s, ok := t.Store.(BatchStore)
+	if !ok {
+		for i, k := range keys {
+			res[i] = t.get(ctx, k)
+		}
+
+		return res
+	}
+
+	for i, k := range keys {
+		paths[i] = t.prefix() + strings.Join(k, t.Sep)
+	}
+
+	values, errs := s.GetMany(ctx, paths)
+
+	for i, k := range keys {
+		res[i].Key = k
+
+		switch err := errs[i]; {
+		case len(k) == 0, errors.Is(err, types.ErrNotFound):
+			res[i] = t.get(ctx, k)
+		case err == nil:
+			res[i].Value = values[i]
+		default:
+			res[i].Err = &types.Error{
+				Op:  "Get",
+				Key: k,
+				Err: err,
+			}
+		}
+	}
+
+	return res
+}
+
+// SetMany sets the keys with a single SetMany of the store, if it is
+// a BatchStore. Results of a BatchStore do not tell previous values.
+func (t *Tree) SetMany(ctx context.Context, entries []types.Entry) []types.Result {
+	var (
+		res    = make([]types.Result, len(entries))
+		idx    []int
+		paths  []string
+		values [][]byte
+	)
+
+	s, ok := t.Store.(BatchStore)
+	if !ok {
+		for i, e := range entries {
+			res[i] = t.set(ctx, e)
+		}
+
+		return res
+	}
+
+	for i, e := range entries {
+		res[i].Key = e.Key
+
+		var p []byte
+
+		switch v := e.Value.(type) {
+		case []byte:
+			p = v
+		case string:
+			p = []byte(v)
+		default:
+			res[i].Err = &types.Error{
+				Op:   "Set",
+				Key:  e.Key,
+				Got:  e.Value,
+				Want: []byte(nil),
+				Err:  types.ErrUnexpectedType,
+			}
+			continue
+		}
+
+		if len(e.Key) == 0 {
+			res[i] = t.set(ctx, e)
+			continue
+		}
+
+		idx = append(idx, i)
+		paths = append(paths, t.prefix()+strings.Join(e.Key, t.Sep))
+		values = append(values, p)
+	}
+
+	if len(paths) == 0 {
+		return res
+	}
+
+	for j, err := range s.SetMany(ctx, paths, values) {
+		if err != nil {
+			res[idx[j]].Err = &types.Error{
+				Op:  "Set",
+				Key: entries[idx[j]].Key,
+				Err: err,
+			}
+		}
+	}
+
+	return res
+}
+
+func (t *Tree) DelMany(ctx context.Context, keys []types.Key) []types.Result {
+	res := make([]types.Result, len(keys))
+
+	for i, k := range keys {
+		res[i].Key = k
+
+		if len(k) == 0 {
+			res[i].Err = &types.Error{
+				Op:  "Del",
+				Err: types.ErrEmpty,
+			}
+			continue
+		}
+
+		res[i].Err = types.PrefixWriter(t, k.Dir()...).SafeDel(ctx, k.Base())
+	}
+
+	return res
+}
+
+func (t *Tree) get(ctx context.Context, k types.Key) types.Result {
+	res := types.Result{Key: k}
+
+	if len(k) == 0 {
+		res.Err = &types.Error{
+			Op:  "Get",
+			Err: types.ErrEmpty,
+		}
+		return res
+	}
+
+	res.Value, res.Err = types.PrefixReader(t, k.Dir()...).SafeGet(ctx, k.Base())
+
+	return res
+}
+
+func (t *Tree) set(ctx context.Context, e types.Entry) types.Result {
+	res := types.Result{Key: e.Key}
+
+	if len(e.Key) == 0 {
+		res.Err = &types.Error{
+			Op:  "Set",
+			Err: types.ErrEmpty,
+		}
+		return res
+	}
+
+	res.Value, res.Err = types.PrefixWriter(t, e.Key.Dir()...).SafeSet(ctx, e.Key.Base(), e.Value)
+
+	return res
+}
+
+func (t *Tree) Watch(ctx context.Context, key string) <-chan types.Event {
+	var (
+		ch     = make(chan types.Event)
+		path   = t.path(key)
+		prefix = t.prefix()
+	)
+
+	if key == "" {
+		path = strings.TrimSuffix(prefix, t.Sep)
+	}
+
+	w, ok := t.Store.(Watcher)
+	if !ok {
+		close(ch)
+		return ch
+	}
+
+	changes, err := w.Watch(ctx, path)
+	if err != nil {
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer close(ch)
+
+		for c := range changes {
+			if path != "" && c.Key != path && !strings.HasPrefix(c.Key, path+t.Sep) {
+				continue
+			}
+
+			ev := types.Event{
+				Op:  "Set",
+				Key: strings.Split(strings.TrimPrefix(c.Key, prefix), t.Sep),
+			}
+
+			if c.Del {
+				ev.Op = "Del"
+			} else {
+				ev.Value = c.Value
+			}
+
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+func (t *Tree) child(key string) *Tree {
+	return &Tree{
+		Store: t.Store,
+		Sep:   t.Sep,
+		Key:   append(t.Key.Copy(), key),
+	}
+}
+
+func (t *Tree) path(key string) string {
+	return t.prefix() + key
+}
+
+func (t *Tree) prefix() string {
+	if len(t.Key) == 0 {
+		return ""
+	}
+	return strings.Join(t.Key, t.Sep) + t.Sep
+}
+
+func (t *Tree) Ping(ctx context.Context) error {
+	return types.Ping(ctx, t.Store)
+}
+
+func (t *Tree) Close(ctx context.Context) error {
+	return types.Close(ctx, t.Store)
+}
+
+func (t *Tree) Unwrap() any {
+	return t.Store
+}
@@ -0,0 +1,150 @@
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/kv"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mapStore map[string][]byte
+
+func (m mapStore) Get(_ context.Context, key string) ([]byte, error) {
+	p, ok := m[key]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return p, nil
+}
+
+func (m mapStore) Set(_ context.Context, key string, value []byte) (bool, error) {
+	_, ok := m[key]
+	m[key] = value
+	return ok, nil
+}
+
+func (m mapStore) Del(_ context.Context, key string) error {
+	if _, ok := m[key]; !ok {
+		return types.ErrNotFound
+	}
+	delete(m, key)
+	return nil
+}
+
+func (m mapStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range m {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func TestTree(t *testing.T) {
+	var (
+		s   = make(mapStore)
+		tr  = kv.New(s, "/")
+		ctx = context.Background()
+		src = types.Map{
+			"foo": types.Map{
+				"bar": "1",
+				"baz": types.Map{
+					"qux": "2",
+				},
+			},
+			"top": "3",
+		}
+	)
+
+	if err := objects.Copy(ctx, tr, src); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	got := make(map[string]string)
+
+	for k, v := range s {
+		got[k] = string(v)
+	}
+
+	want := map[string]string{
+		"foo/bar":     "1",
+		"foo/baz/qux": "2",
+		"top":         "3",
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if got, want := tr.List(ctx), []string{"foo", "top"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	v, err := objects.Get(ctx, tr, "foo", "baz", "qux")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if string(v.([]byte)) != "2" {
+		t.Fatalf("got %q, want %q", v, "2")
+	}
+
+	if err := objects.Del(ctx, tr, "foo"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if got, want := tr.List(ctx), []string{"top"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	_, err = objects.Get(ctx, tr, "foo", "bar")
+	if e := (&types.Error{}); !types.ErrAs(err, e, types.IsSentinelErr(types.ErrNotFound)) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrNotFound)
+	}
+}
+
+type downStore struct {
+	mapStore
+}
+
+func (downStore) Keys(context.Context, string) ([]string, error) {
+	return nil, errDown
+}
+
+var errDown = errors.New("backend is down")
+
+func TestTreeSafeList(t *testing.T) {
+	var (
+		tr  = kv.New(downStore{}, "/")
+		ctx = context.Background()
+	)
+
+	if _, err := tr.SafeList(ctx); !errors.Is(err, errDown) {
+		t.Fatalf("got %+v, want %+v", err, errDown)
+	}
+
+	it := objects.Walk(tr)
+
+	for it.Next(ctx) {
+	}
+
+	if err := it.Err(); !errors.Is(err, errDown) {
+		t.Fatalf("got %+v, want %+v", err, errDown)
+	}
+}
+
+func TestTreeConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(*testing.T) types.Interface {
+		return kv.New(make(mapStore), "/")
+	})
+}
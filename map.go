@@ -2,8 +2,11 @@ package objects
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+
+	"rafal.dev/objects/types"
 )
 
 type Map struct {
@@ -13,7 +16,10 @@ type Map struct {
 var (
 	_ Reader     = (*Map)(nil)
 	_ SafeReader = (*Map)(nil)
+	_ SafeLister = (*Map)(nil)
 	_ ListerTo   = (*Map)(nil)
+	_ Writer     = (*Map)(nil)
+	_ SafeWriter = (*Map)(nil)
 )
 
 func (m *Map) Type() Type {
@@ -26,17 +32,13 @@ func (m *Map) Get(ctx context.Context, key string) (any, bool) {
 }
 
 func (m *Map) SafeGet(ctx context.Context, key string) (any, error) {
-	var (
-		t = m.v.Type().Key()
-		k = reflect.ValueOf(key)
-	)
-
-	if k.CanConvert(t) {
-		k = k.Convert(t)
+	k, err := m.key("Get", key)
+	if err != nil {
+		return nil, err
 	}
 
 	switch v := m.v.MapIndex(k); {
-	case v.IsZero():
+	case !v.IsValid() || v.IsZero():
 		return nil, &Error{
 			Op:  "Get",
 			Key: []string{key},
@@ -62,6 +64,10 @@ func (m *Map) List(ctx context.Context) []string {
 	return keys
 }
 
+func (m *Map) SafeList(ctx context.Context) ([]string, error) {
+	return m.List(ctx), nil
+}
+
 func (m *Map) ListTo(ctx context.Context, keys *[]string) {
 	for _, k := range m.v.MapKeys() {
 		var key string
@@ -74,3 +80,196 @@ func (m *Map) ListTo(ctx context.Context, keys *[]string) {
 		*keys = append(*keys, key)
 	}
 }
+
+func (m *Map) Del(ctx context.Context, key string) bool {
+	return m.SafeDel(ctx, key) == nil
+}
+
+func (m *Map) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := m.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (m *Map) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := m.SafePut(ctx, key, hint)
+	return w
+}
+
+func (m *Map) SafeDel(ctx context.Context, key string) error {
+	k, err := m.key("Del", key)
+	if err != nil {
+		return err
+	}
+
+	if !m.v.MapIndex(k).IsValid() {
+		return &Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	m.v.SetMapIndex(k, reflect.Value{})
+
+	return nil
+}
+
+func (m *Map) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	k, err := m.key("Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	ok := m.v.MapIndex(k).IsValid()
+
+	v, err := convert(ctx, value, m.v.Type().Elem(), "Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	if err := m.init("Set", key); err != nil {
+		return false, err
+	}
+
+	m.v.SetMapIndex(k, v)
+
+	return ok, nil
+}
+
+func (m *Map) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	k, err := m.key("Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := m.v.MapIndex(k); v.IsValid() && !v.IsZero() && v.CanInterface() {
+		if w, ok := tryMake(v.Interface()).(Writer); ok {
+			return w, nil
+		}
+	}
+
+	v, w, err := makeElem(m.v.Type().Elem(), hint, "Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case w != nil:
+	case v.Kind() == reflect.Map:
+		w = &Map{v: v}
+	default:
+		return nil, &Error{
+			Op:   "Put",
+			Key:  []string{key},
+			Got:  v.Type().String(),
+			Want: Writer(nil),
+			Err:  errors.New("map elements are not addressable"),
+		}
+	}
+
+	if err := m.init("Put", key); err != nil {
+		return nil, err
+	}
+
+	m.v.SetMapIndex(k, v)
+
+	return w, nil
+}
+
+func convert(ctx context.Context, value any, t reflect.Type, op, key string) (reflect.Value, error) {
+	if v := reflect.ValueOf(value); v.IsValid() && v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	var (
+		d = newDecoder(nil)
+		v = reflect.New(t).Elem()
+	)
+
+	if d.decode(ctx, tryMake(value), v, Key{key}); len(d.errs) != 0 {
+		return reflect.Value{}, &Error{
+			Op:  op,
+			Key: []string{key},
+			Err: d.errs.Err(),
+		}
+	}
+
+	return v, nil
+}
+
+// makeElem creates an empty container value of type t. The returned
+// Writer is nil for reflected maps and slices.
+func makeElem(t reflect.Type, hint Type, op, key string) (reflect.Value, Writer, error) {
+	switch t.Kind() {
+	case reflect.Map:
+		return reflect.MakeMap(t), nil, nil
+	case reflect.Slice:
+		return reflect.MakeSlice(t, 0, 0), nil, nil
+	case reflect.Interface:
+		var w Writer = types.Map{}
+		if hint == TypeSlice {
+			w = &types.Slice{}
+		}
+
+		if v := reflect.ValueOf(w); v.Type().AssignableTo(t) {
+			return v, w, nil
+		}
+	}
+
+	return reflect.Value{}, nil, &Error{
+		Op:   op,
+		Key:  []string{key},
+		Got:  t.String(),
+		Want: Writer(nil),
+		Err:  ErrUnexpectedType,
+	}
+}
+
+func (m *Map) key(op, key string) (reflect.Value, error) {
+	var (
+		t = m.v.Type().Key()
+		k = reflect.ValueOf(key)
+	)
+
+	if t.Kind() != reflect.String {
+		var err error
+		if k, err = parse(key, t); err != nil {
+			return reflect.Value{}, &Error{
+				Op:   op,
+				Key:  []string{key},
+				Want: t.String(),
+				Err:  err,
+			}
+		}
+	}
+
+	if !k.CanConvert(t) {
+		return reflect.Value{}, &Error{
+			Op:   op,
+			Key:  []string{key},
+			Got:  key,
+			Want: t.String(),
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	return k.Convert(t), nil
+}
+
+func (m *Map) init(op, key string) error {
+	if !m.v.IsNil() {
+		return nil
+	}
+
+	if !m.v.CanSet() {
+		return &Error{
+			Op:  op,
+			Key: []string{key},
+			Err: errors.New("cannot set value of nil map"),
+		}
+	}
+
+	m.v.Set(reflect.MakeMap(m.v.Type()))
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package objects_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMapWriter(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   map[string]map[int]string
+		w   = objects.Make(&m).(objects.Writer)
+	)
+
+	sub, err := objects.Put(ctx, w, objects.TypeMap, "ports")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	if _, err := sub.(objects.SafeWriter).SafeSet(ctx, "80", "http"); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, w, "https", "ports", "443"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, w, "x", "ports", "abc"); err == nil {
+		t.Fatal("expected Set with invalid key to fail")
+	}
+
+	if _, err := objects.Set(ctx, w, map[string]any{"22": "ssh"}, "admin"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, w, "ports", "80"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	want := map[string]map[int]string{
+		"ports": {443: "https"},
+		"admin": {22: "ssh"},
+	}
+
+	if !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+}
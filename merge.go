@@ -0,0 +1,377 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+
+	"rafal.dev/objects/types"
+)
+
+// MergeStrategy tells how Merge resolves a conflict, i.e. a key,
+// which is in both trees with different values and is not a subtree
+// on both sides.
+type MergeStrategy int
+
+const (
+	MergeOverwrite MergeStrategy = iota // src value replaces dst one
+	MergeKeep                           // dst value is kept
+	MergeError                          // conflict is reported
+)
+
+type MergeOptions struct {
+	Strategy MergeStrategy
+
+	// AppendSlices appends elements of src slices to dst slices,
+	// instead of merging them index by index.
+	AppendSlices bool
+}
+
+var DefaultMergeOptions = &MergeOptions{
+	Strategy: MergeOverwrite,
+}
+
+// Merge deep-merges src into dst, e.g. to layer configuration
+// files. Maps, and slices of the same type, are merged key by key,
+// keys missing in dst are written and conflicts are resolved with
+// the strategy of opts, which defaults to DefaultMergeOptions.
+//
+// With the MergeError strategy all conflicts are reported, each
+// with its key; keys without conflicts are merged nonetheless.
+func Merge(ctx context.Context, dst Writer, src Reader, opts *MergeOptions) error {
+	if opts == nil {
+		opts = DefaultMergeOptions
+	}
+
+	m := &merger{opts: opts}
+
+	if err := m.merge(ctx, dst, src, nil); err != nil {
+		return err
+	}
+
+	return m.errs.Err()
+}
+
+type merger struct {
+	opts *MergeOptions
+	errs types.Errors
+}
+
+func (m *merger) merge(ctx context.Context, dst Writer, src Reader, key Key) error {
+	keys, err := List(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	var (
+		pw    = PrefixedWriter{W: dst}
+		dr, _ = dst.(Reader)
+	)
+
+	for _, k := range keys {
+		sv, err := PrefixedReader{R: src}.SafeGet(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		var dv any
+
+		if dr != nil {
+			dv, err = PrefixedReader{R: dr}.SafeGet(ctx, k)
+		} else {
+			err = ErrNotFound
+		}
+
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if err := set(ctx, pw, k, sv); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+
+		var (
+			child  = append(key.Copy(), k)
+			sr, sx = sv.(Reader)
+			dr, dx = dv.(Reader)
+		)
+
+		switch {
+		case sx && dx && sr.Type() == TypeSlice && dr.Type() == TypeSlice && m.opts.AppendSlices:
+			if err := m.append(ctx, pw, k, dr, sr); err != nil {
+				return err
+			}
+		case sx && dx && sr.Type() == dr.Type():
+			w, ok := dv.(Writer)
+			if !ok {
+				if w, err = pw.SafePut(ctx, k, sr.Type()); err != nil {
+					return err
+				}
+			}
+
+			if err := m.merge(ctx, w, sr, child); err != nil {
+				return err
+			}
+		case !sx && !dx && reflect.DeepEqual(sv, dv):
+		default:
+			if err := m.conflict(ctx, pw, k, dv, sv, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *merger) conflict(ctx context.Context, pw PrefixedWriter, k string, dv, sv any, key Key) error {
+	switch m.opts.Strategy {
+	case MergeKeep:
+		return nil
+	case MergeError:
+		m.errs = append(m.errs, &Error{
+			Op:   "Merge",
+			Key:  key,
+			Got:  sv,
+			Want: dv,
+			Err:  ErrConflict,
+		})
+		return nil
+	}
+
+	if _, ok := dv.(Reader); ok {
+		if err := pw.SafeDel(ctx, k); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	return set(ctx, pw, k, sv)
+}
+
+// append replaces the dst slice under the key with the concatenation
+// of dst and src elements, so that it works regardless of whether
+// the slice is stored by value.
+func (m *merger) append(ctx context.Context, pw PrefixedWriter, k string, dst, src Reader) error {
+	var s types.Slice
+
+	for _, r := range []Reader{dst, src} {
+		kvs, err := ListValues(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		for _, kv := range kvs {
+			v, err := detach(ctx, kv.Value)
+			if err != nil {
+				return err
+			}
+
+			s = append(s, v)
+		}
+	}
+
+	if err := pw.SafeDel(ctx, k); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return set(ctx, pw, k, &s)
+}
+
+// set writes v under the key, copying the tree of v if it is
+// a Reader.
+func set(ctx context.Context, pw PrefixedWriter, k string, v any) error {
+	r, ok := v.(Reader)
+	if !ok {
+		_, err := pw.SafeSet(ctx, k, v)
+		return err
+	}
+
+	w, err := pw.SafePut(ctx, k, r.Type())
+	if err != nil {
+		return err
+	}
+
+	return Copy(ctx, w, r)
+}
+
+// detach copies the tree of v, if it is a Reader, so that it stays
+// valid after the original is deleted.
+func detach(ctx context.Context, v any) (any, error) {
+	r, ok := v.(Reader)
+	if !ok {
+		return v, nil
+	}
+
+	w := makeLike(r)
+
+	if err := Copy(ctx, w, r); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Conflict is a key changed differently in both trees merged with
+// Merge3. Values missing in a tree are nil.
+type Conflict struct {
+	Key    Key
+	Base   any
+	Ours   any
+	Theirs any
+}
+
+// Merge3 merges changes made to base in ours and theirs, e.g. local
+// and upstream edits of a configuration. Keys changed only in one of
+// the trees take its value; subtrees changed in both are merged key
+// by key, so slices are merged index by index. A key changed
+// differently in both trees is a conflict and keeps the value of ours
+// in the merged tree.
+func Merge3(ctx context.Context, base, ours, theirs Reader) (Interface, []Conflict, error) {
+	var (
+		m      = &merger3{}
+		merged = makeLike(ours)
+	)
+
+	if err := m.merge(ctx, merged, base, ours, theirs, nil); err != nil {
+		return nil, nil, err
+	}
+
+	return merged, m.conflicts, nil
+}
+
+type merger3 struct {
+	conflicts []Conflict
+}
+
+func (m *merger3) merge(ctx context.Context, w Writer, base, ours, theirs Reader, key Key) error {
+	var (
+		pw    = PrefixedWriter{W: w}
+		trees [3]map[string]any
+		all   []string
+	)
+
+	for i, r := range []Reader{base, ours, theirs} {
+		if r == nil {
+			continue
+		}
+
+		kvs, err := keys(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		for k := range kvs {
+			if !contains(trees[:i], k) {
+				all = append(all, k)
+			}
+		}
+
+		trees[i] = kvs
+	}
+
+	sort.Strings(all)
+
+	for _, k := range all {
+		var (
+			b, bok = trees[0][k]
+			o, ook = trees[1][k]
+			t, tok = trees[2][k]
+		)
+
+		switch {
+		case m.equal(ctx, o, ook, t, tok), m.equal(ctx, b, bok, t, tok):
+			if ook {
+				if err := set(ctx, pw, k, o); err != nil {
+					return err
+				}
+			}
+			continue
+		case m.equal(ctx, b, bok, o, ook):
+			if tok {
+				if err := set(ctx, pw, k, t); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var (
+			child  = append(key.Copy(), k)
+			or, ox = o.(Reader)
+			tr, tx = t.(Reader)
+		)
+
+		if ox && tx && or.Type() == tr.Type() {
+			br, bx := b.(Reader)
+			if bx && br.Type() != or.Type() {
+				br = nil
+			}
+
+			sub, err := pw.SafePut(ctx, k, or.Type())
+			if err != nil {
+				return err
+			}
+
+			if err := m.merge(ctx, sub, br, or, tr, child); err != nil {
+				return err
+			}
+			continue
+		}
+
+		m.conflicts = append(m.conflicts, Conflict{
+			Key:    child,
+			Base:   b,
+			Ours:   o,
+			Theirs: t,
+		})
+
+		if ook {
+			if err := set(ctx, pw, k, o); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *merger3) equal(ctx context.Context, x any, okx bool, y any, oky bool) bool {
+	if okx != oky {
+		return false
+	}
+
+	rx, isx := x.(Reader)
+	ry, isy := y.(Reader)
+
+	switch {
+	case isx && isy:
+		if rx.Type() != ry.Type() {
+			return false
+		}
+
+		p, err := Diff(ctx, rx, ry)
+		return err == nil && len(p) == 0
+	case isx || isy:
+		return false
+	default:
+		return reflect.DeepEqual(x, y)
+	}
+}
+
+func contains(trees []map[string]any, k string) bool {
+	for _, t := range trees {
+		if _, ok := t[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func makeLike(r Reader) Interface {
+	if r != nil && r.Type() == TypeSlice {
+		return &types.Slice{}
+	}
+	return make(types.Map)
+}
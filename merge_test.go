@@ -0,0 +1,159 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMerge(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		base = func() types.Map {
+			return types.Map{
+				"name": "api",
+				"db": types.Map{
+					"host": "localhost",
+					"port": 5432,
+				},
+				"tags": &types.Slice{"a", "b"},
+			}
+		}
+		override = types.Map{
+			"db": types.Map{
+				"host": "db.internal",
+				"pool": 10,
+			},
+			"tags": &types.Slice{"c"},
+			"name": "api",
+		}
+	)
+
+	cases := map[string]struct {
+		opts *objects.MergeOptions
+		want types.Map
+	}{
+		"overwrite": {
+			opts: nil,
+			want: types.Map{
+				"name": "api",
+				"db": types.Map{
+					"host": "db.internal",
+					"port": 5432,
+					"pool": 10,
+				},
+				"tags": &types.Slice{"c", "b"},
+			},
+		},
+		"keep": {
+			opts: &objects.MergeOptions{Strategy: objects.MergeKeep},
+			want: types.Map{
+				"name": "api",
+				"db": types.Map{
+					"host": "localhost",
+					"port": 5432,
+					"pool": 10,
+				},
+				"tags": &types.Slice{"a", "b"},
+			},
+		},
+		"append": {
+			opts: &objects.MergeOptions{AppendSlices: true},
+			want: types.Map{
+				"name": "api",
+				"db": types.Map{
+					"host": "db.internal",
+					"port": 5432,
+					"pool": 10,
+				},
+				"tags": &types.Slice{"a", "b", "c"},
+			},
+		},
+	}
+
+	for name, cas := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := base()
+
+			if err := objects.Merge(ctx, got, override, cas.opts); err != nil {
+				t.Fatalf("Merge()=%+v", err)
+			}
+
+			if diff := cmp.Diff(cas.want, got); diff != "" {
+				t.Fatalf("got != want (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	got := base()
+
+	err := objects.Merge(ctx, got, override, &objects.MergeOptions{Strategy: objects.MergeError})
+	if !errors.Is(err, objects.ErrConflict) {
+		t.Fatalf("Merge()=%+v, want %v", err, objects.ErrConflict)
+	}
+
+	var errs objects.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %+v, want 2 errors", err)
+	}
+
+	if v, err := objects.Get(ctx, got, "db", "pool"); err != nil || v != 10 {
+		t.Fatalf("Get()=%v, %+v, want 10", v, err)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		base = types.Map{
+			"name":  "api",
+			"port":  8080,
+			"debug": false,
+			"db":    types.Map{"host": "localhost", "pool": 5},
+		}
+		ours = types.Map{
+			"name":  "api",
+			"port":  9090,
+			"debug": false,
+			"db":    types.Map{"host": "db.local", "pool": 5},
+			"tls":   true,
+		}
+		theirs = types.Map{
+			"name": "api-v2",
+			"port": 7070,
+			"db":   types.Map{"host": "localhost", "pool": 10},
+		}
+	)
+
+	got, conflicts, err := objects.Merge3(ctx, base, ours, theirs)
+	if err != nil {
+		t.Fatalf("Merge3()=%+v", err)
+	}
+
+	want := types.Map{
+		"name": "api-v2",
+		"port": 9090,
+		"db":   types.Map{"host": "db.local", "pool": 10},
+		"tls":  true,
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	wantConflicts := []objects.Conflict{{
+		Key:    objects.Key{"port"},
+		Base:   8080,
+		Ours:   9090,
+		Theirs: 7070,
+	}}
+
+	if diff := cmp.Diff(wantConflicts, conflicts); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
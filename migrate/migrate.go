@@ -0,0 +1,333 @@
+// Package migrate rewrites paths and values of stored trees with
+// declarative rules, e.g. when the schema of a configuration changes.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// Rule is a rewrite of keys matching a dot-separated path, which
+// segments may be "*" wildcards, e.g. "tenants.*.db_host". Rules are
+// identified by their IDs in the state of migrations.
+type Rule struct {
+	ID string
+
+	op        string
+	from, to  types.Key
+	split     func(any) (map[string]any, error)
+	transform func(any) (any, error)
+}
+
+// Rename moves values of keys matching from to to, which has
+// wildcards substituted with the matched segments in order, e.g.
+// "tenants.*.db_host" to "tenants.*.db.host".
+func Rename(id, from, to string) Rule {
+	return Rule{ID: id, op: "Rename", from: strings.Split(from, "."), to: strings.Split(to, ".")}
+}
+
+// Split replaces values of keys matching from with the values fn
+// gives under dot-separated keys relative to the parent of the key.
+func Split(id, from string, fn func(any) (map[string]any, error)) Rule {
+	return Rule{ID: id, op: "Split", from: strings.Split(from, "."), split: fn}
+}
+
+// Transform replaces values of keys matching from with the ones
+// fn gives.
+func Transform(id, from string, fn func(any) (any, error)) Rule {
+	return Rule{ID: id, op: "Transform", from: strings.Split(from, "."), transform: fn}
+}
+
+// Step is a rewrite of a single key.
+type Step struct {
+	Rule string
+	Op   string
+	From types.Key
+	To   []types.Key
+}
+
+// Progress is reported after each step.
+type Progress struct {
+	Step  Step
+	Rule  int // index of the rule
+	Rules int
+	Steps int // steps made so far
+}
+
+type Options struct {
+	// DryRun gives the steps without making them.
+	DryRun bool
+
+	// Progress, when non-nil, is called after each step.
+	Progress func(context.Context, Progress)
+
+	// StateKey is the key, under which IDs of completed rules, and
+	// the keys already rewritten by a rule in progress, are stored in
+	// the migrated tree, so that an interrupted migration resumes with
+	// the first step not made. Rules do not match the StateKey itself.
+	// Empty StateKey disables the state.
+	StateKey string
+}
+
+var DefaultOptions = &Options{
+	StateKey: "_migrations",
+}
+
+// Run applies the rules to the tree in order, giving the steps made,
+// or the ones it would make for a dry run. It stops at the first
+// failing step; running the migration again resumes it.
+func Run(ctx context.Context, iface types.Interface, rules []Rule, opts *Options) ([]Step, error) {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	var steps []Step
+
+	for i, rule := range rules {
+		done, made, err := completed(ctx, iface, opts, rule)
+		if err != nil {
+			return steps, err
+		}
+
+		if done {
+			continue
+		}
+
+		matches, err := match(ctx, iface, rule.from)
+		if err != nil {
+			return steps, ruleError(rule, rule.from, err)
+		}
+
+		for _, m := range matches {
+			if opts.StateKey != "" && m.key[0] == opts.StateKey {
+				continue
+			}
+
+			if _, ok := made[m.key.ID()]; ok {
+				continue
+			}
+
+			step, err := apply(ctx, iface, rule, m, opts.DryRun)
+			if err != nil {
+				return steps, ruleError(rule, m.key, err)
+			}
+
+			if !opts.DryRun && opts.StateKey != "" {
+				if err := set(ctx, iface, types.Key{opts.StateKey, rule.ID, m.key.ID()}, true); err != nil {
+					return steps, ruleError(rule, m.key, err)
+				}
+			}
+
+			steps = append(steps, step)
+
+			if opts.Progress != nil {
+				opts.Progress(ctx, Progress{
+					Step:  step,
+					Rule:  i,
+					Rules: len(rules),
+					Steps: len(steps),
+				})
+			}
+		}
+
+		if !opts.DryRun && opts.StateKey != "" {
+			if err := set(ctx, iface, types.Key{opts.StateKey, rule.ID}, true); err != nil {
+				return steps, ruleError(rule, nil, err)
+			}
+		}
+	}
+
+	return steps, nil
+}
+
+type matched struct {
+	key      types.Key
+	wildcard []string
+	value    any
+}
+
+// match finds keys of r matching the pattern, along with the keys
+// matched by its wildcards.
+func match(ctx context.Context, r types.Reader, pattern types.Key) ([]matched, error) {
+	var all []matched
+
+	err := types.Match(ctx, r, pattern, nil, func(key types.Key, v any) error {
+		var wildcard []string
+
+		for i := range pattern {
+			if pattern[i] == "*" {
+				wildcard = append(wildcard, key[i])
+			}
+		}
+
+		all = append(all, matched{key: key, wildcard: wildcard, value: v})
+		return nil
+	})
+
+	return all, err
+}
+
+func apply(ctx context.Context, iface types.Interface, rule Rule, m matched, dry bool) (Step, error) {
+	step := Step{
+		Rule: rule.ID,
+		Op:   rule.op,
+		From: m.key,
+	}
+
+	var writes []any // values of step.To, in order
+
+	switch rule.op {
+	case "Rename":
+		to := substitute(rule.to, m.wildcard)
+		step.To = []types.Key{to}
+		writes = []any{m.value}
+	case "Split":
+		parts, err := rule.split(m.value)
+		if err != nil {
+			return step, err
+		}
+
+		type part struct {
+			to types.Key
+			v  any
+		}
+
+		var ps []part
+
+		for k, v := range parts {
+			ps = append(ps, part{to: append(types.Key(m.key.Dir()).Copy(), strings.Split(k, ".")...), v: v})
+		}
+
+		sort.Slice(ps, func(i, j int) bool {
+			return ps[i].to.Compare(ps[j].to) < 0
+		})
+
+		for _, p := range ps {
+			step.To = append(step.To, p.to)
+			writes = append(writes, p.v)
+		}
+	case "Transform":
+		v, err := rule.transform(m.value)
+		if err != nil {
+			return step, err
+		}
+
+		step.To = []types.Key{m.key}
+		writes = []any{v}
+	}
+
+	if dry {
+		return step, nil
+	}
+
+	for i, to := range step.To {
+		if err := set(ctx, iface, to, writes[i]); err != nil {
+			return step, err
+		}
+	}
+
+	if rule.op != "Transform" && !moved(step.To, m.key) {
+		if err := objects.Del(ctx, iface, m.key...); err != nil {
+			return step, err
+		}
+	}
+
+	return step, nil
+}
+
+// set sets the key, creating its parents, to the leaf v or to a copy
+// of the subtree v.
+func set(ctx context.Context, iface types.Interface, key types.Key, v any) error {
+	if r, ok := v.(types.Reader); ok {
+		w, err := objects.Put(ctx, iface, r.Type(), key...)
+		if err != nil {
+			return err
+		}
+
+		return objects.Copy(ctx, w, r)
+	}
+
+	var w types.Writer = iface
+
+	if dir := key.Dir(); len(dir) != 0 {
+		var err error
+
+		if w, err = objects.Put(ctx, iface, types.TypeMap, dir...); err != nil {
+			return err
+		}
+	}
+
+	_, err := types.PrefixedWriter{W: w}.SafeSet(ctx, key.Base(), v)
+
+	return err
+}
+
+// completed reports whether the rule is recorded in the state as
+// completed and, if it is in progress, gives IDs of keys it has
+// already rewritten.
+func completed(ctx context.Context, r types.Reader, opts *Options, rule Rule) (bool, map[string]struct{}, error) {
+	if opts.StateKey == "" {
+		return false, nil, nil
+	}
+
+	v, err := objects.Get(ctx, r, opts.StateKey, rule.ID)
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		return false, nil, nil
+	case err != nil:
+		return false, nil, ruleError(rule, nil, err)
+	}
+
+	made, ok := v.(types.Reader)
+	if !ok {
+		return true, nil, nil
+	}
+
+	ids, err := types.List(ctx, made)
+	if err != nil {
+		return false, nil, ruleError(rule, nil, err)
+	}
+
+	var set = make(map[string]struct{}, len(ids))
+
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+
+	return false, set, nil
+}
+
+func moved(to []types.Key, key types.Key) bool {
+	for _, k := range to {
+		if k.Equal(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func substitute(pattern types.Key, wildcard []string) types.Key {
+	key := pattern.Copy()
+
+	for i := range key {
+		if key[i] == "*" && len(wildcard) != 0 {
+			key[i], wildcard = wildcard[0], wildcard[1:]
+		}
+	}
+
+	return key
+}
+
+func ruleError(rule Rule, key types.Key, err error) error {
+	return &types.Error{
+		Op:  "Migrate",
+		Key: key,
+		Got: rule.ID,
+		Err: err,
+	}
+}
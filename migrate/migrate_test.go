@@ -0,0 +1,200 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects/migrate"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTree() types.Map {
+	return types.Map{
+		"tenants": types.Map{
+			"acme": types.Map{
+				"db_host": "db.acme",
+				"addr":    "acme.local:8080",
+				"limits":  types.Map{"rps": "100"},
+			},
+			"initech": types.Map{
+				"db_host": "db.initech",
+				"addr":    "initech.local:9090",
+			},
+		},
+	}
+}
+
+var rules = []migrate.Rule{
+	migrate.Rename("db-host", "tenants.*.db_host", "tenants.*.db.host"),
+	migrate.Rename("quotas", "tenants.*.limits", "tenants.*.quotas"),
+	migrate.Split("addr", "tenants.*.addr", func(v any) (map[string]any, error) {
+		host, port, ok := strings.Cut(v.(string), ":")
+		if !ok {
+			return nil, errors.New("no port")
+		}
+		return map[string]any{"server.host": host, "server.port": port}, nil
+	}),
+	migrate.Transform("upper", "tenants.*.server.host", func(v any) (any, error) {
+		return strings.ToUpper(v.(string)), nil
+	}),
+}
+
+func TestRun(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m        = newTree()
+		progress int
+		opts     = &migrate.Options{
+			StateKey: "_migrations",
+			Progress: func(_ context.Context, p migrate.Progress) {
+				progress = p.Steps
+			},
+		}
+	)
+
+	steps, err := migrate.Run(ctx, m, rules, opts)
+	if err != nil {
+		t.Fatalf("Run()=%+v", err)
+	}
+
+	if len(steps) != 7 || progress != 7 {
+		t.Fatalf("got %d steps and %d progress, want 7", len(steps), progress)
+	}
+
+	want := types.Map{
+		"tenants": types.Map{
+			"acme": types.Map{
+				"db":     types.Map{"host": "db.acme"},
+				"server": types.Map{"host": "ACME.LOCAL", "port": "8080"},
+				"quotas": types.Map{"rps": "100"},
+			},
+			"initech": types.Map{
+				"db":     types.Map{"host": "db.initech"},
+				"server": types.Map{"host": "INITECH.LOCAL", "port": "9090"},
+			},
+		},
+		"_migrations": types.Map{"db-host": true, "quotas": true, "addr": true, "upper": true},
+	}
+
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if steps, err = migrate.Run(ctx, m, rules, opts); err != nil || len(steps) != 0 {
+		t.Fatalf("Run()=%d, %+v, want no steps", len(steps), err)
+	}
+}
+
+func TestRunDry(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = newTree()
+	)
+
+	steps, err := migrate.Run(ctx, m, rules[:1], &migrate.Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Run()=%+v", err)
+	}
+
+	want := []migrate.Step{
+		{Rule: "db-host", Op: "Rename", From: types.Key{"tenants", "acme", "db_host"}, To: []types.Key{{"tenants", "acme", "db", "host"}}},
+		{Rule: "db-host", Op: "Rename", From: types.Key{"tenants", "initech", "db_host"}, To: []types.Key{{"tenants", "initech", "db", "host"}}},
+	}
+
+	if diff := cmp.Diff(want, steps); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(newTree(), m); diff != "" {
+		t.Fatalf("dry run modified the tree (-want, +got):\n%s", diff)
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m    = newTree()
+		fail = errors.New("interrupted")
+		bad  = append(rules[:1:1], migrate.Transform("fail", "tenants.initech.addr", func(any) (any, error) {
+			return nil, fail
+		}))
+	)
+
+	if _, err := migrate.Run(ctx, m, bad, nil); !errors.Is(err, fail) {
+		t.Fatalf("Run()=%+v, want %v", err, fail)
+	}
+
+	steps, err := migrate.Run(ctx, m, rules, nil)
+	if err != nil {
+		t.Fatalf("Run()=%+v", err)
+	}
+
+	if len(steps) != 5 {
+		t.Fatalf("got %d steps, want 5 after resuming", len(steps))
+	}
+}
+
+func TestRunDottedKeys(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		tree = types.Map{"cfg": types.Map{"a.b": 1}}
+	)
+
+	// The wildcard matches the dotted "a.b" segment, which is not
+	// the same key as the "a", "b" segments it is renamed to.
+	rules := []migrate.Rule{
+		migrate.Rename("nest", "cfg.*", "cfg.a.b"),
+	}
+
+	if _, err := migrate.Run(ctx, tree, rules, &migrate.Options{}); err != nil {
+		t.Fatalf("Run()=%+v", err)
+	}
+
+	want := types.Map{"cfg": types.Map{"a": types.Map{"b": 1}}}
+
+	if diff := cmp.Diff(want, tree); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestRunResumeTransform(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		m     = types.Map{"n": types.Map{"a": 1, "b": 1}}
+		fail  = errors.New("interrupted")
+		calls int
+	)
+
+	incr := func(fail error) []migrate.Rule {
+		return []migrate.Rule{
+			migrate.Transform("incr", "*.*", func(v any) (any, error) {
+				if calls++; fail != nil && calls == 2 {
+					return nil, fail
+				}
+				return v.(int) + 1, nil
+			}),
+		}
+	}
+
+	if _, err := migrate.Run(ctx, m, incr(fail), nil); !errors.Is(err, fail) {
+		t.Fatalf("Run()=%+v, want %v", err, fail)
+	}
+
+	if _, err := migrate.Run(ctx, m, incr(nil), nil); err != nil {
+		t.Fatalf("Run()=%+v", err)
+	}
+
+	want := types.Map{"a": 2, "b": 2}
+
+	if diff := cmp.Diff(want, m["n"]); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if done, ok := m["_migrations"].(types.Map)["incr"].(bool); !ok || !done {
+		t.Fatalf("got %#v, want completed state", m["_migrations"])
+	}
+}
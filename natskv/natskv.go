@@ -0,0 +1,158 @@
+package natskv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"rafal.dev/objects/kv"
+	"rafal.dev/objects/types"
+)
+
+const Sep = "."
+
+// KeyValue is the subset of a JetStream KV bucket used by the backend.
+// Get and Delete are expected to return an error wrapping types.ErrNotFound
+// for missing keys; Watch follows JetStream semantics, where a nil entry
+// separates initial values from live updates.
+type KeyValue interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	Put(ctx context.Context, key string, value []byte) (revision uint64, err error)
+	Delete(ctx context.Context, key string) error
+	Keys(ctx context.Context) ([]string, error)
+	Watch(ctx context.Context, keys string) (<-chan *Entry, error)
+}
+
+type Entry struct {
+	Key      string
+	Value    []byte
+	Revision uint64
+	Deleted  bool
+}
+
+type store struct {
+	kv KeyValue
+}
+
+var (
+	_ kv.Store   = store{}
+	_ kv.Watcher = store{}
+)
+
+func New(bucket KeyValue) *kv.Tree {
+	return kv.New(store{kv: bucket}, Sep)
+}
+
+func (s store) Get(ctx context.Context, key string) ([]byte, error) {
+	e, err := s.kv.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if e.Deleted {
+		return nil, types.ErrNotFound
+	}
+	return e.Value, nil
+}
+
+func (s store) Set(ctx context.Context, key string, value []byte) (bool, error) {
+	var previous = true
+
+	if _, err := s.Get(ctx, key); errors.Is(err, types.ErrNotFound) {
+		previous = false
+	} else if err != nil {
+		return false, err
+	}
+
+	if _, err := s.kv.Put(ctx, key, value); err != nil {
+		return false, err
+	}
+
+	return previous, nil
+}
+
+func (s store) Del(ctx context.Context, key string) error {
+	if _, err := s.Get(ctx, key); err != nil {
+		return err
+	}
+
+	return s.kv.Delete(ctx, key)
+}
+
+func (s store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	all, err := s.kv.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, k := range all {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s store) Watch(ctx context.Context, prefix string) (<-chan kv.Change, error) {
+	var filters = []string{">"}
+
+	if prefix != "" {
+		filters = []string{prefix, prefix + Sep + ">"}
+	}
+
+	var (
+		ch = make(chan kv.Change)
+		wg sync.WaitGroup
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	for _, f := range filters {
+		entries, err := s.kv.Watch(ctx, f)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for e := range entries {
+				if e == nil {
+					continue
+				}
+
+				select {
+				case ch <- kv.Change{Key: e.Key, Value: e.Value, Del: e.Deleted}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	return types.Ping(ctx, s.kv)
+}
+
+func (s store) Close(ctx context.Context) error {
+	return types.Close(ctx, s.kv)
+}
+
+func (s store) Unwrap() any {
+	return s.kv
+}
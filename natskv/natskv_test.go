@@ -0,0 +1,113 @@
+package natskv_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"rafal.dev/objects/natskv"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type bucket struct {
+	entries map[string]*natskv.Entry
+	watch   chan *natskv.Entry
+}
+
+func (b *bucket) Get(_ context.Context, key string) (*natskv.Entry, error) {
+	e, ok := b.entries[key]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return e, nil
+}
+
+func (b *bucket) Put(_ context.Context, key string, value []byte) (uint64, error) {
+	b.entries[key] = &natskv.Entry{Key: key, Value: value}
+	return uint64(len(b.entries)), nil
+}
+
+func (b *bucket) Delete(_ context.Context, key string) error {
+	b.entries[key] = &natskv.Entry{Key: key, Deleted: true}
+	return nil
+}
+
+func (b *bucket) Keys(context.Context) ([]string, error) {
+	var keys []string
+	for k, e := range b.entries {
+		if !e.Deleted {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *bucket) Watch(_ context.Context, keys string) (<-chan *natskv.Entry, error) {
+	if keys != ">" {
+		ch := make(chan *natskv.Entry)
+		close(ch)
+		return ch, nil
+	}
+	return b.watch, nil
+}
+
+func TestNatsKV(t *testing.T) {
+	var (
+		b = &bucket{
+			entries: make(map[string]*natskv.Entry),
+			watch:   make(chan *natskv.Entry, 2),
+		}
+		tr  = natskv.New(b)
+		ctx = context.Background()
+	)
+
+	w, err := tr.SafePut(ctx, "app", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	if ok := w.Set(ctx, "port", "8080"); ok {
+		t.Fatalf("got %t, want %t", ok, false)
+	}
+
+	if ok := w.Set(ctx, "port", "8081"); !ok {
+		t.Fatalf("got %t, want %t", ok, true)
+	}
+
+	if got, want := string(b.entries["app.port"].Value), "8081"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if err := tr.SafeDel(ctx, "app"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if err := tr.SafeDel(ctx, "app"); err == nil {
+		t.Fatal("expected SafeDel() to fail")
+	}
+
+	ch := tr.Watch(ctx, "")
+
+	b.watch <- nil
+	b.watch <- &natskv.Entry{Key: "app.host", Value: []byte("localhost")}
+	close(b.watch)
+
+	var got []types.Event
+
+	for ev := range ch {
+		got = append(got, ev)
+	}
+
+	want := []types.Event{{
+		Op:    "Set",
+		Key:   types.Key{"app", "host"},
+		Value: []byte("localhost"),
+	}}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
@@ -2,7 +2,10 @@ package objects
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+
+	"rafal.dev/objects/types"
 )
 
 func Copy(ctx context.Context, w Writer, r Reader) error {
@@ -41,6 +44,40 @@ func TGet[T any](ctx context.Context, r Reader, keys ...string) (T, error) {
 	return t, nil
 }
 
+// Bytes gets a []byte leaf under the keys. String leaves, as produced
+// by codecs without a native binary type, are decoded from base64.
+func Bytes(ctx context.Context, r Reader, keys ...string) ([]byte, error) {
+	v, err := Get(ctx, r, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		p, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, &Error{
+				Op:   "Get",
+				Key:  keys,
+				Got:  v,
+				Want: TypeBytes,
+				Err:  err,
+			}
+		}
+		return p, nil
+	default:
+		return nil, &Error{
+			Op:   "Get",
+			Key:  keys,
+			Got:  v,
+			Want: TypeBytes,
+			Err:  ErrUnexpectedType,
+		}
+	}
+}
+
 func Get(ctx context.Context, r Reader, keys ...string) (any, error) {
 	var n = len(keys) - 1
 
@@ -57,6 +94,20 @@ func Get(ctx context.Context, r Reader, keys ...string) (any, error) {
 	}.SafeGet(ctx, keys[n])
 }
 
+func List(ctx context.Context, r Reader, keys ...string) ([]string, error) {
+	return PrefixedReader{
+		Key: keys,
+		R:   r,
+	}.SafeList(ctx)
+}
+
+func ListValues(ctx context.Context, r Reader, keys ...string) ([]KV, error) {
+	return PrefixedReader{
+		Key: keys,
+		R:   r,
+	}.ListValues(ctx)
+}
+
 func Set(ctx context.Context, w Writer, v any, keys ...string) (bool, error) {
 	var n = len(keys) - 1
 
@@ -125,3 +176,35 @@ func npmin(i, j int) int {
 	}
 	return j
 }
+
+func Ping(ctx context.Context, ifaces ...any) error {
+	return types.Ping(ctx, ifaces...)
+}
+
+func CloseAll(ctx context.Context, ifaces ...any) error {
+	return types.Close(ctx, ifaces...)
+}
+
+func As(iface any, target any) bool {
+	return types.As(iface, target)
+}
+
+func PositionOf(ctx context.Context, r Reader, keys ...string) (Position, bool) {
+	return types.PositionOf(ctx, r, keys)
+}
+
+func Capabilities(iface any) Capability {
+	return types.Capabilities(iface)
+}
+
+func GetMany(ctx context.Context, r Reader, keys ...Key) []Result {
+	return types.GetMany(ctx, r, keys...)
+}
+
+func SetMany(ctx context.Context, w Writer, entries ...Entry) []Result {
+	return types.SetMany(ctx, w, entries...)
+}
+
+func DelMany(ctx context.Context, w Writer, keys ...Key) []Result {
+	return types.DelMany(ctx, w, keys...)
+}
@@ -0,0 +1,104 @@
+// Package objectstest provides utilities for testing code built
+// on objects.
+package objectstest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+// Clock is a fake types.Clock, which time moves only when
+// advanced with Add or Set.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+type timer struct {
+	c    *Clock
+	at   time.Time
+	f    func()
+	done bool
+}
+
+var _ types.Clock = (*Clock)(nil)
+
+// NewClock creates a fake clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) types.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// Add advances the clock by d, running functions which became
+// due in order of their schedule.
+func (c *Clock) Add(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set sets the clock to now, running functions which became due
+// in order of their schedule.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+
+	var due, pending []*timer
+
+	for _, t := range c.timers {
+		if !t.at.After(now) {
+			t.done = true
+			due = append(due, t)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+
+	c.timers = pending
+	c.now = now
+
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *timer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	if t.done {
+		return false
+	}
+
+	t.done = true
+
+	for i, x := range t.c.timers {
+		if x == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,46 @@
+package objectstest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"rafal.dev/objects/objectstest"
+)
+
+func TestClock(t *testing.T) {
+	var (
+		start = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = objectstest.NewClock(start)
+		got   []string
+	)
+
+	clock.AfterFunc(2*time.Second, func() { got = append(got, "b") })
+	clock.AfterFunc(time.Second, func() { got = append(got, "a") })
+	stopped := clock.AfterFunc(time.Second, func() { got = append(got, "c") })
+
+	if !stopped.Stop() {
+		t.Fatal("Stop()=false")
+	}
+
+	clock.Add(500 * time.Millisecond)
+
+	if len(got) != 0 {
+		t.Fatalf("got %v, want none", got)
+	}
+
+	clock.Add(2 * time.Second)
+
+	if diff := cmp.Diff([]string{"a", "b"}, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if want := start.Add(2500 * time.Millisecond); !clock.Now().Equal(want) {
+		t.Fatalf("got %v, want %v", clock.Now(), want)
+	}
+
+	if stopped.Stop() {
+		t.Fatal("Stop()=true")
+	}
+}
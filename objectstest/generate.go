@@ -0,0 +1,235 @@
+package objectstest
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+
+	"rafal.dev/objects/types"
+)
+
+// GenOpts configures trees created by Generate.
+type GenOpts struct {
+	// MaxDepth is the maximum number of nested maps and slices
+	// below the root; 3 is used if 0.
+	MaxDepth int
+
+	// MaxFanout is the maximum number of children of a map or
+	// a slice; 4 is used if 0.
+	MaxFanout int
+
+	// LeafKinds are kinds of generated leaves, out of reflect.Bool,
+	// reflect.Int, reflect.Float64, reflect.String and reflect.Slice
+	// for []byte. Bool, Int, Float64 and String are used if empty.
+	LeafKinds []reflect.Kind
+}
+
+var defaultLeafKinds = []reflect.Kind{
+	reflect.Bool,
+	reflect.Int,
+	reflect.Float64,
+	reflect.String,
+}
+
+// Generate creates a random tree of maps and slices, which is
+// reproducible for the same seed of r. Maps are types.Map and
+// slices are *types.Slice.
+func Generate(r *rand.Rand, opts GenOpts) types.Map {
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = 3
+	}
+
+	if opts.MaxFanout == 0 {
+		opts.MaxFanout = 4
+	}
+
+	if len(opts.LeafKinds) == 0 {
+		opts.LeafKinds = defaultLeafKinds
+	}
+
+	g := &generator{r: r, opts: opts}
+
+	return g.node(types.TypeMap, 0).(types.Map)
+}
+
+type generator struct {
+	r    *rand.Rand
+	opts GenOpts
+}
+
+func (g *generator) node(typ types.Type, depth int) any {
+	var (
+		n = g.r.Intn(g.opts.MaxFanout + 1)
+		m types.Map
+		s types.Slice
+	)
+
+	if typ == types.TypeMap {
+		m = make(types.Map, n)
+	} else {
+		s = make(types.Slice, 0, n)
+	}
+
+	for i := 0; i < n; i++ {
+		v := g.value(depth + 1)
+
+		if m != nil {
+			m[g.key()] = v
+		} else {
+			s = append(s, v)
+		}
+	}
+
+	if m != nil {
+		return m
+	}
+
+	return &s
+}
+
+func (g *generator) value(depth int) any {
+	if depth <= g.opts.MaxDepth {
+		switch g.r.Intn(4) {
+		case 0:
+			return g.node(types.TypeMap, depth)
+		case 1:
+			return g.node(types.TypeSlice, depth)
+		}
+	}
+
+	return g.leaf()
+}
+
+func (g *generator) key() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+	b := make([]byte, 1+g.r.Intn(8))
+	for i := range b {
+		b[i] = alphabet[g.r.Intn(len(alphabet))]
+	}
+
+	return string(b)
+}
+
+func (g *generator) leaf() any {
+	switch g.opts.LeafKinds[g.r.Intn(len(g.opts.LeafKinds))] {
+	case reflect.Bool:
+		return g.r.Intn(2) == 1
+	case reflect.Int:
+		return g.r.Intn(2001) - 1000
+	case reflect.Float64:
+		return g.r.NormFloat64() * 1000
+	case reflect.Slice:
+		b := make([]byte, g.r.Intn(16))
+		g.r.Read(b)
+		return b
+	default:
+		return g.key()
+	}
+}
+
+// Shrink gives trees, each smaller than v by a single step: an entry
+// removed or a leaf reduced towards its zero value. They are ordered
+// from the largest reduction and share unchanged subtrees with v, so
+// when a property fails for v, the first candidate it still fails for
+// can be shrunk further.
+func Shrink(v types.Map) []types.Map {
+	var ms []types.Map
+
+	for _, x := range shrink(v) {
+		ms = append(ms, x.(types.Map))
+	}
+
+	return ms
+}
+
+func shrink(v any) []any {
+	switch v := v.(type) {
+	case types.Map:
+		var (
+			keys = make([]string, 0, len(v))
+			vs   []any
+		)
+
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			m := copyMap(v)
+			delete(m, k)
+			vs = append(vs, m)
+		}
+
+		for _, k := range keys {
+			for _, x := range shrink(v[k]) {
+				m := copyMap(v)
+				m[k] = x
+				vs = append(vs, m)
+			}
+		}
+
+		return vs
+	case *types.Slice:
+		var vs []any
+
+		for i := range *v {
+			s := append(append(types.Slice{}, (*v)[:i]...), (*v)[i+1:]...)
+			vs = append(vs, &s)
+		}
+
+		for i := range *v {
+			for _, x := range shrink((*v)[i]) {
+				s := append(types.Slice{}, *v...)
+				s[i] = x
+				vs = append(vs, &s)
+			}
+		}
+
+		return vs
+	case bool:
+		if v {
+			return []any{false}
+		}
+	case int:
+		if v/2 != 0 {
+			return []any{0, v / 2}
+		}
+		if v != 0 {
+			return []any{0}
+		}
+	case float64:
+		if n := float64(int64(v)); n != v && n != 0 {
+			return []any{float64(0), n}
+		}
+		if v != 0 {
+			return []any{float64(0)}
+		}
+	case string:
+		if len(v) > 1 {
+			return []any{"", v[:len(v)/2]}
+		}
+		if v != "" {
+			return []any{""}
+		}
+	case []byte:
+		if len(v) > 1 {
+			return []any{[]byte{}, v[:len(v)/2]}
+		}
+		if len(v) != 0 {
+			return []any{[]byte{}}
+		}
+	}
+
+	return nil
+}
+
+func copyMap(m types.Map) types.Map {
+	c := make(types.Map, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,111 @@
+package objectstest_test
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := objectstest.GenOpts{
+		MaxDepth:  2,
+		MaxFanout: 3,
+		LeafKinds: []reflect.Kind{reflect.String, reflect.Int, reflect.Slice},
+	}
+
+	for seed := int64(0); seed < 50; seed++ {
+		var (
+			a = objectstest.Generate(rand.New(rand.NewSource(seed)), opts)
+			b = objectstest.Generate(rand.New(rand.NewSource(seed)), opts)
+		)
+
+		if !cmp.Equal(a, b) {
+			t.Fatalf("%d: trees differ for the same seed:\n%s", seed, cmp.Diff(a, b))
+		}
+
+		if d := depth(a); d > opts.MaxDepth {
+			t.Fatalf("%d: got depth %d, want at most %d", seed, d, opts.MaxDepth)
+		}
+	}
+}
+
+func TestGenerateCopy(t *testing.T) {
+	ctx := context.Background()
+
+	property := func(m types.Map) bool {
+		c := make(types.Map)
+
+		if err := objects.Copy(ctx, c, m); err != nil {
+			return false
+		}
+
+		p, err := objects.Diff(ctx, m, c)
+		return err == nil && len(p) == 0
+	}
+
+	for seed := int64(0); seed < 100; seed++ {
+		m := objectstest.Generate(rand.New(rand.NewSource(seed)), objectstest.GenOpts{})
+
+		if !property(m) {
+			t.Fatalf("%d: Copy() differs from the original: %+v", seed, m)
+		}
+	}
+}
+
+func TestShrink(t *testing.T) {
+	m := types.Map{
+		"a": 10,
+		"b": &types.Slice{"xy"},
+	}
+
+	got := objectstest.Shrink(m)
+
+	want := []types.Map{
+		{"b": &types.Slice{"xy"}},
+		{"a": 10},
+		{"a": 0, "b": &types.Slice{"xy"}},
+		{"a": 5, "b": &types.Slice{"xy"}},
+		{"a": 10, "b": &types.Slice{}},
+		{"a": 10, "b": &types.Slice{""}},
+		{"a": 10, "b": &types.Slice{"x"}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if len(objectstest.Shrink(types.Map{})) != 0 {
+		t.Fatal("expected empty map not to shrink")
+	}
+}
+
+func depth(v any) int {
+	var children []any
+
+	switch v := v.(type) {
+	case types.Map:
+		for _, v := range v {
+			children = append(children, v)
+		}
+	case *types.Slice:
+		children = *v
+	default:
+		return -1
+	}
+
+	d := 0
+	for _, v := range children {
+		if n := depth(v) + 1; n > d {
+			d = n
+		}
+	}
+
+	return d
+}
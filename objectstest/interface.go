@@ -0,0 +1,272 @@
+package objectstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// TestInterface runs the conformance suite against Interfaces created
+// by factory, which must give a new, empty tree on each call. Backends
+// are expected to keep string leaves and nested maps; leaves may be
+// read back as []byte.
+func TestInterface(t *testing.T, factory func(t *testing.T) types.Interface) {
+	t.Helper()
+
+	for _, tc := range []struct {
+		name string
+		fn   func(context.Context, *testing.T, types.Interface)
+	}{
+		{"Empty", testEmpty},
+		{"SetGet", testSetGet},
+		{"Overwrite", testOverwrite},
+		{"NotFound", testNotFound},
+		{"Del", testDel},
+		{"Put", testPut},
+		{"Copy", testCopy},
+		{"Watch", testWatch},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(context.Background(), t, factory(t))
+		})
+	}
+}
+
+func testEmpty(ctx context.Context, t *testing.T, iface types.Interface) {
+	keys, err := types.List(ctx, iface)
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if len(keys) != 0 {
+		t.Fatalf("got %q, want no keys", keys)
+	}
+}
+
+func testSetGet(ctx context.Context, t *testing.T, iface types.Interface) {
+	mustSet(ctx, t, iface, "value", "key")
+	mustGet(ctx, t, iface, "value", "key")
+
+	if v, ok := iface.Get(ctx, "key"); !ok || !equal(v, "value") {
+		t.Fatalf("Get()=%v, %t", v, ok)
+	}
+
+	if sr, ok := iface.(types.SafeReader); ok {
+		if v, err := sr.SafeGet(ctx, "key"); err != nil || !equal(v, "value") {
+			t.Fatalf("SafeGet()=%v, %+v", v, err)
+		}
+	}
+
+	keys, err := types.List(ctx, iface)
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"key"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func testOverwrite(ctx context.Context, t *testing.T, iface types.Interface) {
+	mustSet(ctx, t, iface, "old", "key")
+	mustSet(ctx, t, iface, "new", "key")
+	mustGet(ctx, t, iface, "new", "key")
+}
+
+func testNotFound(ctx context.Context, t *testing.T, iface types.Interface) {
+	if v, ok := iface.Get(ctx, "missing"); ok {
+		t.Fatalf("Get()=%v, want not found", v)
+	}
+
+	if sr, ok := iface.(types.SafeReader); ok {
+		if _, err := sr.SafeGet(ctx, "missing"); !errors.Is(err, types.ErrNotFound) {
+			t.Fatalf("SafeGet()=%+v, want %v", err, types.ErrNotFound)
+		}
+	}
+
+	if _, err := objects.Get(ctx, iface, "missing", "nested"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, types.ErrNotFound)
+	}
+
+	var e *types.Error
+
+	if _, err := objects.Get(ctx, iface, "missing"); !errors.As(err, &e) {
+		t.Fatalf("Get()=%+v, want %T", err, e)
+	}
+
+	if err := objects.Del(ctx, iface, "missing"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Del()=%+v, want %v", err, types.ErrNotFound)
+	}
+}
+
+func testDel(ctx context.Context, t *testing.T, iface types.Interface) {
+	mustSet(ctx, t, iface, "1", "a")
+	mustSet(ctx, t, iface, "2", "b")
+
+	if err := objects.Del(ctx, iface, "a"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if _, err := objects.Get(ctx, iface, "a"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, types.ErrNotFound)
+	}
+
+	mustGet(ctx, t, iface, "2", "b")
+
+	keys, err := types.List(ctx, iface)
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"b"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func testPut(ctx context.Context, t *testing.T, iface types.Interface) {
+	w, err := objects.Put(ctx, iface, types.TypeMap, "db")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	mustSet(ctx, t, w, "localhost", "host")
+	mustSet(ctx, t, w, "5432", "port")
+	mustGet(ctx, t, iface, "localhost", "db", "host")
+
+	keys, err := objects.List(ctx, iface, "db")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"host", "port"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	v, err := objects.Get(ctx, iface, "db")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if _, ok := v.(types.Reader); !ok {
+		t.Fatalf("got %T, want %T", v, types.Reader(nil))
+	}
+
+	if err := objects.Del(ctx, iface, "db", "host"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if _, err := objects.Get(ctx, iface, "db", "host"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, types.ErrNotFound)
+	}
+
+	mustGet(ctx, t, iface, "5432", "db", "port")
+}
+
+func testCopy(ctx context.Context, t *testing.T, iface types.Interface) {
+	want := types.Map{
+		"a": "1",
+		"b": types.Map{
+			"c": "2",
+			"d": types.Map{
+				"e": "3",
+			},
+		},
+	}
+
+	if err := objects.Copy(ctx, iface, want); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	mustGet(ctx, t, iface, "1", "a")
+	mustGet(ctx, t, iface, "2", "b", "c")
+	mustGet(ctx, t, iface, "3", "b", "d", "e")
+
+	keys, err := objects.List(ctx, iface, "b")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"c", "d"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+// testWatch is skipped for Interfaces, which are not Watchers or
+// which close the channel of Watch right away.
+func testWatch(ctx context.Context, t *testing.T, iface types.Interface) {
+	w, ok := iface.(types.Watcher)
+	if !ok {
+		t.Skip("not a Watcher")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ch := w.Watch(ctx, "")
+
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Skip("Watch is not supported")
+		}
+		t.Fatalf("unexpected event: %+v", ev)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	mustSet(ctx, t, iface, "value", "key")
+
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatal("Watch channel closed before the event")
+			}
+
+			if ev.Op == "Set" && ev.Key.Base() == "key" {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatalf("no event: %+v", ctx.Err())
+		}
+	}
+}
+
+func mustSet(ctx context.Context, t *testing.T, w types.Writer, value any, keys ...string) {
+	t.Helper()
+
+	if _, err := objects.Set(ctx, w, value, keys...); err != nil {
+		t.Fatalf("Set(%q)=%+v", keys, err)
+	}
+}
+
+func mustGet(ctx context.Context, t *testing.T, r types.Reader, want string, keys ...string) {
+	t.Helper()
+
+	got, err := objects.Get(ctx, r, keys...)
+	if err != nil {
+		t.Fatalf("Get(%q)=%+v", keys, err)
+	}
+
+	if !equal(got, want) {
+		t.Fatalf("Get(%q)=%v, want %v", keys, got, want)
+	}
+}
+
+func equal(got any, want string) bool {
+	switch got := got.(type) {
+	case string:
+		return got == want
+	case []byte:
+		return string(got) == want
+	default:
+		return false
+	}
+}
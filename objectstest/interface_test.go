@@ -0,0 +1,14 @@
+package objectstest_test
+
+import (
+	"testing"
+
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+func TestInterfaceMap(t *testing.T) {
+	objectstest.TestInterface(t, func(*testing.T) types.Interface {
+		return make(types.Map)
+	})
+}
@@ -0,0 +1,292 @@
+package objectstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"rafal.dev/objects/types"
+)
+
+var errUnexpected = errors.New("unexpected call")
+
+// Mock is an Interface, which operations are checked against
+// expectations. Keys of expectations are full dot-separated paths,
+// e.g. "a.b"; reading a parent of an expected key gives a Mock of
+// the subtree, unless the parent has an expectation of its own.
+// Unexpected operations fail the test.
+type Mock struct {
+	m   *mock
+	key types.Key
+}
+
+type mock struct {
+	t     testing.TB
+	mu    sync.Mutex
+	calls []*Call
+}
+
+// Call is an expected operation.
+type Call struct {
+	op    string
+	key   string
+	value any
+	ret   any
+	err   error
+	times int
+	n     int
+}
+
+var (
+	_ types.Interface  = (*Mock)(nil)
+	_ types.SafeReader = (*Mock)(nil)
+	_ types.SafeLister = (*Mock)(nil)
+	_ types.SafeWriter = (*Mock)(nil)
+)
+
+// NewMock creates a Mock, which expectations are verified when t
+// completes.
+func NewMock(t testing.TB) *Mock {
+	m := &Mock{m: &mock{t: t}}
+	t.Cleanup(m.Verify)
+	return m
+}
+
+// ExpectGet expects a Get of the key; Return sets the value.
+func (m *Mock) ExpectGet(key string) *Call {
+	return m.m.expect(&Call{op: "Get", key: key})
+}
+
+// ExpectList expects a List of the key; Return sets the keys.
+func (m *Mock) ExpectList(key string) *Call {
+	return m.m.expect(&Call{op: "List", key: key})
+}
+
+// ExpectSet expects a Set of the key to the value; Return sets
+// the previous flag.
+func (m *Mock) ExpectSet(key string, value any) *Call {
+	return m.m.expect(&Call{op: "Set", key: key, value: value})
+}
+
+// ExpectDel expects a Del of the key.
+func (m *Mock) ExpectDel(key string) *Call {
+	return m.m.expect(&Call{op: "Del", key: key})
+}
+
+// ExpectPut expects a Put of the key with the hint. The Writer it
+// gives is a Mock of the subtree.
+func (m *Mock) ExpectPut(key string, hint types.Type) *Call {
+	return m.m.expect(&Call{op: "Put", key: key, value: hint})
+}
+
+// Return sets the result of the call.
+func (c *Call) Return(v any) *Call {
+	c.ret = v
+	return c
+}
+
+// ReturnErr makes the call fail with err.
+func (c *Call) ReturnErr(err error) *Call {
+	c.err = err
+	return c
+}
+
+// Times sets how many times the call is expected; it is expected
+// once by default. If n is negative, the call is expected any number
+// of times.
+func (c *Call) Times(n int) *Call {
+	c.times = n
+	return c
+}
+
+func (c *Call) Once() *Call {
+	return c.Times(1)
+}
+
+func (c *Call) AnyTimes() *Call {
+	return c.Times(-1)
+}
+
+func (c *Call) String() string {
+	if c.op == "Set" || c.op == "Put" {
+		return fmt.Sprintf("%s(%q, %v)", c.op, c.key, c.value)
+	}
+	return fmt.Sprintf("%s(%q)", c.op, c.key)
+}
+
+// Verify fails the test for every expected call, which was made
+// fewer times than expected.
+func (m *Mock) Verify() {
+	m.m.mu.Lock()
+	defer m.m.mu.Unlock()
+
+	m.m.t.Helper()
+
+	for _, c := range m.m.calls {
+		if c.times > 0 && c.n < c.times {
+			m.m.t.Errorf("objectstest: %s called %d times, want %d", c, c.n, c.times)
+		}
+	}
+}
+
+func (m *Mock) Type() types.Type {
+	return types.TypeMap
+}
+
+func (m *Mock) Get(ctx context.Context, key string) (any, bool) {
+	v, err := m.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (m *Mock) SafeGet(ctx context.Context, key string) (any, error) {
+	full := m.path(key)
+
+	c, err := m.m.call("Get", full, nil)
+	if err != nil {
+		if !m.m.parent(full) {
+			return nil, err
+		}
+
+		return m.child(key), nil
+	}
+
+	return c.ret, c.err
+}
+
+func (m *Mock) List(ctx context.Context) []string {
+	keys, _ := m.SafeList(ctx)
+	return keys
+}
+
+func (m *Mock) SafeList(ctx context.Context) ([]string, error) {
+	c, err := m.m.call("List", m.key.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, _ := c.ret.([]string)
+
+	return keys, c.err
+}
+
+func (m *Mock) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := m.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (m *Mock) Del(ctx context.Context, key string) bool {
+	return m.SafeDel(ctx, key) == nil
+}
+
+func (m *Mock) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := m.SafePut(ctx, key, hint)
+	return w
+}
+
+func (m *Mock) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	c, err := m.m.call("Set", m.path(key), value)
+	if err != nil {
+		return false, err
+	}
+
+	ok, _ := c.ret.(bool)
+
+	return ok, c.err
+}
+
+func (m *Mock) SafeDel(ctx context.Context, key string) error {
+	c, err := m.m.call("Del", m.path(key), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.err
+}
+
+func (m *Mock) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	c, err := m.m.call("Put", m.path(key), hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return m.child(key), nil
+}
+
+func (m *Mock) path(key string) string {
+	return append(m.key.Copy(), key).String()
+}
+
+func (m *Mock) child(key string) *Mock {
+	return &Mock{
+		m:   m.m,
+		key: append(m.key.Copy(), key),
+	}
+}
+
+func (m *mock) expect(c *Call) *Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c.times = 1
+	m.calls = append(m.calls, c)
+
+	return c
+}
+
+func (m *mock) call(op, key string, value any) (*Call, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.calls {
+		if c.op != op || c.key != key || (c.times >= 0 && c.n >= c.times) {
+			continue
+		}
+
+		if (op == "Set" || op == "Put") && !cmp.Equal(c.value, value) {
+			continue
+		}
+
+		c.n++
+
+		return c, nil
+	}
+
+	// Reads of parents of expected keys are resolved by the caller.
+	if op != "Get" || !m.isParent(key) {
+		m.t.Helper()
+		m.t.Errorf("objectstest: unexpected %s", &Call{op: op, key: key, value: value})
+	}
+
+	return nil, &types.Error{
+		Op:  op,
+		Key: strings.Split(key, "."),
+		Got: value,
+		Err: errUnexpected,
+	}
+}
+
+func (m *mock) parent(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.isParent(key)
+}
+
+func (m *mock) isParent(key string) bool {
+	for _, c := range m.calls {
+		if strings.HasPrefix(c.key, key+".") {
+			return true
+		}
+	}
+
+	return false
+}
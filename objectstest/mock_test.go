@@ -0,0 +1,85 @@
+package objectstest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+func TestMock(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m    = objectstest.NewMock(t)
+		fail = errors.New("fail")
+	)
+
+	m.ExpectGet("db.host").Return("localhost").Once()
+	m.ExpectGet("db.port").ReturnErr(fail)
+	m.ExpectSet("db.user", "admin").Return(true)
+	m.ExpectList("db").Return([]string{"host", "port"}).AnyTimes()
+	m.ExpectDel("tmp")
+
+	if v, err := objects.Get(ctx, m, "db", "host"); err != nil || v != "localhost" {
+		t.Fatalf("Get()=%v, %+v", v, err)
+	}
+
+	if _, err := objects.Get(ctx, m, "db", "port"); !errors.Is(err, fail) {
+		t.Fatalf("Get()=%+v, want %v", err, fail)
+	}
+
+	if ok, err := objects.Set(ctx, m, "admin", "db", "user"); err != nil || !ok {
+		t.Fatalf("Set()=%t, %+v", ok, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if keys, err := objects.List(ctx, m, "db"); err != nil || len(keys) != 2 {
+			t.Fatalf("List()=%v, %+v", keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, m, "tmp"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+}
+
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Cleanup(func()) {}
+
+func TestMockUnexpected(t *testing.T) {
+	var (
+		ctx = context.Background()
+		tb  = &recordTB{TB: t}
+		m   = objectstest.NewMock(tb)
+	)
+
+	m.ExpectGet("a").Return("1").Times(2)
+	m.ExpectPut("b", types.TypeMap)
+
+	if _, err := objects.Get(ctx, m, "a"); err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if _, err := objects.Get(ctx, m, "c"); err == nil {
+		t.Fatal("expected Get() to fail")
+	}
+
+	m.Verify()
+
+	if len(tb.errors) != 3 {
+		t.Fatalf("got %d errors, want 3", len(tb.errors))
+	}
+}
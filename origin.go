@@ -0,0 +1,52 @@
+package objects
+
+import (
+	"context"
+	"errors"
+)
+
+// Layer is a named source of values, e.g. "file", "env" or "flag",
+// which is one of the readers of a precedence stack.
+type Layer struct {
+	Name   string
+	Reader Reader
+}
+
+// Origin gives the first of layers, which holds the value under
+// the keys, that is the layer supplying the effective value when
+// the layers are given in order of precedence.
+func Origin(ctx context.Context, layers []Layer, keys ...string) (Layer, error) {
+	for _, l := range layers {
+		switch _, err := Get(ctx, l.Reader, keys...); {
+		case err == nil:
+			return l, nil
+		case !errors.Is(err, ErrNotFound):
+			return Layer{}, err
+		}
+	}
+
+	return Layer{}, &Error{
+		Op:  "Origin",
+		Key: keys,
+		Err: ErrNotFound,
+	}
+}
+
+// origin describes the layer of the value under the key and its
+// source position, if known, e.g. "file config.yaml:3:7".
+func origin(ctx context.Context, layers []Layer, key Key) (string, error) {
+	l, err := Origin(ctx, layers, key...)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if pos, ok := PositionOf(ctx, l.Reader, key...); ok {
+		return l.Name + " " + pos.String(), nil
+	}
+
+	return l.Name, nil
+}
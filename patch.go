@@ -0,0 +1,284 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// PatchOp is a single mutation of a key, either a "Set" or a "Del".
+// The Value of a "Set" may be a Reader, in which case the whole
+// subtree is written.
+type PatchOp struct {
+	Op    string
+	Key   Key
+	Value any
+}
+
+type Patch []PatchOp
+
+// Diff gives the minimal patch, which turns before into after. Both
+// values can be anything Make accepts, e.g. Go structs. A nil value,
+// or a nil pointer, is an empty tree.
+func Diff(ctx context.Context, before, after any) (Patch, error) {
+	var p Patch
+
+	b, err := tree(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := tree(after)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b == nil && a == nil:
+		return p, nil
+	case b == nil:
+		b = makeLike(a)
+	case a == nil:
+		a = makeLike(b)
+	}
+
+	if err := p.diff(ctx, b, a, nil); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// tree gives the Reader of v, which is nil for a nil value or a nil
+// pointer.
+func tree(v any) (Reader, error) {
+	for rv := reflect.ValueOf(v); ; rv = rv.Elem() {
+		if !rv.IsValid() {
+			return nil, nil
+		}
+
+		if k := rv.Kind(); k != reflect.Ptr && k != reflect.Interface {
+			break
+		}
+
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	r := Make(v)
+	if r == nil {
+		return nil, &Error{
+			Op:   "Diff",
+			Got:  v,
+			Want: "tree",
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	return r, nil
+}
+
+// DiffFields gives a patch, which writes the dirty keys of v.
+// Keys missing in v are deleted.
+func DiffFields(ctx context.Context, v any, dirty ...Key) (Patch, error) {
+	var (
+		p Patch
+		r = Make(v)
+	)
+
+	for _, key := range dirty {
+		x, err := PrefixedReader{Key: key.Dir(), R: r}.SafeGet(ctx, key.Base())
+
+		switch {
+		case errors.Is(err, ErrNotFound):
+			p = append(p, PatchOp{Op: "Del", Key: key.Copy()})
+		case err != nil:
+			return nil, err
+		default:
+			p = append(p, PatchOp{Op: "Set", Key: key.Copy(), Value: x})
+		}
+	}
+
+	return p, nil
+}
+
+func (p *Patch) diff(ctx context.Context, before, after Reader, key Key) error {
+	return changes(ctx, before, after, key, func(c Change) {
+		if c.Op == "Remove" {
+			*p = append(*p, PatchOp{Op: "Del", Key: c.Key})
+		} else {
+			*p = append(*p, PatchOp{Op: "Set", Key: c.Key, Value: c.New})
+		}
+	})
+}
+
+// Change is a difference of a key between two trees: "Add" of a key
+// missing in the first tree, "Remove" of a key missing in the second
+// one or "Modify" of its value. Old and New may be Readers, e.g. for
+// a leaf replaced by a subtree.
+type Change struct {
+	Op  string
+	Key Key
+	Old any
+	New any
+}
+
+// Changes gives the differences between the trees of a and b, with
+// nested subtrees of the same type compared key by key, e.g. to
+// detect drift of a configuration.
+func Changes(ctx context.Context, a, b Reader) ([]Change, error) {
+	var c []Change
+
+	err := changes(ctx, a, b, nil, func(ch Change) {
+		c = append(c, ch)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func changes(ctx context.Context, before, after Reader, key Key, fn func(Change)) error {
+	lhs, err := keys(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	rhs, err := keys(ctx, after)
+	if err != nil {
+		return err
+	}
+
+	var all = make([]string, 0, len(lhs)+len(rhs))
+
+	for k := range lhs {
+		all = append(all, k)
+	}
+
+	for k := range rhs {
+		if _, ok := lhs[k]; !ok {
+			all = append(all, k)
+		}
+	}
+
+	sort.Strings(all)
+
+	for _, k := range all {
+		var (
+			x, okx = lhs[k]
+			y, oky = rhs[k]
+			child  = append(key.Copy(), k)
+		)
+
+		switch rx, isx := x.(Reader); {
+		case !oky:
+			fn(Change{Op: "Remove", Key: child, Old: x})
+		case !okx:
+			fn(Change{Op: "Add", Key: child, New: y})
+		default:
+			ry, isy := y.(Reader)
+
+			switch {
+			case isx && isy && rx.Type() == ry.Type():
+				if err := changes(ctx, rx, ry, child, fn); err != nil {
+					return err
+				}
+			case isx || isy || !reflect.DeepEqual(x, y):
+				fn(Change{Op: "Modify", Key: child, Old: x, New: y})
+			}
+		}
+	}
+
+	return nil
+}
+
+// Apply writes the patch to w.
+func (p Patch) Apply(ctx context.Context, w Writer) error {
+	for _, op := range p {
+		if err := op.apply(ctx, w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op PatchOp) apply(ctx context.Context, w Writer) error {
+	var (
+		dir  = op.Key.Dir()
+		base = op.Key.Base()
+	)
+
+	switch op.Op {
+	case "Del":
+		err := PrefixedWriter{Key: dir, W: w}.SafeDel(ctx, base)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	case "Set":
+	default:
+		return &Error{
+			Op:   "Patch",
+			Key:  op.Key,
+			Got:  op.Op,
+			Want: "Set or Del",
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	if len(dir) != 0 {
+		var err error
+		if w, err = Put(ctx, w, TypeMap, dir...); err != nil {
+			return err
+		}
+	}
+
+	r, ok := op.Value.(Reader)
+	if !ok {
+		_, err := PrefixedWriter{W: w}.SafeSet(ctx, base, op.Value)
+		return err
+	}
+
+	if err := (PrefixedWriter{W: w}).SafeDel(ctx, base); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	sub, err := Put(ctx, w, r.Type(), base)
+	if err != nil {
+		return err
+	}
+
+	return Copy(ctx, sub, r)
+}
+
+func keys(ctx context.Context, r Reader) (map[string]any, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	ks, err := List(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var m = make(map[string]any, len(ks))
+
+	for _, k := range ks {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		m[k] = tryMake(v)
+	}
+
+	return m, nil
+}
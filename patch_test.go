@@ -0,0 +1,155 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		before = Config{
+			Server:   Server{Host: "localhost", Port: 8080},
+			Backends: []string{"a", "b"},
+			Labels:   map[string]string{"env": "dev", "team": "x"},
+		}
+		after = Config{
+			Server:   Server{Host: "localhost", Port: 9090},
+			Backends: []string{"a", "b"},
+			Labels:   map[string]string{"env": "prod"},
+		}
+	)
+
+	p, err := objects.Diff(ctx, before, after)
+	if err != nil {
+		t.Fatalf("Diff()=%+v", err)
+	}
+
+	want := objects.Patch{
+		{Op: "Set", Key: objects.Key{"labels", "env"}, Value: "prod"},
+		{Op: "Del", Key: objects.Key{"labels", "team"}},
+		{Op: "Set", Key: objects.Key{"server", "port"}, Value: 9090},
+	}
+
+	if !cmp.Equal(p, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(p, want))
+	}
+
+	m := types.Map{
+		"labels": types.Map{"env": "dev", "team": "x"},
+		"server": types.Map{"host": "localhost", "port": 8080},
+	}
+
+	if err := p.Apply(ctx, m); err != nil {
+		t.Fatalf("Apply()=%+v", err)
+	}
+
+	wantm := types.Map{
+		"labels": types.Map{"env": "prod"},
+		"server": types.Map{"host": "localhost", "port": 9090},
+	}
+
+	if !cmp.Equal(m, wantm) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, wantm))
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	var (
+		ctx = context.Background()
+		cfg = Config{
+			Server: Server{Host: "example.com"},
+		}
+		m = types.Map{
+			"server": types.Map{"host": "localhost", "port": 8080},
+		}
+	)
+
+	p, err := objects.DiffFields(ctx, cfg, objects.Key{"server", "host"}, objects.Key{"server", "port"})
+	if err != nil {
+		t.Fatalf("DiffFields()=%+v", err)
+	}
+
+	if err := p.Apply(ctx, m); err != nil {
+		t.Fatalf("Apply()=%+v", err)
+	}
+
+	want := types.Map{
+		"server": types.Map{"host": "example.com"},
+	}
+
+	if !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+}
+
+func TestChanges(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		before = types.Map{
+			"server": types.Map{"host": "localhost", "port": 8080},
+			"labels": types.Map{"team": "x"},
+			"debug":  true,
+		}
+		after = types.Map{
+			"server": types.Map{"host": "localhost", "port": 9090},
+			"labels": "none",
+			"tls":    types.Map{"cert": "a.pem"},
+		}
+	)
+
+	got, err := objects.Changes(ctx, before, after)
+	if err != nil {
+		t.Fatalf("Changes()=%+v", err)
+	}
+
+	want := []objects.Change{
+		{Op: "Remove", Key: objects.Key{"debug"}, Old: true},
+		{Op: "Modify", Key: objects.Key{"labels"}, Old: types.Map{"team": "x"}, New: "none"},
+		{Op: "Modify", Key: objects.Key{"server", "port"}, Old: 8080, New: 9090},
+		{Op: "Add", Key: objects.Key{"tls"}, New: types.Map{"cert": "a.pem"}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDiffNil(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		cfg  = &Config{Server: Server{Host: "localhost"}}
+		none *Config
+	)
+
+	p, err := objects.Diff(ctx, none, cfg)
+	if err != nil {
+		t.Fatalf("Diff()=%+v", err)
+	}
+
+	if len(p) != 1 || p[0].Op != "Set" || !p[0].Key.Equal(objects.Key{"server"}) {
+		t.Fatalf("got %+v, want Set of server", p)
+	}
+
+	if p, err = objects.Diff(ctx, cfg, nil); err != nil {
+		t.Fatalf("Diff()=%+v", err)
+	}
+
+	if want := (objects.Patch{{Op: "Del", Key: objects.Key{"server"}}}); !cmp.Equal(p, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(p, want))
+	}
+
+	if p, err = objects.Diff(ctx, nil, none); err != nil || len(p) != 0 {
+		t.Fatalf("Diff()=%v, %+v, want empty patch", p, err)
+	}
+
+	if _, err := objects.Diff(ctx, 1, cfg); !errors.Is(err, objects.ErrUnexpectedType) {
+		t.Fatalf("Diff()=%+v, want %v", err, objects.ErrUnexpectedType)
+	}
+}
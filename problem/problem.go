@@ -0,0 +1,101 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"rafal.dev/objects/types"
+)
+
+const ContentType = "application/problem+json"
+
+// Details is a problem details document as defined by RFC 7807,
+// extended with the list of failing keys.
+type Details struct {
+	Type   string  `json:"type,omitempty"`
+	Title  string  `json:"title"`
+	Status int     `json:"status,omitempty"`
+	Detail string  `json:"detail,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Key    string `json:"key,omitempty"`
+	Op     string `json:"op,omitempty"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+var codes = []struct {
+	err  error
+	code string
+}{
+	{types.ErrNotFound, "not_found"},
+	{types.ErrOutOfBounds, "out_of_bounds"},
+	{types.ErrEmpty, "empty"},
+	{types.ErrUnexpectedType, "unexpected_type"},
+	{types.ErrClosed, "closed"},
+	{types.ErrQueueFull, "queue_full"},
+	{types.ErrConflict, "conflict"},
+	{types.ErrReadOnly, "read_only"},
+	{types.ErrUnused, "unused"},
+	{types.ErrTooLarge, "too_large"},
+	{types.ErrQuotaExceeded, "quota_exceeded"},
+	{types.ErrInvalid, "invalid"},
+}
+
+// Code gives a machine-readable code of the sentinel error err wraps,
+// or "internal" if it wraps none.
+func Code(err error) string {
+	for _, c := range codes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "internal"
+}
+
+// New builds a problem details document from err, reporting
+// every error of a types.Errors aggregate separately.
+func New(err error, status int) *Details {
+	d := &Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	var errs types.Errors
+	if !errors.As(err, &errs) {
+		errs = types.Errors{err}
+	}
+
+	for _, err := range errs {
+		e := Error{
+			Code:   Code(err),
+			Detail: err.Error(),
+		}
+
+		var te *types.Error
+		if errors.As(err, &te) {
+			e.Key = types.Key(te.Key).String()
+			e.Op = te.Op
+		}
+
+		d.Errors = append(d.Errors, e)
+	}
+
+	if len(d.Errors) == 1 {
+		d.Detail = d.Errors[0].Detail
+	}
+
+	return d
+}
+
+// Write writes the problem details document of err to w.
+func Write(w http.ResponseWriter, err error, status int) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(New(err, status))
+}
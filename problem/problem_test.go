@@ -0,0 +1,66 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rafal.dev/objects/problem"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	var (
+		rec = httptest.NewRecorder()
+		err = types.Errors{
+			&types.Error{Op: "Get", Key: []string{"server", "port"}, Err: types.ErrNotFound},
+			&types.Error{Op: "Decode", Key: []string{"server", "prot"}, Err: types.ErrUnused},
+		}
+	)
+
+	if err := problem.Write(rec, err, http.StatusUnprocessableEntity); err != nil {
+		t.Fatalf("Write()=%+v", err)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != problem.ContentType {
+		t.Fatalf("got %q, want %q", got, problem.ContentType)
+	}
+
+	var got problem.Details
+
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Decode()=%+v", err)
+	}
+
+	want := problem.Details{
+		Type:   "about:blank",
+		Title:  "Unprocessable Entity",
+		Status: http.StatusUnprocessableEntity,
+		Errors: []problem.Error{
+			{Key: "server.port", Op: "Get", Code: "not_found", Detail: err[0].Error()},
+			{Key: "server.prot", Op: "Decode", Code: "unused", Detail: err[1].Error()},
+		},
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestCode(t *testing.T) {
+	cases := map[error]string{
+		&types.Error{Op: "Set", Err: types.ErrQuotaExceeded}: "quota_exceeded",
+		&types.Error{Op: "Parse", Err: types.ErrInvalid}:     "invalid",
+		errors.New("unknown"):                                "internal",
+	}
+
+	for err, want := range cases {
+		if got := problem.Code(err); got != want {
+			t.Errorf("Code(%v)=%q, want %q", err, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package protobuf exposes protobuf messages as trees of objects.
+//
+// Fields are keyed by their names. Messages are exposed as structs,
+// repeated fields as slices and map fields as maps keyed by the string
+// form of their keys. Enum values are given by their names.
+package protobuf
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"rafal.dev/objects"
+)
+
+// Message is a reader of a protobuf message.
+type Message struct {
+	m protoreflect.Message
+}
+
+// List is a reader of a repeated field.
+type List struct {
+	l  protoreflect.List
+	fd protoreflect.FieldDescriptor
+}
+
+// Map is a reader of a map field.
+type Map struct {
+	m  protoreflect.Map
+	fd protoreflect.FieldDescriptor
+}
+
+var (
+	_ objects.Reader     = (*Message)(nil)
+	_ objects.SafeReader = (*Message)(nil)
+	_ objects.SafeLister = (*Message)(nil)
+	_ objects.Reader     = (*List)(nil)
+	_ objects.SafeReader = (*List)(nil)
+	_ objects.SafeLister = (*List)(nil)
+	_ objects.Reader     = (*Map)(nil)
+	_ objects.SafeReader = (*Map)(nil)
+	_ objects.SafeLister = (*Map)(nil)
+)
+
+// Read creates a reader of the message m. Dynamic messages, created
+// with dynamicpb from a descriptor, are supported as well.
+func Read(m protoreflect.ProtoMessage) *Message {
+	return &Message{m: m.ProtoReflect()}
+}
+
+func (m *Message) Type() objects.Type {
+	return objects.TypeStruct
+}
+
+func (m *Message) Get(ctx context.Context, key string) (any, bool) {
+	v, err := m.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+// SafeGet gives the value of a populated field.
+func (m *Message) SafeGet(ctx context.Context, key string) (any, error) {
+	fd := m.m.Descriptor().Fields().ByName(protoreflect.Name(key))
+
+	if fd == nil || !m.m.Has(fd) {
+		return nil, &objects.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: objects.ErrNotFound,
+		}
+	}
+
+	v := m.m.Get(fd)
+
+	switch {
+	case fd.IsList():
+		return &List{l: v.List(), fd: fd}, nil
+	case fd.IsMap():
+		return &Map{m: v.Map(), fd: fd}, nil
+	default:
+		return value(fd, v), nil
+	}
+}
+
+func (m *Message) List(ctx context.Context) []string {
+	keys, _ := m.SafeList(ctx)
+	return keys
+}
+
+// SafeList lists populated fields in order of their declaration.
+func (m *Message) SafeList(ctx context.Context) ([]string, error) {
+	var (
+		keys   []string
+		fields = m.m.Descriptor().Fields()
+	)
+
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); m.m.Has(fd) {
+			keys = append(keys, string(fd.Name()))
+		}
+	}
+
+	return keys, nil
+}
+
+func (l *List) Type() objects.Type {
+	return objects.TypeSlice
+}
+
+func (l *List) Get(ctx context.Context, key string) (any, bool) {
+	v, err := l.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (l *List) SafeGet(ctx context.Context, key string) (any, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i >= l.l.Len() {
+		return nil, &objects.Error{
+			Op:   "Get",
+			Key:  []string{key},
+			Got:  key,
+			Want: l.l.Len(),
+			Err:  objects.ErrOutOfBounds,
+		}
+	}
+
+	return value(l.fd, l.l.Get(i)), nil
+}
+
+func (l *List) List(ctx context.Context) []string {
+	keys, _ := l.SafeList(ctx)
+	return keys
+}
+
+func (l *List) SafeList(ctx context.Context) ([]string, error) {
+	keys := make([]string, l.l.Len())
+
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	return keys, nil
+}
+
+func (m *Map) Type() objects.Type {
+	return objects.TypeMap
+}
+
+func (m *Map) Get(ctx context.Context, key string) (any, bool) {
+	v, err := m.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (m *Map) SafeGet(ctx context.Context, key string) (any, error) {
+	k, err := mapKey(m.fd.MapKey(), key)
+	if err == nil && m.m.Has(k) {
+		return value(m.fd.MapValue(), m.m.Get(k)), nil
+	}
+
+	return nil, &objects.Error{
+		Op:  "Get",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+func (m *Map) List(ctx context.Context) []string {
+	keys, _ := m.SafeList(ctx)
+	return keys
+}
+
+// SafeList lists keys of the map; their order is undefined.
+func (m *Map) SafeList(ctx context.Context) ([]string, error) {
+	keys := make([]string, 0, m.m.Len())
+
+	m.m.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k.String())
+		return true
+	})
+
+	return keys, nil
+}
+
+func value(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return &Message{m: v.Message()}
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+func mapKey(fd protoreflect.FieldDescriptor, key string) (protoreflect.MapKey, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(key).MapKey(), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(key)
+		return protoreflect.ValueOfBool(b).MapKey(), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(key, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)).MapKey(), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(key, 10, 64)
+		return protoreflect.ValueOfInt64(n).MapKey(), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(key, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)).MapKey(), err
+	default:
+		n, err := strconv.ParseUint(key, 10, 64)
+		return protoreflect.ValueOfUint64(n).MapKey(), err
+	}
+}
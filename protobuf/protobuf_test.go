@@ -0,0 +1,146 @@
+package protobuf_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/protobuf"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newServer builds a dynamic message described by:
+//
+//	enum State { UNKNOWN = 0; READY = 1; }
+//	message Server {
+//	  string name = 1;
+//	  repeated string tags = 2;
+//	  map<int32, string> ports = 3;
+//	  Server backup = 4;
+//	  State state = 5;
+//	}
+func newServer(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	var (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		str      = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+		i32      = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+		msg      = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		enum     = descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum()
+	)
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("server.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("State"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("READY"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Server"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), Number: proto.Int32(1), Label: optional, Type: str},
+				{Name: proto.String("tags"), Number: proto.Int32(2), Label: repeated, Type: str},
+				{Name: proto.String("ports"), Number: proto.Int32(3), Label: repeated, Type: msg, TypeName: proto.String(".test.Server.PortsEntry")},
+				{Name: proto.String("backup"), Number: proto.Int32(4), Label: optional, Type: msg, TypeName: proto.String(".test.Server")},
+				{Name: proto.String("state"), Number: proto.Int32(5), Label: optional, Type: enum, TypeName: proto.String(".test.State")},
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("PortsEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("key"), Number: proto.Int32(1), Label: optional, Type: i32},
+					{Name: proto.String("value"), Number: proto.Int32(2), Label: optional, Type: str},
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatalf("NewFile()=%+v", err)
+	}
+
+	return fd.Messages().ByName("Server")
+}
+
+func TestRead(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		md     = newServer(t)
+		m      = dynamicpb.NewMessage(md)
+		backup = dynamicpb.NewMessage(md)
+		fields = md.Fields()
+	)
+
+	backup.Set(fields.ByName("name"), protoreflect.ValueOfString("b"))
+
+	m.Set(fields.ByName("name"), protoreflect.ValueOfString("a"))
+	m.Set(fields.ByName("backup"), protoreflect.ValueOfMessage(backup))
+	m.Set(fields.ByName("state"), protoreflect.ValueOfEnum(1))
+
+	tags := m.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("x"))
+	tags.Append(protoreflect.ValueOfString("y"))
+
+	ports := m.Mutable(fields.ByName("ports")).Map()
+	ports.Set(protoreflect.ValueOfInt32(80).MapKey(), protoreflect.ValueOfString("http"))
+	ports.Set(protoreflect.ValueOfInt32(443).MapKey(), protoreflect.ValueOfString("https"))
+
+	r := protobuf.Read(m)
+
+	if got, want := r.List(ctx), []string{"name", "tags", "ports", "backup", "state"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	gets := []struct {
+		keys []string
+		want any
+	}{
+		{[]string{"name"}, "a"},
+		{[]string{"tags", "1"}, "y"},
+		{[]string{"ports", "443"}, "https"},
+		{[]string{"backup", "name"}, "b"},
+		{[]string{"state"}, "READY"},
+	}
+
+	for _, get := range gets {
+		v, err := objects.Get(ctx, r, get.keys...)
+		if err != nil {
+			t.Fatalf("Get(%v)=%+v", get.keys, err)
+		}
+
+		if !cmp.Equal(v, get.want) {
+			t.Fatalf("Get(%v): got %#v, want %#v", get.keys, v, get.want)
+		}
+	}
+
+	keys, err := objects.List(ctx, r, "ports")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	sort.Strings(keys)
+
+	if want := []string{"443", "80"}; !cmp.Equal(keys, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(keys, want))
+	}
+
+	if _, err := objects.Get(ctx, r, "backup", "tags"); err == nil {
+		t.Fatal("expected Get of an unpopulated field to fail")
+	}
+}
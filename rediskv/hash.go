@@ -0,0 +1,339 @@
+package rediskv
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects/types"
+)
+
+// Hashes is a tree, which top-level keys are Redis hashes stored under
+// a prefix and which leaves are fields of the hashes.
+type Hashes struct {
+	c      Client
+	prefix string
+}
+
+// Hash is a Redis hash.
+type Hash struct {
+	c   Client
+	key string
+}
+
+var (
+	_ types.Interface  = (*Hashes)(nil)
+	_ types.SafeReader = (*Hashes)(nil)
+	_ types.SafeLister = (*Hashes)(nil)
+	_ types.SafeWriter = (*Hashes)(nil)
+	_ types.Interface  = (*Hash)(nil)
+	_ types.SafeReader = (*Hash)(nil)
+	_ types.SafeLister = (*Hash)(nil)
+	_ types.SafeWriter = (*Hash)(nil)
+)
+
+// NewHashes gives a tree of hashes stored under the prefix, e.g. the
+// db.host key is the host field of the app:db hash for "app:" prefix.
+func NewHashes(c Client, prefix string) *Hashes {
+	return &Hashes{
+		c:      c,
+		prefix: prefix,
+	}
+}
+
+func (h *Hashes) Type() types.Type {
+	return types.TypeMap
+}
+
+func (h *Hashes) Get(ctx context.Context, key string) (any, bool) {
+	v, err := h.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (h *Hashes) SafeGet(ctx context.Context, key string) (any, error) {
+	hash := h.hash(key)
+
+	fields, err := hash.SafeList(ctx)
+	if err != nil {
+		return nil, hashError("Get", key, err)
+	}
+
+	if len(fields) == 0 {
+		return nil, &types.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: types.ErrNotFound,
+		}
+	}
+
+	return hash, nil
+}
+
+func (h *Hashes) List(ctx context.Context) []string {
+	keys, _ := h.SafeList(ctx)
+	return keys
+}
+
+func (h *Hashes) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	keys, err := h.c.Keys(ctx, glob(h.prefix)+"*")
+	if err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, h.prefix)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (h *Hashes) Del(ctx context.Context, key string) bool {
+	return h.SafeDel(ctx, key) == nil
+}
+
+func (h *Hashes) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := h.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (h *Hashes) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := h.SafePut(ctx, key, hint)
+	return w
+}
+
+func (h *Hashes) SafeDel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return hashError("Del", key, err)
+	}
+
+	n, err := h.c.Del(ctx, h.prefix+key)
+	if err != nil {
+		return hashError("Del", key, err)
+	}
+
+	if n == 0 {
+		return hashError("Del", key, types.ErrNotFound)
+	}
+
+	return nil
+}
+
+// SafeSet replaces the hash with fields of value, which must be
+// a map of string or []byte leaves.
+func (h *Hashes) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	r, ok := value.(types.Reader)
+	if !ok || r.Type() == types.TypeSlice {
+		return false, &types.Error{
+			Op:   "Set",
+			Key:  []string{key},
+			Got:  value,
+			Want: types.TypeMap,
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+
+	err := h.SafeDel(ctx, key)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		return false, err
+	}
+
+	previous := err == nil
+
+	fields, err := types.List(ctx, r)
+	if err != nil {
+		return false, hashError("Set", key, err)
+	}
+
+	hash := h.hash(key)
+
+	for _, f := range fields {
+		v, err := types.PrefixedReader{R: r}.SafeGet(ctx, f)
+		if err != nil {
+			return false, hashError("Set", key, err)
+		}
+
+		if _, err := hash.SafeSet(ctx, f, v); err != nil {
+			return false, hashError("Set", key, err)
+		}
+	}
+
+	return previous, nil
+}
+
+// SafePut gives the hash, which is created on the first write of
+// a field.
+func (h *Hashes) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	return h.hash(key), nil
+}
+
+func (h *Hashes) Ping(ctx context.Context) error {
+	return types.Ping(ctx, h.c)
+}
+
+func (h *Hashes) Close(ctx context.Context) error {
+	return types.Close(ctx, h.c)
+}
+
+func (h *Hashes) Unwrap() any {
+	return h.c
+}
+
+func (h *Hashes) hash(key string) *Hash {
+	return &Hash{
+		c:   h.c,
+		key: h.prefix + key,
+	}
+}
+
+func (h *Hash) Type() types.Type {
+	return types.TypeMap
+}
+
+func (h *Hash) Get(ctx context.Context, key string) (any, bool) {
+	v, err := h.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (h *Hash) SafeGet(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, hashError("Get", key, err)
+	}
+
+	p, err := h.c.HGet(ctx, h.key, key)
+	if err != nil {
+		return nil, hashError("Get", key, err)
+	}
+
+	return p, nil
+}
+
+func (h *Hash) List(ctx context.Context) []string {
+	keys, _ := h.SafeList(ctx)
+	return keys
+}
+
+func (h *Hash) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	keys, err := h.c.HKeys(ctx, h.key)
+	if err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (h *Hash) Del(ctx context.Context, key string) bool {
+	return h.SafeDel(ctx, key) == nil
+}
+
+func (h *Hash) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := h.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (h *Hash) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := h.SafePut(ctx, key, hint)
+	return w
+}
+
+func (h *Hash) SafeDel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return hashError("Del", key, err)
+	}
+
+	n, err := h.c.HDel(ctx, h.key, key)
+	if err != nil {
+		return hashError("Del", key, err)
+	}
+
+	if n == 0 {
+		return hashError("Del", key, types.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (h *Hash) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	var p []byte
+
+	switch v := value.(type) {
+	case []byte:
+		p = v
+	case string:
+		p = []byte(v)
+	default:
+		return false, &types.Error{
+			Op:   "Set",
+			Key:  []string{key},
+			Got:  value,
+			Want: []byte(nil),
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, hashError("Set", key, err)
+	}
+
+	created, err := h.c.HSet(ctx, h.key, key, p)
+	if err != nil {
+		return false, hashError("Set", key, err)
+	}
+
+	return !created, nil
+}
+
+// SafePut fails, as fields of hashes are leaves.
+func (h *Hash) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	return nil, &types.Error{
+		Op:   "Put",
+		Key:  []string{key},
+		Got:  hint,
+		Want: []byte(nil),
+		Err:  types.ErrUnexpectedType,
+	}
+}
+
+func (h *Hash) Ping(ctx context.Context) error {
+	return types.Ping(ctx, h.c)
+}
+
+func (h *Hash) Close(ctx context.Context) error {
+	return types.Close(ctx, h.c)
+}
+
+func (h *Hash) Unwrap() any {
+	return h.c
+}
+
+func hashError(op, key string, err error) error {
+	return &types.Error{
+		Op:  op,
+		Key: []string{key},
+		Err: err,
+	}
+}
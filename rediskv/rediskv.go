@@ -0,0 +1,218 @@
+package rediskv
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects/kv"
+	"rafal.dev/objects/types"
+)
+
+const Sep = ":"
+
+// Client is the subset of a Redis client used by the backends, e.g.
+// an adapter over go-redis. Get and HGet are expected to return an
+// error wrapping types.ErrNotFound for missing keys and fields; Keys
+// gives keys matching the glob pattern, e.g. with SCAN.
+type Client interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Del(ctx context.Context, key string) (deleted int64, err error)
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	HGet(ctx context.Context, key, field string) ([]byte, error)
+	HSet(ctx context.Context, key, field string, value []byte) (created bool, err error)
+	HDel(ctx context.Context, key, field string) (deleted int64, err error)
+	HKeys(ctx context.Context, key string) ([]string, error)
+}
+
+// Batcher is implemented by clients able to get and set many keys in
+// one round trip, e.g. with MGET and MSET. MGet gives nil values for
+// missing keys.
+type Batcher interface {
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	MSet(ctx context.Context, values map[string][]byte) error
+}
+
+type store struct {
+	c      Client
+	prefix string
+}
+
+var (
+	_ kv.Store      = store{}
+	_ kv.BatchStore = store{}
+)
+
+// New gives a tree of keys stored under the namespace prefix, e.g.
+// "app:", with segments of the key joined by Sep, so that the db.host
+// key is stored as app:db:host.
+func New(c Client, prefix string) *kv.Tree {
+	return kv.New(store{c: c, prefix: prefix}, Sep)
+}
+
+func (s store) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.c.Get(ctx, s.prefix+key)
+}
+
+func (s store) Set(ctx context.Context, key string, value []byte) (bool, error) {
+	var previous = true
+
+	if _, err := s.Get(ctx, key); errors.Is(err, types.ErrNotFound) {
+		previous = false
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := s.c.Set(ctx, s.prefix+key, value); err != nil {
+		return false, err
+	}
+
+	return previous, nil
+}
+
+func (s store) Del(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	n, err := s.c.Del(ctx, s.prefix+key)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetMany gets the keys with MGet, if the client is a Batcher.
+func (s store) GetMany(ctx context.Context, keys []string) ([][]byte, []error) {
+	var (
+		values = make([][]byte, len(keys))
+		errs   = make([]error, len(keys))
+	)
+
+	b, ok := s.c.(Batcher)
+	if !ok {
+		for i, k := range keys {
+			values[i], errs[i] = s.Get(ctx, k)
+		}
+
+		return values, errs
+	}
+
+	if err := ctx.Err(); err != nil {
+		return values, fill(errs, err)
+	}
+
+	prefixed := make([]string, len(keys))
+
+	for i, k := range keys {
+		prefixed[i] = s.prefix + k
+	}
+
+	got, err := b.MGet(ctx, prefixed...)
+	if err != nil {
+		return values, fill(errs, err)
+	}
+
+	for i := range keys {
+		if i >= len(got) || got[i] == nil {
+			errs[i] = types.ErrNotFound
+			continue
+		}
+
+		values[i] = got[i]
+	}
+
+	return values, errs
+}
+
+// SetMany sets the keys with MSet, if the client is a Batcher.
+func (s store) SetMany(ctx context.Context, keys []string, values [][]byte) []error {
+	errs := make([]error, len(keys))
+
+	b, ok := s.c.(Batcher)
+	if !ok {
+		for i, k := range keys {
+			_, errs[i] = s.Set(ctx, k, values[i])
+		}
+
+		return errs
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fill(errs, err)
+	}
+
+	m := make(map[string][]byte, len(keys))
+
+	for i, k := range keys {
+		m[s.prefix+k] = values[i]
+	}
+
+	if err := b.MSet(ctx, m); err != nil {
+		return fill(errs, err)
+	}
+
+	return errs
+}
+
+func (s store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.c.Keys(ctx, glob(s.prefix+prefix)+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, s.prefix)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	return types.Ping(ctx, s.c)
+}
+
+func (s store) Close(ctx context.Context) error {
+	return types.Close(ctx, s.c)
+}
+
+func (s store) Unwrap() any {
+	return s.c
+}
+
+func glob(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func fill(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,290 @@
+package rediskv_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/rediskv"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type client struct {
+	strings map[string][]byte
+	hashes  map[string]map[string][]byte
+	err     error
+}
+
+func newClient() *client {
+	return &client{
+		strings: make(map[string][]byte),
+		hashes:  make(map[string]map[string][]byte),
+	}
+}
+
+func (c *client) Get(_ context.Context, key string) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	p, ok := c.strings[key]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return p, nil
+}
+
+func (c *client) Set(_ context.Context, key string, value []byte) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.strings[key] = value
+	return nil
+}
+
+func (c *client) Del(_ context.Context, key string) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	_, s := c.strings[key]
+	_, h := c.hashes[key]
+	delete(c.strings, key)
+	delete(c.hashes, key)
+	if s || h {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (c *client) Keys(_ context.Context, pattern string) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var keys []string
+	for k := range c.strings {
+		if ok, _ := path.Match(pattern, k); ok {
+			keys = append(keys, k)
+		}
+	}
+	for k := range c.hashes {
+		if ok, _ := path.Match(pattern, k); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (c *client) HGet(_ context.Context, key, field string) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	p, ok := c.hashes[key][field]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return p, nil
+}
+
+func (c *client) HSet(_ context.Context, key, field string, value []byte) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	h, ok := c.hashes[key]
+	if !ok {
+		h = make(map[string][]byte)
+		c.hashes[key] = h
+	}
+	_, ok = h[field]
+	h[field] = value
+	return !ok, nil
+}
+
+func (c *client) HDel(_ context.Context, key, field string) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	if _, ok := c.hashes[key][field]; !ok {
+		return 0, nil
+	}
+	delete(c.hashes[key], field)
+	if len(c.hashes[key]) == 0 {
+		delete(c.hashes, key)
+	}
+	return 1, nil
+}
+
+func (c *client) HKeys(_ context.Context, key string) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var keys []string
+	for k := range c.hashes[key] {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func TestNew(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newClient()
+		tr  = rediskv.New(c, "app:")
+	)
+
+	c.strings["other:key"] = []byte("x")
+
+	if err := objects.Copy(ctx, tr, types.Map{"db": types.Map{"host": "localhost"}}); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	want := map[string][]byte{
+		"app:db:host": []byte("localhost"),
+		"other:key":   []byte("x"),
+	}
+
+	if !cmp.Equal(c.strings, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(c.strings, want))
+	}
+
+	down := errors.New("connection refused")
+	c.err = down
+
+	if _, err := objects.Get(ctx, tr, "db", "host"); !errors.Is(err, down) {
+		t.Fatalf("Get()=%+v, want %v", err, down)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(*testing.T) types.Interface {
+		return rediskv.New(newClient(), "app:")
+	})
+}
+
+func TestHashes(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newClient()
+		h   = rediskv.NewHashes(c, "app:")
+	)
+
+	if _, err := objects.Set(ctx, h, types.Map{"host": "localhost", "port": "5432"}, "db"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, h, "admin", "db", "user"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, h, "db", "port"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	want := map[string]map[string][]byte{
+		"app:db": {
+			"host": []byte("localhost"),
+			"user": []byte("admin"),
+		},
+	}
+
+	if !cmp.Equal(c.hashes, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(c.hashes, want))
+	}
+
+	keys, err := objects.List(ctx, h, "db")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"host", "user"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, err := objects.Get(ctx, h, "cache"); !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, objects.ErrNotFound)
+	}
+
+	if _, err := objects.Put(ctx, h, objects.TypeMap, "db", "host"); !errors.Is(err, objects.ErrUnexpectedType) {
+		t.Fatalf("Put()=%+v, want %v", err, objects.ErrUnexpectedType)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := objects.Get(ctx, h, "db", "host"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Get()=%+v, want %v", err, context.Canceled)
+	}
+}
+
+type batchClient struct {
+	*client
+	calls int
+}
+
+func (c *batchClient) MGet(_ context.Context, keys ...string) ([][]byte, error) {
+	c.calls++
+
+	values := make([][]byte, len(keys))
+
+	for i, k := range keys {
+		values[i] = c.strings[k]
+	}
+
+	return values, nil
+}
+
+func (c *batchClient) MSet(_ context.Context, values map[string][]byte) error {
+	c.calls++
+
+	for k, v := range values {
+		c.strings[k] = v
+	}
+
+	return nil
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = &batchClient{client: newClient()}
+		tr  = rediskv.New(c, "app:")
+	)
+
+	res := objects.SetMany(ctx, tr,
+		objects.Entry{Key: objects.Key{"db", "host"}, Value: "localhost"},
+		objects.Entry{Key: objects.Key{"db", "port"}, Value: "5432"},
+		objects.Entry{Key: objects.Key{"name"}, Value: 1},
+	)
+
+	if res[0].Err != nil || res[1].Err != nil || !errors.Is(res[2].Err, types.ErrUnexpectedType) {
+		t.Fatalf("SetMany()=%+v", res)
+	}
+
+	res = objects.GetMany(ctx, tr, objects.Key{"db", "host"}, objects.Key{"db", "port"}, objects.Key{"db"}, objects.Key{"missing"})
+
+	if err := types.Errs(res[:3]); err != nil {
+		t.Fatalf("GetMany()=%+v", err)
+	}
+
+	if !errors.Is(res[3].Err, types.ErrNotFound) {
+		t.Fatalf("GetMany()=%+v, want %v", res[3].Err, types.ErrNotFound)
+	}
+
+	got := []any{res[0].Value, res[1].Value}
+	want := []any{[]byte("localhost"), []byte("5432")}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, ok := res[2].Value.(types.Reader); !ok {
+		t.Fatalf("got %T, want a subtree", res[2].Value)
+	}
+
+	if c.calls != 2 {
+		t.Fatalf("got %d round trips, want 2", c.calls)
+	}
+}
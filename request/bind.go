@@ -0,0 +1,144 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// sources maps binding tags to keys of the tree of a request.
+var sources = []struct {
+	tag  string
+	key  string
+	name func(string) string
+}{
+	{"path", "params", nil},
+	{"query", "query", nil},
+	{"header", "headers", strings.ToLower},
+	{"claim", "claims", nil},
+}
+
+// Bind decodes data of the request into the struct pointed to by dst.
+// Fields are bound to their source by tags:
+//
+//	type GetUser struct {
+//		ID      string `path:"id"`
+//		Page    int    `query:"page"`
+//		TraceID string `header:"X-Trace-Id"`
+//		Subject string `claim:"sub"`
+//		Name    string `json:"name"` // from the body
+//	}
+//
+// Fields without these tags are decoded from the body, named after
+// the "body" tag or as by objects.Decode. Bind uses the tree stored
+// by Middleware, building one with DefaultOptions if there is none.
+func Bind(req *http.Request, dst any) error {
+	ctx := req.Context()
+
+	tree, ok := From(ctx)
+	if !ok {
+		var err error
+
+		if tree, err = New(req, nil); err != nil {
+			return err
+		}
+	}
+
+	src, err := bindings(ctx, tree, dst)
+	if err != nil {
+		return err
+	}
+
+	opts := &objects.DecodeOptions{
+		Options: &objects.Options{
+			StructField: field,
+		},
+	}
+
+	return objects.Decode(ctx, src, dst, opts)
+}
+
+// bindings gives the body of the tree, with values of the fields of
+// dst bound to other sources added under their "tag:name" keys.
+func bindings(ctx context.Context, tree types.Reader, dst any) (types.Map, error) {
+	src := make(types.Map)
+
+	if body, err := objects.Get(ctx, tree, "body"); err == nil {
+		if r, ok := body.(types.Reader); ok && r.Type() == types.TypeMap {
+			kvs, err := types.ListValues(ctx, r)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, kv := range kvs {
+				src[kv.Key] = kv.Value
+			}
+		}
+	} else if !errors.Is(err, objects.ErrNotFound) {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(dst)
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return src, nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := field(t.Field(i))
+
+		tag, key, ok := strings.Cut(name, ":")
+		if !ok {
+			continue
+		}
+
+		for _, s := range sources {
+			if s.tag != tag {
+				continue
+			}
+
+			if s.name != nil {
+				key = s.name(key)
+			}
+
+			v, err := objects.Get(ctx, tree, s.key, key)
+			if errors.Is(err, objects.ErrNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			src[name] = v
+		}
+	}
+
+	return src, nil
+}
+
+// field names struct fields bound to sources other than the body
+// as "tag:name", e.g. "query:page".
+func field(f reflect.StructField) string {
+	for _, s := range sources {
+		if v, ok := f.Tag.Lookup(s.tag); ok {
+			name, _, _ := strings.Cut(v, ",")
+			return s.tag + ":" + name
+		}
+	}
+
+	if v, _, _ := strings.Cut(f.Tag.Get("body"), ","); v != "" {
+		return v
+	}
+
+	return objects.DefaultField(f)
+}
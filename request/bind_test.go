@@ -0,0 +1,72 @@
+package request_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/request"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type updateUser struct {
+	ID      string `path:"id"`
+	Page    int    `query:"page" validate:"min=1"`
+	TraceID string `header:"X-Trace-Id"`
+	Name    string `json:"name" validate:"required"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func TestBind(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/users/42?page=2", strings.NewReader(`{"name":"gopher","address":{"city":"Warsaw"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trace-Id", "abc")
+
+	tree, err := request.New(req, &request.Options{
+		Params: func(*http.Request) map[string]string {
+			return map[string]string{"id": "42"}
+		},
+		Codecs: request.DefaultOptions.Codecs,
+	})
+	if err != nil {
+		t.Fatalf("New()=%+v", err)
+	}
+
+	req = req.WithContext(request.WithTree(req.Context(), tree))
+
+	var got updateUser
+
+	if err := request.Bind(req, &got); err != nil {
+		t.Fatalf("Bind()=%+v", err)
+	}
+
+	want := updateUser{ID: "42", Page: 2, TraceID: "abc", Name: "gopher"}
+	want.Address.City = "Warsaw"
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBindInvalid(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/users?page=0", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	var u updateUser
+
+	err := request.Bind(req, &u)
+	if !errors.Is(err, objects.ErrInvalid) {
+		t.Fatalf("Bind()=%+v, want %v", err, objects.ErrInvalid)
+	}
+
+	var errs objects.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %+v, want 2 errors", err)
+	}
+}
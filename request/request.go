@@ -0,0 +1,223 @@
+// Package request exposes data of HTTP requests as trees, so that
+// handlers and the libraries they call read it through one API.
+//
+// The tree of a request has the following keys:
+//
+//	method  - the request method
+//	host    - the request host
+//	path    - the URL path
+//	headers - values of headers under their lower-case names
+//	query   - values of query parameters
+//	params  - route parameters, see Options.Params
+//	claims  - claims of the authenticated token, see Options.Claims
+//	body    - the decoded body, see Options.Codecs
+//
+// Headers and query parameters with many values hold the first one,
+// as http.Header.Get and url.Values.Get do.
+package request
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/problem"
+	"rafal.dev/objects/types"
+)
+
+type Options struct {
+	// Params gives route parameters of the request, e.g. from
+	// a router's context.
+	Params func(*http.Request) map[string]string
+
+	// Claims gives claims of the token the request is authenticated
+	// with. The token is expected to be verified by Claims, as the
+	// tree is trusted by its readers.
+	Claims func(*http.Request) (map[string]any, error)
+
+	// Codecs decode bodies by their content type, e.g.
+	// "application/json". Form bodies are always decoded.
+	Codecs map[string]codec.Codec
+
+	// MaxBody limits the size of decoded bodies, in bytes.
+	MaxBody int64
+}
+
+var DefaultOptions = &Options{
+	Codecs: map[string]codec.Codec{
+		"application/json": codec.JSON,
+	},
+	MaxBody: 1 << 20,
+}
+
+type treeKey struct{}
+
+// WithTree stores the tree of a request in ctx.
+func WithTree(ctx context.Context, r types.Reader) context.Context {
+	return context.WithValue(ctx, treeKey{}, r)
+}
+
+// From gives the tree stored in ctx with WithTree.
+func From(ctx context.Context) (types.Reader, bool) {
+	r, ok := ctx.Value(treeKey{}).(types.Reader)
+	return r, ok
+}
+
+// Middleware stores the tree of each request in its context. It
+// responds with a problem details document if the tree cannot be
+// built: 401 if Claims fails, 413 for a too large body and 400
+// for a malformed one.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			tree, err := New(req, opts)
+			if err != nil {
+				var (
+					status = http.StatusBadRequest
+					e      *types.Error
+				)
+
+				switch {
+				case errors.As(err, &e) && e.Op == "Claims":
+					status = http.StatusUnauthorized
+				case errors.Is(err, types.ErrTooLarge):
+					status = http.StatusRequestEntityTooLarge
+				}
+
+				_ = problem.Write(w, err, status)
+				return
+			}
+
+			next.ServeHTTP(w, req.WithContext(WithTree(req.Context(), tree)))
+		})
+	}
+}
+
+// New builds the tree of the request. The body, if decoded, is
+// buffered, so that it remains readable from req.Body.
+func New(req *http.Request, opts *Options) (types.Map, error) {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	tree := types.Map{
+		"method":  req.Method,
+		"host":    req.Host,
+		"path":    req.URL.Path,
+		"headers": first(req.Header, strings.ToLower),
+		"query":   first(req.URL.Query(), nil),
+		"params":  types.Map{},
+		"claims":  types.Map{},
+	}
+
+	if opts.Params != nil {
+		params := tree["params"].(types.Map)
+
+		for k, v := range opts.Params(req) {
+			params[k] = v
+		}
+	}
+
+	if opts.Claims != nil {
+		claims, err := opts.Claims(req)
+		if err != nil {
+			return nil, &types.Error{
+				Op:  "Claims",
+				Key: []string{"claims"},
+				Err: err,
+			}
+		}
+
+		tree["claims"] = types.Map(claims)
+	}
+
+	body, err := decode(req, opts)
+	if err != nil {
+		return nil, &types.Error{
+			Op:  "Decode",
+			Key: []string{"body"},
+			Err: err,
+		}
+	}
+
+	if body != nil {
+		tree["body"] = body
+	}
+
+	return tree, nil
+}
+
+func decode(req *http.Request, opts *Options) (any, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	typ, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+
+	c, ok := opts.Codecs[typ]
+	if !ok && typ != "application/x-www-form-urlencoded" {
+		return nil, nil
+	}
+
+	r := io.Reader(req.Body)
+
+	if opts.MaxBody > 0 {
+		r = io.LimitReader(r, opts.MaxBody+1)
+	}
+
+	p, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxBody > 0 && int64(len(p)) > opts.MaxBody {
+		return nil, types.ErrTooLarge
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(p))
+
+	if !ok {
+		form, err := url.ParseQuery(string(p))
+		if err != nil {
+			return nil, err
+		}
+
+		return first(form, nil), nil
+	}
+
+	var v any
+
+	if err := c.Unmarshal(p, &v); err != nil {
+		return nil, err
+	}
+
+	if r := types.Make(v); r != nil {
+		return r, nil
+	}
+
+	return v, nil
+}
+
+func first(values map[string][]string, name func(string) string) types.Map {
+	m := make(types.Map, len(values))
+
+	for k, v := range values {
+		if len(v) == 0 {
+			continue
+		}
+
+		if name != nil {
+			k = name(k)
+		}
+
+		m[k] = v[0]
+	}
+
+	return m
+}
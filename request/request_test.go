@@ -0,0 +1,115 @@
+package request_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/request"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMiddleware(t *testing.T) {
+	var (
+		got  = make(map[string]any)
+		body string
+		opts = &request.Options{
+			Params: func(*http.Request) map[string]string {
+				return map[string]string{"id": "42"}
+			},
+			Claims: func(req *http.Request) (map[string]any, error) {
+				if req.Header.Get("Authorization") != "Bearer ok" {
+					return nil, errors.New("invalid token")
+				}
+				return map[string]any{"sub": "user-1"}, nil
+			},
+			Codecs:  request.DefaultOptions.Codecs,
+			MaxBody: 64,
+		}
+	)
+
+	h := request.Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		tree, ok := request.From(ctx)
+		if !ok {
+			t.Fatal("no tree in ctx")
+		}
+
+		for _, key := range []string{"method", "headers.x-request-id", "query.page", "params.id", "claims.sub", "body.name"} {
+			v, err := objects.Get(ctx, tree, strings.Split(key, ".")...)
+			if err != nil {
+				t.Fatalf("Get(%s)=%+v", key, err)
+			}
+
+			got[key] = v
+		}
+
+		p, _ := io.ReadAll(req.Body)
+		body = string(p)
+	}))
+
+	serve := func(auth, payload string) int {
+		req := httptest.NewRequest("POST", "/users/42?page=2", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Request-Id", "abc")
+		req.Header.Set("Authorization", auth)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	if code := serve("Bearer ok", `{"name":"gopher"}`); code != http.StatusOK {
+		t.Fatalf("got %d, want %d", code, http.StatusOK)
+	}
+
+	want := map[string]any{
+		"method":               "POST",
+		"headers.x-request-id": "abc",
+		"query.page":           "2",
+		"params.id":            "42",
+		"claims.sub":           "user-1",
+		"body.name":            "gopher",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if body != `{"name":"gopher"}` {
+		t.Fatalf("got body %q", body)
+	}
+
+	if code := serve("Bearer bad", `{}`); code != http.StatusUnauthorized {
+		t.Fatalf("got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if code := serve("Bearer ok", `{"name":"`+strings.Repeat("x", 64)+`"}`); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got %d, want %d", code, http.StatusRequestEntityTooLarge)
+	}
+
+	if code := serve("Bearer ok", `{`); code != http.StatusBadRequest {
+		t.Fatalf("got %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	if _, ok := request.From(context.Background()); ok {
+		t.Fatal("want no tree in an empty ctx")
+	}
+
+	ctx := request.WithTree(context.Background(), types.Map{})
+
+	if _, ok := request.From(ctx); !ok {
+		t.Fatal("want a tree in ctx")
+	}
+}
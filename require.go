@@ -0,0 +1,31 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Require checks that r has values under all the dot-separated paths,
+// e.g. "db.host", reporting every missing one at once.
+func Require(ctx context.Context, r Reader, paths ...string) error {
+	var errs Errors
+
+	for _, p := range paths {
+		key := strings.Split(p, ".")
+
+		switch _, err := Get(ctx, r, key...); {
+		case err == nil:
+		case errors.Is(err, ErrNotFound):
+			errs = append(errs, &Error{
+				Op:  "Require",
+				Key: key,
+				Err: ErrNotFound,
+			})
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.Err()
+}
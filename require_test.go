@@ -0,0 +1,51 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRequire(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{
+				"host": "localhost",
+			},
+		}
+		opts = &objects.DecodeOptions{
+			Options:  objects.DefaultOptions,
+			Required: []string{"server.host", "server.port", "db.host"},
+		}
+		cfg Config
+	)
+
+	if err := objects.Require(ctx, m, "server", "server.host"); err != nil {
+		t.Fatalf("Require()=%+v", err)
+	}
+
+	err := objects.Decode(ctx, m, &cfg, opts)
+	if !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("got %+v, want %+v", err, objects.ErrNotFound)
+	}
+
+	var got []string
+
+	for _, err := range err.(objects.Errors) {
+		got = append(got, types.Key(err.(*objects.Error).Key).String())
+	}
+
+	if want := []string{"server.port", "db.host"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if cfg.Server.Host != "localhost" {
+		t.Fatalf("got %q, want %q", cfg.Server.Host, "localhost")
+	}
+}
@@ -0,0 +1,401 @@
+// Package s3kv exposes objects of an S3 bucket as a tree.
+//
+// Prefixes, delimited by Sep, are read as nested trees and objects as
+// leaves. Objects with a content type of a configured codec are
+// decoded, other ones are read as []byte.
+package s3kv
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects/codec"
+	"rafal.dev/objects/types"
+)
+
+const Sep = "/"
+
+// Client is the subset of an S3 client used by the backend, e.g.
+// an adapter over the AWS SDK. GetObject is expected to return an
+// error wrapping types.ErrNotFound for missing objects.
+type Client interface {
+	GetObject(ctx context.Context, bucket, key string) (*Object, error)
+	PutObject(ctx context.Context, bucket, key string, obj *Object) error
+	DeleteObject(ctx context.Context, bucket, key string) error
+	ListObjectsV2(ctx context.Context, in *ListInput) (*ListOutput, error)
+}
+
+type Object struct {
+	Body        []byte
+	ContentType string
+}
+
+type ListInput struct {
+	Bucket            string
+	Prefix            string
+	Delimiter         string
+	ContinuationToken string
+	MaxKeys           int
+}
+
+// ListOutput is a page of listed keys; NextContinuationToken is
+// empty for the last page.
+type ListOutput struct {
+	Keys                  []string
+	CommonPrefixes        []string
+	NextContinuationToken string
+}
+
+type Options struct {
+	// Codecs decode objects by their content type, e.g.
+	// "application/json".
+	Codecs map[string]codec.Codec
+}
+
+var DefaultOptions = &Options{
+	Codecs: map[string]codec.Codec{
+		"application/json": codec.JSON,
+	},
+}
+
+// Tree is a tree of objects stored under a prefix of a bucket.
+type Tree struct {
+	c      Client
+	bucket string
+	prefix string
+	opts   *Options
+	key    types.Key
+}
+
+var (
+	_ types.Interface  = (*Tree)(nil)
+	_ types.SafeReader = (*Tree)(nil)
+	_ types.SafeLister = (*Tree)(nil)
+	_ types.SafeWriter = (*Tree)(nil)
+)
+
+// New gives a tree of objects of the bucket, which keys start with
+// the prefix, e.g. "app/", so that the db.host key is stored as
+// the app/db/host object.
+func New(c Client, bucket, prefix string, opts *Options) *Tree {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	return &Tree{
+		c:      c,
+		bucket: bucket,
+		prefix: prefix,
+		opts:   opts,
+	}
+}
+
+func (t *Tree) Type() types.Type {
+	return types.TypeMap
+}
+
+func (t *Tree) Get(ctx context.Context, key string) (any, bool) {
+	v, err := t.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+// SafeGet gives the object under the key, decoded if there is a codec
+// for its content type, or a *Tree for a prefix.
+func (t *Tree) SafeGet(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	path := t.path(key)
+
+	switch obj, err := t.c.GetObject(ctx, t.bucket, path); {
+	case err == nil:
+		return t.decode(key, obj)
+	case !errors.Is(err, types.ErrNotFound):
+		return nil, keyError("Get", key, err)
+	}
+
+	out, err := t.c.ListObjectsV2(ctx, &ListInput{
+		Bucket:  t.bucket,
+		Prefix:  path + Sep,
+		MaxKeys: 1,
+	})
+	if err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	if len(out.Keys) == 0 {
+		return nil, keyError("Get", key, types.ErrNotFound)
+	}
+
+	return t.child(key), nil
+}
+
+func (t *Tree) List(ctx context.Context) []string {
+	keys, _ := t.SafeList(ctx)
+	return keys
+}
+
+func (t *Tree) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	var (
+		prefix = t.path("")
+		seen   = make(map[string]struct{})
+		keys   []string
+	)
+
+	err := t.list(ctx, prefix, Sep, func(out *ListOutput) {
+		for _, k := range append(out.Keys, out.CommonPrefixes...) {
+			k = strings.TrimSuffix(strings.TrimPrefix(k, prefix), Sep)
+
+			if _, ok := seen[k]; ok || k == "" {
+				continue
+			}
+
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	})
+
+	if err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (t *Tree) Del(ctx context.Context, key string) bool {
+	return t.SafeDel(ctx, key) == nil
+}
+
+func (t *Tree) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := t.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (t *Tree) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := t.SafePut(ctx, key, hint)
+	return w
+}
+
+// SafeDel deletes the object under the key and all the objects under
+// the key prefix.
+func (t *Tree) SafeDel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return keyError("Del", key, err)
+	}
+
+	n, err := t.del(ctx, t.path(key))
+	if err != nil {
+		return keyError("Del", key, err)
+	}
+
+	if n == 0 {
+		return keyError("Del", key, types.ErrNotFound)
+	}
+
+	return nil
+}
+
+// SafeSet replaces the value under the key. Leaves are stored as
+// objects, Readers as objects under the key prefix.
+func (t *Tree) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	if _, ok := value.(types.Reader); !ok {
+		if _, err := object(value); err != nil {
+			return false, keyError("Set", key, err)
+		}
+	}
+
+	n, err := t.del(ctx, t.path(key))
+	if err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	if err := t.put(ctx, t.path(key), value); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	return n != 0, nil
+}
+
+// SafePut gives the tree under the key prefix, as S3 has no
+// directories to create.
+func (t *Tree) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	return t.child(key), nil
+}
+
+func (t *Tree) Ping(ctx context.Context) error {
+	return types.Ping(ctx, t.c)
+}
+
+func (t *Tree) Close(ctx context.Context) error {
+	return types.Close(ctx, t.c)
+}
+
+func (t *Tree) Unwrap() any {
+	return t.c
+}
+
+func (t *Tree) put(ctx context.Context, path string, value any) error {
+	r, ok := value.(types.Reader)
+	if !ok {
+		obj, err := object(value)
+		if err != nil {
+			return err
+		}
+
+		return t.c.PutObject(ctx, t.bucket, path, obj)
+	}
+
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		v, err := types.PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		if err := t.put(ctx, path+Sep+k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// del deletes the object under the path and the objects under
+// the path prefix, giving the number of deleted objects.
+func (t *Tree) del(ctx context.Context, path string) (int, error) {
+	var keys []string
+
+	err := t.list(ctx, path, "", func(out *ListOutput) {
+		for _, k := range out.Keys {
+			if k == path || strings.HasPrefix(k, path+Sep) {
+				keys = append(keys, k)
+			}
+		}
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		if err := t.c.DeleteObject(ctx, t.bucket, k); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+func (t *Tree) list(ctx context.Context, prefix, delim string, fn func(*ListOutput)) error {
+	in := &ListInput{
+		Bucket:    t.bucket,
+		Prefix:    prefix,
+		Delimiter: delim,
+	}
+
+	for {
+		out, err := t.c.ListObjectsV2(ctx, in)
+		if err != nil {
+			return err
+		}
+
+		fn(out)
+
+		if out.NextContinuationToken == "" {
+			return nil
+		}
+
+		in.ContinuationToken = out.NextContinuationToken
+	}
+}
+
+func (t *Tree) decode(key string, obj *Object) (any, error) {
+	typ, _, _ := mime.ParseMediaType(obj.ContentType)
+
+	c, ok := t.opts.Codecs[typ]
+	if !ok {
+		return obj.Body, nil
+	}
+
+	var v any
+
+	if err := c.Unmarshal(obj.Body, &v); err != nil {
+		return nil, &types.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Got: obj.ContentType,
+			Err: err,
+		}
+	}
+
+	if r := types.Make(v); r != nil {
+		return r, nil
+	}
+
+	return v, nil
+}
+
+func (t *Tree) child(key string) *Tree {
+	return &Tree{
+		c:      t.c,
+		bucket: t.bucket,
+		prefix: t.prefix,
+		opts:   t.opts,
+		key:    append(t.key.Copy(), key),
+	}
+}
+
+func (t *Tree) path(key string) string {
+	if len(t.key) == 0 {
+		return t.prefix + key
+	}
+	return t.prefix + strings.Join(t.key, Sep) + Sep + key
+}
+
+func object(value any) (*Object, error) {
+	switch v := value.(type) {
+	case []byte:
+		return &Object{Body: v, ContentType: "application/octet-stream"}, nil
+	case string:
+		return &Object{Body: []byte(v), ContentType: "text/plain; charset=utf-8"}, nil
+	default:
+		return nil, &types.Error{
+			Op:   "Set",
+			Got:  value,
+			Want: []byte(nil),
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+}
+
+func keyError(op, key string, err error) error {
+	return &types.Error{
+		Op:  op,
+		Key: []string{key},
+		Err: err,
+	}
+}
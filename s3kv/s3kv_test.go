@@ -0,0 +1,145 @@
+package s3kv_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/s3kv"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// client is an in-memory bucket, which lists two keys per page.
+type client struct {
+	objs map[string]*s3kv.Object
+}
+
+func (c *client) GetObject(_ context.Context, _, key string) (*s3kv.Object, error) {
+	obj, ok := c.objs[key]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return obj, nil
+}
+
+func (c *client) PutObject(_ context.Context, _, key string, obj *s3kv.Object) error {
+	c.objs[key] = obj
+	return nil
+}
+
+func (c *client) DeleteObject(_ context.Context, _, key string) error {
+	delete(c.objs, key)
+	return nil
+}
+
+func (c *client) ListObjectsV2(_ context.Context, in *s3kv.ListInput) (*s3kv.ListOutput, error) {
+	var (
+		out  = &s3kv.ListOutput{}
+		seen = make(map[string]bool)
+		all  []string
+	)
+
+	for k := range c.objs {
+		if strings.HasPrefix(k, in.Prefix) {
+			all = append(all, k)
+		}
+	}
+
+	sort.Strings(all)
+
+	start, _ := strconv.Atoi(in.ContinuationToken)
+
+	max := 2
+	if in.MaxKeys != 0 {
+		max = in.MaxKeys
+	}
+
+	for i := start; i < len(all); i++ {
+		if i-start == max {
+			out.NextContinuationToken = strconv.Itoa(i)
+			break
+		}
+
+		k := all[i]
+
+		if in.Delimiter != "" {
+			if j := strings.Index(k[len(in.Prefix):], in.Delimiter); j != -1 {
+				p := k[:len(in.Prefix)+j+1]
+				if !seen[p] {
+					seen[p] = true
+					out.CommonPrefixes = append(out.CommonPrefixes, p)
+				}
+				continue
+			}
+		}
+
+		out.Keys = append(out.Keys, k)
+	}
+
+	return out, nil
+}
+
+func TestTree(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = &client{objs: map[string]*s3kv.Object{
+			"app/db.json":         {Body: []byte(`{"host":"localhost"}`), ContentType: "application/json; charset=utf-8"},
+			"app/certs/a.pem":     {Body: []byte("a"), ContentType: "application/x-pem-file"},
+			"app/certs/b.pem":     {Body: []byte("b")},
+			"app/certs/old/c.pem": {Body: []byte("c")},
+			"other/key":           {Body: []byte("other")},
+		}}
+		tree = s3kv.New(c, "bucket", "app/", nil)
+	)
+
+	host, err := objects.Get(ctx, tree, "db.json", "host")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if host != "localhost" {
+		t.Fatalf("got %v, want localhost", host)
+	}
+
+	keys, err := objects.List(ctx, tree, "certs")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"a.pem", "b.pem", "old"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	ok, err := objects.Set(ctx, tree, types.Map{"new.pem": []byte("n")}, "certs")
+	if err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if !ok {
+		t.Fatal("want previous value")
+	}
+
+	var got []string
+
+	for k := range c.objs {
+		got = append(got, k)
+	}
+
+	sort.Strings(got)
+
+	if diff := cmp.Diff([]string{"app/certs/new.pem", "app/db.json", "other/key"}, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTreeConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(t *testing.T) types.Interface {
+		return s3kv.New(&client{objs: make(map[string]*s3kv.Object)}, "bucket", "prefix/", nil)
+	})
+}
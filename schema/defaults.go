@@ -0,0 +1,242 @@
+package schema
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Value parses the default value of the field into its type.
+func (f Field) Value() (any, error) {
+	t := indirect(f.Type)
+
+	if reflect.PtrTo(t).Implements(textUnmarshaler) {
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(f.Default)); err != nil {
+			return nil, f.error(err)
+		}
+		return v.Elem().Interface(), nil
+	}
+
+	var (
+		v   = reflect.New(t).Elem()
+		err error
+	)
+
+	switch {
+	case t == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(f.Default)
+		v.SetInt(int64(d))
+	case t.Kind() == reflect.String:
+		v.SetString(f.Default)
+	case t.Kind() == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(f.Default)
+		v.SetBool(b)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(f.Default, 0, t.Bits())
+		v.SetInt(n)
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		var n uint64
+		n, err = strconv.ParseUint(f.Default, 0, t.Bits())
+		v.SetUint(n)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(f.Default, t.Bits())
+		v.SetFloat(n)
+	default:
+		err = types.ErrUnexpectedType
+	}
+
+	if err != nil {
+		return nil, f.error(err)
+	}
+
+	return v.Interface(), nil
+}
+
+func (f Field) error(err error) error {
+	return &types.Error{
+		Op:   "Default",
+		Key:  f.Key,
+		Got:  f.Default,
+		Want: f.Type.String(),
+		Err:  err,
+	}
+}
+
+// ApplyDefaults writes default values of the schema fields, which
+// are missing in w. Fields under the "*" key are skipped, as they
+// describe elements of a collection.
+func ApplyDefaults(ctx context.Context, w types.Writer, s Schema) error {
+	var errs types.Errors
+
+	for _, f := range s {
+		if f.Default == "" || wildcard(f.Key) {
+			continue
+		}
+
+		var (
+			parent, key = f.Key[:len(f.Key)-1], f.Key[len(f.Key)-1]
+			pw          = types.PrefixedWriter{W: w}
+		)
+
+		if r, ok := w.(types.Reader); ok {
+			if _, err := types.PrefixReader(r, parent...).SafeGet(ctx, key); err == nil {
+				continue
+			}
+		}
+
+		v, err := f.Value()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if len(parent) != 0 {
+			pw.Key = parent[:len(parent)-1]
+
+			if pw.W, err = pw.SafePut(ctx, parent[len(parent)-1], types.TypeMap); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			pw.Key = nil
+		}
+
+		if _, err := pw.SafeSet(ctx, key, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.Err()
+}
+
+// Defaulted is a reader, which serves default values of the schema
+// fields for keys missing in the underlying reader.
+type Defaulted struct {
+	r types.Reader
+	s Schema
+}
+
+var (
+	_ types.Reader     = (*Defaulted)(nil)
+	_ types.SafeReader = (*Defaulted)(nil)
+	_ types.SafeLister = (*Defaulted)(nil)
+)
+
+func Defaults(r types.Reader, s Schema) *Defaulted {
+	return &Defaulted{
+		r: r,
+		s: s,
+	}
+}
+
+func (d *Defaulted) Type() types.Type {
+	return d.r.Type()
+}
+
+func (d *Defaulted) Get(ctx context.Context, key string) (any, bool) {
+	v, err := d.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (d *Defaulted) SafeGet(ctx context.Context, key string) (any, error) {
+	var (
+		sub    = d.s.sub(key)
+		v, err = types.PrefixedReader{R: d.r}.SafeGet(ctx, key)
+	)
+
+	if err != nil && errors.Is(err, types.ErrNotFound) {
+		if f, ok := d.s.Lookup(types.Key{key}); ok && f.Default != "" {
+			return f.Value()
+		}
+
+		if len(sub) != 0 {
+			v, err = make(types.Map), nil
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(types.Reader); ok && len(sub) != 0 {
+		return &Defaulted{r: r, s: sub}, nil
+	}
+
+	return v, nil
+}
+
+func (d *Defaulted) List(ctx context.Context) []string {
+	keys, _ := d.SafeList(ctx)
+	return keys
+}
+
+func (d *Defaulted) SafeList(ctx context.Context) ([]string, error) {
+	keys, err := types.List(ctx, d.r)
+	if err != nil {
+		return nil, err
+	}
+
+	var seen = make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		seen[k] = struct{}{}
+	}
+
+	for _, f := range d.s {
+		k := f.Key[0]
+
+		if _, ok := seen[k]; ok || k == "*" || wildcard(f.Key) || f.Default == "" {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (d *Defaulted) Unwrap() any {
+	return d.r
+}
+
+// sub gives fields with default values under the key, which
+// are relative to it.
+func (s Schema) sub(key string) Schema {
+	var sub Schema
+
+	for _, f := range s {
+		if len(f.Key) < 2 || f.Default == "" || (f.Key[0] != key && f.Key[0] != "*") {
+			continue
+		}
+
+		f.Key = f.Key[1:]
+		sub = append(sub, f)
+	}
+
+	return sub
+}
+
+func wildcard(key types.Key) bool {
+	for _, k := range key {
+		if k == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/schema"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{
+				"port": 9090,
+			},
+		}
+		want = types.Map{
+			"server": types.Map{
+				"host":    "localhost",
+				"port":    9090,
+				"timeout": 30 * time.Second,
+			},
+		}
+	)
+
+	if err := schema.ApplyDefaults(ctx, m, schema.Of(Config{})); err != nil {
+		t.Fatalf("ApplyDefaults()=%+v", err)
+	}
+
+	if !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = schema.Defaults(types.Map{
+			"backends": &types.Slice{
+				types.Map{"url": "http://a"},
+				types.Map{"url": "http://b", "weight": 2},
+			},
+		}, schema.Of(Config{}))
+	)
+
+	if got, want := d.List(ctx), []string{"backends", "server"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	cases := []struct {
+		key  types.Key
+		want any
+	}{
+		{types.Key{"server", "host"}, "localhost"},
+		{types.Key{"server", "port"}, 8080},
+		{types.Key{"server", "timeout"}, 30 * time.Second},
+		{types.Key{"backends", "0", "weight"}, 1},
+		{types.Key{"backends", "1", "weight"}, 2},
+	}
+
+	for _, cas := range cases {
+		t.Run(cas.key.String(), func(t *testing.T) {
+			n := len(cas.key) - 1
+
+			got, err := types.PrefixReader(d, cas.key[:n]...).SafeGet(ctx, cas.key[n])
+			if err != nil {
+				t.Fatalf("SafeGet()=%+v", err)
+			}
+
+			if got != cas.want {
+				t.Fatalf("got %#v, want %#v", got, cas.want)
+			}
+		})
+	}
+
+	if _, ok := types.PrefixReader(d, "backends", "0").Get(ctx, "missing"); ok {
+		t.Fatal("expected missing key to not be found")
+	}
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+func Markdown(w io.Writer, s Schema) error {
+	var sb strings.Builder
+
+	sb.WriteString("| Key | Type | Default | Description |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, f := range s {
+		fmt.Fprintf(&sb, "| `%s` | `%s` | %s | %s |\n",
+			f.Key,
+			f.TypeName(),
+			cell(code(f.Default)),
+			cell(f.Description),
+		)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var htmlDoc = template.Must(template.New("").Parse(`<table>
+<thead>
+<tr><th>Key</th><th>Type</th><th>Default</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr><td><code>{{.Key}}</code></td><td><code>{{.TypeName}}</code></td><td>{{with .Default}}<code>{{.}}</code>{{end}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+`))
+
+func HTML(w io.Writer, s Schema) error {
+	return htmlDoc.Execute(w, s)
+}
+
+// cell escapes pipes and newlines, which would end the table cell
+// or row.
+func cell(s string) string {
+	return cellReplacer.Replace(s)
+}
+
+var cellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
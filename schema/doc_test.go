@@ -0,0 +1,87 @@
+package schema_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/schema"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Config struct {
+	Server struct {
+		Host    string        `json:"host" default:"localhost" desc:"Address to listen on"`
+		Port    int           `json:"port" default:"8080" desc:"Port to listen on"`
+		Timeout time.Duration `json:"timeout" default:"30s"`
+	} `json:"server" desc:"HTTP server"`
+	Backends []struct {
+		URL    string `json:"url" desc:"Backend URL"`
+		Weight int    `json:"weight" default:"1"`
+	} `json:"backends"`
+	Labels map[string]string `json:"labels" desc:"Arbitrary labels, e.g. env|region"`
+	Debug  bool              `json:"-"`
+}
+
+func TestMarkdown(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		golden = filepath.Join("testdata", "config.md.golden")
+	)
+
+	if err := schema.Markdown(&buf, schema.Of(Config{})); err != nil {
+		t.Fatalf("Markdown()=%+v", err)
+	}
+
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("WriteFile()=%+v", err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("ReadFile()=%+v", err)
+	}
+
+	if got := buf.String(); !cmp.Equal(got, string(want)) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, string(want)))
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := schema.HTML(&buf, schema.Of(&Config{})); err != nil {
+		t.Fatalf("HTML()=%+v", err)
+	}
+
+	want := "<tr><td><code>server.port</code></td><td><code>int</code></td><td><code>8080</code></td><td>Port to listen on</td></tr>"
+
+	if !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Fatalf("%q not found in:\n%s", want, buf.String())
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		v   struct {
+			Sep string `json:"sep" default:"|" desc:"Separator of joined labels.\nIt must be a single character."`
+		}
+		want = "| Key | Type | Default | Description |\n" +
+			"| --- | --- | --- | --- |\n" +
+			"| `sep` | `string` | `\\|` | Separator of joined labels.<br>It must be a single character. |\n"
+	)
+
+	if err := schema.Markdown(&buf, schema.Of(v)); err != nil {
+		t.Fatalf("Markdown()=%+v", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
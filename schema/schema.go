@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"encoding"
+	"reflect"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/internal/misc"
+	"rafal.dev/objects/types"
+)
+
+type Field struct {
+	Key         types.Key
+	Type        reflect.Type
+	Default     string
+	Description string
+}
+
+// Schema describes keys of a configuration tree.
+type Schema []Field
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Of builds a Schema from the struct v, which field names follow
+// objects.DefaultOptions. The `default` and `desc` struct tags
+// provide a default value and a description of a field.
+// Elements of slices and maps are described under the "*" key.
+// A field of a recursive type is described once, as a leaf.
+func Of(v any) Schema {
+	var s Schema
+	s.walk(misc.TypeOf(v, true), nil, make(map[reflect.Type]bool))
+	return s
+}
+
+// walk describes the fields of t under the key; path holds the struct
+// types being walked, which are not descended into again.
+func (s *Schema) walk(t reflect.Type, key types.Key, path map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case isLeaf(t):
+	case t.Kind() == reflect.Struct:
+		if path[t] {
+			return
+		}
+
+		path[t] = true
+		defer delete(path, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			var (
+				f    = t.Field(i)
+				name = objects.DefaultOptions.StructField(f)
+			)
+
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+
+			if f.Anonymous && name == f.Name && indirect(f.Type).Kind() == reflect.Struct {
+				s.walk(f.Type, key, path)
+				continue
+			}
+
+			k := append(key.Copy(), name)
+
+			*s = append(*s, Field{
+				Key:         k,
+				Type:        f.Type,
+				Default:     f.Tag.Get("default"),
+				Description: f.Tag.Get("desc"),
+			})
+
+			s.walk(f.Type, k, path)
+		}
+	case t.Kind() == reflect.Slice, t.Kind() == reflect.Array, t.Kind() == reflect.Map:
+		if !isLeaf(t.Elem()) {
+			s.walk(t.Elem(), append(key.Copy(), "*"), path)
+		}
+	}
+}
+
+// TypeName gives a readable name of the field type, where
+// anonymous structs are called objects.
+func (f Field) TypeName() string {
+	return typeName(f.Type)
+}
+
+func (s Schema) Lookup(key types.Key) (Field, bool) {
+	for _, f := range s {
+		if f.Key.Equal(key) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+func isLeaf(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return true
+	}
+
+	switch t = indirect(t); t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return false
+	case reflect.Slice, reflect.Array:
+		return isLeaf(t.Elem())
+	default:
+		return true
+	}
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "object"
+		}
+	}
+	return t.String()
+}
@@ -0,0 +1,52 @@
+package schema_test
+
+import (
+	"testing"
+
+	"rafal.dev/objects/schema"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Node struct {
+	Name     string  `json:"name"`
+	Next     *Node   `json:"next"`
+	Children []Node  `json:"children"`
+	Dotted   string  `json:"a.b"`
+	Nested   *Nested `json:"a"`
+}
+
+type Nested struct {
+	B string `json:"b"`
+}
+
+func TestOfRecursive(t *testing.T) {
+	var got []types.Key
+
+	for _, f := range schema.Of(Node{}) {
+		got = append(got, f.Key)
+	}
+
+	want := []types.Key{
+		{"name"},
+		{"next"},
+		{"children"},
+		{"a.b"},
+		{"a"},
+		{"a", "b"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	f, ok := schema.Of(Node{}).Lookup(types.Key{"a", "b"})
+	if !ok {
+		t.Fatal("Lookup() failed")
+	}
+
+	if !f.Key.Equal(types.Key{"a", "b"}) {
+		t.Fatalf("got %q, want %q", f.Key, types.Key{"a", "b"})
+	}
+}
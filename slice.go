@@ -15,7 +15,10 @@ type Slice struct {
 var (
 	_ Reader     = (*Slice)(nil)
 	_ SafeReader = (*Slice)(nil)
+	_ SafeLister = (*Slice)(nil)
 	_ ListerTo   = (*Slice)(nil)
+	_ Writer     = (*Slice)(nil)
+	_ SafeWriter = (*Slice)(nil)
 )
 
 func (s *Slice) Type() Type {
@@ -63,8 +66,186 @@ func (s *Slice) List(ctx context.Context) []string {
 	return keys
 }
 
+func (s *Slice) SafeList(ctx context.Context) ([]string, error) {
+	return s.List(ctx), nil
+}
+
 func (s *Slice) ListTo(ctx context.Context, keys *[]string) {
 	for i := 0; i < s.v.Len(); i++ {
 		*keys = append(*keys, strconv.Itoa(i))
 	}
 }
+
+func (s *Slice) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Slice) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Slice) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *Slice) SafeDel(ctx context.Context, key string) error {
+	n, err := s.index("Del", key)
+	if err != nil {
+		return err
+	}
+
+	if n >= s.v.Len() {
+		return &Error{
+			Op:   "Del",
+			Key:  []string{key},
+			Got:  n,
+			Want: s.v.Len(),
+			Err:  ErrOutOfBounds,
+		}
+	}
+
+	if err := s.settable("Del", key); err != nil {
+		return err
+	}
+
+	reflect.Copy(s.v.Slice(n, s.v.Len()), s.v.Slice(n+1, s.v.Len()))
+	s.v.Index(s.v.Len() - 1).Set(reflect.Zero(s.v.Type().Elem()))
+	s.v.SetLen(s.v.Len() - 1)
+
+	return nil
+}
+
+func (s *Slice) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	n, err := s.index("Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := convert(ctx, value, s.v.Type().Elem(), "Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	ok := n < s.v.Len()
+
+	if err := s.grow("Set", key, n); err != nil {
+		return false, err
+	}
+
+	if !s.v.Index(n).CanSet() {
+		return false, &Error{
+			Op:  "Set",
+			Key: []string{key},
+			Err: errors.New("cannot set unaddressable value"),
+		}
+	}
+
+	s.v.Index(n).Set(v)
+
+	return ok, nil
+}
+
+func (s *Slice) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	n, err := s.index("Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < s.v.Len() {
+		if v := s.v.Index(n); !v.IsZero() && v.CanInterface() {
+			switch w := tryMake(v.Interface()).(type) {
+			case *Slice:
+				return &Slice{v: v}, nil
+			case Writer:
+				return w, nil
+			}
+		}
+	}
+
+	v, w, err := makeElem(s.v.Type().Elem(), hint, "Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.grow("Put", key, n); err != nil {
+		return nil, err
+	}
+
+	if !s.v.Index(n).CanSet() {
+		return nil, &Error{
+			Op:  "Put",
+			Key: []string{key},
+			Err: errors.New("cannot set unaddressable value"),
+		}
+	}
+
+	s.v.Index(n).Set(v)
+
+	switch {
+	case w != nil:
+		return w, nil
+	case v.Kind() == reflect.Map:
+		return &Map{v: v}, nil
+	default:
+		return &Slice{v: s.v.Index(n)}, nil
+	}
+}
+
+// index parses key into an index, where "-" stands for the index
+// past the last element. Indexes further past the end are out of
+// bounds, so a key can't grow the slice arbitrarily.
+func (s *Slice) index(op, key string) (int, error) {
+	if key == "-" {
+		return s.v.Len(), nil
+	}
+
+	n, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, &Error{
+			Op:  op,
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	if n < 0 || n > s.v.Len() {
+		return 0, &Error{
+			Op:   op,
+			Key:  []string{key},
+			Got:  n,
+			Want: s.v.Len(),
+			Err:  ErrOutOfBounds,
+		}
+	}
+
+	return n, nil
+}
+
+// grow appends an element for the index n past the last element.
+func (s *Slice) grow(op, key string, n int) error {
+	if n < s.v.Len() {
+		return nil
+	}
+
+	if err := s.settable(op, key); err != nil {
+		return err
+	}
+
+	s.v.Set(reflect.Append(s.v, reflect.Zero(s.v.Type().Elem())))
+
+	return nil
+}
+
+func (s *Slice) settable(op, key string) error {
+	if s.v.Kind() != reflect.Slice || !s.v.CanSet() {
+		return &Error{
+			Op:  op,
+			Key: []string{key},
+			Got: s.v.Type().String(),
+			Err: errors.New("cannot resize value"),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSliceWriter(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = []int{1, 2, 3}
+		w   = objects.Make(&s).(objects.Writer)
+	)
+
+	if _, err := objects.Set(ctx, w, 4, "-"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, w, "20", "1"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, w, "0"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if want := []int{20, 3, 4}; !cmp.Equal(s, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(s, want))
+	}
+}
+
+func TestSliceWriterBounds(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = []int{1}
+		w   = objects.Make(&s).(objects.Writer)
+	)
+
+	if _, err := objects.Set(ctx, w, 2, "1"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	for _, key := range []string{"3", "9223372036854775807"} {
+		if _, err := objects.Set(ctx, w, 3, key); !errors.Is(err, objects.ErrOutOfBounds) {
+			t.Fatalf("Set(%q)=%+v, want %v", key, err, objects.ErrOutOfBounds)
+		}
+
+		if _, err := objects.Put(ctx, w, objects.TypeMap, key); !errors.Is(err, objects.ErrOutOfBounds) {
+			t.Fatalf("Put(%q)=%+v, want %v", key, err, objects.ErrOutOfBounds)
+		}
+	}
+
+	if want := []int{1, 2}; !cmp.Equal(s, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(s, want))
+	}
+}
+
+func TestSliceWriterNested(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   [][]string
+		w   = objects.Make(&s).(objects.Writer)
+	)
+
+	sub, err := objects.Put(ctx, w, objects.TypeSlice, "-")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	sub.Set(ctx, "-", "a")
+	sub.Set(ctx, "-", "b")
+
+	if want := [][]string{{"a", "b"}}; !cmp.Equal(s, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(s, want))
+	}
+
+	m := types.Map{"list": &types.Slice{1}}
+
+	if _, err := objects.Set(ctx, m, 2, "list", "-"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if want := (types.Map{"list": &types.Slice{1, 2}}); !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+}
@@ -0,0 +1,584 @@
+package sqlkv
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+const Sep = "/"
+
+// Options configures the table, which stores the tree.
+type Options struct {
+	// Table is the name of the table, it is not quoted.
+	Table string
+
+	// Placeholder gives the bind parameter for the n-th argument of
+	// a query, counting from 1, e.g. Dollar for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+var DefaultOptions = &Options{
+	Table:       "objects",
+	Placeholder: Question,
+}
+
+// Question gives ? placeholders, e.g. for SQLite and MySQL.
+func Question(int) string {
+	return "?"
+}
+
+// Dollar gives $n placeholders, e.g. for PostgreSQL.
+func Dollar(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Tree is a tree persisted in a (path, value, type) table, where path
+// is the key with its segments joined by Sep, with each "%" and Sep
+// within the segments percent-encoded. Maps and slices are stored as
+// rows with no value, so that empty ones are kept as well.
+//
+// Subtrees are read with range queries over the path column, which
+// must compare bytewise, e.g. with COLLATE "C" on PostgreSQL.
+type Tree struct {
+	db   *sql.DB
+	opts *Options
+	key  types.Key
+	typ  types.Type
+}
+
+var (
+	_ types.Interface  = (*Tree)(nil)
+	_ types.SafeReader = (*Tree)(nil)
+	_ types.SafeLister = (*Tree)(nil)
+	_ types.SafeWriter = (*Tree)(nil)
+)
+
+// New gives a tree stored in the table of the db, which is expected
+// to exist, e.g. created with Init.
+func New(db *sql.DB, opts *Options) *Tree {
+	return &Tree{
+		db:   db,
+		opts: opts,
+	}
+}
+
+// Init creates the table, if it does not exist yet.
+func Init(ctx context.Context, db *sql.DB, opts *Options) error {
+	t := New(db, opts)
+
+	if _, err := db.ExecContext(ctx, t.query("CREATE TABLE IF NOT EXISTS %s (path TEXT PRIMARY KEY, value TEXT, type TEXT NOT NULL)")); err != nil {
+		return &types.Error{
+			Op:  "Init",
+			Got: t.options().Table,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) Type() types.Type {
+	if t.typ == "" {
+		return types.TypeMap
+	}
+	return t.typ
+}
+
+func (t *Tree) Get(ctx context.Context, key string) (any, bool) {
+	v, err := t.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (t *Tree) SafeGet(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	var (
+		path = t.path(key)
+		row  = t.db.QueryRowContext(ctx, t.query("SELECT value, type FROM %s WHERE path = ?"), path)
+		val  sql.NullString
+		typ  string
+	)
+
+	switch err := row.Scan(&val, &typ); {
+	case err == nil:
+		v, err := t.decode(key, val.String, typ)
+		if err != nil {
+			return nil, keyError("Get", key, err)
+		}
+
+		return v, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, keyError("Get", key, err)
+	}
+
+	var n int
+
+	row = t.db.QueryRowContext(ctx, t.query("SELECT COUNT(*) FROM %s WHERE path > ? AND path < ?"), bounds(path)...)
+
+	if err := row.Scan(&n); err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	if n == 0 {
+		return nil, keyError("Get", key, types.ErrNotFound)
+	}
+
+	return t.child(key, types.TypeMap), nil
+}
+
+func (t *Tree) List(ctx context.Context) []string {
+	keys, _ := t.SafeList(ctx)
+	return keys
+}
+
+func (t *Tree) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	var (
+		rows *sql.Rows
+		err  error
+	)
+
+	if len(t.key) == 0 {
+		rows, err = t.db.QueryContext(ctx, t.query("SELECT path FROM %s"))
+	} else {
+		rows, err = t.db.QueryContext(ctx, t.query("SELECT path FROM %s WHERE path > ? AND path < ?"), bounds(encode(t.key))...)
+	}
+
+	if err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+	defer rows.Close()
+
+	var (
+		prefix = t.prefix()
+		seen   = make(map[string]struct{})
+		keys   []string
+	)
+
+	for rows.Next() {
+		var path string
+
+		if err := rows.Scan(&path); err != nil {
+			return nil, &types.Error{
+				Op:  "List",
+				Err: err,
+			}
+		}
+
+		k := strings.TrimPrefix(path, prefix)
+
+		if i := strings.Index(k, Sep); i != -1 {
+			k = k[:i]
+		}
+
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		keys = append(keys, unescape(k))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	if t.Type() == types.TypeSlice {
+		sort.Slice(keys, func(i, j int) bool {
+			m, _ := strconv.Atoi(keys[i])
+			n, _ := strconv.Atoi(keys[j])
+			return m < n
+		})
+	} else {
+		sort.Strings(keys)
+	}
+
+	return keys, nil
+}
+
+func (t *Tree) Del(ctx context.Context, key string) bool {
+	return t.SafeDel(ctx, key) == nil
+}
+
+func (t *Tree) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := t.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (t *Tree) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := t.SafePut(ctx, key, hint)
+	return w
+}
+
+func (t *Tree) SafeDel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return keyError("Del", key, err)
+	}
+
+	n, err := t.del(ctx, t.db, t.path(key))
+	if err != nil {
+		return keyError("Del", key, err)
+	}
+
+	if n == 0 {
+		return keyError("Del", key, types.ErrNotFound)
+	}
+
+	return nil
+}
+
+// SafeSet replaces the value under the key. Readers are stored
+// recursively, in a single transaction.
+func (t *Tree) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	var previous bool
+
+	err := t.tx(ctx, func(tx *sql.Tx) error {
+		if err := t.ancestors(ctx, tx); err != nil {
+			return err
+		}
+
+		n, err := t.del(ctx, tx, t.path(key))
+		if err != nil {
+			return err
+		}
+
+		previous = n != 0
+
+		return t.insert(ctx, tx, t.path(key), value)
+	})
+
+	if err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	return previous, nil
+}
+
+// SafePut creates the map or slice under the key, together with any
+// missing parents, in a single transaction. An existing map or slice
+// is kept, while a leaf is replaced.
+func (t *Tree) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	if hint != types.TypeSlice {
+		hint = types.TypeMap
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	var typ types.Type
+
+	err := t.tx(ctx, func(tx *sql.Tx) (err error) {
+		if err := t.ancestors(ctx, tx); err != nil {
+			return err
+		}
+
+		typ, err = t.node(ctx, tx, t.path(key), hint)
+		return err
+	})
+
+	if err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	return t.child(key, typ), nil
+}
+
+func (t *Tree) Ping(ctx context.Context) error {
+	return t.db.PingContext(ctx)
+}
+
+func (t *Tree) Close(ctx context.Context) error {
+	return types.Close(ctx, t.db)
+}
+
+func (t *Tree) Unwrap() any {
+	return t.db
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (t *Tree) tx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ancestors creates the missing maps on the path of the tree.
+func (t *Tree) ancestors(ctx context.Context, tx *sql.Tx) error {
+	for i := range t.key {
+		typ := types.TypeMap
+
+		if i == len(t.key)-1 {
+			typ = t.Type()
+		}
+
+		if _, err := t.node(ctx, tx, encode(t.key[:i+1]), typ); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// node ensures the path is a map or a slice and gives its type.
+func (t *Tree) node(ctx context.Context, tx *sql.Tx, path string, hint types.Type) (types.Type, error) {
+	var typ string
+
+	switch err := tx.QueryRowContext(ctx, t.query("SELECT type FROM %s WHERE path = ?"), path).Scan(&typ); {
+	case err == nil:
+		if typ == string(types.TypeMap) || typ == string(types.TypeSlice) {
+			return types.Type(typ), nil
+		}
+
+		if _, err := t.del(ctx, tx, path); err != nil {
+			return "", err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", err
+	}
+
+	_, err := tx.ExecContext(ctx, t.query("INSERT INTO %s (path, value, type) VALUES (?, ?, ?)"), path, nil, string(hint))
+
+	return hint, err
+}
+
+func (t *Tree) insert(ctx context.Context, tx *sql.Tx, path string, value any) error {
+	r, ok := value.(types.Reader)
+	if !ok {
+		s, typ, err := encodeValue(value)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, t.query("INSERT INTO %s (path, value, type) VALUES (?, ?, ?)"), path, s, typ)
+		return err
+	}
+
+	typ := types.TypeMap
+
+	if r.Type() == types.TypeSlice {
+		typ = types.TypeSlice
+	}
+
+	if _, err := tx.ExecContext(ctx, t.query("INSERT INTO %s (path, value, type) VALUES (?, ?, ?)"), path, nil, string(typ)); err != nil {
+		return err
+	}
+
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		v, err := types.PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		if err := t.insert(ctx, tx, path+Sep+escape(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) del(ctx context.Context, e execer, path string) (int64, error) {
+	args := append([]any{path}, bounds(path)...)
+
+	res, err := e.ExecContext(ctx, t.query("DELETE FROM %s WHERE path = ? OR (path > ? AND path < ?)"), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (t *Tree) decode(key, s, typ string) (any, error) {
+	switch typ {
+	case string(types.TypeMap), string(types.TypeSlice):
+		return t.child(key, types.Type(typ)), nil
+	case "string":
+		return s, nil
+	case "bytes":
+		return base64.StdEncoding.DecodeString(s)
+	case "bool":
+		return strconv.ParseBool(s)
+	case "int":
+		return strconv.ParseInt(s, 10, 64)
+	case "uint":
+		return strconv.ParseUint(s, 10, 64)
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "time":
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		return nil, &types.Error{
+			Op:  "Decode",
+			Got: typ,
+			Err: types.ErrUnexpectedType,
+		}
+	}
+}
+
+func encodeValue(v any) (string, string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, "string", nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), "bytes", nil
+	case bool:
+		return strconv.FormatBool(v), "bool", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), "time", nil
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), "int", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), "uint", nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), "float", nil
+	}
+
+	return "", "", &types.Error{
+		Op:  "Encode",
+		Got: v,
+		Err: types.ErrUnexpectedType,
+	}
+}
+
+func (t *Tree) child(key string, typ types.Type) *Tree {
+	return &Tree{
+		db:   t.db,
+		opts: t.opts,
+		key:  append(t.key.Copy(), key),
+		typ:  typ,
+	}
+}
+
+func (t *Tree) path(key string) string {
+	return t.prefix() + escape(key)
+}
+
+func (t *Tree) prefix() string {
+	if len(t.key) == 0 {
+		return ""
+	}
+	return encode(t.key) + Sep
+}
+
+func (t *Tree) options() *Options {
+	if t.opts != nil {
+		return t.opts
+	}
+	return DefaultOptions
+}
+
+// query formats the query with the table name and replaces each ?
+// with the placeholder of the options.
+func (t *Tree) query(format string) string {
+	var (
+		opts        = t.options()
+		table       = opts.Table
+		placeholder = opts.Placeholder
+		b           strings.Builder
+		n           int
+	)
+
+	if table == "" {
+		table = DefaultOptions.Table
+	}
+
+	if placeholder == nil {
+		placeholder = Question
+	}
+
+	for _, r := range fmt.Sprintf(format, table) {
+		if r == '?' {
+			n++
+			b.WriteString(placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// bounds gives the range of paths of descendants of path, as the
+// character following Sep in the byte order is '0'.
+func bounds(path string) []any {
+	return []any{path + Sep, path + "0"}
+}
+
+var (
+	escaper   = strings.NewReplacer("%", "%25", Sep, "%2F")
+	unescaper = strings.NewReplacer("%25", "%", "%2F", Sep)
+)
+
+func encode(key types.Key) string {
+	parts := make([]string, len(key))
+
+	for i, k := range key {
+		parts[i] = escape(k)
+	}
+
+	return strings.Join(parts, Sep)
+}
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
+
+func keyError(op, key string, err error) error {
+	return &types.Error{
+		Op:  op,
+		Key: []string{key},
+		Err: err,
+	}
+}
@@ -0,0 +1,348 @@
+package sqlkv_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/sqlkv"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// table is an in-memory database/sql driver, which understands
+// the queries of the backend only.
+type table struct {
+	mu      sync.Mutex
+	rows    map[string][2]driver.Value
+	queries []string
+}
+
+var (
+	placeholder = regexp.MustCompile(`\$\d+`)
+	name        = regexp.MustCompile(`(FROM|INTO|EXISTS) \w+`)
+)
+
+func newDB(t *testing.T, opts *sqlkv.Options) (*sql.DB, *table) {
+	tb := &table{rows: make(map[string][2]driver.Value)}
+	db := sql.OpenDB(tb)
+
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqlkv.Init(context.Background(), db, opts); err != nil {
+		t.Fatalf("Init()=%+v", err)
+	}
+
+	return db, tb
+}
+
+func (tb *table) Connect(context.Context) (driver.Conn, error) { return conn{tb}, nil }
+func (tb *table) Driver() driver.Driver                        { return nil }
+
+type conn struct{ tb *table }
+
+func (c conn) Prepare(query string) (driver.Stmt, error) { return stmt{c.tb, query}, nil }
+func (c conn) Close() error                              { return nil }
+
+func (c conn) Begin() (driver.Tx, error) {
+	c.tb.mu.Lock()
+	defer c.tb.mu.Unlock()
+
+	snapshot := make(map[string][2]driver.Value, len(c.tb.rows))
+
+	for k, v := range c.tb.rows {
+		snapshot[k] = v
+	}
+
+	return tx{c.tb, snapshot}, nil
+}
+
+type tx struct {
+	tb       *table
+	snapshot map[string][2]driver.Value
+}
+
+func (tx tx) Commit() error { return nil }
+
+func (tx tx) Rollback() error {
+	tx.tb.mu.Lock()
+	tx.tb.rows = tx.snapshot
+	tx.tb.mu.Unlock()
+	return nil
+}
+
+type stmt struct {
+	tb    *table
+	query string
+}
+
+func (s stmt) Close() error  { return nil }
+func (s stmt) NumInput() int { return -1 }
+
+func (s stmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, n, err := s.tb.do(s.query, args)
+	return driver.RowsAffected(n), err
+}
+
+func (s stmt) Query(args []driver.Value) (driver.Rows, error) {
+	res, _, err := s.tb.do(s.query, args)
+	return &rows{res: res}, err
+}
+
+func (tb *table) do(query string, args []driver.Value) ([][]driver.Value, int64, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.queries = append(tb.queries, query)
+
+	q := name.ReplaceAllString(placeholder.ReplaceAllString(query, "?"), "$1 t")
+
+	str := func(i int) string { return args[i].(string) }
+
+	var res [][]driver.Value
+
+	switch {
+	case strings.HasPrefix(q, "CREATE TABLE"):
+	case q == "SELECT value, type FROM t WHERE path = ?":
+		if row, ok := tb.rows[str(0)]; ok {
+			res = append(res, row[:])
+		}
+	case q == "SELECT type FROM t WHERE path = ?":
+		if row, ok := tb.rows[str(0)]; ok {
+			res = append(res, row[1:])
+		}
+	case q == "SELECT COUNT(*) FROM t WHERE path > ? AND path < ?":
+		var n int64
+
+		for path := range tb.rows {
+			if path > str(0) && path < str(1) {
+				n++
+			}
+		}
+
+		res = append(res, []driver.Value{n})
+	case strings.HasPrefix(q, "SELECT path FROM t"):
+		for _, path := range tb.paths() {
+			if len(args) == 0 || path > str(0) && path < str(1) {
+				res = append(res, []driver.Value{path})
+			}
+		}
+	case q == "INSERT INTO t (path, value, type) VALUES (?, ?, ?)":
+		if _, ok := tb.rows[str(0)]; ok {
+			return nil, 0, fmt.Errorf("duplicate path %q", str(0))
+		}
+
+		tb.rows[str(0)] = [2]driver.Value{args[1], args[2]}
+
+		return nil, 1, nil
+	case q == "DELETE FROM t WHERE path = ? OR (path > ? AND path < ?)":
+		var n int64
+
+		for path := range tb.rows {
+			if path == str(0) || path > str(1) && path < str(2) {
+				delete(tb.rows, path)
+				n++
+			}
+		}
+
+		return nil, n, nil
+	default:
+		return nil, 0, fmt.Errorf("unexpected query: %s", query)
+	}
+
+	return res, 0, nil
+}
+
+func (tb *table) paths() []string {
+	paths := make([]string, 0, len(tb.rows))
+
+	for path := range tb.rows {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+type rows struct {
+	res [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	if len(r.res) == 0 {
+		return []string{"value", "type"}
+	}
+	return make([]string, len(r.res[0]))
+}
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.res) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.res[0])
+	r.res = r.res[1:]
+
+	return nil
+}
+
+func TestTree(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		db, _ = newDB(t, nil)
+		tree  = sqlkv.New(db, nil)
+	)
+
+	value := types.Map{
+		"name":    "app",
+		"port":    8080,
+		"debug":   true,
+		"ratio":   0.5,
+		"key":     []byte{0xde, 0xad},
+		"servers": types.Slice{"a", "b"},
+		"empty":   types.Map{},
+		"a/b":     "escaped",
+	}
+
+	if _, err := objects.Set(ctx, tree, value, "config"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	got := make(types.Map)
+
+	if err := objects.Copy(ctx, got, tree); err != nil {
+		t.Fatalf("Copy()=%+v", err)
+	}
+
+	want := types.Map{
+		"config": types.Map{
+			"name":    "app",
+			"port":    int64(8080),
+			"debug":   true,
+			"ratio":   0.5,
+			"servers": &types.Slice{"a", "b"},
+			"empty":   types.Map{},
+			"a/b":     "escaped",
+		},
+	}
+
+	key, err := objects.Get(ctx, tree, "config", "key")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]byte{0xde, 0xad}, key); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	objects.Del(ctx, got, "config", "key")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	v, err := objects.Get(ctx, tree, "config", "servers")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if typ := v.(types.Reader).Type(); typ != types.TypeSlice {
+		t.Fatalf("got %q, want %q", typ, types.TypeSlice)
+	}
+}
+
+func TestTreePut(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		db, tb = newDB(t, nil)
+		tree   = sqlkv.New(db, nil)
+	)
+
+	if _, err := objects.Set(ctx, tree, "leaf", "a"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	v, err := objects.Get(ctx, tree, "a")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if v != "leaf" {
+		t.Fatalf("got %v, want leaf", v)
+	}
+
+	w, err := tree.SafePut(ctx, "a", types.TypeMap)
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	w, err = w.(types.SafeWriter).SafePut(ctx, "b", types.TypeSlice)
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"a", "a/b"}, tb.paths()); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	// The unsupported leaf fails the Set after the other rows are inserted.
+	if _, err := objects.Set(ctx, w, types.Map{"c": "d", "e": make(chan int)}, "0"); !errors.Is(err, types.ErrUnexpectedType) {
+		t.Fatalf("Set()=%+v, want %v", err, types.ErrUnexpectedType)
+	}
+
+	if diff := cmp.Diff([]string{"a", "a/b"}, tb.paths()); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if err := objects.Del(ctx, tree, "a"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if paths := tb.paths(); len(paths) != 0 {
+		t.Fatalf("got %q, want no paths", paths)
+	}
+}
+
+func TestTreeOptions(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		opts   = &sqlkv.Options{Table: "kv", Placeholder: sqlkv.Dollar}
+		db, tb = newDB(t, opts)
+		tree   = sqlkv.New(db, opts)
+	)
+
+	if _, err := objects.Get(ctx, tree, "missing"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Get()=%+v, want %v", err, types.ErrNotFound)
+	}
+
+	want := []string{
+		"CREATE TABLE IF NOT EXISTS kv (path TEXT PRIMARY KEY, value TEXT, type TEXT NOT NULL)",
+		"SELECT value, type FROM kv WHERE path = $1",
+		"SELECT COUNT(*) FROM kv WHERE path > $1 AND path < $2",
+	}
+
+	if diff := cmp.Diff(want, tb.queries); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTreeConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(t *testing.T) types.Interface {
+		db, _ := newDB(t, nil)
+		return sqlkv.New(db, nil)
+	})
+}
@@ -0,0 +1,356 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"rafal.dev/objects/types"
+)
+
+// Staged layers uncommitted writes over an Interface, so reads
+// reflect them immediately. Commit flushes the writes and Discard
+// drops them.
+type Staged struct {
+	r   Reader
+	key Key
+	s   *stage
+}
+
+type stage struct {
+	root Interface
+	mu   sync.Mutex
+	ops  Patch
+}
+
+var (
+	_ Interface  = (*Staged)(nil)
+	_ SafeReader = (*Staged)(nil)
+	_ SafeLister = (*Staged)(nil)
+	_ SafeWriter = (*Staged)(nil)
+)
+
+func Stage(iface Interface) *Staged {
+	return &Staged{
+		r: iface,
+		s: &stage{root: iface},
+	}
+}
+
+func (s *Staged) Type() Type {
+	return s.r.Type()
+}
+
+func (s *Staged) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Staged) SafeGet(ctx context.Context, key string) (any, error) {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	return s.get(ctx, append(s.key.Copy(), key))
+}
+
+func (s *Staged) get(ctx context.Context, key Key) (any, error) {
+	v, err := s.s.resolve(ctx, key)
+	if errors.Is(err, ErrNotFound) && s.s.parent(key) {
+		v, err = make(types.Map), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Staged{r: r, key: key, s: s.s}, nil
+	}
+
+	return v, nil
+}
+
+func (s *Staged) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Staged) SafeList(ctx context.Context) ([]string, error) {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	var (
+		n    = len(s.key)
+		keys []string
+		seen = make(map[string]struct{})
+	)
+
+	if v, err := s.s.resolve(ctx, s.key); err == nil {
+		if r, ok := v.(Reader); ok {
+			if keys, err = types.List(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, op := range s.s.ops {
+		if len(op.Key) > n && op.Key.HasPrefix(s.key) {
+			keys = append(keys, op.Key[n])
+		}
+	}
+
+	var list = keys[:0]
+
+	for _, k := range keys {
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		seen[k] = struct{}{}
+
+		if _, err := s.get(ctx, append(s.key.Copy(), k)); err == nil {
+			list = append(list, k)
+		}
+	}
+
+	sort.Strings(list)
+
+	return list, nil
+}
+
+func (s *Staged) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Staged) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Staged) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *Staged) SafeDel(ctx context.Context, key string) error {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	full := append(s.key.Copy(), key)
+
+	if _, err := s.get(ctx, full); err != nil {
+		return err
+	}
+
+	s.s.ops = append(s.s.ops, PatchOp{Op: "Del", Key: full})
+
+	return nil
+}
+
+func (s *Staged) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	var (
+		full   = append(s.key.Copy(), key)
+		_, err = s.get(ctx, full)
+	)
+
+	s.s.ops = append(s.s.ops, PatchOp{Op: "Set", Key: full, Value: value})
+
+	return err == nil, nil
+}
+
+func (s *Staged) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	full := append(s.key.Copy(), key)
+
+	if v, err := s.get(ctx, full); err == nil {
+		if w, ok := v.(Writer); ok {
+			return w, nil
+		}
+	}
+
+	var r Reader = make(types.Map)
+	if hint == TypeSlice {
+		r = &types.Slice{}
+	}
+
+	s.s.ops = append(s.s.ops, PatchOp{Op: "Set", Key: full, Value: r})
+
+	return &Staged{r: r, key: full, s: s.s}, nil
+}
+
+// Pending gives a copy of the uncommitted writes.
+func (s *Staged) Pending() Patch {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	return append(Patch(nil), s.s.ops...)
+}
+
+// Commit writes the pending writes to the underlying Interface.
+// Consecutive Sets of leaf values and consecutive Dels are written
+// in batches, with types.SetMany and types.DelMany, and the writes
+// which fail in a batch are retried one by one. On error, the writes
+// that failed to apply remain pending, along with the ones after them.
+func (s *Staged) Commit(ctx context.Context) error {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	for len(s.s.ops) != 0 {
+		if !batchable(s.s.ops[0]) {
+			if err := s.s.ops[0].apply(ctx, s.s.root); err != nil {
+				return err
+			}
+
+			s.s.ops = s.s.ops[1:]
+			continue
+		}
+
+		var (
+			n      = batchLen(s.s.ops)
+			failed []PatchOp
+			errs   Errors
+		)
+
+		for i, res := range s.s.batch(ctx, s.s.ops[:n]) {
+			if res.Err == nil {
+				continue
+			}
+
+			if err := s.s.ops[i].apply(ctx, s.s.root); err != nil {
+				failed = append(failed, s.s.ops[i])
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) != 0 {
+			s.s.ops = append(failed, s.s.ops[n:]...)
+			return errs.Err()
+		}
+
+		s.s.ops = s.s.ops[n:]
+	}
+
+	s.s.ops = nil
+
+	return nil
+}
+
+// batchLen gives the number of leading ops, which can be written
+// in a single batch.
+func batchLen(ops []PatchOp) int {
+	n := 1
+	for n < len(ops) && batchable(ops[n]) && ops[n].Op == ops[0].Op {
+		n++
+	}
+
+	return n
+}
+
+// batchable reports whether op is a Del or a Set of a leaf value.
+func batchable(op PatchOp) bool {
+	if op.Op == "Del" {
+		return true
+	}
+
+	_, ok := op.Value.(Reader)
+
+	return op.Op == "Set" && !ok
+}
+
+// batch writes the ops, which are a batch as given by batchLen,
+// with a result for each of them.
+func (s *stage) batch(ctx context.Context, ops []PatchOp) []types.Result {
+	switch ops[0].Op {
+	case "Del":
+		keys := make([]Key, len(ops))
+		for i, op := range ops {
+			keys[i] = op.Key
+		}
+
+		res := types.DelMany(ctx, s.root, keys...)
+		for i := range res {
+			if errors.Is(res[i].Err, ErrNotFound) {
+				res[i].Err = nil
+			}
+		}
+
+		return res
+	default:
+		entries := make([]types.Entry, len(ops))
+		for i, op := range ops {
+			entries[i] = types.Entry{Key: op.Key, Value: op.Value}
+		}
+
+		return types.SetMany(ctx, s.root, entries...)
+	}
+}
+
+// Discard drops the pending writes.
+func (s *Staged) Discard() {
+	s.s.mu.Lock()
+	s.s.ops = nil
+	s.s.mu.Unlock()
+}
+
+func (s *Staged) Unwrap() any {
+	return s.s.root
+}
+
+func (s *stage) resolve(ctx context.Context, key Key) (any, error) {
+	for i := len(s.ops) - 1; i >= 0; i-- {
+		op := s.ops[i]
+
+		if !key.HasPrefix(op.Key) {
+			continue
+		}
+
+		if op.Op == "Del" {
+			return nil, &Error{
+				Op:  "Get",
+				Key: key,
+				Err: ErrNotFound,
+			}
+		}
+
+		if len(op.Key) == len(key) {
+			return op.Value, nil
+		}
+
+		r, ok := op.Value.(Reader)
+		if !ok {
+			return nil, &Error{
+				Op:   "Get",
+				Key:  key,
+				Got:  op.Value,
+				Want: Reader(nil),
+				Err:  ErrUnexpectedType,
+			}
+		}
+
+		rest := key[len(op.Key):]
+
+		return PrefixedReader{Key: rest.Dir(), R: r}.SafeGet(ctx, rest.Base())
+	}
+
+	if len(key) == 0 {
+		return s.root, nil
+	}
+
+	return PrefixedReader{Key: key.Dir(), R: s.root}.SafeGet(ctx, key.Base())
+}
+
+// parent reports whether any pending write is below key.
+func (s *stage) parent(key Key) bool {
+	for _, op := range s.ops {
+		if op.Op == "Set" && len(op.Key) > len(key) && op.Key.HasPrefix(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStage(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{"host": "localhost", "port": 8080},
+			"debug":  true,
+		}
+		s = objects.Stage(m)
+	)
+
+	if _, err := objects.Set(ctx, s, 9090, "server", "port"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	w, err := objects.Put(ctx, s, objects.TypeMap, "labels")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	w.Set(ctx, "env", "prod")
+
+	if err := objects.Del(ctx, s, "debug"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if v, err := objects.Get(ctx, s, "server", "port"); err != nil || v != 9090 {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+
+	if got, want := s.List(ctx), []string{"labels", "server"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v := m["server"].(types.Map)["port"]; v != 8080 {
+		t.Fatalf("underlying value changed before commit: %#v", v)
+	}
+
+	if n := len(s.Pending()); n != 4 {
+		t.Fatalf("got %d pending writes, want 4", n)
+	}
+
+	if err := s.Commit(ctx); err != nil {
+		t.Fatalf("Commit()=%+v", err)
+	}
+
+	want := types.Map{
+		"server": types.Map{"host": "localhost", "port": 9090},
+		"labels": types.Map{"env": "prod"},
+	}
+
+	if !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+
+	s.Set(ctx, "debug", true)
+	s.Discard()
+
+	if _, ok := s.Get(ctx, "debug"); ok {
+		t.Fatal("expected discarded write to not be visible")
+	}
+}
+
+// batchMap records batches written to it and rejects writes
+// of the "locked" key.
+type batchMap struct {
+	types.Map
+	batches []int
+}
+
+var errLocked = errors.New("locked")
+
+func (m *batchMap) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if key == "locked" {
+		return false, errLocked
+	}
+	return m.Map.Set(ctx, key, value), nil
+}
+
+func (m *batchMap) SafeDel(ctx context.Context, key string) error {
+	return types.PrefixedWriter{W: m.Map}.SafeDel(ctx, key)
+}
+
+func (m *batchMap) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	return types.PrefixedWriter{W: m.Map}.SafePut(ctx, key, hint)
+}
+
+func (m *batchMap) SetMany(ctx context.Context, entries []types.Entry) []types.Result {
+	m.batches = append(m.batches, len(entries))
+	return types.SetMany(ctx, types.PrefixedWriter{W: m}, entries...)
+}
+
+func (m *batchMap) DelMany(ctx context.Context, keys []types.Key) []types.Result {
+	m.batches = append(m.batches, len(keys))
+	return types.DelMany(ctx, types.PrefixedWriter{W: m}, keys...)
+}
+
+func TestStageCommitBatch(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = &batchMap{Map: types.Map{"old": 1, "older": 2}}
+		s   = objects.Stage(m)
+	)
+
+	s.Set(ctx, "a", 1)
+	s.Set(ctx, "locked", 2)
+	s.Set(ctx, "b", 3)
+	s.Del(ctx, "old")
+	s.Del(ctx, "older")
+
+	err := s.Commit(ctx)
+	if !errors.Is(err, errLocked) {
+		t.Fatalf("Commit()=%+v, want %v", err, errLocked)
+	}
+
+	if got, want := m.batches, []int{3}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want (-want, +got):\n%s", cmp.Diff(want, got))
+	}
+
+	var keys []string
+
+	for _, op := range s.Pending() {
+		keys = append(keys, op.Op+" "+op.Key.String())
+	}
+
+	if want := []string{"Set locked", "Del old", "Del older"}; !cmp.Equal(keys, want) {
+		t.Fatalf("got != want (-want, +got):\n%s", cmp.Diff(want, keys))
+	}
+
+	if m.Map["a"] != 1 || m.Map["b"] != 3 {
+		t.Fatalf("got %v, want a and b written", m.Map)
+	}
+}
@@ -2,9 +2,12 @@ package objects
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+
+	"rafal.dev/objects/internal/misc"
 )
 
 var DefaultOptions = &Options{
@@ -16,15 +19,28 @@ type Options struct {
 }
 
 type Struct struct {
-	v reflect.Value
+	v    reflect.Value
+	opts *Options
 }
 
 var (
 	_ Reader     = (*Struct)(nil)
 	_ SafeReader = (*Struct)(nil)
+	_ SafeLister = (*Struct)(nil)
 	_ ListerTo   = (*Struct)(nil)
+	_ Writer     = (*Struct)(nil)
+	_ SafeWriter = (*Struct)(nil)
 )
 
+// MakeStruct creates a reader of the struct v, which field names are
+// given by opts. DefaultOptions are used if opts is nil.
+func MakeStruct(v any, opts *Options) *Struct {
+	return &Struct{
+		v:    misc.ValueOf(v, true),
+		opts: opts,
+	}
+}
+
 func (s *Struct) Type() Type {
 	return TypeStruct
 }
@@ -35,8 +51,8 @@ func (s *Struct) Get(ctx context.Context, key string) (any, bool) {
 }
 
 func (s *Struct) SafeGet(ctx context.Context, key string) (any, error) {
-	switch v := s.v.FieldByName(key); {
-	case !v.IsValid() || v.IsZero():
+	switch v := s.field(key); {
+	case !v.IsValid() || (v.IsZero() && !(v.CanAddr() && v.Kind() == reflect.Struct)):
 		return nil, &Error{
 			Op:  "Get",
 			Key: []string{key},
@@ -50,8 +66,150 @@ func (s *Struct) SafeGet(ctx context.Context, key string) (any, error) {
 			Err: fmt.Errorf("cannot access value: %s", v.Type()),
 		}
 	default:
-		return tryMake(v.Interface()), nil
+		return s.make(v), nil
+	}
+}
+
+// make keeps addressable structs and slices settable, so they can
+// be written to through the returned value.
+func (s *Struct) make(v reflect.Value) any {
+	if v.CanAddr() {
+		switch v.Kind() {
+		case reflect.Struct:
+			return &Struct{v: v, opts: s.opts}
+		case reflect.Slice, reflect.Array:
+			return &Slice{v: v}
+		}
+	}
+
+	x := tryMake(v.Interface())
+	if st, ok := x.(*Struct); ok {
+		st.opts = s.opts
 	}
+	return x
+}
+
+func (s *Struct) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Struct) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Struct) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+// SafeDel sets the field to its zero value.
+func (s *Struct) SafeDel(ctx context.Context, key string) error {
+	v, err := s.settable("Del", key)
+	if err != nil {
+		return err
+	}
+
+	if v.IsZero() {
+		return &Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	v.Set(reflect.Zero(v.Type()))
+
+	return nil
+}
+
+func (s *Struct) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	v, err := s.settable("Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	x, err := convert(ctx, value, v.Type(), "Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	ok := !v.IsZero()
+	v.Set(x)
+
+	return ok, nil
+}
+
+// SafePut gives a writer of the field, allocating nil pointers
+// and maps on the way.
+func (s *Struct) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	v, err := s.settable("Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return &Struct{v: v, opts: s.opts}, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		}
+		return &Slice{v: v}, nil
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		if w, ok := tryMake(v.Interface()).(Writer); ok {
+			return w, nil
+		}
+		return &Map{v: v}, nil
+	case reflect.Interface:
+		if !v.IsNil() {
+			if w, ok := tryMake(v.Interface()).(Writer); ok {
+				return w, nil
+			}
+		}
+	}
+
+	x, w, err := makeElem(v.Type(), hint, "Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set(x)
+
+	return w, nil
+}
+
+func (s *Struct) settable(op, key string) (reflect.Value, error) {
+	v := s.field(key)
+
+	if !v.IsValid() {
+		return reflect.Value{}, &Error{
+			Op:  op,
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	if !v.CanSet() {
+		return reflect.Value{}, &Error{
+			Op:  op,
+			Key: []string{key},
+			Got: s.v.Type().String(),
+			Err: errors.New("cannot set field of unaddressable struct"),
+		}
+	}
+
+	return v, nil
 }
 
 func (s *Struct) List(ctx context.Context) []string {
@@ -60,23 +218,118 @@ func (s *Struct) List(ctx context.Context) []string {
 	return keys
 }
 
+func (s *Struct) SafeList(ctx context.Context) ([]string, error) {
+	return s.List(ctx), nil
+}
+
 func (s *Struct) ListTo(ctx context.Context, keys *[]string) {
-	for _, f := range reflect.VisibleFields(s.v.Type()) {
-		*keys = append(*keys, s.options().StructField(f))
+	s.fields(func(name string, _ reflect.StructField) bool {
+		*keys = append(*keys, name)
+		return true
+	})
+}
+
+func (s *Struct) field(key string) (v reflect.Value) {
+	s.fields(func(name string, f reflect.StructField) bool {
+		if name == key {
+			v, _ = s.v.FieldByIndexErr(f.Index)
+			return false
+		}
+		return true
+	})
+	return v
+}
+
+// fields calls fn for each exported field of the struct, including
+// fields promoted from untagged embedded structs, until fn returns
+// false. Of fields with the same name the shallowest one is visible,
+// and none is if there are more at the same depth.
+func (s *Struct) fields(fn func(string, reflect.StructField) bool) {
+	var (
+		t       = s.v.Type()
+		opts    = s.options()
+		names   []string
+		fields  []reflect.StructField
+		shallow = make(map[string]int) // depth of the shallowest field
+		count   = make(map[string]int) // number of fields at that depth
+	)
+
+	for _, f := range reflect.VisibleFields(t) {
+		name := opts.StructField(f)
+
+		switch {
+		case f.Anonymous && name == f.Name && indirectKind(f.Type) == reflect.Struct:
+			continue
+		case !f.IsExported() || name == "-" || !s.promoted(t, f.Index):
+			continue
+		}
+
+		switch d, ok := shallow[name]; {
+		case !ok || len(f.Index) < d:
+			shallow[name], count[name] = len(f.Index), 1
+		case len(f.Index) == d:
+			count[name]++
+		}
+
+		names = append(names, name)
+		fields = append(fields, f)
+	}
+
+	for i, f := range fields {
+		if name := names[i]; len(f.Index) == shallow[name] && count[name] == 1 {
+			if !fn(name, f) {
+				return
+			}
+		}
 	}
 }
 
+// promoted reports whether the field of the index is promoted only
+// through untagged embedded structs.
+func (s *Struct) promoted(t reflect.Type, index []int) bool {
+	for i := 1; i < len(index); i++ {
+		f := t.FieldByIndex(index[:i])
+
+		if !f.Anonymous || s.options().StructField(f) != f.Name {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (s *Struct) options() *Options {
+	if s.opts != nil {
+		return s.opts
+	}
 	return DefaultOptions
 }
 
-func DefaultField(f reflect.StructField) string {
-	return nonempty(
-		tag(f.Tag, "object"),
-		tag(f.Tag, "json"),
-		tag(f.Tag, "yaml"),
-		f.Name,
-	)
+// DefaultField names a field after its "objects" tag, falling back
+// to the "json" tag and the field name.
+var DefaultField = TagField("objects")
+
+// TagField names a field after the given tag, falling back to
+// the "json" tag and the field name.
+func TagField(name string) func(reflect.StructField) string {
+	return func(f reflect.StructField) string {
+		return nonempty(
+			tag(f.Tag, name),
+			tag(f.Tag, "json"),
+			f.Name,
+		)
+	}
+}
+
+func indirectKind(t reflect.Type) reflect.Kind {
+	return indirect(t).Kind()
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
 }
 
 func tag(t reflect.StructTag, name string) string {
@@ -0,0 +1,213 @@
+package objects_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Base struct {
+	ID string `objects:"id" json:"-"`
+}
+
+type Resource struct {
+	Base
+	Name   string `json:"name"`
+	Owner  *Base  `objects:"owner"`
+	Secret string `objects:"-"`
+	hidden string
+	Label  string `yaml:"label"`
+}
+
+func TestStruct(t *testing.T) {
+	var (
+		ctx = context.Background()
+		v   = Resource{
+			Base:   Base{ID: "1"},
+			Name:   "res",
+			Owner:  &Base{ID: "2"},
+			Secret: "s",
+			hidden: "h",
+			Label:  "l",
+		}
+		r = objects.Make(v)
+	)
+
+	if got, want := r.List(ctx), []string{"id", "name", "owner", "Label"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	cases := map[string]any{
+		"id":       "1",
+		"name":     "res",
+		"owner.id": "2",
+		"Label":    "l",
+	}
+
+	for key, want := range cases {
+		keys := objects.Key{key}
+		if key == "owner.id" {
+			keys = objects.Key{"owner", "id"}
+		}
+
+		got, err := objects.Get(ctx, r, keys...)
+		if err != nil {
+			t.Fatalf("Get(%q)=%+v", key, err)
+		}
+
+		if got != want {
+			t.Fatalf("Get(%q): got %#v, want %#v", key, got, want)
+		}
+	}
+
+	if _, err := objects.Get(ctx, r, "Secret"); err == nil {
+		t.Fatal("expected ignored field to not be found")
+	}
+}
+
+func TestStructTag(t *testing.T) {
+	var (
+		ctx = context.Background()
+		r   = objects.MakeStruct(Resource{Label: "l"}, &objects.Options{
+			StructField: objects.TagField("yaml"),
+		})
+	)
+
+	if got, want := r.List(ctx), []string{"name", "Owner", "Secret", "label"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, _ := r.Get(ctx, "label"); v != "l" {
+		t.Fatalf("got %#v, want %#v", v, "l")
+	}
+}
+
+type Node struct {
+	*Node
+	Value int `json:"value"`
+}
+
+type Named struct {
+	Name  string `json:"name"`
+	Extra string `json:"extra"`
+}
+
+type Tagged struct {
+	ID string `json:"id"`
+}
+
+type Other struct {
+	ID string `objects:"id"`
+}
+
+type Shadowed struct {
+	Named
+	Tagged
+	Other
+	Title string `json:"name"`
+}
+
+func TestStructEmbedded(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		node = objects.Make(Node{Node: &Node{Value: 1}, Value: 2})
+	)
+
+	if got, want := node.List(ctx), []string{"value"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, _ := node.Get(ctx, "value"); v != 2 {
+		t.Fatalf("got %#v, want %#v", v, 2)
+	}
+
+	r := objects.Make(Shadowed{
+		Named:  Named{Name: "inner", Extra: "e"},
+		Tagged: Tagged{ID: "1"},
+		Other:  Other{ID: "2"},
+		Title:  "outer",
+	})
+
+	if got, want := r.List(ctx), []string{"extra", "name"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, _ := r.Get(ctx, "name"); v != "outer" {
+		t.Fatalf("got %#v, want %#v", v, "outer")
+	}
+}
+
+type Settings struct {
+	Name    string            `json:"name"`
+	Port    int               `json:"port"`
+	Server  *Server           `json:"server"`
+	Labels  map[string]string `json:"labels"`
+	Ports   []int             `json:"ports"`
+	Nested  struct{ On bool } `json:"nested"`
+	Any     any               `json:"any"`
+	private int
+}
+
+func TestStructWriter(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   Settings
+		w   = objects.Make(&s).(objects.Writer)
+	)
+
+	if _, err := objects.Set(ctx, w, "app", "name"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, w, "8080", "port"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	for _, key := range []string{"server", "labels", "ports", "nested", "any"} {
+		if _, err := objects.Put(ctx, w, objects.TypeMap, key); err != nil {
+			t.Fatalf("Put(%q)=%+v", key, err)
+		}
+	}
+
+	sets := []struct {
+		value any
+		keys  []string
+	}{
+		{"localhost", []string{"server", "host"}},
+		{"prod", []string{"labels", "env"}},
+		{443, []string{"ports", "-"}},
+		{true, []string{"nested", "On"}},
+		{1, []string{"any", "x"}},
+	}
+
+	for _, set := range sets {
+		if _, err := objects.Set(ctx, w, set.value, set.keys...); err != nil {
+			t.Fatalf("Set(%v)=%+v", set.keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, w, "name"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	want := Settings{
+		Port:   8080,
+		Server: &Server{Host: "localhost"},
+		Labels: map[string]string{"env": "prod"},
+		Ports:  []int{443},
+		Any:    types.Map{"x": 1},
+	}
+	want.Nested.On = true
+
+	if !cmp.Equal(s, want, cmp.AllowUnexported(Settings{})) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(s, want, cmp.AllowUnexported(Settings{})))
+	}
+
+	if _, err := objects.Set(ctx, objects.Make(s).(objects.Writer), "x", "name"); err == nil {
+		t.Fatal("expected Set on unaddressable struct to fail")
+	}
+}
@@ -1,6 +1,10 @@
 package objects
 
-import "context"
+import (
+	"context"
+
+	"rafal.dev/objects/types"
+)
 
 type teeReader struct {
 	R Reader
@@ -10,32 +14,15 @@ type teeReader struct {
 var (
 	_ Reader     = (*teeReader)(nil)
 	_ SafeReader = (*teeReader)(nil)
+	_ SafeLister = (*teeReader)(nil)
 	_ ListerTo   = (*teeReader)(nil)
+	_ Unwrapper  = (*teeReader)(nil)
 )
 
+// TeeReader gives a reader, which writes everything read from r
+// to w. Errors of r are reported by SafeGet and SafeList.
 func TeeReader(r Reader, w Writer) Reader {
-	var (
-		tr    = &teeReader{R: r, W: w}
-		_, sr = r.(SafeReader)
-		_, lt = r.(ListerTo)
-	)
-
-	switch {
-	case sr && lt:
-		return tr
-	case sr:
-		return struct {
-			Reader
-			SafeReader
-		}{tr, tr}
-	case lt:
-		return struct {
-			Reader
-			ListerTo
-		}{tr, tr}
-	default:
-		return struct{ Reader }{tr}
-	}
+	return &teeReader{R: r, W: w}
 }
 
 func (tr *teeReader) Type() Type {
@@ -57,7 +44,7 @@ func (tr *teeReader) Get(ctx context.Context, key string) (any, bool) {
 }
 
 func (tr *teeReader) SafeGet(ctx context.Context, key string) (any, error) {
-	v, err := tr.R.(SafeReader).SafeGet(ctx, key)
+	v, err := PrefixedReader{R: tr.R}.SafeGet(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +73,14 @@ func (tr *teeReader) List(ctx context.Context) []string {
 	return tr.R.List(ctx)
 }
 
+func (tr *teeReader) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, tr.R)
+}
+
 func (tr *teeReader) ListTo(ctx context.Context, keys *[]string) {
-	tr.R.(ListerTo).ListTo(ctx, keys)
+	types.ListTo(ctx, tr.R, keys)
+}
+
+func (tr *teeReader) Unwrap() any {
+	return tr.R
 }
@@ -2,6 +2,7 @@ package objects_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"rafal.dev/objects"
@@ -30,6 +31,46 @@ func TestTeeReader(t *testing.T) {
 	}
 }
 
+// failingReader is a subtree, which backend fails to be read.
+type failingReader struct{ err error }
+
+func (fr failingReader) Type() types.Type                        { return types.TypeMap }
+func (fr failingReader) Get(context.Context, string) (any, bool) { return nil, false }
+func (fr failingReader) List(context.Context) []string           { return nil }
+
+func (fr failingReader) SafeGet(context.Context, string) (any, error) {
+	return nil, fr.err
+}
+
+func (fr failingReader) SafeList(context.Context) ([]string, error) {
+	return nil, fr.err
+}
+
+func TestTeeReaderError(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		want = errors.New("backend failed")
+		r    = types.Map{"a": failingReader{err: want}}
+	)
+
+	it := objects.Walk(objects.TeeReader(r, make(types.Map)))
+
+	for it.Next(ctx) {
+	}
+
+	if err := it.Err(); !errors.Is(err, want) {
+		t.Fatalf("Err()=%+v, want %v", err, want)
+	}
+
+	if err := objects.Copy(ctx, make(types.Map), objects.TeeReader(r, make(types.Map))); !errors.Is(err, want) {
+		t.Fatalf("Copy()=%+v, want %v", err, want)
+	}
+
+	if _, err := objects.Get(ctx, objects.TeeReader(r, make(types.Map)), "a", "b"); !errors.Is(err, want) {
+		t.Fatalf("Get()=%+v, want %v", err, want)
+	}
+}
+
 func TestCopy(t *testing.T) {
 	var (
 		x   = newX()
@@ -0,0 +1,159 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"rafal.dev/objects/types"
+)
+
+// Instantiate writes the tree of the template tmpl into w, with
+// placeholders in keys and string leaves resolved from params, e.g.
+//
+//	"${tenant}-db":
+//	  host: db.${region}.internal
+//	  replicas: ${replicas:-1}
+//
+// A placeholder is a dot-separated key of params with an optional
+// default after ":-". A leaf, which is a single placeholder, is
+// replaced by the param as it is, e.g. an int or a subtree; other
+// placeholders are formatted as strings. "$$" is a literal "$".
+//
+// All unresolved placeholders are reported, each with the key of
+// the template it was found in.
+func Instantiate(ctx context.Context, w Writer, tmpl, params Reader) error {
+	in := &instantiator{params: params}
+	in.tree(ctx, w, tmpl, nil)
+	return in.errs.Err()
+}
+
+type instantiator struct {
+	params Reader
+	errs   types.Errors
+}
+
+func (in *instantiator) tree(ctx context.Context, w Writer, tmpl Reader, key Key) {
+	keys, err := types.List(ctx, tmpl)
+	if err != nil {
+		in.errs = append(in.errs, err)
+		return
+	}
+
+	for _, k := range keys {
+		tk := append(key.Copy(), k)
+
+		v, err := PrefixedReader{R: tmpl}.SafeGet(ctx, k)
+		if err != nil {
+			in.errs = append(in.errs, err)
+			continue
+		}
+
+		name, ok := in.expand(ctx, k, tk)
+		if !ok {
+			continue
+		}
+
+		var (
+			pw         = PrefixedWriter{W: w}
+			r, subtree = v.(Reader)
+		)
+
+		if subtree {
+			sub, err := pw.SafePut(ctx, name, r.Type())
+			if err != nil {
+				in.errs = append(in.errs, err)
+				continue
+			}
+
+			in.tree(ctx, sub, r, tk)
+			continue
+		}
+
+		if s, isString := v.(string); isString {
+			if v, ok = in.value(ctx, s, tk); !ok {
+				continue
+			}
+		}
+
+		if _, err := pw.SafeSet(ctx, name, v); err != nil {
+			in.errs = append(in.errs, err)
+		}
+	}
+}
+
+// value resolves the string leaf s, keeping the type of the param
+// of a single placeholder.
+func (in *instantiator) value(ctx context.Context, s string, key Key) (any, bool) {
+	if strings.HasPrefix(s, "${") && strings.Index(s, "}") == len(s)-1 {
+		return in.param(ctx, s[2:len(s)-1], key)
+	}
+
+	return in.expand(ctx, s, key)
+}
+
+func (in *instantiator) expand(ctx context.Context, s string, key Key) (string, bool) {
+	var (
+		sb strings.Builder
+		ok = true
+	)
+
+	for {
+		i := strings.IndexByte(s, '$')
+		if i == -1 || i == len(s)-1 {
+			sb.WriteString(s)
+			break
+		}
+
+		sb.WriteString(s[:i])
+
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			s = s[i+2:]
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			s = s[i+1:]
+			continue
+		}
+
+		j := strings.IndexByte(s[i:], '}')
+		if j == -1 {
+			sb.WriteString(s[i:])
+			break
+		}
+
+		v, found := in.param(ctx, s[i+2:i+j], key)
+		if !found {
+			ok = false
+		}
+
+		sb.WriteString(envValue(v))
+		s = s[i+j+1:]
+	}
+
+	return sb.String(), ok
+}
+
+func (in *instantiator) param(ctx context.Context, expr string, key Key) (any, bool) {
+	name, def, hasDef := strings.Cut(expr, ":-")
+
+	v, err := Get(ctx, in.params, strings.Split(name, ".")...)
+	switch {
+	case err == nil:
+		return v, true
+	case errors.Is(err, ErrNotFound) && hasDef:
+		return def, true
+	}
+
+	in.errs = append(in.errs, &Error{
+		Op:  "Instantiate",
+		Key: key,
+		Got: "${" + expr + "}",
+		Err: err,
+	})
+
+	return nil, false
+}
@@ -0,0 +1,61 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInstantiate(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		tmpl = types.Map{
+			"${tenant}-db": types.Map{
+				"host":     "db.${region}.internal",
+				"replicas": "${replicas:-1}",
+				"port":     5432,
+			},
+			"limits":  "${limits}",
+			"comment": "costs $$5 in ${region}",
+		}
+		params = types.Map{
+			"tenant": "acme",
+			"region": "eu",
+			"limits": types.Map{"rps": 100},
+		}
+		got = make(types.Map)
+	)
+
+	if err := objects.Instantiate(ctx, got, tmpl, params); err != nil {
+		t.Fatalf("Instantiate()=%+v", err)
+	}
+
+	want := types.Map{
+		"acme-db": types.Map{
+			"host":     "db.eu.internal",
+			"replicas": "1",
+			"port":     5432,
+		},
+		"limits":  types.Map{"rps": 100},
+		"comment": "costs $5 in eu",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	err := objects.Instantiate(ctx, make(types.Map), tmpl, types.Map{"tenant": "acme"})
+	if !errors.Is(err, objects.ErrNotFound) {
+		t.Fatalf("Instantiate()=%+v, want %v", err, objects.ErrNotFound)
+	}
+
+	var errs objects.Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("got %+v, want 3 errors", err)
+	}
+}
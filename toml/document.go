@@ -0,0 +1,538 @@
+package toml
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// Document is a parsed TOML document. Writes modify the parsed tree,
+// and Bytes applies them to the source as in-place edits.
+type Document struct {
+	types.Map
+
+	// File is the name of the source file reported in positions.
+	File string
+
+	src []byte
+}
+
+var _ objects.Provenance = (*Document)(nil)
+
+// Bytes serializes the document back to TOML. Changed values are
+// replaced in the source, keeping their string quoting; removed keys
+// have their lines deleted and new keys are appended to their tables,
+// so comments, blank lines and formatting of untouched lines are
+// preserved. Changes, which cannot be expressed by such edits, like
+// removing a table or appending to an array of tables, make the whole
+// document marshaled anew.
+func (d *Document) Bytes() ([]byte, error) {
+	ctx := context.Background()
+
+	orig, err := parse(d.src)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := objects.Diff(ctx, orig, d.Map)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patch) == 0 {
+		return append([]byte(nil), d.src...), nil
+	}
+
+	var (
+		idx   = scan(d.src)
+		edits = make(map[[2]int]string)
+	)
+
+	for _, op := range patch {
+		if !idx.edit(ctx, d.Map, op, edits) {
+			return Marshal(ctx, d.Map)
+		}
+	}
+
+	return apply(d.src, edits)
+}
+
+// Position gives the position of the key of a key-value pair, or of
+// the header of a table, in the source. Keys added after parsing have
+// no position.
+func (d *Document) Position(key objects.Key) (objects.Position, bool) {
+	var (
+		idx = scan(d.src)
+		off int
+	)
+
+	if e, ok := idx.entries[path(key)]; ok {
+		off = e.key
+	} else if off, ok = idx.headers[path(key)]; !ok {
+		return objects.Position{}, false
+	}
+
+	var (
+		line = 1 + bytes.Count(d.src[:off], []byte("\n"))
+		col  = off - bytes.LastIndexByte(d.src[:off], '\n')
+	)
+
+	return objects.Position{
+		File:   d.File,
+		Line:   line,
+		Column: col,
+	}, true
+}
+
+// entry is a key-value line of the source.
+type entry struct {
+	key   int    // offset of the key
+	value [2]int // span of the value
+	line  [2]int // span of the line, including its newline
+}
+
+type index struct {
+	src     []byte
+	entries map[string]entry
+	tables  map[string]int // insertion offsets of tables
+	headers map[string]int // offsets of table headers
+	arrays  map[string]bool
+}
+
+func (idx *index) edit(ctx context.Context, tree types.Map, op objects.PatchOp, edits map[[2]int]string) bool {
+	for i := len(op.Key); i > 0; i-- {
+		e, ok := idx.entries[path(op.Key[:i])]
+		if !ok {
+			continue
+		}
+
+		if i == len(op.Key) && op.Op == "Del" {
+			edits[e.line] = ""
+			return true
+		}
+
+		v, err := objects.Get(ctx, tree, op.Key[:i]...)
+		if err != nil {
+			return false
+		}
+
+		s, ok := encode(ctx, v, string(idx.src[e.value[0]:e.value[1]]))
+		if !ok {
+			return false
+		}
+
+		edits[e.value] = s
+		return true
+	}
+
+	if op.Op != "Set" || len(op.Key) == 0 {
+		return false
+	}
+
+	var (
+		dir    = path(op.Key.Dir())
+		at, ok = idx.tables[dir]
+	)
+
+	if _, isTable := idx.tables[path(op.Key)]; !ok || isTable || idx.arrays[path(op.Key)] {
+		return false
+	}
+
+	s, ok := encode(ctx, op.Value, "")
+	if !ok {
+		return false
+	}
+
+	span := [2]int{at, at}
+	edits[span] += encodeKey(op.Key.Base()) + " = " + s + "\n"
+
+	return true
+}
+
+func apply(src []byte, edits map[[2]int]string) ([]byte, error) {
+	spans := make([][2]int, 0, len(edits))
+
+	for span := range edits {
+		spans = append(spans, span)
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i][0] != spans[j][0] {
+			return spans[i][0] < spans[j][0]
+		}
+		return spans[i][1] < spans[j][1]
+	})
+
+	var (
+		p    []byte
+		last int
+	)
+
+	for _, span := range spans {
+		if span[0] < last {
+			return nil, &objects.Error{
+				Op:  "Marshal",
+				Got: span,
+				Err: objects.ErrConflict,
+			}
+		}
+
+		p = append(p, src[last:span[0]]...)
+		p = append(p, edits[span]...)
+		last = span[1]
+	}
+
+	return append(p, src[last:]...), nil
+}
+
+func path(key objects.Key) string {
+	return strings.Join(key, "\x00")
+}
+
+// scan indexes key-value lines and tables of the TOML document src,
+// which is expected to be valid.
+func scan(src []byte) *index {
+	var (
+		idx = &index{
+			src:     src,
+			entries: make(map[string]entry),
+			tables:  map[string]int{"": 0},
+			headers: make(map[string]int),
+			arrays:  make(map[string]bool),
+		}
+		counts = make(map[string]int)
+		table  objects.Key
+		i      int
+	)
+
+	for i < len(src) {
+		start := i
+
+		for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+			i++
+		}
+
+		switch {
+		case i == len(src):
+		case src[i] == '\n' || src[i] == '\r' || src[i] == '#':
+		case src[i] == '[':
+			header := i
+			array := i+1 < len(src) && src[i+1] == '['
+
+			if array {
+				i++
+			}
+
+			key, j := scanKey(src, i+1)
+			i = j
+
+			if array {
+				n := counts[path(key)]
+				counts[path(key)]++
+				idx.arrays[path(key)] = true
+				key = append(key, strconv.Itoa(n))
+			}
+
+			table = key
+			idx.tables[path(table)] = lineEnd(src, i)
+			idx.headers[path(table)] = header
+		default:
+			at := i
+			key, j := scanKey(src, i)
+			i = skipSpace(src, j) + 1 // '='
+			i = skipSpace(src, i)
+
+			end := scanValue(src, i)
+			key = append(table.Copy(), key...)
+
+			idx.entries[path(key)] = entry{
+				key:   at,
+				value: [2]int{i, end},
+				line:  [2]int{start, lineEnd(src, end)},
+			}
+
+			i = end
+			idx.tables[path(table)] = lineEnd(src, i)
+		}
+
+		i = lineEnd(src, i)
+	}
+
+	return idx
+}
+
+func scanKey(src []byte, i int) (objects.Key, int) {
+	var key objects.Key
+
+	for i < len(src) {
+		i = skipSpace(src, i)
+
+		switch c := src[i]; c {
+		case '"', '\'':
+			end := scanValue(src, i)
+			s := string(src[i+1 : end-1])
+
+			if c == '"' {
+				if u, err := strconv.Unquote(`"` + s + `"`); err == nil {
+					s = u
+				}
+			}
+
+			key = append(key, s)
+			i = end
+		default:
+			j := i
+			for j < len(src) && isBare(src[j]) {
+				j++
+			}
+
+			key = append(key, string(src[i:j]))
+			i = j
+		}
+
+		if i = skipSpace(src, i); i < len(src) && src[i] == '.' {
+			i++
+			continue
+		}
+
+		return key, i
+	}
+
+	return key, i
+}
+
+// scanValue gives the end of the value starting at i.
+func scanValue(src []byte, i int) int {
+	if i >= len(src) {
+		return i
+	}
+
+	switch c := src[i]; {
+	case strings.HasPrefix(string(src[i:]), `"""`), strings.HasPrefix(string(src[i:]), `'''`):
+		delim := string(src[i : i+3])
+
+		for j := i + 3; j+3 <= len(src); j++ {
+			if string(src[j:j+3]) == delim && (c == '\'' || !escaped(src, j)) {
+				j += 3
+				for n := 0; n < 2 && j < len(src) && src[j] == c; n++ {
+					j++
+				}
+				return j
+			}
+		}
+
+		return len(src)
+	case c == '"' || c == '\'':
+		for j := i + 1; j < len(src); j++ {
+			if src[j] == c && (c == '\'' || !escaped(src, j)) {
+				return j + 1
+			}
+		}
+
+		return len(src)
+	case c == '[' || c == '{':
+		j := i + 1
+
+		for j < len(src) {
+			switch src[j] {
+			case ']', '}':
+				return j + 1
+			case '#':
+				for j < len(src) && src[j] != '\n' {
+					j++
+				}
+			case ' ', '\t', '\r', '\n', ',', '=':
+				j++
+			default:
+				j = scanValue(src, j)
+			}
+		}
+
+		return j
+	default:
+		j := i
+
+		for j < len(src) && !strings.ContainsRune(" \t\r\n,]}#=", rune(src[j])) {
+			j++
+		}
+
+		// A space separates the date and the time of a datetime.
+		if j-i == 10 && j+1 < len(src) && src[j] == ' ' && src[i+4] == '-' && src[j+1] >= '0' && src[j+1] <= '9' {
+			return scanValue(src, j+1)
+		}
+
+		return j
+	}
+}
+
+func escaped(src []byte, i int) bool {
+	n := 0
+	for i--; i >= 0 && src[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func skipSpace(src []byte, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func lineEnd(src []byte, i int) int {
+	for i < len(src) && src[i] != '\n' {
+		i++
+	}
+	if i < len(src) {
+		i++
+	}
+	return i
+}
+
+func isBare(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func encodeKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isBare(key[i]) {
+			return quote(key)
+		}
+	}
+
+	if key == "" {
+		return `""`
+	}
+
+	return key
+}
+
+// encode encodes v as an inline TOML value. Strings keep the literal
+// quoting of old, if they can be represented with it.
+func encode(ctx context.Context, v any, old string) (string, bool) {
+	if r, ok := v.(objects.Reader); ok {
+		var x any
+		if err := objects.Decode(ctx, r, &x, nil); err != nil {
+			return "", false
+		}
+		v = x
+	}
+
+	switch v := v.(type) {
+	case string:
+		if strings.HasPrefix(old, "'") && !strings.HasPrefix(old, "'''") && !strings.ContainsAny(v, "'\r\n") {
+			return "'" + v + "'", true
+		}
+		return quote(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	case float32:
+		return encodeFloat(float64(v)), true
+	case float64:
+		return encodeFloat(v), true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	case []any:
+		parts := make([]string, 0, len(v))
+
+		for _, v := range v {
+			s, ok := encode(ctx, v, "")
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, s)
+		}
+
+		return "[" + strings.Join(parts, ", ") + "]", true
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(v))
+
+		for _, k := range keys {
+			s, ok := encode(ctx, v[k], "")
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, encodeKey(k)+" = "+s)
+		}
+
+		if len(parts) == 0 {
+			return "{}", true
+		}
+
+		return "{ " + strings.Join(parts, ", ") + " }", true
+	default:
+		return "", false
+	}
+}
+
+func encodeFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+
+	return s
+}
+
+func quote(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
@@ -0,0 +1,124 @@
+// Package toml parses TOML documents into trees of objects
+// and serializes trees back to TOML.
+package toml
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/BurntSushi/toml"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+)
+
+// Parse parses the TOML document p. Tables and inline tables become
+// maps, arrays and arrays of tables become slices.
+func Parse(p []byte) (*Document, error) {
+	m, err := parse(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Map: m,
+		src: append([]byte(nil), p...),
+	}, nil
+}
+
+func parse(p []byte) (types.Map, error) {
+	var v map[string]any
+
+	if err := toml.Unmarshal(p, &v); err != nil {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: err,
+		}
+	}
+
+	return convert(v).(types.Map), nil
+}
+
+// Marshal serializes the tree of r into a TOML document. The tree
+// must be a map, as TOML documents are tables.
+func Marshal(ctx context.Context, r objects.Reader) ([]byte, error) {
+	if r.Type() == objects.TypeSlice {
+		return nil, &objects.Error{
+			Op:   "Marshal",
+			Got:  r.Type(),
+			Want: objects.TypeMap,
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	var (
+		v   map[string]any
+		buf bytes.Buffer
+	)
+
+	if err := objects.Decode(ctx, r, &v, nil); err != nil {
+		return nil, err
+	}
+
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+
+	if err := enc.Encode(v); err != nil {
+		return nil, &objects.Error{
+			Op:  "Marshal",
+			Err: err,
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Get reads the value under the keys of the TOML document p.
+func Get(ctx context.Context, p []byte, keys ...string) (any, error) {
+	iface, err := Parse(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return objects.Get(ctx, iface, keys...)
+}
+
+// Set writes the value under the keys of the TOML document p
+// and returns the updated document, see Document.Bytes.
+func Set(ctx context.Context, p []byte, value any, keys ...string) ([]byte, error) {
+	doc, err := Parse(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := objects.Set(ctx, doc, value, keys...); err != nil {
+		return nil, err
+	}
+
+	return doc.Bytes()
+}
+
+func convert(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(types.Map, len(v))
+		for k, v := range v {
+			m[k] = convert(v)
+		}
+		return m
+	case []map[string]any:
+		s := make(types.Slice, len(v))
+		for i, v := range v {
+			s[i] = convert(v)
+		}
+		return &s
+	case []any:
+		s := make(types.Slice, len(v))
+		for i, v := range v {
+			s[i] = convert(v)
+		}
+		return &s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,201 @@
+package toml_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/toml"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const config = `title = "example"
+
+[server]
+host = "localhost"
+port = 8080
+tls = { enabled = true, cert = "server.pem" }
+
+[[backends]]
+name = "a"
+weight = 1
+
+[[backends]]
+name = "b"
+weight = 2
+`
+
+func TestParse(t *testing.T) {
+	var ctx = context.Background()
+
+	iface, err := toml.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	gets := []struct {
+		keys []string
+		want any
+	}{
+		{[]string{"title"}, "example"},
+		{[]string{"server", "port"}, int64(8080)},
+		{[]string{"server", "tls", "enabled"}, true},
+		{[]string{"backends", "1", "name"}, "b"},
+	}
+
+	for _, get := range gets {
+		v, err := objects.Get(ctx, iface, get.keys...)
+		if err != nil {
+			t.Fatalf("Get(%v)=%+v", get.keys, err)
+		}
+
+		if !cmp.Equal(v, get.want) {
+			t.Fatalf("Get(%v): got %#v, want %#v", get.keys, v, get.want)
+		}
+	}
+
+	if _, err := toml.Parse([]byte("key = ")); err == nil {
+		t.Fatal("expected Parse of an invalid document to fail")
+	}
+}
+
+func TestSet(t *testing.T) {
+	var ctx = context.Background()
+
+	p, err := toml.Set(ctx, []byte(config), int64(3), "backends", "0", "weight")
+	if err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if v, err := toml.Get(ctx, p, "backends", "0", "weight"); err != nil || v != int64(3) {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+
+	if v, err := toml.Get(ctx, p, "server", "tls", "cert"); err != nil || v != "server.pem" {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+}
+
+const commented = `# Service configuration.
+title = 'example' # the title
+
+[server]
+host = "localhost"
+port = 8080 # listen port
+tls = { enabled = true, cert = "server.pem" }
+
+# Backends are tried in order.
+[[backends]]
+name = "a"
+weight = 1
+
+[[backends]]
+name = "b"
+weight = 2
+`
+
+func TestDocument(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := toml.Parse([]byte(commented))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if p, err := doc.Bytes(); err != nil || string(p) != commented {
+		t.Fatalf("Bytes()=%q, %+v", p, err)
+	}
+
+	sets := []struct {
+		keys  []string
+		value any
+	}{
+		{[]string{"title"}, "updated"},
+		{[]string{"server", "port"}, int64(9090)},
+		{[]string{"server", "tls", "enabled"}, false},
+		{[]string{"server", "timeout"}, "5s"},
+		{[]string{"backends", "1", "weight"}, int64(3)},
+	}
+
+	for _, set := range sets {
+		if _, err := objects.Set(ctx, doc, set.value, set.keys...); err != nil {
+			t.Fatalf("Set(%v)=%+v", set.keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, doc, "backends", "0", "weight"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	p, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes()=%+v", err)
+	}
+
+	want := `# Service configuration.
+title = 'updated' # the title
+
+[server]
+host = "localhost"
+port = 9090 # listen port
+tls = { cert = "server.pem", enabled = false }
+timeout = "5s"
+
+# Backends are tried in order.
+[[backends]]
+name = "a"
+
+[[backends]]
+name = "b"
+weight = 3
+`
+
+	if diff := cmp.Diff(want, string(p)); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, err := objects.Set(ctx, doc, types.Map{"name": "c"}, "backends", "2"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if p, err = doc.Bytes(); err != nil {
+		t.Fatalf("Bytes()=%+v", err)
+	}
+
+	if v, err := toml.Get(ctx, p, "backends", "2", "name"); err != nil || v != "c" {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := toml.Parse([]byte(commented))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	doc.File = "config.toml"
+
+	cases := []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"title"}, "config.toml:2:1"},
+		{[]string{"server"}, "config.toml:4:1"},
+		{[]string{"server", "port"}, "config.toml:6:1"},
+		{[]string{"backends", "1", "weight"}, "config.toml:16:1"},
+	}
+
+	for _, cas := range cases {
+		if pos, ok := objects.PositionOf(ctx, doc, cas.keys...); !ok || pos.String() != cas.want {
+			t.Fatalf("PositionOf(%v)=%v, %t, want %s", cas.keys, pos, ok, cas.want)
+		}
+	}
+
+	if _, ok := objects.PositionOf(ctx, doc, "server", "tls", "cert"); ok {
+		t.Fatal("expected PositionOf() of an inline table key to fail")
+	}
+}
@@ -0,0 +1,34 @@
+package types
+
+import "reflect"
+
+// As finds the first value in the chain of wrappers, starting with v
+// and following Unwrap, that is assignable to the value pointed to by
+// target, and if one is found, sets target to that value.
+// It panics if target is not a non-nil pointer.
+func As(v any, target any) bool {
+	const maxDepth = 128 // to prevent infinite loop on cyclic wrappers
+
+	t := reflect.ValueOf(target)
+	if t.Kind() != reflect.Ptr || t.IsNil() {
+		panic("types: target must be a non-nil pointer")
+	}
+
+	typ := t.Type().Elem()
+
+	for i := 0; i < maxDepth && v != nil; i++ {
+		if reflect.TypeOf(v).AssignableTo(typ) {
+			t.Elem().Set(reflect.ValueOf(v))
+			return true
+		}
+
+		u, ok := v.(Unwrapper)
+		if !ok {
+			return false
+		}
+
+		v = u.Unwrap()
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+func TestAs(t *testing.T) {
+	var (
+		l     = &types.Locks{}
+		lm    = lockedMap{newM(), l}
+		stack = types.Prefix(types.Edit(types.SoftDelete(lm, time.Hour, nil)), "foo")
+	)
+
+	var locker types.Locker
+
+	if !types.As(stack, &locker) {
+		t.Fatal("expected As() to find a Locker")
+	}
+
+	if _, err := locker.Lock(context.Background(), types.Key{"foo"}); err != nil {
+		t.Fatalf("Lock()=%+v", err)
+	}
+
+	var sd *types.SoftDeleted
+
+	if !types.As(stack, &sd) {
+		t.Fatal("expected As() to find a *SoftDeleted")
+	}
+
+	var d *types.Debounced
+
+	if types.As(stack, &d) {
+		t.Fatal("unexpected *Debounced found")
+	}
+}
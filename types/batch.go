@@ -0,0 +1,124 @@
+package types
+
+import "context"
+
+// Entry is a key with a value to set.
+type Entry struct {
+	Key   Key
+	Value any
+}
+
+// Result is the result of an operation on one of many keys. Value
+// is the read value for GetMany and the previous flag for SetMany.
+type Result struct {
+	Key   Key
+	Value any
+	Err   error
+}
+
+// BatchReader is implemented by readers able to get many keys at
+// once, e.g. with pipelining, instead of a round trip per key.
+type BatchReader interface {
+	GetMany(ctx context.Context, keys []Key) []Result
+}
+
+// BatchWriter is implemented by writers able to set or delete many
+// keys at once. Unlike a Tx, a batch is not atomic: each key has
+// its own result.
+type BatchWriter interface {
+	SetMany(ctx context.Context, entries []Entry) []Result
+	DelMany(ctx context.Context, keys []Key) []Result
+}
+
+// GetMany gets values of the keys, with a result for each of them
+// in order. It uses r's GetMany, if r is a BatchReader.
+func GetMany(ctx context.Context, r Reader, keys ...Key) []Result {
+	if br, ok := r.(BatchReader); ok {
+		return br.GetMany(ctx, keys)
+	}
+
+	res := make([]Result, len(keys))
+
+	for i, k := range keys {
+		res[i].Key = k
+
+		if res[i].Err = checkKey("Get", k); res[i].Err != nil {
+			continue
+		}
+
+		res[i].Value, res[i].Err = PrefixReader(r, k.Dir()...).SafeGet(ctx, k.Base())
+	}
+
+	return res
+}
+
+// SetMany sets values of the entries, with a result for each of them
+// in order. It uses w's SetMany, if w is a BatchWriter.
+func SetMany(ctx context.Context, w Writer, entries ...Entry) []Result {
+	if bw, ok := w.(BatchWriter); ok {
+		return bw.SetMany(ctx, entries)
+	}
+
+	res := make([]Result, len(entries))
+
+	for i, e := range entries {
+		res[i].Key = e.Key
+
+		if res[i].Err = checkKey("Set", e.Key); res[i].Err != nil {
+			continue
+		}
+
+		res[i].Value, res[i].Err = PrefixWriter(w, e.Key.Dir()...).SafeSet(ctx, e.Key.Base(), e.Value)
+	}
+
+	return res
+}
+
+// DelMany deletes the keys, with a result for each of them in order.
+// It uses w's DelMany, if w is a BatchWriter.
+func DelMany(ctx context.Context, w Writer, keys ...Key) []Result {
+	if bw, ok := w.(BatchWriter); ok {
+		return bw.DelMany(ctx, keys)
+	}
+
+	res := make([]Result, len(keys))
+
+	for i, k := range keys {
+		res[i].Key = k
+
+		if res[i].Err = checkKey("Del", k); res[i].Err != nil {
+			continue
+		}
+
+		res[i].Err = PrefixWriter(w, k.Dir()...).SafeDel(ctx, k.Base())
+	}
+
+	return res
+}
+
+// Errs gives errors of the results, or nil if all succeeded.
+func Errs(res []Result) error {
+	var errs Errors
+
+	for _, r := range res {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func checkKey(op string, k Key) error {
+	if len(k) == 0 {
+		return &Error{
+			Op:  op,
+			Err: ErrEmpty,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBatch(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"db": types.Map{
+				"host": "localhost",
+			},
+		}
+	)
+
+	res := types.SetMany(ctx, m,
+		types.Entry{Key: types.Key{"db", "port"}, Value: 5432},
+		types.Entry{Key: types.Key{"db", "host"}, Value: "db.local"},
+		types.Entry{Key: types.Key{"cache", "ttl"}, Value: "1m"},
+	)
+
+	if got, want := res[1].Value, true; got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if !errors.Is(res[2].Err, types.ErrNotFound) {
+		t.Fatalf("SetMany()=%+v, want %v", res[2].Err, types.ErrNotFound)
+	}
+
+	res = types.GetMany(ctx, m, types.Key{"db", "host"}, types.Key{"db", "port"}, nil)
+
+	if got, want := []any{res[0].Value, res[1].Value}, []any{"db.local", 5432}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if !errors.Is(res[2].Err, types.ErrEmpty) {
+		t.Fatalf("GetMany()=%+v, want %v", res[2].Err, types.ErrEmpty)
+	}
+
+	if err := types.Errs(types.DelMany(ctx, m, types.Key{"db", "port"}, types.Key{"db", "host"})); err != nil {
+		t.Fatalf("DelMany()=%+v", err)
+	}
+
+	if diff := cmp.Diff(types.Map{"db": types.Map{}}, m); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,262 @@
+package types
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type budgetKey struct{}
+
+// Budget records time spent by layers of a composed stack on
+// operations sharing a context.
+type Budget struct {
+	mu       sync.Mutex
+	deadline time.Time
+	timings  []Timing
+}
+
+// Timing is the time spent by a layer on an operation. Time of
+// outer layers includes time of the inner ones.
+type Timing struct {
+	Layer    string
+	Op       string
+	Key      Key
+	Duration time.Duration
+}
+
+// WithBudget gives a context, which operations must complete within
+// the total duration and which records their timings in a Budget.
+func WithBudget(ctx context.Context, total time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, total)
+	deadline, _ := ctx.Deadline()
+
+	return context.WithValue(ctx, budgetKey{}, &Budget{deadline: deadline}), cancel
+}
+
+// BudgetFrom gives the Budget of the context, or nil if it has none.
+func BudgetFrom(ctx context.Context) *Budget {
+	b, _ := ctx.Value(budgetKey{}).(*Budget)
+	return b
+}
+
+// Record records the time spent by a layer. It is a nop on
+// a nil Budget.
+func (b *Budget) Record(t Timing) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.timings = append(b.timings, t)
+}
+
+// Remaining gives the time left until the deadline, or zero for
+// a nil Budget.
+func (b *Budget) Remaining() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	return time.Until(b.deadline)
+}
+
+// Timings gives recorded timings in order of completion, or nil
+// for a nil Budget.
+func (b *Budget) Timings() []Timing {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Timing(nil), b.timings...)
+}
+
+// Spent gives the total time recorded by the layer, or zero for
+// a nil Budget.
+func (b *Budget) Spent(layer string) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var d time.Duration
+	for _, t := range b.timings {
+		if t.Layer == layer {
+			d += t.Duration
+		}
+	}
+	return d
+}
+
+func record(ctx context.Context, layer, op string, key Key, start time.Time) {
+	BudgetFrom(ctx).Record(Timing{
+		Layer:    layer,
+		Op:       op,
+		Key:      key,
+		Duration: time.Since(start),
+	})
+}
+
+// Timed records time spent on operations of an Interface in the
+// Budget of their context, under the name of the layer. Operations
+// fail without being started when the budget is exhausted.
+type Timed struct {
+	iface Interface
+	key   Key
+	layer string
+}
+
+var (
+	_ Interface  = (*Timed)(nil)
+	_ SafeReader = (*Timed)(nil)
+	_ SafeLister = (*Timed)(nil)
+	_ SafeWriter = (*Timed)(nil)
+)
+
+func Time(iface Interface, layer string) *Timed {
+	return &Timed{
+		iface: iface,
+		layer: layer,
+	}
+}
+
+func (t *Timed) Type() Type {
+	return t.iface.Type()
+}
+
+func (t *Timed) Get(ctx context.Context, key string) (any, bool) {
+	v, err := t.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (t *Timed) SafeGet(ctx context.Context, key string) (any, error) {
+	full, err := t.start(ctx, "Get", key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer record(ctx, t.layer, "Get", full, time.Now())
+
+	v, err := PrefixedReader{R: t.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := v.(Interface); ok {
+		return &Timed{iface: iface, key: full, layer: t.layer}, nil
+	}
+
+	return v, nil
+}
+
+func (t *Timed) List(ctx context.Context) []string {
+	keys, _ := t.SafeList(ctx)
+	return keys
+}
+
+func (t *Timed) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &Error{
+			Op:  "List",
+			Key: t.key,
+			Err: err,
+		}
+	}
+
+	defer record(ctx, t.layer, "List", t.key, time.Now())
+
+	return List(ctx, t.iface)
+}
+
+func (t *Timed) Del(ctx context.Context, key string) bool {
+	return t.SafeDel(ctx, key) == nil
+}
+
+func (t *Timed) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := t.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (t *Timed) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := t.SafePut(ctx, key, hint)
+	return w
+}
+
+func (t *Timed) SafeDel(ctx context.Context, key string) error {
+	full, err := t.start(ctx, "Del", key)
+	if err != nil {
+		return err
+	}
+
+	defer record(ctx, t.layer, "Del", full, time.Now())
+
+	return PrefixedWriter{W: t.iface}.SafeDel(ctx, key)
+}
+
+func (t *Timed) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	full, err := t.start(ctx, "Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	defer record(ctx, t.layer, "Set", full, time.Now())
+
+	return PrefixedWriter{W: t.iface}.SafeSet(ctx, key, value)
+}
+
+func (t *Timed) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	full, err := t.start(ctx, "Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer record(ctx, t.layer, "Put", full, time.Now())
+
+	w, err := PrefixedWriter{W: t.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return &Timed{iface: iface, key: full, layer: t.layer}, nil
+	}
+
+	return w, nil
+}
+
+func (t *Timed) Ping(ctx context.Context) error {
+	return Ping(ctx, t.iface)
+}
+
+func (t *Timed) Close(ctx context.Context) error {
+	return Close(ctx, t.iface)
+}
+
+func (t *Timed) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (t *Timed) Unwrap() any {
+	return t.iface
+}
+
+func (t *Timed) start(ctx context.Context, op, key string) (Key, error) {
+	full := append(t.key.Copy(), key)
+
+	if err := ctx.Err(); err != nil {
+		return nil, &Error{
+			Op:  op,
+			Key: full,
+			Err: err,
+		}
+	}
+
+	return full, nil
+}
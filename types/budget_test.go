@@ -0,0 +1,81 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+type sleepMap struct {
+	types.Map
+	d time.Duration
+}
+
+func (m sleepMap) Get(ctx context.Context, key string) (any, bool) {
+	time.Sleep(m.d)
+	return m.Map.Get(ctx, key)
+}
+
+func TestBudget(t *testing.T) {
+	var (
+		backend = types.Time(sleepMap{Map: types.Map{"key": "value"}, d: 20 * time.Millisecond}, "backend")
+		r       = types.Time(types.Limit(backend, nil), "frontend")
+	)
+
+	ctx, cancel := types.WithBudget(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		if v, err := r.SafeGet(ctx, "key"); err != nil || v != "value" {
+			t.Fatalf("SafeGet()=%#v, %+v", v, err)
+		}
+	}
+
+	b := types.BudgetFrom(ctx)
+
+	if n := len(b.Timings()); n != 6 {
+		t.Fatalf("got %d timings, want 6: %+v", n, b.Timings())
+	}
+
+	if backend, frontend := b.Spent("backend"), b.Spent("frontend"); backend < 40*time.Millisecond || frontend < backend {
+		t.Fatalf("got backend=%s frontend=%s", backend, frontend)
+	}
+
+	if got := b.Timings()[0]; got.Layer != "limit" || got.Op != "Wait" {
+		t.Fatalf("got %+v, want limit wait first", got)
+	}
+
+	if b.Remaining() > time.Second {
+		t.Fatalf("got %s remaining, want less than budget", b.Remaining())
+	}
+
+	ctx, cancel = types.WithBudget(context.Background(), time.Millisecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	if _, err := r.SafeGet(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %+v, want %+v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBudgetNil(t *testing.T) {
+	b := types.BudgetFrom(context.Background())
+
+	b.Record(types.Timing{Layer: "backend"})
+
+	if d := b.Remaining(); d != 0 {
+		t.Fatalf("got %s remaining, want 0", d)
+	}
+
+	if d := b.Spent("backend"); d != 0 {
+		t.Fatalf("got %s spent, want 0", d)
+	}
+
+	if timings := b.Timings(); timings != nil {
+		t.Fatalf("got %+v, want nil", timings)
+	}
+}
@@ -0,0 +1,272 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CacheOptions struct {
+	// TTL is the time values are cached for.
+	TTL time.Duration
+
+	// NegativeTTL is the time not found results are cached for.
+	// Not found results are not cached if it is 0.
+	NegativeTTL time.Duration
+
+	// Clock is used to expire values; SystemClock is used if nil.
+	Clock Clock
+}
+
+var DefaultCacheOptions = &CacheOptions{
+	TTL: time.Minute,
+}
+
+// Cached is a read-through cache of values read from a reader.
+type Cached struct {
+	r   Reader
+	key Key
+	c   *cache
+}
+
+type cache struct {
+	opts    *CacheOptions
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	clock   Clock
+}
+
+type cacheEntry struct {
+	value   any
+	err     error
+	expires time.Time
+}
+
+var (
+	_ Reader     = (*Cached)(nil)
+	_ SafeReader = (*Cached)(nil)
+	_ SafeLister = (*Cached)(nil)
+	_ ListerTo   = (*Cached)(nil)
+)
+
+func Cache(r Reader, opts *CacheOptions) *Cached {
+	if opts == nil {
+		opts = DefaultCacheOptions
+	}
+
+	return &Cached{
+		r: r,
+		c: &cache{
+			opts:    opts,
+			entries: make(map[string]cacheEntry),
+			clock:   clockOr(opts.Clock),
+		},
+	}
+}
+
+func (c *Cached) Type() Type {
+	return c.r.Type()
+}
+
+func (c *Cached) Get(ctx context.Context, key string) (any, bool) {
+	v, err := c.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (c *Cached) SafeGet(ctx context.Context, key string) (any, error) {
+	var (
+		full   = append(c.key.Copy(), key)
+		v, err = c.c.get(full.ID(), func() (any, error) {
+			return PrefixedReader{R: c.r}.SafeGet(ctx, key)
+		})
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Cached{r: r, key: full, c: c.c}, nil
+	}
+
+	return v, nil
+}
+
+func (c *Cached) List(ctx context.Context) []string {
+	keys, _ := c.SafeList(ctx)
+	return keys
+}
+
+func (c *Cached) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, c.r)
+}
+
+func (c *Cached) ListTo(ctx context.Context, keys *[]string) {
+	ListTo(ctx, c.r, keys)
+}
+
+// Invalidate removes the cached values of keys and their children.
+// All values are removed if no keys are given.
+func (c *Cached) Invalidate(keys ...Key) {
+	c.c.mu.Lock()
+	defer c.c.mu.Unlock()
+
+	if len(keys) == 0 {
+		c.c.entries = make(map[string]cacheEntry)
+		return
+	}
+
+	for _, key := range keys {
+		k := append(c.key.Copy(), key...).ID()
+
+		for e := range c.c.entries {
+			if strings.HasPrefix(e, k) {
+				delete(c.c.entries, e)
+			}
+		}
+	}
+}
+
+// Warm loads values of keys into the cache, in a single GetMany call.
+func (c *Cached) Warm(ctx context.Context, keys ...Key) error {
+	var nonempty = make([]Key, 0, len(keys))
+
+	for _, key := range keys {
+		if len(key) != 0 {
+			nonempty = append(nonempty, key)
+		}
+	}
+
+	if len(nonempty) == 0 {
+		return nil
+	}
+
+	var errs Errors
+
+	for _, res := range GetMany(ctx, c.r, nonempty...) {
+		_, err := c.c.put(append(c.key.Copy(), res.Key...).ID(), res.Value, res.Err)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.Err()
+}
+
+// WarmFromList loads all values of the subtree under prefix
+// into the cache, with a GetMany call per listed subtree.
+func (c *Cached) WarmFromList(ctx context.Context, prefix Key) error {
+	var r = c.r
+
+	if len(prefix) != 0 {
+		v, err := c.c.get(append(c.key.Copy(), prefix...).ID(), func() (any, error) {
+			return PrefixReader(c.r, prefix.Dir()...).SafeGet(ctx, prefix.Base())
+		})
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		if r, ok = v.(Reader); !ok {
+			return nil
+		}
+	}
+
+	return c.warm(ctx, r, prefix.Copy())
+}
+
+// warm loads the subtree of r, which is under the key, into the cache.
+func (c *Cached) warm(ctx context.Context, r Reader, key Key) error {
+	names, err := List(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var keys = make([]Key, len(names))
+
+	for i, name := range names {
+		keys[i] = Key{name}
+	}
+
+	var errs Errors
+
+	for _, res := range GetMany(ctx, r, keys...) {
+		full := append(key.Copy(), res.Key...)
+
+		v, err := c.c.put(append(c.key.Copy(), full...).ID(), res.Value, res.Err)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if r, ok := v.(Reader); ok {
+			if err := c.warm(ctx, r, full); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+func (c *Cached) Ping(ctx context.Context) error {
+	return Ping(ctx, c.r)
+}
+
+func (c *Cached) Close(ctx context.Context) error {
+	return Close(ctx, c.r)
+}
+
+func (c *Cached) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (c *Cached) Unwrap() any {
+	return c.r
+}
+
+func (c *cache) get(key string, fn func() (any, error)) (any, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && c.clock.Now().Before(e.expires) {
+		return e.value, e.err
+	}
+
+	v, err := fn()
+
+	return c.put(key, v, err)
+}
+
+// put caches the result of reading the key; errors other than
+// ErrNotFound are not cached.
+func (c *cache) put(key string, v any, err error) (any, error) {
+	var ttl = c.opts.TTL
+
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) || c.opts.NegativeTTL == 0 {
+			return nil, err
+		}
+
+		ttl = c.opts.NegativeTTL
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{
+		value:   v,
+		err:     err,
+		expires: c.clock.Now().Add(ttl),
+	}
+	c.mu.Unlock()
+
+	return v, err
+}
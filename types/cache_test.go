@@ -0,0 +1,170 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+type countMap struct {
+	types.Map
+	n int32
+}
+
+func (cm *countMap) Get(ctx context.Context, key string) (any, bool) {
+	atomic.AddInt32(&cm.n, 1)
+	return cm.Map.Get(ctx, key)
+}
+
+func (cm *countMap) count() int {
+	return int(atomic.LoadInt32(&cm.n))
+}
+
+func TestCache(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		clock = objectstest.NewClock(time.Now())
+		m     = &countMap{Map: types.Map{"host": "localhost"}}
+		c     = types.Cache(m, &types.CacheOptions{
+			TTL:         time.Minute,
+			NegativeTTL: 50 * time.Millisecond,
+			Clock:       clock,
+		})
+	)
+
+	for i := 0; i < 3; i++ {
+		if v, _ := c.Get(ctx, "host"); v != "localhost" {
+			t.Fatalf("got %#v, want %#v", v, "localhost")
+		}
+
+		if _, err := c.SafeGet(ctx, "port"); !errors.Is(err, types.ErrNotFound) {
+			t.Fatalf("got %+v, want %+v", err, types.ErrNotFound)
+		}
+	}
+
+	if n := m.count(); n != 2 {
+		t.Fatalf("got %d backend reads, want 2", n)
+	}
+
+	m.Map["port"] = 8080
+	m.Map["host"] = "example.com"
+
+	clock.Add(100 * time.Millisecond)
+
+	if v, _ := c.Get(ctx, "port"); v != 8080 {
+		t.Fatalf("got %#v, want %#v", v, 8080)
+	}
+
+	if v, _ := c.Get(ctx, "host"); v != "localhost" {
+		t.Fatalf("got %#v, want %#v", v, "localhost")
+	}
+
+	c.Invalidate(types.Key{"host"})
+
+	if v, _ := c.Get(ctx, "host"); v != "example.com" {
+		t.Fatalf("got %#v, want %#v", v, "example.com")
+	}
+}
+
+func TestCacheWarm(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = &countMap{Map: types.Map{
+			"server": types.Map{
+				"host": "localhost",
+				"port": 8080,
+			},
+			"debug": true,
+		}}
+		c = types.Cache(m, nil)
+	)
+
+	if err := c.Warm(ctx, types.Key{"debug"}, types.Key{"missing"}); err != nil {
+		t.Fatalf("Warm()=%+v", err)
+	}
+
+	if err := c.WarmFromList(ctx, types.Key{"server"}); err != nil {
+		t.Fatalf("WarmFromList()=%+v", err)
+	}
+
+	n := m.count()
+
+	if v, _ := c.Get(ctx, "debug"); v != true {
+		t.Fatalf("got %#v, want %#v", v, true)
+	}
+
+	if v, _ := types.PrefixReader(c, "server").Get(ctx, "port"); v != 8080 {
+		t.Fatalf("got %#v, want %#v", v, 8080)
+	}
+
+	if got := m.count(); got != n {
+		t.Fatalf("got %d backend reads after warm-up, want %d", got, n)
+	}
+}
+
+func TestCacheDottedKeys(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = types.Cache(types.Map{
+			"a.b": 1,
+			"a":   types.Map{"b": 2},
+		}, nil)
+	)
+
+	if v, _ := c.Get(ctx, "a.b"); v != 1 {
+		t.Fatalf("got %#v, want %#v", v, 1)
+	}
+
+	a, _ := c.Get(ctx, "a")
+
+	if v, _ := a.(types.Reader).Get(ctx, "b"); v != 2 {
+		t.Fatalf("got %#v, want %#v", v, 2)
+	}
+}
+
+// batchMap counts GetMany calls.
+type batchMap struct {
+	countMap
+	batches int32
+}
+
+func (bm *batchMap) GetMany(ctx context.Context, keys []types.Key) []types.Result {
+	atomic.AddInt32(&bm.batches, 1)
+	return types.GetMany(ctx, bm.Map, keys...)
+}
+
+func TestCacheWarmBatch(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = &batchMap{countMap: countMap{Map: types.Map{
+			"server": types.Map{"host": "localhost"},
+			"debug":  true,
+		}}}
+		c = types.Cache(m, nil)
+	)
+
+	if err := c.Warm(ctx, types.Key{"debug"}, types.Key{"missing"}); err != nil {
+		t.Fatalf("Warm()=%+v", err)
+	}
+
+	if err := c.WarmFromList(ctx, nil); err != nil {
+		t.Fatalf("WarmFromList()=%+v", err)
+	}
+
+	if n := atomic.LoadInt32(&m.batches); n != 2 {
+		t.Fatalf("got %d GetMany calls, want 2", n)
+	}
+
+	if v, _ := types.PrefixReader(c, "server").Get(ctx, "host"); v != "localhost" {
+		t.Fatalf("got %#v, want %#v", v, "localhost")
+	}
+
+	if n := m.count(); n != 0 {
+		t.Fatalf("got %d backend reads, want 0", n)
+	}
+}
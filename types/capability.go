@@ -0,0 +1,135 @@
+package types
+
+import "strings"
+
+type Capability uint
+
+const (
+	CapSafeReader Capability = 1 << iota
+	CapSafeLister
+	CapListerTo
+	CapWriter
+	CapSafeWriter
+	CapWatcher
+	CapLocker
+	CapPinger
+	CapCloser
+	CapValueLister
+	CapTransactor
+	CapBatchReader
+	CapBatchWriter
+)
+
+var capNames = []string{
+	"SafeReader",
+	"SafeLister",
+	"ListerTo",
+	"Writer",
+	"SafeWriter",
+	"Watcher",
+	"Locker",
+	"Pinger",
+	"Closer",
+	"ValueLister",
+	"Transactor",
+	"BatchReader",
+	"BatchWriter",
+}
+
+// Capabilities reports optional interfaces supported by v.
+//
+// The interfaces that extend a Reader or a Writer are reported only
+// if v implements them itself, as they must be called on v directly.
+// The remaining ones are resolved through the Unwrap chain of v,
+// in the same way As does, skipping wrappers which only forward them.
+func Capabilities(v any) Capability {
+	var c Capability
+
+	if _, ok := v.(SafeReader); ok {
+		c |= CapSafeReader
+	}
+	if _, ok := v.(SafeLister); ok {
+		c |= CapSafeLister
+	}
+	if _, ok := v.(ListerTo); ok {
+		c |= CapListerTo
+	}
+	if _, ok := v.(Writer); ok {
+		c |= CapWriter
+	}
+	if _, ok := v.(SafeWriter); ok {
+		c |= CapSafeWriter
+	}
+	if _, ok := v.(ValueLister); ok {
+		c |= CapValueLister
+	}
+	if _, ok := v.(Transactor); ok {
+		c |= CapTransactor
+	}
+	if _, ok := v.(BatchReader); ok {
+		c |= CapBatchReader
+	}
+	if _, ok := v.(BatchWriter); ok {
+		c |= CapBatchWriter
+	}
+
+	if resolve(v, CapWatcher, func(v any) bool { _, ok := v.(Watcher); return ok }) {
+		c |= CapWatcher
+	}
+	if resolve(v, CapLocker, func(v any) bool { _, ok := v.(Locker); return ok }) {
+		c |= CapLocker
+	}
+	if resolve(v, CapPinger, func(v any) bool { _, ok := v.(Pinger); return ok }) {
+		c |= CapPinger
+	}
+	if resolve(v, CapCloser, func(v any) bool { _, ok := v.(Closer); return ok }) {
+		c |= CapCloser
+	}
+
+	return c
+}
+
+// forwarder is implemented by wrappers, which methods of the returned
+// capabilities only forward the calls to the unwrapped value.
+type forwarder interface {
+	forwards() Capability
+}
+
+// resolve reports whether a value in the Unwrap chain of v implements
+// the capability c, other than by forwarding it.
+func resolve(v any, c Capability, is func(any) bool) bool {
+	const maxDepth = 128 // to prevent infinite loop on cyclic wrappers
+
+	for i := 0; i < maxDepth && v != nil; i++ {
+		if is(v) {
+			if f, ok := v.(forwarder); !ok || !f.forwards().Has(c) {
+				return true
+			}
+		}
+
+		u, ok := v.(Unwrapper)
+		if !ok {
+			return false
+		}
+
+		v = u.Unwrap()
+	}
+
+	return false
+}
+
+func (c Capability) Has(other Capability) bool {
+	return c&other == other
+}
+
+func (c Capability) String() string {
+	var names []string
+
+	for i, name := range capNames {
+		if c.Has(1 << i) {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,75 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+func TestCapabilities(t *testing.T) {
+	cases := []struct {
+		v    any
+		want string
+	}{
+		0: {
+			v:    newM(),
+			want: "SafeLister|ListerTo|Writer|ValueLister",
+		},
+		1: {
+			v:    types.Debounce(lockedMap{newM(), &types.Locks{}}, time.Second, nil),
+			want: "Writer|SafeWriter|Locker|Closer",
+		},
+		2: {
+			v:    types.NoCtx(newM()),
+			want: "",
+		},
+		3: {
+			v:    types.PrefixReader(types.Map{}),
+			want: "SafeReader|SafeLister|ListerTo|ValueLister",
+		},
+		4: {
+			v:    types.Cache(types.Observe(types.Map{}), nil),
+			want: "SafeReader|SafeLister|ListerTo|Watcher",
+		},
+	}
+
+	for _, cas := range cases {
+		t.Run("", func(t *testing.T) {
+			if got := types.Capabilities(cas.v).String(); got != cas.want {
+				t.Fatalf("got %q, want %q", got, cas.want)
+			}
+		})
+	}
+}
+
+func TestListerToWrappers(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{"a": 1, "b": 2}
+	)
+
+	cases := map[string]types.Reader{
+		"Cache":        types.Cache(m, nil),
+		"Sync":         types.Sync(m),
+		"Singleflight": types.Singleflight(m),
+		"Session":      types.NewSession(m, m, time.Second, nil),
+		"Limit":        types.Limit(m, nil),
+	}
+
+	for name, r := range cases {
+		t.Run(name, func(t *testing.T) {
+			if !types.Capabilities(r).Has(types.CapListerTo) {
+				t.Fatalf("got %q, want ListerTo", types.Capabilities(r))
+			}
+
+			keys := []string{"x"}
+			types.ListTo(ctx, r, &keys)
+
+			if got, want := len(keys), 3; got != want {
+				t.Fatalf("got %v, want %d keys", keys, want)
+			}
+		})
+	}
+}
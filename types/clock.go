@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+// Clock tells the time and schedules functions, so time-dependent
+// wrappers can be tested with a fake clock.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a function scheduled with Clock.AfterFunc.
+type Timer interface {
+	Stop() bool
+}
+
+// SystemClock is a Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func clockOr(c Clock) Clock {
+	if c != nil {
+		return c
+	}
+	return SystemClock
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"context"
+	"io"
+)
+
+// Close closes, in order, each of the given values which implements
+// Closer or io.Closer, and reports all the failures. An error value
+// is reported as is, which lets callers fold a preceding failure
+// into the result.
+func Close(ctx context.Context, vs ...any) error {
+	var errs Errors
+
+	for _, v := range vs {
+		var err error
+
+		switch v := v.(type) {
+		case nil:
+		case Closer:
+			err = v.Close(ctx)
+		case io.Closer:
+			err = v.Close()
+		case error:
+			err = v
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if e, ok := err.(Errors); ok {
+			errs = append(errs, e...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.Err()
+}
@@ -0,0 +1,46 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+type closeMap struct {
+	types.Map
+	closed *int
+	err    error
+}
+
+func (cm closeMap) Close() error {
+	*cm.closed++
+	return cm.err
+}
+
+func TestClose(t *testing.T) {
+	var (
+		n        int
+		errClose = errors.New("close failed")
+		ctx      = context.Background()
+		d        = types.Debounce(closeMap{Map: newM(), closed: &n, err: errClose}, time.Hour, nil)
+	)
+
+	d.Set(ctx, "pending", true)
+
+	err := types.Close(ctx,
+		types.PrefixReader(closeMap{Map: newM(), closed: &n}, "foo"),
+		types.Edit(closeMap{Map: newM(), closed: &n}),
+		d,
+	)
+
+	if !errors.Is(err, errClose) {
+		t.Fatalf("got %+v, want %+v", err, errClose)
+	}
+
+	if n != 3 {
+		t.Fatalf("got %d, want %d", n, 3)
+	}
+}
@@ -0,0 +1,212 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+type ComputeFunc func(ctx context.Context, root Reader) (any, error)
+
+// Computed serves virtual keys, which values are computed on every
+// read by calling a func with the root reader. Virtual keys can be
+// nested, e.g. "server.url", and cannot be written to.
+type Computed struct {
+	r    Reader
+	root Reader
+	fns  map[string]ComputeFunc
+}
+
+var (
+	_ Interface  = (*Computed)(nil)
+	_ SafeReader = (*Computed)(nil)
+	_ SafeWriter = (*Computed)(nil)
+	_ SafeLister = (*Computed)(nil)
+)
+
+func Compute(r Reader, fns map[string]ComputeFunc) *Computed {
+	return &Computed{
+		r:    r,
+		root: r,
+		fns:  fns,
+	}
+}
+
+func (c *Computed) Type() Type {
+	return c.r.Type()
+}
+
+func (c *Computed) Get(ctx context.Context, key string) (any, bool) {
+	v, err := c.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (c *Computed) SafeGet(ctx context.Context, key string) (any, error) {
+	if fn, ok := c.fns[key]; ok {
+		v, err := fn(ctx, c.root)
+		if err != nil {
+			return nil, &Error{
+				Op:  "Get",
+				Key: []string{key},
+				Err: err,
+			}
+		}
+
+		return v, nil
+	}
+
+	var (
+		fns    = c.children(key)
+		v, err = PrefixedReader{R: c.r}.SafeGet(ctx, key)
+	)
+
+	if len(fns) == 0 {
+		return v, err
+	}
+
+	r, ok := v.(Reader)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		r, ok = make(Map), true
+	case err != nil:
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &Error{
+			Op:   "Get",
+			Key:  []string{key},
+			Got:  v,
+			Want: Reader(nil),
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	return &Computed{
+		r:    r,
+		root: c.root,
+		fns:  fns,
+	}, nil
+}
+
+func (c *Computed) List(ctx context.Context) []string {
+	keys, _ := c.SafeList(ctx)
+	return keys
+}
+
+func (c *Computed) SafeList(ctx context.Context) ([]string, error) {
+	keys, err := List(ctx, c.r)
+	if err != nil {
+		return nil, err
+	}
+
+	var seen = make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		seen[k] = struct{}{}
+	}
+
+	for k := range c.fns {
+		if i := strings.IndexByte(k, '.'); i != -1 {
+			k = k[:i]
+		}
+
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (c *Computed) Del(ctx context.Context, key string) bool {
+	return c.SafeDel(ctx, key) == nil
+}
+
+func (c *Computed) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := c.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (c *Computed) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := c.SafePut(ctx, key, hint)
+	return w
+}
+
+func (c *Computed) SafeDel(ctx context.Context, key string) error {
+	if err := c.check("Del", key); err != nil {
+		return err
+	}
+
+	return PrefixedWriter{W: c.writer()}.SafeDel(ctx, key)
+}
+
+func (c *Computed) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if err := c.check("Set", key); err != nil {
+		return false, err
+	}
+
+	return PrefixedWriter{W: c.writer()}.SafeSet(ctx, key, value)
+}
+
+func (c *Computed) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	if err := c.check("Put", key); err != nil {
+		return nil, err
+	}
+
+	w, err := PrefixedWriter{W: c.writer()}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if fns := c.children(key); len(fns) != 0 {
+		if r, ok := w.(Reader); ok {
+			return &Computed{r: r, root: c.root, fns: fns}, nil
+		}
+	}
+
+	return w, nil
+}
+
+func (c *Computed) Unwrap() any {
+	return c.r
+}
+
+func (c *Computed) check(op, key string) error {
+	if _, ok := c.fns[key]; !ok {
+		return nil
+	}
+
+	return &Error{
+		Op:  op,
+		Key: []string{key},
+		Err: ErrReadOnly,
+	}
+}
+
+func (c *Computed) children(key string) map[string]ComputeFunc {
+	var fns map[string]ComputeFunc
+
+	for k, fn := range c.fns {
+		if strings.HasPrefix(k, key+".") {
+			if fns == nil {
+				fns = make(map[string]ComputeFunc)
+			}
+			fns[k[len(key)+1:]] = fn
+		}
+	}
+
+	return fns
+}
+
+func (c *Computed) writer() Writer {
+	if w, ok := c.r.(Writer); ok {
+		return w
+	}
+	return nil
+}
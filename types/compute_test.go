@@ -0,0 +1,94 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompute(t *testing.T) {
+	var (
+		m = types.Map{
+			"server": types.Map{
+				"host": "localhost",
+				"port": 8080,
+			},
+		}
+		c = types.Compute(m, map[string]types.ComputeFunc{
+			"server.url": func(ctx context.Context, root types.Reader) (any, error) {
+				var (
+					pr      = types.PrefixReader(root, "server")
+					host, _ = pr.Get(ctx, "host")
+					port, _ = pr.Get(ctx, "port")
+				)
+				return fmt.Sprintf("http://%s:%d", host, port), nil
+			},
+			"version": func(context.Context, types.Reader) (any, error) {
+				return "v1", nil
+			},
+		})
+		pr  = types.PrefixReader(c, "server")
+		pw  = types.PrefixWriter(c, "server")
+		ctx = context.Background()
+	)
+
+	if got, want := c.List(ctx), []string{"server", "version"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if got, want := pr.List(ctx), []string{"host", "port", "url"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if _, err := pw.SafeSet(ctx, "port", 9090); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	v, err := pr.SafeGet(ctx, "url")
+	if err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	if want := "http://localhost:9090"; v != want {
+		t.Fatalf("got %#v, want %#v", v, want)
+	}
+
+	if _, err := pw.SafeSet(ctx, "url", "http://example.com"); !errors.Is(err, types.ErrReadOnly) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrReadOnly)
+	}
+
+	if err := c.SafeDel(ctx, "version"); !errors.Is(err, types.ErrReadOnly) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrReadOnly)
+	}
+}
+
+// failMap is a map, which backend fails to be read.
+type failMap struct {
+	types.Map
+	err error
+}
+
+func (m failMap) SafeGet(context.Context, string) (any, error) {
+	return nil, m.err
+}
+
+func TestComputeError(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		want = errors.New("backend failed")
+		c    = types.Compute(failMap{Map: types.Map{}, err: want}, map[string]types.ComputeFunc{
+			"server.url": func(context.Context, types.Reader) (any, error) {
+				return "http://localhost", nil
+			},
+		})
+	)
+
+	if v, err := c.SafeGet(ctx, "server"); !errors.Is(err, want) {
+		t.Fatalf("SafeGet()=%#v, %+v, want %v", v, err, want)
+	}
+}
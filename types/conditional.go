@@ -0,0 +1,313 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Conditional is a read-only view of a tree with conditional keys
+// resolved against attributes, like env or region.
+//
+// A key "name[k=v,...]" is a variant of the "name" key, which
+// overrides it when all of its conditions match the attributes; of
+// several matching variants, the one with most conditions wins. A key
+// of conditions only, like "[env=prod]", holds a block overriding the
+// keys next to it. Maps are resolved as merged views of the matching
+// variants and blocks over the base value, so that e.g.
+//
+//	db:
+//	  host: localhost
+//	  port: 5432
+//	db[env=prod]:
+//	  host: db.prod
+//
+// reads as db.host "db.prod" and db.port 5432 for env=prod.
+type Conditional struct {
+	layers []Reader // in order of precedence
+	attrs  map[string]string
+}
+
+var (
+	_ Reader     = (*Conditional)(nil)
+	_ SafeReader = (*Conditional)(nil)
+	_ SafeLister = (*Conditional)(nil)
+)
+
+// Conditions wraps r with resolution of conditional keys against
+// the attributes, which may be overridden with WithAttributes.
+func Conditions(r Reader, attrs map[string]string) *Conditional {
+	return &Conditional{
+		layers: []Reader{r},
+		attrs:  attrs,
+	}
+}
+
+type attributesKey struct{}
+
+// WithAttributes sets attributes for conditions of Conditional,
+// on top of the ones already set in ctx.
+func WithAttributes(ctx context.Context, attrs map[string]string) context.Context {
+	merged := make(map[string]string)
+
+	for k, v := range Attributes(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range attrs {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+// Attributes gives attributes set with WithAttributes.
+func Attributes(ctx context.Context) map[string]string {
+	attrs, _ := ctx.Value(attributesKey{}).(map[string]string)
+	return attrs
+}
+
+func (c *Conditional) Type() Type {
+	return c.layers[0].Type()
+}
+
+func (c *Conditional) Get(ctx context.Context, key string) (any, bool) {
+	v, err := c.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (c *Conditional) SafeGet(ctx context.Context, key string) (any, error) {
+	layers, err := c.resolve(ctx)
+	if err != nil {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	var readers []Reader
+
+	for _, r := range layers {
+		keys, err := List(ctx, r)
+		if err != nil {
+			return nil, &Error{
+				Op:  "Get",
+				Key: []string{key},
+				Err: err,
+			}
+		}
+
+		for _, k := range append(c.variants(ctx, keys, key), key) {
+			v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			vr, ok := v.(Reader)
+			if !ok {
+				if vr = Make(v); vr == nil {
+					if len(readers) == 0 {
+						return v, nil
+					}
+
+					// The leaf shadows maps of lower precedence.
+					return c.child(readers), nil
+				}
+			}
+
+			readers = append(readers, vr)
+		}
+	}
+
+	if len(readers) == 0 {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	return c.child(readers), nil
+}
+
+func (c *Conditional) List(ctx context.Context) []string {
+	keys, _ := c.SafeList(ctx)
+	return keys
+}
+
+// SafeList lists names of plain keys and of matching variants.
+func (c *Conditional) SafeList(ctx context.Context) ([]string, error) {
+	layers, err := c.resolve(ctx)
+	if err != nil {
+		return nil, &Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	var (
+		attrs = c.attributes(ctx)
+		seen  = make(map[string]struct{})
+		keys  []string
+	)
+
+	for _, r := range layers {
+		all, err := List(ctx, r)
+		if err != nil {
+			return nil, &Error{
+				Op:  "List",
+				Err: err,
+			}
+		}
+
+		for _, k := range all {
+			if name, conds, ok := parseConditional(k); ok {
+				if name == "" || !match(conds, attrs) {
+					continue
+				}
+				k = name
+			}
+
+			if _, ok := seen[k]; ok {
+				continue
+			}
+
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	if c.Type() == TypeSlice {
+		sort.Slice(keys, func(i, j int) bool {
+			m, _ := strconv.Atoi(keys[i])
+			n, _ := strconv.Atoi(keys[j])
+			return m < n
+		})
+	} else {
+		sort.Strings(keys)
+	}
+
+	return keys, nil
+}
+
+func (c *Conditional) Unwrap() any {
+	return c.layers[0]
+}
+
+// resolve gives the layers, each preceded by its matching blocks.
+func (c *Conditional) resolve(ctx context.Context) ([]Reader, error) {
+	var layers []Reader
+
+	for _, r := range c.layers {
+		keys, err := List(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, k := range c.variants(ctx, keys, "") {
+			v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+			if err != nil {
+				return nil, err
+			}
+
+			if vr, ok := v.(Reader); ok {
+				layers = append(layers, vr)
+			} else if vr := Make(v); vr != nil {
+				layers = append(layers, vr)
+			}
+		}
+
+		layers = append(layers, r)
+	}
+
+	return layers, nil
+}
+
+// variants gives the keys of matching variants of the name, most
+// specific first.
+func (c *Conditional) variants(ctx context.Context, keys []string, name string) []string {
+	var (
+		attrs = c.attributes(ctx)
+		vs    []string
+		n     = make(map[string]int)
+	)
+
+	for _, k := range keys {
+		if base, conds, ok := parseConditional(k); ok && base == name && match(conds, attrs) {
+			vs = append(vs, k)
+			n[k] = len(conds)
+		}
+	}
+
+	sort.SliceStable(vs, func(i, j int) bool {
+		return n[vs[i]] > n[vs[j]]
+	})
+
+	return vs
+}
+
+func (c *Conditional) attributes(ctx context.Context) map[string]string {
+	from := Attributes(ctx)
+
+	if len(from) == 0 {
+		return c.attrs
+	}
+
+	attrs := make(map[string]string, len(c.attrs)+len(from))
+
+	for k, v := range c.attrs {
+		attrs[k] = v
+	}
+
+	for k, v := range from {
+		attrs[k] = v
+	}
+
+	return attrs
+}
+
+func (c *Conditional) child(layers []Reader) *Conditional {
+	return &Conditional{
+		layers: layers,
+		attrs:  c.attrs,
+	}
+}
+
+// parseConditional splits the "name[k=v,...]" key into the name and
+// the conditions.
+func parseConditional(key string) (string, map[string]string, bool) {
+	i := strings.IndexByte(key, '[')
+
+	if i == -1 || !strings.HasSuffix(key, "]") {
+		return "", nil, false
+	}
+
+	conds := make(map[string]string)
+
+	for _, s := range strings.Split(key[i+1:len(key)-1], ",") {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok {
+			return "", nil, false
+		}
+
+		conds[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return key[:i], conds, true
+}
+
+func match(conds, attrs map[string]string) bool {
+	for k, v := range conds {
+		if attr, ok := attrs[k]; !ok || attr != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConditional(t *testing.T) {
+	var (
+		m = types.Map{
+			"db": types.Map{
+				"host": "localhost",
+				"port": "5432",
+			},
+			"db[env=prod]": types.Map{
+				"host": "db.prod",
+			},
+			"db[env=prod,region=eu]": types.Map{
+				"host": "db.prod.eu",
+			},
+			"debug":           true,
+			"debug[env=prod]": false,
+			"[region=eu]": types.Map{
+				"currency": "EUR",
+			},
+			"currency": "USD",
+		}
+		c   = types.Conditions(m, map[string]string{"env": "dev"})
+		ctx = context.Background()
+	)
+
+	get := func(ctx context.Context, keys ...string) any {
+		t.Helper()
+
+		v, err := types.PrefixReader(c, keys[:len(keys)-1]...).SafeGet(ctx, keys[len(keys)-1])
+		if err != nil {
+			t.Fatalf("SafeGet()=%+v", err)
+		}
+
+		return v
+	}
+
+	if got, want := c.List(ctx), []string{"currency", "db", "debug"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	cases := []struct {
+		attrs map[string]string
+		keys  []string
+		want  any
+	}{
+		{nil, []string{"db", "host"}, "localhost"},
+		{nil, []string{"debug"}, true},
+		{nil, []string{"currency"}, "USD"},
+		{map[string]string{"env": "prod"}, []string{"db", "host"}, "db.prod"},
+		{map[string]string{"env": "prod"}, []string{"db", "port"}, "5432"},
+		{map[string]string{"env": "prod"}, []string{"debug"}, false},
+		{map[string]string{"env": "prod", "region": "eu"}, []string{"db", "host"}, "db.prod.eu"},
+		{map[string]string{"region": "eu"}, []string{"currency"}, "EUR"},
+	}
+
+	for _, cas := range cases {
+		got := get(types.WithAttributes(ctx, cas.attrs), cas.keys...)
+
+		if !cmp.Equal(got, cas.want) {
+			t.Errorf("%v %v: got %v, want %v", cas.attrs, cas.keys, got, cas.want)
+		}
+	}
+
+	if _, err := c.SafeGet(ctx, "db[env=prod]"); err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	if _, err := c.SafeGet(ctx, "missing"); err == nil {
+		t.Fatal("expected SafeGet() to fail")
+	}
+}
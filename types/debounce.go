@@ -0,0 +1,360 @@
+package types
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Debounced coalesces Sets of the same key made within a window
+// into a single write to the wrapped Writer, where the last value wins.
+type Debounced struct {
+	w   Writer
+	key Key
+	d   *debouncer
+}
+
+type debouncer struct {
+	window  time.Duration
+	clock   Clock
+	mu      sync.Mutex
+	pending map[string]*debounce
+	errs    Errors
+	closed  bool
+
+	// inflight are the writes started by timers, idle is closed
+	// once the last of them is done.
+	inflight map[*debounce]struct{}
+	idle     chan struct{}
+}
+
+type debounce struct {
+	w     Writer
+	key   Key
+	value any
+	timer Timer
+}
+
+var (
+	_ Writer     = (*Debounced)(nil)
+	_ SafeWriter = (*Debounced)(nil)
+)
+
+// Debounce wraps w, scheduling writes with the clock, which defaults
+// to SystemClock when nil.
+func Debounce(w Writer, window time.Duration, clock Clock) *Debounced {
+	return &Debounced{
+		w: w,
+		d: &debouncer{
+			window:   window,
+			clock:    clockOr(clock),
+			pending:  make(map[string]*debounce),
+			inflight: make(map[*debounce]struct{}),
+		},
+	}
+}
+
+func (d *Debounced) Del(ctx context.Context, key string) bool {
+	return d.SafeDel(ctx, key) == nil
+}
+
+// Set schedules the write and reports whether it replaced a pending value.
+func (d *Debounced) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := d.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (d *Debounced) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := d.SafePut(ctx, key, hint)
+	return w
+}
+
+func (d *Debounced) SafeDel(ctx context.Context, key string) error {
+	if err := d.d.check("Del", d.path(key)); err != nil {
+		return err
+	}
+
+	d.d.cancel(d.path(key), true)
+
+	if sw, ok := d.w.(SafeWriter); ok {
+		return sw.SafeDel(ctx, key)
+	}
+
+	if !d.w.Del(ctx, key) {
+		return &Error{
+			Op:  "Del",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	return nil
+}
+
+func (d *Debounced) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	var (
+		path = d.path(key)
+		id   = path.ID()
+	)
+
+	d.d.mu.Lock()
+	defer d.d.mu.Unlock()
+
+	if d.d.closed {
+		return false, &Error{
+			Op:  "Set",
+			Key: path,
+			Err: ErrClosed,
+		}
+	}
+
+	if p, ok := d.d.pending[id]; ok {
+		p.value = value
+		return true, nil
+	}
+
+	d.d.pending[id] = &debounce{
+		w:     d.w,
+		key:   path,
+		value: value,
+		timer: d.d.clock.AfterFunc(d.d.window, func() { d.d.fire(id) }),
+	}
+
+	return false, nil
+}
+
+func (d *Debounced) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	var (
+		w   Writer
+		err error
+	)
+
+	if err := d.d.check("Put", d.path(key)); err != nil {
+		return nil, err
+	}
+
+	d.d.cancel(d.path(key), false)
+
+	if sw, ok := d.w.(SafeWriter); ok {
+		if w, err = sw.SafePut(ctx, key, hint); err != nil {
+			return nil, err
+		}
+	} else {
+		w = d.w.Put(ctx, key, hint)
+	}
+
+	return &Debounced{
+		w:   w,
+		key: d.path(key),
+		d:   d.d,
+	}, nil
+}
+
+// Flush writes all pending values immediately and waits for the
+// background writes in progress. The returned Errors also include
+// failures of background writes since the last Flush; values left
+// unwritten once ctx is done are reported with ctx.Err().
+func (d *Debounced) Flush(ctx context.Context) error {
+	d.d.mu.Lock()
+	pending := d.d.drain()
+	d.d.mu.Unlock()
+
+	var errs Errors
+
+	for _, p := range pending {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, &Error{
+				Op:  "Set",
+				Key: p.key,
+				Got: p.value,
+				Err: err,
+			})
+			continue
+		}
+
+		if err := p.write(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, d.d.wait(ctx)...)
+
+	d.d.mu.Lock()
+	errs = append(d.d.errs, errs...)
+	d.d.errs = nil
+	d.d.mu.Unlock()
+
+	return errs.Err()
+}
+
+// Close flushes pending values like Flush does, after which
+// the Debounced rejects any further mutation with ErrClosed
+// and closes the wrapped Writer.
+func (d *Debounced) Close(ctx context.Context) error {
+	d.d.mu.Lock()
+	d.d.closed = true
+	d.d.mu.Unlock()
+
+	return Close(ctx, d.Flush(ctx), d.w)
+}
+
+func (d *Debounced) path(key string) Key {
+	return append(d.key.Copy(), key)
+}
+
+func (d *debouncer) fire(id string) {
+	d.mu.Lock()
+	p, ok := d.pending[id]
+	if ok {
+		delete(d.pending, id)
+		d.inflight[p] = struct{}{}
+	}
+	d.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	err := p.write(context.Background())
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err != nil {
+		d.errs = append(d.errs, err)
+	}
+
+	delete(d.inflight, p)
+
+	if len(d.inflight) == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// wait waits for the writes in progress, reporting those still
+// running once ctx is done with ctx.Err().
+func (d *debouncer) wait(ctx context.Context) Errors {
+	d.mu.Lock()
+	if len(d.inflight) == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var running = make([]*debounce, 0, len(d.inflight))
+
+	for p := range d.inflight {
+		running = append(running, p)
+	}
+
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].key.Compare(running[j].key) < 0
+	})
+
+	var errs Errors
+
+	for _, p := range running {
+		errs = append(errs, &Error{
+			Op:  "Set",
+			Key: p.key,
+			Got: p.value,
+			Err: ctx.Err(),
+		})
+	}
+
+	return errs
+}
+
+func (d *debouncer) check(op string, key Key) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return &Error{
+			Op:  op,
+			Key: key,
+			Err: ErrClosed,
+		}
+	}
+
+	return nil
+}
+
+func (d *debouncer) cancel(key Key, subtree bool) {
+	var id = key.ID()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, p := range d.pending {
+		if k == id || (subtree && strings.HasPrefix(k, id)) {
+			p.timer.Stop()
+			delete(d.pending, k)
+		}
+	}
+}
+
+func (d *debouncer) drain() []*debounce {
+	var pending = make([]*debounce, 0, len(d.pending))
+
+	for id, p := range d.pending {
+		p.timer.Stop()
+		pending = append(pending, p)
+		delete(d.pending, id)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].key.Compare(pending[j].key) < 0
+	})
+
+	return pending
+}
+
+func (p *debounce) write(ctx context.Context) error {
+	var key = p.key.Base()
+
+	if sw, ok := p.w.(SafeWriter); ok {
+		if _, err := sw.SafeSet(ctx, key, p.value); err != nil {
+			return &Error{
+				Op:  "Set",
+				Key: p.key,
+				Got: p.value,
+				Err: err,
+			}
+		}
+
+		return nil
+	}
+
+	p.w.Set(ctx, key, p.value)
+
+	return nil
+}
+
+func (d *Debounced) Ping(ctx context.Context) error {
+	return Ping(ctx, d.w)
+}
+
+func (d *Debounced) forwards() Capability {
+	return CapPinger
+}
+
+func (d *Debounced) Unwrap() any {
+	return d.w
+}
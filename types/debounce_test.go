@@ -0,0 +1,176 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+func TestDebounce(t *testing.T) {
+	var (
+		m   = newM()
+		d   = types.Debounce(m, time.Hour, nil)
+		ctx = context.Background()
+	)
+
+	w, err := d.SafePut(ctx, "foo", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if ok := w.Set(ctx, "slider", i); ok != (i != 0) {
+			t.Fatalf("got %t, want %t", ok, i != 0)
+		}
+	}
+
+	if _, ok := m["foo"].(types.Map)["slider"]; ok {
+		t.Fatal("expected write to be pending")
+	}
+
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("Flush()=%+v", err)
+	}
+
+	if got, want := m["foo"].(types.Map)["slider"], 2; got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+
+	var (
+		cw    = make(chanWriter, 1)
+		clock = objectstest.NewClock(time.Now())
+	)
+
+	d = types.Debounce(cw, time.Millisecond, clock)
+	d.Set(ctx, "top", "value")
+
+	if len(cw) != 0 {
+		t.Fatal("expected write to be pending")
+	}
+
+	clock.Add(time.Millisecond)
+
+	select {
+	case got := <-cw:
+		if got != "value" {
+			t.Fatalf("got %#v, want %#v", got, "value")
+		}
+	default:
+		t.Fatal("expected write once the window passed")
+	}
+}
+
+type chanWriter chan any
+
+func (cw chanWriter) Del(context.Context, string) bool { return false }
+
+func (cw chanWriter) Set(_ context.Context, _ string, value any) bool {
+	cw <- value
+	return false
+}
+
+func (cw chanWriter) Put(context.Context, string, types.Type) types.Writer { return cw }
+
+func TestDebounceClose(t *testing.T) {
+	var (
+		m           = make(types.Map)
+		d           = types.Debounce(m, time.Hour, nil)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	d.Set(ctx, "a", 1)
+	d.Set(ctx, "b", 2)
+
+	cancel()
+
+	err := d.Close(ctx)
+
+	var errs types.Errors
+
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %+v, want 2 errors", err)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %+v, want %+v", err, context.Canceled)
+	}
+
+	if len(m) != 0 {
+		t.Fatalf("got %v, want empty map", m)
+	}
+
+	if _, err := d.SafeSet(context.Background(), "a", 1); !errors.Is(err, types.ErrClosed) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrClosed)
+	}
+}
+
+func TestDebounceDottedKeys(t *testing.T) {
+	var (
+		m   = types.Map{}
+		d   = types.Debounce(m, time.Hour, nil)
+		ctx = context.Background()
+	)
+
+	w, err := d.SafePut(ctx, "a", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	if ok := d.Set(ctx, "a.b", 1); ok {
+		t.Fatal("got true, want false")
+	}
+
+	if ok := w.Set(ctx, "b", 2); ok {
+		t.Fatal("writes to colliding keys were coalesced")
+	}
+
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("Flush()=%+v", err)
+	}
+
+	if got := m["a.b"]; got != 1 {
+		t.Fatalf("got %#v, want %#v", got, 1)
+	}
+
+	if got := m["a"].(types.Map)["b"]; got != 2 {
+		t.Fatalf("got %#v, want %#v", got, 2)
+	}
+}
+
+func TestDebounceCloseInflight(t *testing.T) {
+	var (
+		clock = objectstest.NewClock(time.Now())
+		gw    = &gateWriter{started: make(chan struct{}, 1), gate: make(chan struct{})}
+		d     = types.Debounce(gw, time.Second, clock)
+		ctx   = context.Background()
+	)
+
+	d.Set(ctx, "a", 1)
+
+	go clock.Add(time.Second)
+	<-gw.started
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if err := d.Flush(cctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Flush()=%+v, want %v", err, context.Canceled)
+	}
+
+	go close(gw.gate)
+
+	if err := d.Close(ctx); err != nil {
+		t.Fatalf("Close()=%+v", err)
+	}
+
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if got, want := gw.keys, []string{"a"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got %v, want %v written before Close returned", got, want)
+	}
+}
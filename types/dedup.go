@@ -0,0 +1,317 @@
+package types
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ContentStore keeps values by the hash of their content, counting
+// references to each of them.
+type ContentStore struct {
+	mu     sync.Mutex
+	values map[string]*contentEntry
+}
+
+type contentEntry struct {
+	value any
+	refs  int
+}
+
+func NewContentStore() *ContentStore {
+	return &ContentStore{
+		values: make(map[string]*contentEntry),
+	}
+}
+
+// Acquire stores the value, if not already stored, and adds
+// a reference to it.
+func (s *ContentStore) Acquire(v any) string {
+	var (
+		sum = sha256.Sum256([]byte(fmt.Sprintf("%T:%v", v, v)))
+		id  = hex.EncodeToString(sum[:])
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.values[id]
+	if !ok {
+		e = &contentEntry{value: v}
+		s.values[id] = e
+	}
+
+	e.refs++
+
+	return id
+}
+
+// Lookup gives the value stored under the id.
+func (s *ContentStore) Lookup(id string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.values[id]; ok {
+		return e.value, true
+	}
+
+	return nil, false
+}
+
+// Release removes a reference to the value and removes the value
+// after its last reference is released.
+func (s *ContentStore) Release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.values[id]; ok {
+		if e.refs--; e.refs <= 0 {
+			delete(s.values, id)
+		}
+	}
+}
+
+// Len gives the number of stored values.
+func (s *ContentStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.values)
+}
+
+type DedupOptions struct {
+	// Prefix marks references to stored values in the tree.
+	Prefix string
+}
+
+var DefaultDedupOptions = &DedupOptions{
+	Prefix: "cas:",
+}
+
+// Deduped keeps leaf values of an Interface in a ContentStore,
+// so identical values are stored once and the tree holds only
+// references to them. Overwritten and deleted values are released.
+type Deduped struct {
+	iface Interface
+	d     *dedup
+}
+
+type dedup struct {
+	opts  *DedupOptions
+	store *ContentStore
+}
+
+var (
+	_ Interface  = (*Deduped)(nil)
+	_ SafeReader = (*Deduped)(nil)
+	_ SafeLister = (*Deduped)(nil)
+	_ SafeWriter = (*Deduped)(nil)
+)
+
+func Dedup(iface Interface, store *ContentStore, opts *DedupOptions) *Deduped {
+	if opts == nil {
+		opts = DefaultDedupOptions
+	}
+
+	return &Deduped{
+		iface: iface,
+		d: &dedup{
+			opts:  opts,
+			store: store,
+		},
+	}
+}
+
+func (d *Deduped) Type() Type {
+	return d.iface.Type()
+}
+
+func (d *Deduped) Get(ctx context.Context, key string) (any, bool) {
+	v, err := d.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (d *Deduped) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: d.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := v.(Interface); ok {
+		return &Deduped{iface: iface, d: d.d}, nil
+	}
+
+	id, ok := d.d.ref(v)
+	if !ok {
+		return v, nil
+	}
+
+	if v, ok := d.d.store.Lookup(id); ok {
+		return v, nil
+	}
+
+	return nil, &Error{
+		Op:  "Get",
+		Key: []string{key},
+		Got: v,
+		Err: ErrNotFound,
+	}
+}
+
+func (d *Deduped) List(ctx context.Context) []string {
+	keys, _ := d.SafeList(ctx)
+	return keys
+}
+
+func (d *Deduped) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, d.iface)
+}
+
+func (d *Deduped) Del(ctx context.Context, key string) bool {
+	return d.SafeDel(ctx, key) == nil
+}
+
+func (d *Deduped) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := d.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (d *Deduped) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := d.SafePut(ctx, key, hint)
+	return w
+}
+
+func (d *Deduped) SafeDel(ctx context.Context, key string) error {
+	old, _ := PrefixedReader{R: d.iface}.SafeGet(ctx, key)
+
+	if err := (PrefixedWriter{W: d.iface}).SafeDel(ctx, key); err != nil {
+		return err
+	}
+
+	d.d.release(ctx, old)
+
+	return nil
+}
+
+func (d *Deduped) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	var (
+		old, _ = PrefixedReader{R: d.iface}.SafeGet(ctx, key)
+		v      = d.d.acquire(ctx, value)
+	)
+
+	ok, err := PrefixedWriter{W: d.iface}.SafeSet(ctx, key, v)
+	if err != nil {
+		d.d.release(ctx, v)
+		return false, err
+	}
+
+	d.d.release(ctx, old)
+
+	return ok, nil
+}
+
+func (d *Deduped) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	w, err := PrefixedWriter{W: d.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return &Deduped{iface: iface, d: d.d}, nil
+	}
+
+	return w, nil
+}
+
+func (d *Deduped) Ping(ctx context.Context) error {
+	return Ping(ctx, d.iface)
+}
+
+func (d *Deduped) Close(ctx context.Context) error {
+	return Close(ctx, d.iface)
+}
+
+func (d *Deduped) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (d *Deduped) Unwrap() any {
+	return d.iface
+}
+
+// acquire replaces leaves of v with references, copying readers
+// into plain maps and slices.
+func (d *dedup) acquire(ctx context.Context, v any) any {
+	r, ok := v.(Reader)
+	if !ok {
+		return d.opts.Prefix + d.store.Acquire(v)
+	}
+
+	var (
+		keys, _ = List(ctx, r)
+		m       Map
+		s       Slice
+	)
+
+	if r.Type() != TypeSlice {
+		m = make(Map, len(keys))
+	}
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			continue
+		}
+
+		if m != nil {
+			m[k] = d.acquire(ctx, v)
+		} else {
+			s = append(s, d.acquire(ctx, v))
+		}
+	}
+
+	if m != nil {
+		return m
+	}
+
+	return &s
+}
+
+// release releases references held by v and its children.
+func (d *dedup) release(ctx context.Context, v any) {
+	if r, ok := v.(Reader); ok {
+		keys, _ := List(ctx, r)
+
+		for _, k := range keys {
+			v, _ := PrefixedReader{R: r}.SafeGet(ctx, k)
+			d.release(ctx, v)
+		}
+
+		return
+	}
+
+	if id, ok := d.ref(v); ok {
+		d.store.Release(id)
+	}
+}
+
+// ref gives the ID referenced by v. Strings, which start with
+// the prefix, but are not followed by an ID given by the store,
+// are not references.
+func (d *dedup) ref(v any) (string, bool) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, d.opts.Prefix) {
+		return "", false
+	}
+
+	id := strings.TrimPrefix(s, d.opts.Prefix)
+
+	if b, err := hex.DecodeString(id); err != nil || len(b) != sha256.Size {
+		return "", false
+	}
+
+	return id, true
+}
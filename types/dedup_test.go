@@ -0,0 +1,84 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/types"
+)
+
+func TestDedup(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		m     = types.Map{}
+		store = types.NewContentStore()
+		d     = types.Dedup(m, store, nil)
+		flags = types.Map{
+			"beta":    true,
+			"dark":    true,
+			"limit":   100,
+			"release": "2024-01",
+		}
+	)
+
+	for _, k := range []string{"us", "eu", "ap"} {
+		if _, err := d.SafeSet(ctx, k, flags); err != nil {
+			t.Fatalf("SafeSet(%q)=%+v", k, err)
+		}
+	}
+
+	if n := store.Len(); n != 3 {
+		t.Fatalf("got %d values, want 3", n)
+	}
+
+	for _, k := range []string{"us", "eu", "ap"} {
+		for fk, want := range flags {
+			got, err := types.PrefixReader(d, k).SafeGet(ctx, fk)
+			if err != nil {
+				t.Fatalf("SafeGet(%q, %q)=%+v", k, fk, err)
+			}
+
+			if got != want {
+				t.Fatalf("%s.%s: got %#v, want %#v", k, fk, got, want)
+			}
+		}
+	}
+
+	if _, err := types.PrefixWriter(d, "us").SafeSet(ctx, "limit", 200); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if n := store.Len(); n != 4 {
+		t.Fatalf("got %d values, want 4", n)
+	}
+
+	if err := d.SafeDel(ctx, "us"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if n := store.Len(); n != 3 {
+		t.Fatalf("got %d values, want 3", n)
+	}
+
+	for _, k := range []string{"eu", "ap"} {
+		if err := d.SafeDel(ctx, k); err != nil {
+			t.Fatalf("SafeDel(%q)=%+v", k, err)
+		}
+	}
+
+	if n := store.Len(); n != 0 {
+		t.Fatalf("got %d values, want 0", n)
+	}
+}
+
+func TestDedupPrefixedStrings(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{"raw": "cas:hello"}
+		d   = types.Dedup(m, types.NewContentStore(), nil)
+	)
+
+	if got, err := d.SafeGet(ctx, "raw"); err != nil || got != "cas:hello" {
+		t.Fatalf("SafeGet()=%#v, %+v, want %#v", got, err, "cas:hello")
+	}
+}
@@ -0,0 +1,267 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Deprecation describes a read of a value through a deprecated
+// key path.
+type Deprecation struct {
+	Old      Key
+	New      Key
+	Migrated bool
+	Err      error
+
+	// Position is the source position of the deprecated key, if
+	// the reader knows it, see Provenance.
+	Position Position
+}
+
+type DeprecateOptions struct {
+	// Paths maps deprecated key paths to their replacements,
+	// e.g. "listen" -> "server.addr".
+	Paths map[string]string
+
+	// Migrate moves the value from the deprecated path to its
+	// replacement on first read. It requires the reader to be
+	// a Writer.
+	Migrate bool
+
+	// Warn is called on every read served from, or requested by,
+	// a deprecated path.
+	Warn func(context.Context, Deprecation)
+}
+
+var DefaultDeprecateOptions = &DeprecateOptions{}
+
+// Deprecated is a reader, which serves values of deprecated key
+// paths from either the deprecated or the replacement location.
+type Deprecated struct {
+	r       Reader
+	root    Reader
+	key     Key
+	opts    *DeprecateOptions
+	aliases []alias
+}
+
+// alias is a deprecated key path and its replacement.
+type alias struct {
+	old, repl Key
+}
+
+var (
+	_ Reader     = (*Deprecated)(nil)
+	_ SafeReader = (*Deprecated)(nil)
+	_ SafeLister = (*Deprecated)(nil)
+)
+
+// Deprecate gives a reader serving the deprecated paths of opts,
+// which defaults to DefaultDeprecateOptions. When several deprecated
+// paths are replaced by the same one, the longest of them is served.
+func Deprecate(r Reader, opts *DeprecateOptions) *Deprecated {
+	if opts == nil {
+		opts = DefaultDeprecateOptions
+	}
+
+	var aliases = make([]alias, 0, len(opts.Paths))
+
+	for old, repl := range opts.Paths {
+		aliases = append(aliases, alias{
+			old:  strings.Split(old, "."),
+			repl: strings.Split(repl, "."),
+		})
+	}
+
+	sort.Slice(aliases, func(i, j int) bool {
+		if len(aliases[i].old) != len(aliases[j].old) {
+			return len(aliases[i].old) > len(aliases[j].old)
+		}
+		return aliases[i].old.Compare(aliases[j].old) < 0
+	})
+
+	return &Deprecated{
+		r:       r,
+		root:    r,
+		opts:    opts,
+		aliases: aliases,
+	}
+}
+
+func (d *Deprecated) Type() Type {
+	return d.r.Type()
+}
+
+func (d *Deprecated) Get(ctx context.Context, key string) (any, bool) {
+	v, err := d.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (d *Deprecated) SafeGet(ctx context.Context, key string) (any, error) {
+	var (
+		full   = append(d.key.Copy(), key)
+		v, err = d.get(ctx, full)
+	)
+
+	if err != nil && errors.Is(err, ErrNotFound) && d.parent(full) {
+		v, err = make(Map), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Deprecated{r: r, root: d.root, key: full, opts: d.opts, aliases: d.aliases}, nil
+	}
+
+	return v, nil
+}
+
+func (d *Deprecated) get(ctx context.Context, key Key) (any, error) {
+	for _, a := range d.aliases {
+		if !a.old.Equal(key) {
+			continue
+		}
+
+		pos, _ := PositionOf(ctx, d.root, key)
+		d.warn(ctx, Deprecation{Old: key, New: a.repl.Copy(), Position: pos})
+
+		if v, err := d.lookup(ctx, a.repl); err == nil {
+			return v, nil
+		}
+
+		return d.lookup(ctx, key)
+	}
+
+	v, err := d.lookup(ctx, key)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return v, err
+	}
+
+	for _, a := range d.aliases {
+		if !a.repl.Equal(key) {
+			continue
+		}
+
+		oldKey := a.old.Copy()
+
+		w, e := d.lookup(ctx, oldKey)
+		if e != nil {
+			continue
+		}
+
+		dep := Deprecation{Old: oldKey, New: key}
+		dep.Position, _ = PositionOf(ctx, d.root, oldKey)
+
+		if d.opts.Migrate {
+			dep.Err = d.migrate(ctx, oldKey, key, w)
+			dep.Migrated = dep.Err == nil
+		}
+
+		d.warn(ctx, dep)
+
+		return w, nil
+	}
+
+	return nil, err
+}
+
+func (d *Deprecated) lookup(ctx context.Context, key Key) (any, error) {
+	return PrefixReader(d.root, key.Dir()...).SafeGet(ctx, key.Base())
+}
+
+func (d *Deprecated) migrate(ctx context.Context, old, key Key, v any) error {
+	w, ok := d.root.(Writer)
+	if !ok {
+		return &Error{
+			Op:   "Migrate",
+			Key:  old,
+			Got:  d.root,
+			Want: Writer(nil),
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	var pw = PrefixedWriter{W: w}
+
+	if dir := key.Dir(); len(dir) != 0 {
+		var err error
+
+		pw.Key = dir[:len(dir)-1]
+
+		if pw.W, err = pw.SafePut(ctx, dir[len(dir)-1], TypeMap); err != nil {
+			return err
+		}
+
+		pw.Key = nil
+	}
+
+	if _, err := pw.SafeSet(ctx, key.Base(), v); err != nil {
+		return err
+	}
+
+	return PrefixWriter(w, old.Dir()...).SafeDel(ctx, old.Base())
+}
+
+func (d *Deprecated) warn(ctx context.Context, dep Deprecation) {
+	if d.opts.Warn != nil {
+		d.opts.Warn(ctx, dep)
+	}
+}
+
+// parent reports whether key is a parent of any deprecated or
+// replacement path.
+func (d *Deprecated) parent(key Key) bool {
+	for _, a := range d.aliases {
+		if (len(a.old) > len(key) && a.old.HasPrefix(key)) || (len(a.repl) > len(key) && a.repl.HasPrefix(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Deprecated) List(ctx context.Context) []string {
+	keys, _ := d.SafeList(ctx)
+	return keys
+}
+
+func (d *Deprecated) SafeList(ctx context.Context) ([]string, error) {
+	keys, err := List(ctx, d.r)
+	if err != nil {
+		return nil, err
+	}
+
+	var seen = make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		seen[k] = struct{}{}
+	}
+
+	for _, a := range d.aliases {
+		if len(a.repl) <= len(d.key) || !a.repl.HasPrefix(d.key) {
+			continue
+		}
+
+		k := a.repl[len(d.key)]
+
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		if _, err := d.lookup(ctx, a.old); err == nil {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (d *Deprecated) Unwrap() any {
+	return d.r
+}
@@ -0,0 +1,108 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeprecate(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		deps []string
+		m    = types.Map{
+			"listen": ":8080",
+			"server": types.Map{
+				"timeout": "30s",
+			},
+		}
+		opts = &types.DeprecateOptions{
+			Paths: map[string]string{
+				"listen":  "server.addr",
+				"timeout": "server.timeout",
+			},
+			Warn: func(_ context.Context, d types.Deprecation) {
+				deps = append(deps, d.Old.String()+"->"+d.New.String())
+			},
+		}
+		d = types.Deprecate(m, opts)
+	)
+
+	if got, want := types.PrefixReader(d, "server").List(ctx), []string{"addr", "timeout"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, ok := types.PrefixReader(d, "server").Get(ctx, "addr"); !ok || v != ":8080" {
+		t.Fatalf("Get()=%#v, %t", v, ok)
+	}
+
+	if v, ok := d.Get(ctx, "timeout"); !ok || v != "30s" {
+		t.Fatalf("Get()=%#v, %t", v, ok)
+	}
+
+	if want := []string{"listen->server.addr", "timeout->server.timeout"}; !cmp.Equal(deps, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(deps, want))
+	}
+
+	opts.Migrate = true
+
+	if v, ok := types.PrefixReader(d, "server").Get(ctx, "addr"); !ok || v != ":8080" {
+		t.Fatalf("Get()=%#v, %t", v, ok)
+	}
+
+	want := types.Map{
+		"server": types.Map{
+			"addr":    ":8080",
+			"timeout": "30s",
+		},
+	}
+
+	if !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+}
+
+func TestDeprecateOverlapping(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		deps []types.Key
+		m    = types.Map{
+			"a.b": types.Map{"c": "dotted"},
+			"a":   types.Map{"b": types.Map{"c": "nested"}},
+			"old": "short",
+		}
+		opts = &types.DeprecateOptions{
+			Paths: map[string]string{
+				"a.b.c": "new",
+				"old":   "new",
+			},
+			Warn: func(_ context.Context, d types.Deprecation) {
+				deps = append(deps, d.Old)
+			},
+		}
+	)
+
+	for i := 0; i < 10; i++ {
+		v, err := types.Deprecate(m, opts).SafeGet(ctx, "new")
+		if err != nil || v != "nested" {
+			t.Fatalf("SafeGet()=%#v, %+v, want nested", v, err)
+		}
+	}
+
+	deps = nil
+
+	if v, err := types.PrefixReader(types.Deprecate(m, opts), "a.b").SafeGet(ctx, "c"); err != nil || v != "dotted" {
+		t.Fatalf("SafeGet()=%#v, %+v, want dotted", v, err)
+	}
+
+	if len(deps) != 0 {
+		t.Fatalf("got %v, want no deprecations", deps)
+	}
+
+	if v, err := types.Deprecate(m, nil).SafeGet(ctx, "old"); err != nil || v != "short" {
+		t.Fatalf("SafeGet()=%#v, %+v, want short", v, err)
+	}
+}
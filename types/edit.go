@@ -0,0 +1,255 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// Editor records every mutation made through it along with its inverse,
+// so that they can be undone and redone later.
+type Editor struct {
+	iface Interface
+	key   Key
+	h     *history
+}
+
+type history struct {
+	root Interface
+	mu   sync.Mutex
+	undo []change
+	redo []change
+}
+
+type change struct {
+	do, undo op
+}
+
+type op struct {
+	op    string
+	key   Key
+	value any
+	hint  Type
+}
+
+var (
+	_ Interface  = (*Editor)(nil)
+	_ SafeReader = (*Editor)(nil)
+	_ SafeLister = (*Editor)(nil)
+	_ ListerTo   = (*Editor)(nil)
+	_ SafeWriter = (*Editor)(nil)
+)
+
+func Edit(iface Interface) *Editor {
+	return &Editor{
+		iface: iface,
+		h:     &history{root: iface},
+	}
+}
+
+func (e *Editor) Type() Type {
+	return e.iface.Type()
+}
+
+func (e *Editor) Get(ctx context.Context, key string) (any, bool) {
+	v, err := e.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (e *Editor) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: e.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := v.(Interface); ok {
+		return e.child(iface, key), nil
+	}
+
+	return v, nil
+}
+
+func (e *Editor) List(ctx context.Context) []string {
+	return e.iface.List(ctx)
+}
+
+func (e *Editor) ListTo(ctx context.Context, keys *[]string) {
+	ListTo(ctx, e.iface, keys)
+}
+
+func (e *Editor) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, e.iface)
+}
+
+func (e *Editor) Del(ctx context.Context, key string) bool {
+	return e.SafeDel(ctx, key) == nil
+}
+
+func (e *Editor) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := e.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (e *Editor) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := e.SafePut(ctx, key, hint)
+	return w
+}
+
+func (e *Editor) SafeDel(ctx context.Context, key string) error {
+	e.h.mu.Lock()
+	defer e.h.mu.Unlock()
+
+	v, err := PrefixedReader{R: e.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := (PrefixedWriter{W: e.iface}).SafeDel(ctx, key); err != nil {
+		return err
+	}
+
+	e.h.record(change{
+		do:   op{op: "Del", key: e.path(key)},
+		undo: op{op: "Set", key: e.path(key), value: v},
+	})
+
+	return nil
+}
+
+func (e *Editor) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	e.h.mu.Lock()
+	defer e.h.mu.Unlock()
+
+	var (
+		prev, err = PrefixedReader{R: e.iface}.SafeGet(ctx, key)
+		undo      = op{op: "Set", key: e.path(key), value: prev}
+	)
+
+	if err != nil {
+		undo = op{op: "Del", key: e.path(key)}
+	}
+
+	ok, err := PrefixedWriter{W: e.iface}.SafeSet(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+
+	e.h.record(change{
+		do:   op{op: "Set", key: e.path(key), value: value},
+		undo: undo,
+	})
+
+	return ok, nil
+}
+
+func (e *Editor) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	e.h.mu.Lock()
+	defer e.h.mu.Unlock()
+
+	_, err := PrefixedReader{R: e.iface}.SafeGet(ctx, key)
+	created := err != nil
+
+	w, err := PrefixedWriter{W: e.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		e.h.record(change{
+			do:   op{op: "Put", key: e.path(key), hint: hint},
+			undo: op{op: "Del", key: e.path(key)},
+		})
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return e.child(iface, key), nil
+	}
+
+	return w, nil
+}
+
+// Undo reverts the most recent mutation, failing with ErrEmpty
+// if there is nothing to undo.
+func (e *Editor) Undo(ctx context.Context) error {
+	return e.h.apply(ctx, &e.h.undo, &e.h.redo, "Undo")
+}
+
+// Redo reapplies the most recently undone mutation, failing with
+// ErrEmpty if there is nothing to redo.
+func (e *Editor) Redo(ctx context.Context) error {
+	return e.h.apply(ctx, &e.h.redo, &e.h.undo, "Redo")
+}
+
+func (e *Editor) child(iface Interface, key string) *Editor {
+	return &Editor{
+		iface: iface,
+		key:   e.path(key),
+		h:     e.h,
+	}
+}
+
+func (e *Editor) path(key string) Key {
+	return append(e.key.Copy(), key)
+}
+
+func (h *history) record(c change) {
+	h.undo = append(h.undo, c)
+	h.redo = nil
+}
+
+func (h *history) apply(ctx context.Context, from, to *[]change, name string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := len(*from) - 1
+	if n < 0 {
+		return &Error{
+			Op:  name,
+			Err: ErrEmpty,
+		}
+	}
+
+	c := (*from)[n]
+
+	if err := c.undo.apply(ctx, h.root); err != nil {
+		return err
+	}
+
+	*from = (*from)[:n]
+	*to = append(*to, change{do: c.undo, undo: c.do})
+
+	return nil
+}
+
+func (o op) apply(ctx context.Context, root Writer) error {
+	var (
+		pw  = PrefixedWriter{Key: o.key.Dir(), W: root}
+		err error
+	)
+
+	switch o.op {
+	case "Set":
+		_, err = pw.SafeSet(ctx, o.key.Base(), o.value)
+	case "Del":
+		err = pw.SafeDel(ctx, o.key.Base())
+	case "Put":
+		_, err = pw.SafePut(ctx, o.key.Base(), o.hint)
+	}
+
+	return err
+}
+
+func (e *Editor) Ping(ctx context.Context) error {
+	return Ping(ctx, e.iface)
+}
+
+func (e *Editor) Close(ctx context.Context) error {
+	return Close(ctx, e.iface)
+}
+
+func (e *Editor) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (e *Editor) Unwrap() any {
+	return e.iface
+}
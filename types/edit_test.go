@@ -0,0 +1,57 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEditor(t *testing.T) {
+	var (
+		m   = newM()
+		e   = types.Edit(m)
+		ctx = context.Background()
+	)
+
+	w, err := e.SafePut(ctx, "new", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	w.Set(ctx, "key", "value")
+
+	if err := types.PrefixWriter(e, "foo", "bar").SafeDel(ctx, "file"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := e.Undo(ctx); err != nil {
+			t.Fatalf("Undo()=%+v", err)
+		}
+	}
+
+	if err := e.Undo(ctx); !errors.Is(err, types.ErrEmpty) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrEmpty)
+	}
+
+	if !cmp.Equal(m, newM()) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, newM()))
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := e.Redo(ctx); err != nil {
+			t.Fatalf("Redo()=%+v", err)
+		}
+	}
+
+	want := newM()
+	want["new"] = types.Map{"key": "value"}
+
+	if !cmp.Equal(m, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(m, want))
+	}
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -11,6 +12,14 @@ var (
 	ErrNotFound       = errors.New("not found")
 	ErrNotDone        = errors.New("iterator not done")
 	ErrUnexpectedType = errors.New("unexpected type")
+	ErrClosed         = errors.New("closed")
+	ErrQueueFull      = errors.New("queue is full")
+	ErrConflict       = errors.New("conflict")
+	ErrReadOnly       = errors.New("read-only")
+	ErrUnused         = errors.New("unused key")
+	ErrTooLarge       = errors.New("too large")
+	ErrQuotaExceeded  = errors.New("quota exceeded")
+	ErrInvalid        = errors.New("invalid value")
 )
 
 type Error struct {
@@ -19,11 +28,22 @@ type Error struct {
 	Got  any
 	Want any
 	Err  error
+
+	// Position is the source position of the key, if known,
+	// see Provenance.
+	Position Position
 }
 
 var _ error = (*Error)(nil)
 
 func (e *Error) Error() string {
+	if e.Position.Line != 0 {
+		return e.Position.String() + ": " + e.error()
+	}
+	return e.error()
+}
+
+func (e *Error) error() string {
 	switch {
 	case e.Got != nil && e.Want != nil:
 		return fmt.Sprintf("%q operation error for %v key: got %#v, want %#v: %+v", e.Err, e.Key, e.Got, e.Want, e.Err)
@@ -40,6 +60,52 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+type Errors []error
+
+var _ error = Errors(nil)
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d errors occurred:", len(e))
+
+	for _, err := range e {
+		sb.WriteString("\n\t* ")
+		sb.WriteString(err.Error())
+	}
+
+	return sb.String()
+}
+
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Errors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
 func ErrAs(err error, out *Error, match func(*Error) bool) bool {
 	const maxDepth = 128 // to prevent stack overflow if err has cyclic refs
 
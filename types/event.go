@@ -0,0 +1,29 @@
+package types
+
+import "context"
+
+type Event struct {
+	Op    string
+	Key   Key
+	Value any
+}
+
+// scope gives ev as seen by a watcher of the key. An event of an
+// ancestor of the key becomes an event of the key itself: a Set of
+// its new value or a Del, if the written value does not have it.
+func scope(ctx context.Context, ev Event, key Key) (Event, bool) {
+	switch {
+	case ev.Key.HasPrefix(key):
+		return ev, true
+	case !key.HasPrefix(ev.Key):
+		return Event{}, false
+	}
+
+	if ev.Op == "Set" {
+		if v := at(ctx, ev.Value, key[len(ev.Key):]); v != nil {
+			return Event{Op: "Set", Key: key.Copy(), Value: v}, true
+		}
+	}
+
+	return Event{Op: "Del", Key: key.Copy()}, true
+}
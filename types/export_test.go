@@ -0,0 +1,14 @@
+package types
+
+// SingleflightWaiters gives the number of callers waiting for
+// the shared op call of the key.
+func SingleflightWaiters(s *Singleflighted, op string, key Key) int {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+
+	if c, ok := s.g.calls[op+":"+key.ID()]; ok {
+		return c.dups
+	}
+
+	return 0
+}
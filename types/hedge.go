@@ -0,0 +1,176 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedged reads from replicated readers. When a read does not
+// complete within the delay, it is repeated against the next
+// replica, and the first successful result wins. A key not found
+// in a replica is not read from the remaining ones.
+type Hedged struct {
+	rs    []Reader
+	key   Key
+	typ   Type
+	delay time.Duration
+}
+
+var (
+	_ Reader     = (*Hedged)(nil)
+	_ SafeReader = (*Hedged)(nil)
+	_ SafeLister = (*Hedged)(nil)
+)
+
+// Hedge panics if there are no readers.
+func Hedge(readers []Reader, delay time.Duration) *Hedged {
+	if len(readers) == 0 {
+		panic(&Error{
+			Op:   "Hedge",
+			Got:  readers,
+			Want: "at least one reader",
+			Err:  ErrInvalid,
+		})
+	}
+
+	return &Hedged{
+		rs:    readers,
+		typ:   readers[0].Type(),
+		delay: delay,
+	}
+}
+
+func (h *Hedged) Type() Type {
+	return h.typ
+}
+
+func (h *Hedged) Get(ctx context.Context, key string) (any, bool) {
+	v, err := h.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (h *Hedged) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := h.do(ctx, func(ctx context.Context, r Reader) (any, error) {
+		return PrefixReader(r, h.key...).SafeGet(ctx, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Hedged{
+			rs:    h.rs,
+			key:   append(h.key.Copy(), key),
+			typ:   r.Type(),
+			delay: h.delay,
+		}, nil
+	}
+
+	return v, nil
+}
+
+func (h *Hedged) List(ctx context.Context) []string {
+	keys, _ := h.SafeList(ctx)
+	return keys
+}
+
+func (h *Hedged) SafeList(ctx context.Context) ([]string, error) {
+	v, err := h.do(ctx, func(ctx context.Context, r Reader) (any, error) {
+		return PrefixReader(r, h.key...).SafeList(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]string), nil
+}
+
+func (h *Hedged) Ping(ctx context.Context) error {
+	return Ping(ctx, h.readers()...)
+}
+
+func (h *Hedged) Close(ctx context.Context) error {
+	return Close(ctx, h.readers()...)
+}
+
+func (h *Hedged) Unwrap() any {
+	return h.rs[0]
+}
+
+func (h *Hedged) readers() []any {
+	vs := make([]any, len(h.rs))
+	for i, r := range h.rs {
+		vs[i] = r
+	}
+	return vs
+}
+
+type hedgeResult struct {
+	value any
+	err   error
+}
+
+func (h *Hedged) do(ctx context.Context, fn func(context.Context, Reader) (any, error)) (any, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make(chan hedgeResult, len(h.rs))
+		timer   = time.NewTimer(h.delay)
+		next    = 0
+		pending = 0
+		err     error
+	)
+
+	defer timer.Stop()
+
+	start := func() {
+		r := h.rs[next]
+		next++
+		pending++
+
+		go func() {
+			v, err := fn(ctx, r)
+			results <- hedgeResult{value: v, err: err}
+		}()
+	}
+
+	start()
+
+	for pending != 0 {
+		select {
+		case res := <-results:
+			pending--
+
+			if res.err == nil || errors.Is(res.err, ErrNotFound) {
+				return res.value, res.err
+			}
+
+			if err == nil {
+				err = res.err
+			}
+
+			if pending == 0 && next < len(h.rs) {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+
+				start()
+				timer.Reset(h.delay)
+			}
+		case <-timer.C:
+			if next < len(h.rs) {
+				start()
+				timer.Reset(h.delay)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, err
+}
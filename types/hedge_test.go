@@ -0,0 +1,78 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+func TestHedge(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		slow = &slowMap{
+			Map:     types.Map{"server": types.Map{"host": "slow"}},
+			release: make(chan struct{}),
+		}
+		fast = types.Map{"server": types.Map{"host": "fast"}}
+		h    = types.Hedge([]types.Reader{slow, fast}, 10*time.Millisecond)
+	)
+
+	defer close(slow.release)
+
+	v, err := types.PrefixReader(h, "server").SafeGet(ctx, "host")
+	if err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	if v != "fast" {
+		t.Fatalf("got %#v, want %#v", v, "fast")
+	}
+
+	if n := atomic.LoadInt32(&slow.n); n == 0 {
+		t.Fatal("expected the first replica to be tried")
+	}
+}
+
+func TestHedgeFallback(t *testing.T) {
+	var (
+		ctx = context.Background()
+		h   = types.Hedge([]types.Reader{
+			failMap{Map: types.Map{}, err: errors.New("replica down")},
+			types.Map{"host": "replica"},
+		}, time.Hour)
+	)
+
+	if v, _ := h.Get(ctx, "host"); v != "replica" {
+		t.Fatalf("got %#v, want %#v", v, "replica")
+	}
+}
+
+func TestHedgeNotFound(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		replica = &countMap{Map: types.Map{"host": "replica"}}
+		h       = types.Hedge([]types.Reader{types.Map{}, replica}, time.Hour)
+	)
+
+	if _, err := h.SafeGet(ctx, "host"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrNotFound)
+	}
+
+	if n := replica.count(); n != 0 {
+		t.Fatalf("got %d reads of the second replica, want 0", n)
+	}
+}
+
+func TestHedgeEmpty(t *testing.T) {
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, types.ErrInvalid) {
+			t.Fatalf("got %+v, want %+v", err, types.ErrInvalid)
+		}
+	}()
+
+	types.Hedge(nil, time.Second)
+}
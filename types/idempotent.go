@@ -0,0 +1,252 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+type idempotencyKey struct{}
+
+func WithIdempotencyKey(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, token)
+}
+
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(idempotencyKey{}).(string)
+	return token, ok && token != ""
+}
+
+func token(ctx context.Context) string {
+	token, _ := IdempotencyKey(ctx)
+	return token
+}
+
+// Deduplicated deduplicates mutations carrying an idempotency key
+// (see WithIdempotencyKey), by replaying the result of the last
+// successful mutation made with the same key instead of applying
+// it again. It remembers up to size most recent keys. Mutations with
+// a key, which is being applied, wait for its result.
+type Deduplicated struct {
+	w   Writer
+	key Key
+	c   *tokens
+}
+
+type tokens struct {
+	size     int
+	mu       sync.Mutex
+	seen     map[string]*applied
+	inflight map[string]chan struct{}
+	tokens   []string
+}
+
+type applied struct {
+	op       string
+	key      Key
+	previous bool
+	w        Writer
+}
+
+// DefaultDeduplicateSize is the number of keys remembered by
+// Deduplicate when the given size is not positive.
+const DefaultDeduplicateSize = 1024
+
+var (
+	_ Writer     = (*Deduplicated)(nil)
+	_ SafeWriter = (*Deduplicated)(nil)
+)
+
+func Deduplicate(w Writer, size int) *Deduplicated {
+	if size <= 0 {
+		size = DefaultDeduplicateSize
+	}
+
+	return &Deduplicated{
+		w: w,
+		c: &tokens{
+			size:     size,
+			seen:     make(map[string]*applied),
+			inflight: make(map[string]chan struct{}),
+		},
+	}
+}
+
+func (i *Deduplicated) Del(ctx context.Context, key string) bool {
+	return i.SafeDel(ctx, key) == nil
+}
+
+func (i *Deduplicated) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := i.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (i *Deduplicated) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := i.SafePut(ctx, key, hint)
+	return w
+}
+
+func (i *Deduplicated) SafeDel(ctx context.Context, key string) error {
+	_, err := i.do(ctx, "Del", key, func() (*applied, error) {
+		if sw, ok := i.w.(SafeWriter); ok {
+			return &applied{}, sw.SafeDel(ctx, key)
+		}
+
+		if !i.w.Del(ctx, key) {
+			return nil, &Error{
+				Op:  "Del",
+				Key: []string{key},
+				Err: ErrNotFound,
+			}
+		}
+
+		return &applied{}, nil
+	})
+
+	return err
+}
+
+func (i *Deduplicated) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	a, err := i.do(ctx, "Set", key, func() (*applied, error) {
+		if sw, ok := i.w.(SafeWriter); ok {
+			previous, err := sw.SafeSet(ctx, key, value)
+			return &applied{previous: previous}, err
+		}
+
+		return &applied{previous: i.w.Set(ctx, key, value)}, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return a.previous, nil
+}
+
+func (i *Deduplicated) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	a, err := i.do(ctx, "Put", key, func() (*applied, error) {
+		var (
+			w   Writer
+			err error
+		)
+
+		if sw, ok := i.w.(SafeWriter); ok {
+			if w, err = sw.SafePut(ctx, key, hint); err != nil {
+				return nil, err
+			}
+		} else {
+			w = i.w.Put(ctx, key, hint)
+		}
+
+		return &applied{
+			w: &Deduplicated{
+				w:   w,
+				key: append(i.key.Copy(), key),
+				c:   i.c,
+			},
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.w, nil
+}
+
+func (i *Deduplicated) do(ctx context.Context, op, key string, fn func() (*applied, error)) (*applied, error) {
+	token, ok := IdempotencyKey(ctx)
+	if !ok {
+		return fn()
+	}
+
+	var path = append(i.key.Copy(), key)
+
+	// The lock is not held while fn writes, so mutations of other
+	// keys are not serialized; the ones with the same key wait.
+	for {
+		i.c.mu.Lock()
+
+		if a, ok := i.c.seen[token]; ok {
+			i.c.mu.Unlock()
+
+			if err := a.check(op, path, token); err != nil {
+				return nil, err
+			}
+
+			return a, nil
+		}
+
+		done, ok := i.c.inflight[token]
+		if !ok {
+			break
+		}
+
+		i.c.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	done := make(chan struct{})
+	i.c.inflight[token] = done
+	i.c.mu.Unlock()
+
+	a, err := fn()
+
+	i.c.mu.Lock()
+	delete(i.c.inflight, token)
+	if err == nil {
+		a.op, a.key = op, path
+		i.c.add(token, a)
+	}
+	i.c.mu.Unlock()
+
+	close(done)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *applied) check(op string, key Key, token string) error {
+	if a.op == op && a.key.Equal(key) {
+		return nil
+	}
+
+	return &Error{
+		Op:   op,
+		Key:  key,
+		Got:  token,
+		Want: a.op + " " + a.key.String(),
+		Err:  ErrConflict,
+	}
+}
+
+func (c *tokens) add(token string, a *applied) {
+	if len(c.tokens) >= c.size {
+		delete(c.seen, c.tokens[0])
+		c.tokens = c.tokens[1:]
+	}
+
+	c.seen[token] = a
+	c.tokens = append(c.tokens, token)
+}
+
+func (i *Deduplicated) Ping(ctx context.Context) error {
+	return Ping(ctx, i.w)
+}
+
+func (i *Deduplicated) Close(ctx context.Context) error {
+	return Close(ctx, i.w)
+}
+
+func (i *Deduplicated) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (i *Deduplicated) Unwrap() any {
+	return i.w
+}
@@ -0,0 +1,108 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+func TestDeduplicate(t *testing.T) {
+	var (
+		m   = make(types.Map)
+		d   = types.Deduplicate(m, 2)
+		ctx = types.WithIdempotencyKey(context.Background(), "token-1")
+	)
+
+	if ok, err := d.SafeSet(ctx, "counter", 1); err != nil || ok {
+		t.Fatalf("SafeSet()=%t, %+v", ok, err)
+	}
+
+	m["counter"] = 2
+
+	if ok, err := d.SafeSet(ctx, "counter", 1); err != nil || ok {
+		t.Fatalf("SafeSet()=%t, %+v", ok, err)
+	}
+
+	if got, want := m["counter"], 2; got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+
+	if _, err := d.SafeSet(ctx, "other", 1); !errors.Is(err, types.ErrConflict) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrConflict)
+	}
+
+	for _, token := range []string{"token-2", "token-3"} {
+		d.Set(types.WithIdempotencyKey(ctx, token), "other", token)
+	}
+
+	if ok, err := d.SafeSet(ctx, "counter", 1); err != nil || !ok {
+		t.Fatalf("SafeSet()=%t, %+v", ok, err)
+	}
+
+	if got, want := m["counter"], 1; got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+// slowWriter blocks writes of the "slow" key until release is closed.
+type slowWriter struct {
+	types.Map
+	release chan struct{}
+}
+
+func (gw slowWriter) Set(ctx context.Context, key string, value any) bool {
+	if key == "slow" {
+		<-gw.release
+	}
+	return gw.Map.Set(ctx, key, value)
+}
+
+func TestDeduplicateConcurrent(t *testing.T) {
+	var (
+		m    = slowWriter{Map: types.Map{"a": types.Map{}}, release: make(chan struct{})}
+		d    = types.Deduplicate(m, 0)
+		ctx  = context.Background()
+		slow = make(chan error, 2)
+	)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := d.SafeSet(types.WithIdempotencyKey(ctx, "slow"), "slow", 1)
+			slow <- err
+		}()
+	}
+
+	fast := make(chan error, 1)
+
+	go func() {
+		_, err := d.SafeSet(types.WithIdempotencyKey(ctx, "fast"), "a.b", 1)
+		fast <- err
+	}()
+
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Fatalf("SafeSet()=%+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("write was blocked by a slow write of another key")
+	}
+
+	close(m.release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-slow; err != nil {
+			t.Fatalf("SafeSet()=%+v", err)
+		}
+	}
+
+	w := d.Put(ctx, "a", types.TypeMap)
+
+	_, err := w.(types.SafeWriter).SafeSet(types.WithIdempotencyKey(ctx, "fast"), "b", 1)
+	if !errors.Is(err, types.ErrConflict) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrConflict)
+	}
+}
@@ -12,10 +12,18 @@ type SafeReader interface {
 	SafeGet(ctx context.Context, key string) (value any, err error)
 }
 
+type SafeLister interface {
+	SafeList(ctx context.Context) ([]string, error)
+}
+
 type ListerTo interface {
 	ListTo(context.Context, *[]string)
 }
 
+type ValueLister interface {
+	ListValues(context.Context) ([]KV, error)
+}
+
 type Writer interface {
 	Del(ctx context.Context, key string) (ok bool)
 	Set(ctx context.Context, key string, value any) (previous bool)
@@ -28,6 +36,26 @@ type SafeWriter interface {
 	SafePut(ctx context.Context, key string, hint Type) (Writer, error)
 }
 
+type Watcher interface {
+	Watch(ctx context.Context, key string) <-chan Event
+}
+
+type Locker interface {
+	Lock(ctx context.Context, key Key) (unlock func(), err error)
+}
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+type Unwrapper interface {
+	Unwrap() any
+}
+
 type Interface interface {
 	Reader
 	Writer
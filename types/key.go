@@ -1,6 +1,9 @@
 package types
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 type Key []string
 
@@ -28,6 +31,44 @@ func (k Key) String() string {
 	return strings.Join(k, ".")
 }
 
+// ID gives an unambiguous encoding of the key, e.g. to identify it
+// in a map. Unlike String, it keeps {"a.b"} and {"a", "b"} apart.
+// The ID of a prefix of the key is a prefix of the key's ID.
+func (k Key) ID() string {
+	var sb strings.Builder
+
+	for _, s := range k {
+		sb.WriteString(strconv.Itoa(len(s)))
+		sb.WriteByte(':')
+		sb.WriteString(s)
+	}
+
+	return sb.String()
+}
+
+// Equal reports whether both keys have the same segments.
+func (k Key) Equal(other Key) bool {
+	return len(k) == len(other) && k.HasPrefix(other)
+}
+
+// Compare orders the keys segment by segment, giving -1, 0 or +1.
+func (k Key) Compare(other Key) int {
+	for i := 0; i < len(k) && i < len(other); i++ {
+		if c := strings.Compare(k[i], other[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(k) < len(other):
+		return -1
+	case len(k) > len(other):
+		return 1
+	default:
+		return 0
+	}
+}
+
 func (k Key) Strings() []string {
 	return k
 }
@@ -38,6 +79,20 @@ func (k Key) Copy() Key {
 	return kCopy
 }
 
+func (k Key) HasPrefix(prefix Key) bool {
+	if len(prefix) > len(k) {
+		return false
+	}
+
+	for i := range prefix {
+		if k[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (k *Key) Prepend(prefix Key) {
 	n, m := len(*k), len(prefix)
 
@@ -1,6 +1,7 @@
 package types_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -37,3 +38,34 @@ func TestKeyPrepend(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyID(t *testing.T) {
+	var (
+		dotted = types.Key{"a.b"}
+		nested = types.Key{"a", "b"}
+	)
+
+	if dotted.String() != nested.String() {
+		t.Fatalf("want colliding strings, got %q and %q", dotted, nested)
+	}
+
+	if dotted.ID() == nested.ID() {
+		t.Fatalf("ID()=%q for both %q and %q", dotted.ID(), dotted.Strings(), nested.Strings())
+	}
+
+	if !strings.HasPrefix(nested.ID(), types.Key{"a"}.ID()) {
+		t.Fatalf("ID()=%q does not start with the ID of its prefix", nested.ID())
+	}
+
+	if strings.HasPrefix(types.Key{"ab"}.ID(), types.Key{"a"}.ID()) {
+		t.Fatalf("ID()=%q starts with the ID of a non-prefix", types.Key{"ab"}.ID())
+	}
+
+	if dotted.Equal(nested) || !nested.Equal(types.Key{"a", "b"}) {
+		t.Fatal("Equal() does not compare segments")
+	}
+
+	if nested.Compare(dotted) >= 0 || dotted.Compare(nested) <= 0 || nested.Compare(nested.Copy()) != 0 {
+		t.Fatal("Compare() does not order segments")
+	}
+}
@@ -0,0 +1,117 @@
+package types
+
+import "context"
+
+// LegacyReader is a Reader, which methods do not take a context.
+type LegacyReader interface {
+	Get(key string) (value any, ok bool)
+	List() []string
+	Type() Type
+}
+
+type noCtx struct {
+	r Reader
+}
+
+type withCtx struct {
+	r LegacyReader
+}
+
+var (
+	_ LegacyReader = noCtx{}
+	_ Reader       = withCtx{}
+	_ SafeReader   = withCtx{}
+	_ SafeLister   = withCtx{}
+)
+
+// NoCtx adapts r to the LegacyReader, calling it with context.Background().
+func NoCtx(r Reader) LegacyReader {
+	if w, ok := r.(withCtx); ok {
+		return w.r
+	}
+	return noCtx{r: r}
+}
+
+// WithCtx adapts the LegacyReader r to the Reader. As r cannot
+// be interrupted, the context is only checked before each call.
+func WithCtx(r LegacyReader) Reader {
+	if n, ok := r.(noCtx); ok {
+		return n.r
+	}
+	return withCtx{r: r}
+}
+
+func (n noCtx) Get(key string) (any, bool) {
+	v, ok := n.r.Get(context.Background(), key)
+	if r, isReader := v.(Reader); isReader {
+		return NoCtx(r), ok
+	}
+	return v, ok
+}
+
+func (n noCtx) List() []string {
+	return n.r.List(context.Background())
+}
+
+func (n noCtx) Type() Type {
+	return n.r.Type()
+}
+
+func (w withCtx) Get(ctx context.Context, key string) (any, bool) {
+	v, err := w.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (w withCtx) SafeGet(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	v, ok := w.r.Get(key)
+	if !ok {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	if r, ok := v.(LegacyReader); ok {
+		return WithCtx(r), nil
+	}
+
+	return v, nil
+}
+
+func (w withCtx) List(ctx context.Context) []string {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return w.r.List()
+}
+
+func (w withCtx) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+	return w.r.List(), nil
+}
+
+func (w withCtx) Type() Type {
+	return w.r.Type()
+}
+
+func (n noCtx) Unwrap() any {
+	return n.r
+}
+
+func (w withCtx) Unwrap() any {
+	return w.r
+}
@@ -0,0 +1,73 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type legacyMap map[string]any
+
+func (m legacyMap) Get(key string) (any, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m legacyMap) List() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (m legacyMap) Type() types.Type {
+	return types.TypeMap
+}
+
+func TestLegacy(t *testing.T) {
+	var (
+		lm  = legacyMap{"foo": legacyMap{"bar": 1}}
+		r   = types.WithCtx(lm)
+		ctx = context.Background()
+	)
+
+	v, err := types.PrefixReader(r, "foo").SafeGet(ctx, "bar")
+	if err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	if v != 1 {
+		t.Fatalf("got %#v, want %#v", v, 1)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := types.PrefixReader(r, "foo").SafeGet(canceled, "bar"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %+v, want %+v", err, context.Canceled)
+	}
+
+	var (
+		lr      = types.NoCtx(newM())
+		foo, ok = lr.Get("foo")
+	)
+
+	if !ok {
+		t.Fatalf("Get()=%t", ok)
+	}
+
+	bar, _ := foo.(types.LegacyReader).Get("bar")
+
+	if got, want := bar.(types.LegacyReader).List(), []string{"dir", "file"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if _, ok := types.WithCtx(lr).(types.Map); !ok {
+		t.Fatalf("got %T, want %T", types.WithCtx(lr), types.Map{})
+	}
+}
@@ -0,0 +1,307 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type LimitOptions struct {
+	// Initial, Min and Max bound the number of outstanding operations.
+	Initial int
+	Min     int
+	Max     int
+
+	// Latency is the target latency of an operation. Operations
+	// that fail or take longer than Latency decrease the limit.
+	Latency time.Duration
+
+	// Backoff is the factor the limit is multiplied by on decrease.
+	Backoff float64
+}
+
+var DefaultLimitOptions = &LimitOptions{
+	Initial: 16,
+	Min:     1,
+	Max:     256,
+	Latency: 100 * time.Millisecond,
+	Backoff: 0.5,
+}
+
+// Limited bounds the number of outstanding operations on an
+// Interface with an AIMD limit, which grows additively while
+// operations are fast and shrinks multiplicatively when they are
+// slow or fail. Operations over the limit wait for a free slot.
+type Limited struct {
+	iface Interface
+	l     *limiter
+}
+
+type limiter struct {
+	opts     *LimitOptions
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+	notify   chan struct{}
+}
+
+var (
+	_ Interface  = (*Limited)(nil)
+	_ SafeReader = (*Limited)(nil)
+	_ SafeLister = (*Limited)(nil)
+	_ ListerTo   = (*Limited)(nil)
+	_ SafeWriter = (*Limited)(nil)
+)
+
+// Limit wraps iface with an AIMD limit. Zero fields of opts are taken
+// from DefaultLimitOptions, with the default Initial and Max kept
+// within the given limits. Limit panics if the
+// limits do not satisfy 1 <= Min <= Initial <= Max.
+func Limit(iface Interface, opts *LimitOptions) *Limited {
+	if opts == nil {
+		opts = DefaultLimitOptions
+	}
+
+	o := *opts
+
+	if o.Min == 0 {
+		o.Min = DefaultLimitOptions.Min
+	}
+
+	if o.Max == 0 {
+		o.Max = DefaultLimitOptions.Max
+		if o.Max < o.Initial {
+			o.Max = o.Initial
+		}
+		if o.Max < o.Min {
+			o.Max = o.Min
+		}
+	}
+
+	if o.Initial == 0 {
+		o.Initial = DefaultLimitOptions.Initial
+		if o.Initial > o.Max {
+			o.Initial = o.Max
+		}
+		if o.Initial < o.Min {
+			o.Initial = o.Min
+		}
+	}
+
+	if o.Latency == 0 {
+		o.Latency = DefaultLimitOptions.Latency
+	}
+
+	if o.Backoff == 0 {
+		o.Backoff = DefaultLimitOptions.Backoff
+	}
+
+	if o.Min < 1 || o.Min > o.Initial || o.Initial > o.Max {
+		panic(&Error{
+			Op:   "Limit",
+			Got:  o,
+			Want: "1 <= Min <= Initial <= Max",
+			Err:  ErrInvalid,
+		})
+	}
+
+	return &Limited{
+		iface: iface,
+		l: &limiter{
+			opts:   &o,
+			limit:  float64(o.Initial),
+			notify: make(chan struct{}),
+		},
+	}
+}
+
+// Limit gives the current limit of outstanding operations.
+func (l *Limited) Limit() int {
+	l.l.mu.Lock()
+	defer l.l.mu.Unlock()
+
+	return int(l.l.limit)
+}
+
+func (l *Limited) Type() Type {
+	return l.iface.Type()
+}
+
+func (l *Limited) Get(ctx context.Context, key string) (any, bool) {
+	v, err := l.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (l *Limited) SafeGet(ctx context.Context, key string) (v any, err error) {
+	if err := l.l.acquire(ctx, "Get", key); err != nil {
+		return nil, err
+	}
+
+	defer l.l.release(time.Now(), &err)
+
+	if v, err = (PrefixedReader{R: l.iface}).SafeGet(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return l.child(v), nil
+}
+
+func (l *Limited) List(ctx context.Context) []string {
+	keys, _ := l.SafeList(ctx)
+	return keys
+}
+
+func (l *Limited) SafeList(ctx context.Context) (keys []string, err error) {
+	if err := l.l.acquire(ctx, "List", ""); err != nil {
+		return nil, err
+	}
+
+	defer l.l.release(time.Now(), &err)
+
+	return List(ctx, l.iface)
+}
+
+func (l *Limited) ListTo(ctx context.Context, keys *[]string) {
+	if err := l.l.acquire(ctx, "List", ""); err != nil {
+		return
+	}
+
+	var err error
+
+	defer l.l.release(time.Now(), &err)
+
+	ListTo(ctx, l.iface, keys)
+}
+
+func (l *Limited) Del(ctx context.Context, key string) bool {
+	return l.SafeDel(ctx, key) == nil
+}
+
+func (l *Limited) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := l.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (l *Limited) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := l.SafePut(ctx, key, hint)
+	return w
+}
+
+func (l *Limited) SafeDel(ctx context.Context, key string) (err error) {
+	if err := l.l.acquire(ctx, "Del", key); err != nil {
+		return err
+	}
+
+	defer l.l.release(time.Now(), &err)
+
+	return PrefixedWriter{W: l.iface}.SafeDel(ctx, key)
+}
+
+func (l *Limited) SafeSet(ctx context.Context, key string, value any) (ok bool, err error) {
+	if err := l.l.acquire(ctx, "Set", key); err != nil {
+		return false, err
+	}
+
+	defer l.l.release(time.Now(), &err)
+
+	return PrefixedWriter{W: l.iface}.SafeSet(ctx, key, value)
+}
+
+func (l *Limited) SafePut(ctx context.Context, key string, hint Type) (w Writer, err error) {
+	if err := l.l.acquire(ctx, "Put", key); err != nil {
+		return nil, err
+	}
+
+	defer l.l.release(time.Now(), &err)
+
+	if w, err = (PrefixedWriter{W: l.iface}).SafePut(ctx, key, hint); err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return &Limited{iface: iface, l: l.l}, nil
+	}
+
+	return w, nil
+}
+
+func (l *Limited) Ping(ctx context.Context) error {
+	return Ping(ctx, l.iface)
+}
+
+func (l *Limited) Close(ctx context.Context) error {
+	return Close(ctx, l.iface)
+}
+
+func (l *Limited) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (l *Limited) Unwrap() any {
+	return l.iface
+}
+
+func (l *Limited) child(v any) any {
+	if iface, ok := v.(Interface); ok {
+		return &Limited{iface: iface, l: l.l}
+	}
+	return v
+}
+
+func (l *limiter) acquire(ctx context.Context, op, key string) error {
+	defer record(ctx, "limit", "Wait", Key{key}, time.Now())
+
+	for {
+		l.mu.Lock()
+		if l.inflight < int(l.limit) {
+			l.inflight++
+			l.mu.Unlock()
+			return nil
+		}
+		notify := l.notify
+		l.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return &Error{
+				Op:  op,
+				Key: []string{key},
+				Err: ctx.Err(),
+			}
+		}
+	}
+}
+
+func (l *limiter) release(start time.Time, err *error) {
+	var (
+		rtt  = time.Since(start)
+		fail = *err != nil && !errors.Is(*err, ErrNotFound)
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+
+	if fail || rtt > l.opts.Latency {
+		l.limit *= l.opts.Backoff
+	} else {
+		l.limit += 1 / l.limit
+	}
+
+	if l.limit < float64(l.opts.Min) || l.limit < 1 {
+		l.limit = float64(l.opts.Min)
+		if l.limit < 1 {
+			l.limit = 1
+		}
+	}
+
+	if l.limit > float64(l.opts.Max) {
+		l.limit = float64(l.opts.Max)
+	}
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
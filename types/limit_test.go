@@ -0,0 +1,115 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+func TestLimit(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m    = &slowMap{Map: types.Map{"host": "localhost"}, release: make(chan struct{})}
+		opts = &types.LimitOptions{
+			Initial: 4,
+			Min:     1,
+			Max:     8,
+			Latency: 10 * time.Millisecond,
+			Backoff: 0.5,
+		}
+		l = types.Limit(m, opts)
+	)
+
+	close(m.release)
+
+	for i := 0; i < 100; i++ {
+		if _, err := l.SafeGet(ctx, "host"); err != nil {
+			t.Fatalf("SafeGet()=%+v", err)
+		}
+	}
+
+	if n := l.Limit(); n != 8 {
+		t.Fatalf("got limit %d, want 8", n)
+	}
+
+	m.release = make(chan struct{})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(m.release)
+	}()
+
+	if _, err := l.SafeGet(ctx, "host"); err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	if n := l.Limit(); n != 4 {
+		t.Fatalf("got limit %d, want 4", n)
+	}
+}
+
+func TestLimitWait(t *testing.T) {
+	var (
+		m = &slowMap{Map: types.Map{"host": "localhost"}, release: make(chan struct{})}
+		l = types.Limit(m, &types.LimitOptions{
+			Initial: 1,
+			Min:     1,
+			Max:     1,
+			Latency: time.Second,
+			Backoff: 0.5,
+		})
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+		l.Get(context.Background(), "host")
+	}()
+
+	for {
+		time.Sleep(time.Millisecond)
+		if m.count() == 1 {
+			break
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.SafeGet(ctx, "host"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %+v, want %+v", err, context.DeadlineExceeded)
+	}
+
+	close(m.release)
+	<-done
+}
+
+func TestLimitOptions(t *testing.T) {
+	cases := []struct {
+		opts *types.LimitOptions
+		want int
+	}{
+		{nil, types.DefaultLimitOptions.Initial},
+		{&types.LimitOptions{}, types.DefaultLimitOptions.Initial},
+		{&types.LimitOptions{Max: 10}, 10},
+		{&types.LimitOptions{Min: 32}, 32},
+		{&types.LimitOptions{Initial: 512}, 512},
+	}
+
+	for _, cas := range cases {
+		if n := types.Limit(types.Map{}, cas.opts).Limit(); n != cas.want {
+			t.Errorf("Limit(%+v)=%d, want %d", cas.opts, n, cas.want)
+		}
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, types.ErrInvalid) {
+			t.Fatalf("recover()=%v, want %v", err, types.ErrInvalid)
+		}
+	}()
+
+	types.Limit(types.Map{}, &types.LimitOptions{Initial: 8, Max: 4})
+}
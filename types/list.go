@@ -0,0 +1,107 @@
+package types
+
+import (
+	"context"
+	"errors"
+)
+
+type KV struct {
+	Key   string
+	Value any
+}
+
+// List lists keys of r, reporting an error if r implements SafeLister.
+func List(ctx context.Context, r Reader) ([]string, error) {
+	if sl, ok := r.(SafeLister); ok {
+		return sl.SafeList(ctx)
+	}
+	return r.List(ctx), nil
+}
+
+// ListTo appends keys of r to keys, without allocating
+// if r implements ListerTo.
+func ListTo(ctx context.Context, r Reader, keys *[]string) {
+	if lt, ok := r.(ListerTo); ok {
+		lt.ListTo(ctx, keys)
+	} else {
+		*keys = append(*keys, r.List(ctx)...)
+	}
+}
+
+// ListValues lists keys of r along with their values, in a single
+// call if r implements ValueLister.
+func ListValues(ctx context.Context, r Reader) ([]KV, error) {
+	if vl, ok := r.(ValueLister); ok {
+		return vl.ListValues(ctx)
+	}
+
+	keys, err := List(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	kvs := make([]KV, 0, len(keys))
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		kvs = append(kvs, KV{Key: k, Value: v})
+	}
+
+	return kvs, nil
+}
+
+// Match calls fn for keys of r matching the pattern, which segments
+// may be "*" wildcards matching any key, along with their values,
+// in order of r listing. Keys under the prefix are matched relative
+// to it, but reported with it prepended.
+func Match(ctx context.Context, r Reader, pattern, prefix Key, fn func(Key, any) error) error {
+	if len(pattern) == 0 {
+		return nil
+	}
+
+	names := []string{pattern[0]}
+
+	if pattern[0] == "*" {
+		var err error
+
+		if names, err = List(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		k := append(prefix.Copy(), name)
+
+		if len(pattern) == 1 {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sub, ok := v.(Reader); ok {
+			if err := Match(ctx, sub, pattern[1:], k, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
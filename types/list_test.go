@@ -0,0 +1,70 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestListValues(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"server": types.Map{
+				"host": "localhost",
+				"port": 8080,
+			},
+		}
+		want = []types.KV{
+			{Key: "host", Value: "localhost"},
+			{Key: "port", Value: 8080},
+		}
+	)
+
+	for _, r := range []types.Reader{
+		types.PrefixReader(m, "server"),
+		types.PrefixReader(struct{ types.Reader }{m}, "server"),
+	} {
+		got, err := types.ListValues(ctx, r)
+		if err != nil {
+			t.Fatalf("ListValues()=%+v", err)
+		}
+
+		if !cmp.Equal(got, want) {
+			t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"services": types.Map{
+				"api": types.Map{"owner": "alice"},
+				"db":  types.Map{"owner": "bob"},
+				"web": types.Map{},
+			},
+		}
+		got  = make(map[string]any)
+		want = map[string]any{
+			"services.api.owner": "alice",
+			"services.db.owner":  "bob",
+		}
+	)
+
+	err := types.Match(ctx, m, types.Key{"services", "*", "owner"}, nil, func(key types.Key, v any) error {
+		got[key.String()] = v
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Match()=%+v", err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
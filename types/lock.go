@@ -0,0 +1,93 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// Locks is an in-memory Locker, which serializes access to subtrees:
+// a key is locked if neither the key itself, nor any of its parents
+// or children is held by another owner. The zero value is ready to use.
+type Locks struct {
+	mu       sync.Mutex
+	held     []Key
+	released chan struct{}
+}
+
+var _ Locker = (*Locks)(nil)
+
+func (l *Locks) Lock(ctx context.Context, key Key) (func(), error) {
+	key = key.Copy()
+
+	for {
+		l.mu.Lock()
+
+		if !l.conflicts(key) {
+			l.held = append(l.held, key)
+			l.mu.Unlock()
+
+			var once sync.Once
+
+			return func() { once.Do(func() { l.unlock(key) }) }, nil
+		}
+
+		if l.released == nil {
+			l.released = make(chan struct{})
+		}
+
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, &Error{
+				Op:  "Lock",
+				Key: key,
+				Err: ctx.Err(),
+			}
+		}
+	}
+}
+
+func (l *Locks) unlock(key Key) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, k := range l.held {
+		if len(k) == len(key) && k.HasPrefix(key) {
+			l.held = append(l.held[:i], l.held[i+1:]...)
+			break
+		}
+	}
+
+	if l.released != nil {
+		close(l.released)
+		l.released = nil
+	}
+}
+
+func (l *Locks) conflicts(key Key) bool {
+	for _, k := range l.held {
+		if k.HasPrefix(key) || key.HasPrefix(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pr PrefixedReader) Lock(ctx context.Context, key Key) (func(), error) {
+	var l Locker
+
+	if !As(pr.R, &l) {
+		return nil, &Error{
+			Op:   "Lock",
+			Key:  append(pr.Key.Copy(), key...),
+			Got:  pr.R,
+			Want: Locker(nil),
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	return l.Lock(ctx, append(pr.Key.Copy(), key...))
+}
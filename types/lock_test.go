@@ -0,0 +1,60 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+func TestLocks(t *testing.T) {
+	var (
+		l   types.Locks
+		ctx = context.Background()
+	)
+
+	unlock, err := l.Lock(ctx, types.Key{"foo", "bar"})
+	if err != nil {
+		t.Fatalf("Lock()=%+v", err)
+	}
+
+	for _, key := range []types.Key{{"foo"}, {"foo", "bar"}, {"foo", "bar", "baz"}} {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+
+		if _, err := l.Lock(ctx, key); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("%s: got %+v, want %+v", key, err, context.DeadlineExceeded)
+		}
+
+		cancel()
+	}
+
+	var (
+		pr   = types.PrefixReader(lockedMap{newM(), &l}, "foo")
+		done = make(chan error)
+	)
+
+	go func() {
+		unlock, err := pr.Lock(ctx, types.Key{"bar", "dir"})
+		if err == nil {
+			unlock()
+		}
+		done <- err
+	}()
+
+	unlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Lock()=%+v", err)
+	}
+
+	if _, err := types.PrefixReader(newM(), "foo").Lock(ctx, nil); !errors.Is(err, types.ErrUnexpectedType) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrUnexpectedType)
+	}
+}
+
+type lockedMap struct {
+	types.Map
+	*types.Locks
+}
@@ -6,6 +6,10 @@ const (
 	TypeMap    Type = "Map"
 	TypeSlice  Type = "Slice"
 	TypeStruct Type = "Struct"
+
+	// TypeBytes is the type of []byte leaves. Codecs without a native
+	// binary type encode them as base64 strings.
+	TypeBytes Type = "Bytes"
 )
 
 func Make(v any) Interface {
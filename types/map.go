@@ -8,8 +8,10 @@ import (
 type Map map[string]any
 
 var (
-	_ Interface = Map(nil)
-	_ ListerTo  = Map(nil)
+	_ Interface   = Map(nil)
+	_ ListerTo    = Map(nil)
+	_ SafeLister  = Map(nil)
+	_ ValueLister = Map(nil)
 )
 
 func (m Map) Type() Type {
@@ -27,11 +29,30 @@ func (m Map) List(ctx context.Context) []string {
 	return keys
 }
 
+func (m Map) SafeList(ctx context.Context) ([]string, error) {
+	return m.List(ctx), nil
+}
+
 func (m Map) ListTo(ctx context.Context, keys *[]string) {
+	var n = len(*keys)
+
 	for k := range m {
 		*keys = append(*keys, k)
 	}
-	sort.Strings(*keys)
+
+	sort.Strings((*keys)[n:])
+}
+
+func (m Map) ListValues(ctx context.Context) ([]KV, error) {
+	kvs := make([]KV, 0, len(m))
+
+	for k, v := range m {
+		kvs = append(kvs, KV{Key: k, Value: tryMake(v)})
+	}
+
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+	return kvs, nil
 }
 
 func (m Map) Del(ctx context.Context, key string) bool {
@@ -0,0 +1,193 @@
+package types
+
+import "context"
+
+// Middleware wraps an Interface with a cross-cutting concern, e.g.
+//
+//	func(next Interface) Interface { return Limit(next, nil) }
+type Middleware func(next Interface) Interface
+
+// Chain composes the middlewares, so that the first one is the
+// outermost, i.e. it sees operations first.
+func Chain(mws ...Middleware) Middleware {
+	return func(next Interface) Interface {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// Call is an operation intercepted with Intercept. Key is the full
+// key of the operation, relative to the intercepted Interface; it is
+// the key of the listed subtree for List.
+type Call struct {
+	Op    string
+	Key   Key
+	Value any  // for Set
+	Hint  Type // for Put
+}
+
+// Handler performs an intercepted operation, giving the value for
+// Get, []string for List, the previous flag for Set and the Writer
+// for Put.
+type Handler func(ctx context.Context, c Call) (any, error)
+
+// InterceptFunc handles an operation, usually calling next.
+type InterceptFunc func(ctx context.Context, c Call, next Handler) (any, error)
+
+// Intercept gives a Middleware calling fn for each operation, so
+// that a concern, like logging or authorization, is a single func.
+// Subtrees read or put through the Interface are intercepted too.
+func Intercept(fn InterceptFunc) Middleware {
+	return func(next Interface) Interface {
+		return &Intercepted{
+			iface: next,
+			fn:    fn,
+		}
+	}
+}
+
+// Intercepted is an Interface, which operations are handled by
+// an InterceptFunc.
+type Intercepted struct {
+	iface Interface
+	key   Key
+	fn    InterceptFunc
+}
+
+var (
+	_ Interface  = (*Intercepted)(nil)
+	_ SafeReader = (*Intercepted)(nil)
+	_ SafeLister = (*Intercepted)(nil)
+	_ SafeWriter = (*Intercepted)(nil)
+)
+
+func (i *Intercepted) Type() Type {
+	return i.iface.Type()
+}
+
+func (i *Intercepted) Get(ctx context.Context, key string) (any, bool) {
+	v, err := i.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (i *Intercepted) SafeGet(ctx context.Context, key string) (any, error) {
+	return i.fn(ctx, Call{Op: "Get", Key: i.path(key)}, func(ctx context.Context, _ Call) (any, error) {
+		v, err := PrefixedReader{R: i.iface}.SafeGet(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if iface, ok := v.(Interface); ok {
+			return i.child(iface, key), nil
+		}
+
+		return v, nil
+	})
+}
+
+func (i *Intercepted) List(ctx context.Context) []string {
+	keys, _ := i.SafeList(ctx)
+	return keys
+}
+
+func (i *Intercepted) SafeList(ctx context.Context) ([]string, error) {
+	v, err := i.fn(ctx, Call{Op: "List", Key: i.key.Copy()}, func(ctx context.Context, _ Call) (any, error) {
+		return List(ctx, i.iface)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys, _ := v.([]string)
+
+	return keys, nil
+}
+
+func (i *Intercepted) Del(ctx context.Context, key string) bool {
+	return i.SafeDel(ctx, key) == nil
+}
+
+func (i *Intercepted) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := i.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (i *Intercepted) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := i.SafePut(ctx, key, hint)
+	return w
+}
+
+func (i *Intercepted) SafeDel(ctx context.Context, key string) error {
+	_, err := i.fn(ctx, Call{Op: "Del", Key: i.path(key)}, func(ctx context.Context, _ Call) (any, error) {
+		return nil, PrefixedWriter{W: i.iface}.SafeDel(ctx, key)
+	})
+
+	return err
+}
+
+// SafeSet sets the value of the Call given to the handler, so that
+// fn may alter it.
+func (i *Intercepted) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	v, err := i.fn(ctx, Call{Op: "Set", Key: i.path(key), Value: value}, func(ctx context.Context, c Call) (any, error) {
+		return PrefixedWriter{W: i.iface}.SafeSet(ctx, key, c.Value)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	ok, _ := v.(bool)
+
+	return ok, nil
+}
+
+func (i *Intercepted) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	v, err := i.fn(ctx, Call{Op: "Put", Key: i.path(key), Hint: hint}, func(ctx context.Context, c Call) (any, error) {
+		w, err := PrefixedWriter{W: i.iface}.SafePut(ctx, key, c.Hint)
+		if err != nil {
+			return nil, err
+		}
+
+		if iface, ok := w.(Interface); ok {
+			return i.child(iface, key), nil
+		}
+
+		return w, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := v.(Writer)
+
+	return w, nil
+}
+
+func (i *Intercepted) Ping(ctx context.Context) error {
+	return Ping(ctx, i.iface)
+}
+
+func (i *Intercepted) Close(ctx context.Context) error {
+	return Close(ctx, i.iface)
+}
+
+func (i *Intercepted) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (i *Intercepted) Unwrap() any {
+	return i.iface
+}
+
+func (i *Intercepted) child(iface Interface, key string) *Intercepted {
+	return &Intercepted{
+		iface: iface,
+		key:   i.path(key),
+		fn:    i.fn,
+	}
+}
+
+func (i *Intercepted) path(key string) Key {
+	return append(i.key.Copy(), key)
+}
@@ -0,0 +1,66 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChain(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m    = types.Map{"db": types.Map{"host": "localhost"}}
+		log  []string
+		deny = errors.New("denied")
+	)
+
+	logging := types.Intercept(func(ctx context.Context, c types.Call, next types.Handler) (any, error) {
+		v, err := next(ctx, c)
+		log = append(log, c.Op+" "+c.Key.String())
+		return v, err
+	})
+
+	auth := types.Intercept(func(ctx context.Context, c types.Call, next types.Handler) (any, error) {
+		if c.Op != "Get" && c.Op != "List" && c.Key.Base() == "secret" {
+			return nil, deny
+		}
+		return next(ctx, c)
+	})
+
+	upper := types.Intercept(func(ctx context.Context, c types.Call, next types.Handler) (any, error) {
+		if s, ok := c.Value.(string); ok {
+			c.Value = s + "!"
+		}
+		return next(ctx, c)
+	})
+
+	iface := types.Chain(logging, auth, upper)(m)
+
+	if _, err := objects.Set(ctx, iface, "db.local", "db", "host"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, iface, "x", "db", "secret"); !errors.Is(err, deny) {
+		t.Fatalf("Set()=%+v, want %v", err, deny)
+	}
+
+	if got, want := m["db"].(types.Map)["host"], "db.local!"; got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	wantLog := []string{
+		"Get db",
+		"Set db.host",
+		"Get db",
+		"Set db.secret",
+	}
+
+	if diff := cmp.Diff(wantLog, log); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,235 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// Observed is a Watcher of writes made through it to an Interface,
+// e.g. a Map, which has no change notifications of its own.
+//
+// Each write is an event with the written key: a Set of a subtree
+// is a single Set event with the subtree as its value and a Del of
+// a subtree is a single Del event.
+type Observed struct {
+	iface Interface
+	key   Key
+	o     *observers
+}
+
+type observers struct {
+	mu   sync.Mutex
+	subs map[*observer]struct{}
+}
+
+type observer struct {
+	key    Key
+	mu     sync.Mutex
+	queue  []Event
+	notify chan struct{}
+}
+
+var (
+	_ Interface  = (*Observed)(nil)
+	_ SafeReader = (*Observed)(nil)
+	_ SafeLister = (*Observed)(nil)
+	_ SafeWriter = (*Observed)(nil)
+	_ Watcher    = (*Observed)(nil)
+)
+
+func Observe(iface Interface) *Observed {
+	return &Observed{
+		iface: iface,
+		o: &observers{
+			subs: make(map[*observer]struct{}),
+		},
+	}
+}
+
+func (o *Observed) Type() Type {
+	return o.iface.Type()
+}
+
+func (o *Observed) Get(ctx context.Context, key string) (any, bool) {
+	v, err := o.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (o *Observed) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: o.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := v.(Interface); ok {
+		return o.child(iface, key), nil
+	}
+
+	return v, nil
+}
+
+func (o *Observed) List(ctx context.Context) []string {
+	keys, _ := o.SafeList(ctx)
+	return keys
+}
+
+func (o *Observed) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, o.iface)
+}
+
+func (o *Observed) Del(ctx context.Context, key string) bool {
+	return o.SafeDel(ctx, key) == nil
+}
+
+func (o *Observed) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := o.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (o *Observed) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := o.SafePut(ctx, key, hint)
+	return w
+}
+
+func (o *Observed) SafeDel(ctx context.Context, key string) error {
+	if err := (PrefixedWriter{W: o.iface}).SafeDel(ctx, key); err != nil {
+		return err
+	}
+
+	o.o.publish(ctx, Event{Op: "Del", Key: o.path(key)})
+
+	return nil
+}
+
+func (o *Observed) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	ok, err := PrefixedWriter{W: o.iface}.SafeSet(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+
+	o.o.publish(ctx, Event{Op: "Set", Key: o.path(key), Value: value})
+
+	return ok, nil
+}
+
+func (o *Observed) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	w, err := PrefixedWriter{W: o.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return o.child(iface, key), nil
+	}
+
+	return w, nil
+}
+
+// Watch delivers events of writes to the key and its descendants,
+// or to the whole tree for an empty key, until ctx is done. A write
+// to an ancestor of the key is delivered as an event of the key
+// itself: a Set with the new value of the key, or a Del if the
+// written value has no such key. Keys of
+// the events are relative to o. Events are queued, so writers are
+// never blocked by slow watchers.
+func (o *Observed) Watch(ctx context.Context, key string) <-chan Event {
+	var (
+		ch  = make(chan Event)
+		sub = &observer{
+			key:    o.key.Copy(),
+			notify: make(chan struct{}, 1),
+		}
+	)
+
+	if key != "" {
+		sub.key = append(sub.key, key)
+	}
+
+	o.o.mu.Lock()
+	o.o.subs[sub] = struct{}{}
+	o.o.mu.Unlock()
+
+	go func() {
+		defer close(ch)
+
+		defer func() {
+			o.o.mu.Lock()
+			delete(o.o.subs, sub)
+			o.o.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-sub.notify:
+			case <-ctx.Done():
+				return
+			}
+
+			sub.mu.Lock()
+			queue := sub.queue
+			sub.queue = nil
+			sub.mu.Unlock()
+
+			for _, ev := range queue {
+				ev.Key = ev.Key[len(o.key):]
+
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+func (o *Observed) Ping(ctx context.Context) error {
+	return Ping(ctx, o.iface)
+}
+
+func (o *Observed) Close(ctx context.Context) error {
+	return Close(ctx, o.iface)
+}
+
+func (o *Observed) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (o *Observed) Unwrap() any {
+	return o.iface
+}
+
+func (o *Observed) child(iface Interface, key string) *Observed {
+	return &Observed{
+		iface: iface,
+		key:   o.path(key),
+		o:     o.o,
+	}
+}
+
+func (o *Observed) path(key string) Key {
+	return append(o.key.Copy(), key)
+}
+
+func (o *observers) publish(ctx context.Context, ev Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for sub := range o.subs {
+		e, ok := scope(ctx, ev, sub.key)
+		if !ok {
+			continue
+		}
+
+		sub.mu.Lock()
+		sub.queue = append(sub.queue, e)
+		sub.mu.Unlock()
+
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
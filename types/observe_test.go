@@ -0,0 +1,114 @@
+package types_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestObserved(t *testing.T) {
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		o           = types.Observe(types.Map{"db": types.Map{}, "name": "app"})
+		all         = o.Watch(ctx, "")
+		db          = types.Prefix(o, "db").Watch(ctx, "")
+	)
+	defer cancel()
+
+	next := func(ch <-chan types.Event) string {
+		t.Helper()
+
+		select {
+		case ev := <-ch:
+			if ev.Value == nil {
+				return ev.Op + " " + ev.Key.String()
+			}
+			return fmt.Sprintf("%s %s %v", ev.Op, ev.Key, ev.Value)
+		case <-ctx.Done():
+			t.Fatalf("no event: %+v", ctx.Err())
+			return ""
+		}
+	}
+
+	if _, err := objects.Set(ctx, o, "localhost", "db", "host"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, o, "name"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, o, "db", "host"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	got := []string{next(all), next(all), next(all), next(db), next(db)}
+
+	want := []string{
+		"Set db.host localhost",
+		"Del name",
+		"Del db.host",
+		"Set host localhost",
+		"Del host",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	cancel()
+
+	for range db {
+	}
+}
+
+func TestObservedAncestorWrites(t *testing.T) {
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		o           = types.Observe(types.Map{"a": types.Map{"b": types.Map{"c": 1}}})
+		ab          = types.PrefixReader(o, "a").Watch(ctx, "b")
+	)
+	defer cancel()
+
+	next := func() types.Event {
+		t.Helper()
+
+		select {
+		case ev := <-ab:
+			return ev
+		case <-ctx.Done():
+			t.Fatalf("no event: %+v", ctx.Err())
+			return types.Event{}
+		}
+	}
+
+	if _, err := o.SafeSet(ctx, "a", types.Map{"b": types.Map{"c": 2}}); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if _, err := o.SafeSet(ctx, "a", types.Map{"x": 3}); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if err := o.SafeDel(ctx, "a"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	got := []types.Event{next(), next(), next()}
+
+	want := []types.Event{
+		{Op: "Set", Key: types.Key{"b"}, Value: types.Map{"c": 2}},
+		{Op: "Del", Key: types.Key{"b"}},
+		{Op: "Del", Key: types.Key{"b"}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,300 @@
+package types
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore stores large values outside of the tree.
+type BlobStore interface {
+	PutBlob(ctx context.Context, p []byte) (id string, err error)
+	GetBlob(ctx context.Context, id string) ([]byte, error)
+}
+
+type OffloadOptions struct {
+	// Threshold is the size in bytes above which string and []byte
+	// values are moved to the blob store.
+	Threshold int
+
+	// Prefix marks references to blobs stored in the tree.
+	Prefix string
+}
+
+var DefaultOffloadOptions = &OffloadOptions{
+	Threshold: 64 << 10,
+	Prefix:    "blob:",
+}
+
+// Offloaded moves large leaf values of an Interface to a blob store
+// and keeps only references to them in the tree. References are
+// resolved transparently on reads.
+//
+// Blobs are not removed when values referencing them are deleted
+// or overwritten.
+type Offloaded struct {
+	iface Interface
+	o     *offloader
+}
+
+type offloader struct {
+	opts  *OffloadOptions
+	blobs BlobStore
+}
+
+var (
+	_ Interface  = (*Offloaded)(nil)
+	_ SafeReader = (*Offloaded)(nil)
+	_ SafeLister = (*Offloaded)(nil)
+	_ SafeWriter = (*Offloaded)(nil)
+)
+
+func Offload(iface Interface, blobs BlobStore, opts *OffloadOptions) *Offloaded {
+	if opts == nil {
+		opts = DefaultOffloadOptions
+	}
+
+	return &Offloaded{
+		iface: iface,
+		o: &offloader{
+			opts:  opts,
+			blobs: blobs,
+		},
+	}
+}
+
+func (o *Offloaded) Type() Type {
+	return o.iface.Type()
+}
+
+func (o *Offloaded) Get(ctx context.Context, key string) (any, bool) {
+	v, err := o.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (o *Offloaded) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: o.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := v.(Interface); ok {
+		return &Offloaded{iface: iface, o: o.o}, nil
+	}
+
+	return o.o.resolve(ctx, key, v)
+}
+
+func (o *Offloaded) List(ctx context.Context) []string {
+	keys, _ := o.SafeList(ctx)
+	return keys
+}
+
+func (o *Offloaded) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, o.iface)
+}
+
+func (o *Offloaded) Del(ctx context.Context, key string) bool {
+	return o.SafeDel(ctx, key) == nil
+}
+
+func (o *Offloaded) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := o.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (o *Offloaded) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := o.SafePut(ctx, key, hint)
+	return w
+}
+
+func (o *Offloaded) SafeDel(ctx context.Context, key string) error {
+	return PrefixedWriter{W: o.iface}.SafeDel(ctx, key)
+}
+
+func (o *Offloaded) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	v, err := o.o.offload(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+
+	return PrefixedWriter{W: o.iface}.SafeSet(ctx, key, v)
+}
+
+func (o *Offloaded) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	w, err := PrefixedWriter{W: o.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return &Offloaded{iface: iface, o: o.o}, nil
+	}
+
+	return w, nil
+}
+
+func (o *Offloaded) Ping(ctx context.Context) error {
+	return Ping(ctx, o.iface, o.o.blobs)
+}
+
+func (o *Offloaded) Close(ctx context.Context) error {
+	return Close(ctx, o.iface, o.o.blobs)
+}
+
+func (o *Offloaded) Unwrap() any {
+	return o.iface
+}
+
+// References have the form <prefix><kind>:<id>, where kind tells
+// whether the value was a string or []byte. Strings kept in the tree,
+// which start with the prefix, are escaped with another prefix, so
+// that they are not mistaken for references.
+const (
+	blobString = "string:"
+	blobBytes  = "bytes:"
+)
+
+func (o *offloader) offload(ctx context.Context, key string, value any) (any, error) {
+	var (
+		p    []byte
+		kind string
+	)
+
+	switch v := value.(type) {
+	case string:
+		p, kind = []byte(v), blobString
+	case []byte:
+		p, kind = v, blobBytes
+	default:
+		return value, nil
+	}
+
+	if len(p) <= o.opts.Threshold {
+		if s, ok := value.(string); ok && o.opts.Prefix != "" && strings.HasPrefix(s, o.opts.Prefix) {
+			return o.opts.Prefix + s, nil
+		}
+
+		return value, nil
+	}
+
+	id, err := o.blobs.PutBlob(ctx, p)
+	if err != nil {
+		return nil, &Error{
+			Op:  "Set",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	return o.opts.Prefix + kind + id, nil
+}
+
+func (o *offloader) resolve(ctx context.Context, key string, v any) (any, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, o.opts.Prefix) {
+		return v, nil
+	}
+
+	var (
+		ref  = strings.TrimPrefix(s, o.opts.Prefix)
+		kind string
+	)
+
+	switch {
+	case o.opts.Prefix != "" && strings.HasPrefix(ref, o.opts.Prefix):
+		return ref, nil
+	case strings.HasPrefix(ref, blobString):
+		kind = blobString
+	case strings.HasPrefix(ref, blobBytes):
+		kind = blobBytes
+	default:
+		return v, nil
+	}
+
+	p, err := o.blobs.GetBlob(ctx, strings.TrimPrefix(ref, kind))
+	if err != nil {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Got: s,
+			Err: err,
+		}
+	}
+
+	if kind == blobString {
+		return string(p), nil
+	}
+
+	return p, nil
+}
+
+// DirStore is a BlobStore keeping blobs as files in a directory,
+// named after the SHA-256 of their content.
+type DirStore string
+
+var _ BlobStore = DirStore("")
+
+func (d DirStore) PutBlob(ctx context.Context, p []byte) (string, error) {
+	var (
+		sum  = sha256.Sum256(p)
+		id   = hex.EncodeToString(sum[:])
+		path = filepath.Join(string(d), id)
+	)
+
+	if _, err := os.Stat(path); err == nil {
+		return id, nil
+	}
+
+	if err := os.MkdirAll(string(d), 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(string(d), id+".*")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (d DirStore) GetBlob(ctx context.Context, id string) ([]byte, error) {
+	if strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, &Error{
+			Op:  "GetBlob",
+			Got: id,
+			Err: ErrNotFound,
+		}
+	}
+
+	p, err := os.ReadFile(filepath.Join(string(d), id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &Error{
+			Op:  "GetBlob",
+			Got: id,
+			Err: ErrNotFound,
+		}
+	}
+
+	return p, err
+}
@@ -0,0 +1,107 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOffload(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		dir   = t.TempDir()
+		m     = types.Map{}
+		o     = types.Offload(m, types.DirStore(dir), &types.OffloadOptions{Threshold: 8, Prefix: "blob:"})
+		large = strings.Repeat("x", 16)
+	)
+
+	sets := map[string]any{
+		"small": "abc",
+		"large": large,
+		"bytes": []byte(large),
+		"int":   42,
+	}
+
+	for k, v := range sets {
+		if _, err := o.SafeSet(ctx, k, v); err != nil {
+			t.Fatalf("SafeSet(%q)=%+v", k, err)
+		}
+	}
+
+	for _, k := range []string{"large", "bytes"} {
+		if s, _ := m[k].(string); !strings.HasPrefix(s, "blob:") {
+			t.Fatalf("%q: got %#v, want reference", k, m[k])
+		}
+	}
+
+	if m["small"] != "abc" || m["int"] != 42 {
+		t.Fatalf("got %#v, want small values inline", m)
+	}
+
+	for k, want := range sets {
+		got, err := o.SafeGet(ctx, k)
+		if err != nil {
+			t.Fatalf("SafeGet(%q)=%+v", k, err)
+		}
+
+		if !cmp.Equal(got, want) {
+			t.Fatalf("%q: got != want:\n%s", k, cmp.Diff(got, want))
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir()=%+v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("got %d blobs, want 1", len(entries))
+	}
+
+	w, err := o.SafePut(ctx, "nested", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	if _, err := w.(types.SafeWriter).SafeSet(ctx, "large", large); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if v, err := types.PrefixReader(o, "nested").SafeGet(ctx, "large"); err != nil || v != large {
+		t.Fatalf("SafeGet()=%#v, %+v", v, err)
+	}
+
+	m["missing"] = "blob:string:0000"
+
+	if _, err := o.SafeGet(ctx, "missing"); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrNotFound)
+	}
+}
+
+func TestOffloadPrefixedStrings(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{}
+		o   = types.Offload(m, types.DirStore(t.TempDir()), nil)
+	)
+
+	for _, s := range []string{"blob:string:0000", "blob:blob:bytes:0000", "blob:"} {
+		if _, err := o.SafeSet(ctx, "key", s); err != nil {
+			t.Fatalf("SafeSet(%q)=%+v", s, err)
+		}
+
+		if got, want := m["key"], "blob:"+s; got != want {
+			t.Fatalf("got %#v, want %#v", got, want)
+		}
+
+		if got, err := o.SafeGet(ctx, "key"); err != nil || got != s {
+			t.Fatalf("SafeGet()=%#v, %+v, want %#v", got, err, s)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package types
+
+import "context"
+
+// Ping checks health of each of the given values, which implement
+// Pinger, and reports all the failures. Values that do not implement
+// Pinger are assumed to be healthy.
+func Ping(ctx context.Context, vs ...any) error {
+	var errs Errors
+
+	for _, v := range vs {
+		p, ok := v.(Pinger)
+		if !ok {
+			continue
+		}
+
+		if err := p.Ping(ctx); err != nil {
+			errs = append(errs, &Error{
+				Op:  "Ping",
+				Err: err,
+			})
+		}
+	}
+
+	return errs.Err()
+}
@@ -0,0 +1,44 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+type pingMap struct {
+	types.Map
+	err error
+}
+
+func (pm pingMap) Ping(context.Context) error {
+	return pm.err
+}
+
+func TestPing(t *testing.T) {
+	var (
+		errDown = errors.New("backend is down")
+		up      = pingMap{Map: newM()}
+		down    = pingMap{Map: newM(), err: errDown}
+		ctx     = context.Background()
+	)
+
+	if err := types.Ping(ctx, types.Prefix(up, "foo"), types.Debounce(up, time.Second, nil), newM()); err != nil {
+		t.Fatalf("Ping()=%+v", err)
+	}
+
+	err := types.Ping(ctx, types.PrefixReader(down, "foo"), types.Edit(down), up)
+
+	var errs types.Errors
+
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %+v, want 2 errors", err)
+	}
+
+	if !errors.Is(err, errDown) {
+		t.Fatalf("got %+v, want %+v", err, errDown)
+	}
+}
@@ -21,7 +21,11 @@ var (
 	_ Reader        = PrefixedReader{}
 	_ Writer        = PrefixedWriter{}
 	_ SafeReader    = PrefixedReader{}
+	_ SafeLister    = PrefixedReader{}
+	_ ListerTo      = PrefixedReader{}
+	_ ValueLister   = PrefixedReader{}
 	_ SafeWriter    = PrefixedWriter{}
+	_ Watcher       = PrefixedReader{}
 	_ Interface     = Prefixed{}
 	_ SafeInterface = Prefixed{}
 )
@@ -59,12 +63,52 @@ func (pr PrefixedReader) Get(ctx context.Context, key string) (value any, ok boo
 }
 
 func (pr PrefixedReader) List(ctx context.Context) []string {
+	keys, _ := pr.SafeList(ctx)
+	return keys
+}
+
+func (pr PrefixedReader) SafeList(ctx context.Context) ([]string, error) {
 	r, err := pr.base(ctx, "List")
 	if err != nil {
-		return nil
+		return nil, err
+	}
+
+	keys, err := List(ctx, r)
+	if err != nil {
+		return nil, &Error{
+			Op:  "List",
+			Key: pr.Key,
+			Got: r,
+			Err: err,
+		}
 	}
 
-	return r.List(ctx)
+	return keys, nil
+}
+
+func (pr PrefixedReader) ListTo(ctx context.Context, keys *[]string) {
+	if r, err := pr.base(ctx, "List"); err == nil {
+		ListTo(ctx, r, keys)
+	}
+}
+
+func (pr PrefixedReader) ListValues(ctx context.Context) ([]KV, error) {
+	r, err := pr.base(ctx, "List")
+	if err != nil {
+		return nil, err
+	}
+
+	kvs, err := ListValues(ctx, r)
+	if err != nil {
+		return nil, &Error{
+			Op:  "List",
+			Key: pr.Key,
+			Got: r,
+			Err: err,
+		}
+	}
+
+	return kvs, nil
 }
 
 func (pr PrefixedReader) Type() Type {
@@ -316,3 +360,99 @@ func (pw PrefixedWriter) writer() (Writer, Key) {
 
 	return nil, nil
 }
+
+func (pr PrefixedReader) Ping(ctx context.Context) error {
+	return Ping(ctx, pr.R)
+}
+
+func (pw PrefixedWriter) Ping(ctx context.Context) error {
+	return Ping(ctx, pw.W)
+}
+
+func (p Prefixed) Ping(ctx context.Context) error {
+	return Ping(ctx, p.PrefixedReader.R, p.PrefixedWriter.W)
+}
+
+// Watch watches the key under the prefix, with keys of events
+// relative to the prefix.
+func (pr PrefixedReader) Watch(ctx context.Context, key string) <-chan Event {
+	var w Watcher
+
+	if !As(pr.R, &w) {
+		ch := make(chan Event)
+		close(ch)
+		return ch
+	}
+
+	if len(pr.Key) == 0 {
+		return w.Watch(ctx, key)
+	}
+
+	var (
+		ch   = make(chan Event)
+		full = pr.Key.Copy()
+	)
+
+	if key != "" {
+		full = append(full, key)
+	}
+
+	events := w.Watch(ctx, full[0])
+
+	go func() {
+		defer close(ch)
+
+		for ev := range events {
+			ev, ok := scope(ctx, ev, full)
+			if !ok {
+				continue
+			}
+
+			ev.Key = ev.Key[len(pr.Key):]
+
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+func (pr PrefixedReader) Close(ctx context.Context) error {
+	return Close(ctx, pr.R)
+}
+
+func (pw PrefixedWriter) Close(ctx context.Context) error {
+	return Close(ctx, pw.W)
+}
+
+func (p Prefixed) Close(ctx context.Context) error {
+	return Close(ctx, p.PrefixedReader.R)
+}
+
+func (pr PrefixedReader) forwards() Capability {
+	return CapWatcher | CapLocker | CapPinger | CapCloser
+}
+
+func (pw PrefixedWriter) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (p Prefixed) forwards() Capability {
+	return CapWatcher | CapLocker | CapPinger | CapCloser
+}
+
+func (pr PrefixedReader) Unwrap() any {
+	return pr.R
+}
+
+func (pw PrefixedWriter) Unwrap() any {
+	return pw.W
+}
+
+func (p Prefixed) Unwrap() any {
+	return p.PrefixedReader.R
+}
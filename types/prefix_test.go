@@ -161,3 +161,40 @@ func TestPrefixedWriter(t *testing.T) {
 		t.Fatalf("got %#v, want %#v", v, "foo")
 	}
 }
+
+func TestPrefixedReaderSafeList(t *testing.T) {
+	var (
+		m   = newM()
+		ctx = context.Background()
+	)
+
+	keys, err := types.PrefixReader(m, "foo", "bar").SafeList(ctx)
+	if err != nil {
+		t.Fatalf("SafeList()=%+v", err)
+	}
+
+	if want := []string{"dir", "file"}; !cmp.Equal(keys, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(keys, want))
+	}
+
+	_, err = types.PrefixReader(m, "foo", "notfound").SafeList(ctx)
+
+	if e := (&types.Error{}); !types.ErrAs(err, e, types.IsSentinelErr(types.ErrNotFound)) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrNotFound)
+	}
+}
+
+func TestPrefixedReaderListTo(t *testing.T) {
+	var (
+		m    = newM()
+		ctx  = context.Background()
+		keys = make([]string, 0, 4)
+	)
+
+	types.PrefixReader(types.SoftDelete(m, 0, nil), "foo", "bar").ListTo(ctx, &keys)
+	types.PrefixReader(types.Edit(m), "foo", "bar", "dir").ListTo(ctx, &keys)
+
+	if want := []string{"dir", "file", "1", "2", "3"}; !cmp.Equal(keys, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(keys, want))
+	}
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// Position is the location of a value in a source file. Line and
+// Column are 1-based.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Provenance is implemented by readers of parsed files, which know
+// source positions of their values.
+type Provenance interface {
+	// Position gives the position of the value under the key,
+	// relative to the reader.
+	Position(key Key) (Position, bool)
+}
+
+// PositionOf gives the source position of the value under the key
+// of r. It asks the first reader on the key path, which implements
+// Provenance, directly or through Unwrap.
+func PositionOf(ctx context.Context, r Reader, key Key) (Position, bool) {
+	for i := range key {
+		var p Provenance
+
+		if As(r, &p) {
+			return p.Position(key[i:])
+		}
+
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, key[i])
+		if err != nil {
+			return Position{}, false
+		}
+
+		if r, _ = v.(Reader); r == nil {
+			return Position{}, false
+		}
+	}
+
+	return Position{}, false
+}
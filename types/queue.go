@@ -0,0 +1,377 @@
+package types
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+type QueuePolicy int
+
+const (
+	// QueueBlock makes mutations wait for a free slot in a full queue.
+	QueueBlock QueuePolicy = iota
+	// QueueDrop evicts the lowest-priority queued mutation to make room
+	// for a higher-priority one, rejecting the mutation otherwise.
+	QueueDrop
+)
+
+type QueueOptions struct {
+	// Size is the maximum number of queued mutations; the size of
+	// DefaultQueueOptions is used if it is not positive.
+	Size   int
+	Policy QueuePolicy
+}
+
+var DefaultQueueOptions = &QueueOptions{
+	Size:   1024,
+	Policy: QueueBlock,
+}
+
+type priorityKey struct{}
+
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func Priority(ctx context.Context) int {
+	p, _ := ctx.Value(priorityKey{}).(int)
+	return p
+}
+
+// Queued applies mutations asynchronously, in order of their priority
+// (see WithPriority) and then in order of arrival. Put is applied
+// synchronously, so that mutations of the writer it returns
+// can be queued as well.
+type Queued struct {
+	w   Writer
+	key Key
+	q   *queue
+}
+
+type queue struct {
+	opts     QueueOptions
+	mu       sync.Mutex
+	items    mutations
+	seq      uint64
+	errs     Errors
+	closed   bool
+	notEmpty chan struct{}
+	notFull  chan struct{}
+	closing  chan struct{}
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+type mutation struct {
+	priority int
+	seq      uint64
+	op       string
+	w        Writer
+	key      Key
+	value    any
+	token    string
+}
+
+type mutations []*mutation
+
+var (
+	_ Writer     = (*Queued)(nil)
+	_ SafeWriter = (*Queued)(nil)
+)
+
+func Queue(w Writer, opts *QueueOptions) *Queued {
+	if opts == nil {
+		opts = DefaultQueueOptions
+	}
+
+	q := &queue{
+		opts:     *opts,
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	if q.opts.Size <= 0 {
+		q.opts.Size = DefaultQueueOptions.Size
+	}
+
+	go q.run()
+
+	return &Queued{
+		w: w,
+		q: q,
+	}
+}
+
+func (q *Queued) Del(ctx context.Context, key string) bool {
+	return q.SafeDel(ctx, key) == nil
+}
+
+func (q *Queued) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := q.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (q *Queued) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := q.SafePut(ctx, key, hint)
+	return w
+}
+
+func (q *Queued) SafeDel(ctx context.Context, key string) error {
+	return q.q.push(ctx, &mutation{
+		priority: Priority(ctx),
+		op:       "Del",
+		w:        q.w,
+		key:      append(q.key.Copy(), key),
+		token:    token(ctx),
+	})
+}
+
+// SafeSet enqueues the mutation, thus it never reports a previous value.
+func (q *Queued) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	return false, q.q.push(ctx, &mutation{
+		priority: Priority(ctx),
+		op:       "Set",
+		w:        q.w,
+		key:      append(q.key.Copy(), key),
+		value:    value,
+		token:    token(ctx),
+	})
+}
+
+func (q *Queued) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	var (
+		w   Writer
+		err error
+	)
+
+	if sw, ok := q.w.(SafeWriter); ok {
+		if w, err = sw.SafePut(ctx, key, hint); err != nil {
+			return nil, err
+		}
+	} else {
+		w = q.w.Put(ctx, key, hint)
+	}
+
+	return &Queued{
+		w:   w,
+		key: append(q.key.Copy(), key),
+		q:   q.q,
+	}, nil
+}
+
+// Close stops accepting mutations and waits for the queued ones
+// to be applied, after which it closes the wrapped Writer.
+// Mutations left unapplied once ctx is done and failed ones
+// are reported in the returned Errors; the one being applied
+// at that time is cancelled and waited for.
+func (q *Queued) Close(ctx context.Context) error {
+	q.q.mu.Lock()
+	if !q.q.closed {
+		q.q.closed = true
+		close(q.q.closing)
+	}
+	q.q.mu.Unlock()
+
+	signal(q.q.notEmpty)
+
+	select {
+	case <-q.q.done:
+	case <-ctx.Done():
+		q.q.mu.Lock()
+		for _, m := range q.q.items {
+			q.q.errs = append(q.q.errs, m.err(ctx.Err()))
+		}
+		q.q.items = nil
+		q.q.mu.Unlock()
+
+		q.q.cancel()
+		signal(q.q.notEmpty)
+
+		<-q.q.done
+	}
+
+	q.q.mu.Lock()
+	errs := q.q.errs
+	q.q.errs = nil
+	q.q.mu.Unlock()
+
+	return Close(ctx, errs.Err(), q.w)
+}
+
+func (q *queue) push(ctx context.Context, m *mutation) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.items) >= q.opts.Size {
+		if q.opts.Policy == QueueDrop {
+			if i := q.items.lowest(); q.items[i].priority < m.priority {
+				q.errs = append(q.errs, heap.Remove(&q.items, i).(*mutation).err(ErrQueueFull))
+				break
+			}
+
+			return m.err(ErrQueueFull)
+		}
+
+		q.mu.Unlock()
+		select {
+		case <-q.notFull:
+		case <-q.closing:
+		case <-ctx.Done():
+			q.mu.Lock()
+			return m.err(ctx.Err())
+		}
+		q.mu.Lock()
+	}
+
+	if q.closed {
+		return m.err(ErrClosed)
+	}
+
+	q.seq++
+	m.seq = q.seq
+
+	heap.Push(&q.items, m)
+
+	if len(q.items) < q.opts.Size {
+		signal(q.notFull)
+	}
+
+	signal(q.notEmpty)
+
+	return nil
+}
+
+func (q *queue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.mu.Unlock()
+			<-q.notEmpty
+			q.mu.Lock()
+		}
+
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		m := heap.Pop(&q.items).(*mutation)
+		q.mu.Unlock()
+
+		signal(q.notFull)
+
+		ctx := q.ctx
+
+		if m.token != "" {
+			ctx = WithIdempotencyKey(ctx, m.token)
+		}
+
+		if err := m.apply(ctx); err != nil {
+			q.mu.Lock()
+			q.errs = append(q.errs, err)
+			q.mu.Unlock()
+		}
+	}
+}
+
+func (m *mutation) apply(ctx context.Context) error {
+	var (
+		key = m.key.Base()
+		err error
+	)
+
+	switch sw, ok := m.w.(SafeWriter); {
+	case ok && m.op == "Set":
+		_, err = sw.SafeSet(ctx, key, m.value)
+	case ok:
+		err = sw.SafeDel(ctx, key)
+	case m.op == "Set":
+		m.w.Set(ctx, key, m.value)
+	case !m.w.Del(ctx, key):
+		err = ErrNotFound
+	}
+
+	if err != nil {
+		return m.err(err)
+	}
+
+	return nil
+}
+
+func (m *mutation) err(err error) error {
+	return &Error{
+		Op:  m.op,
+		Key: m.key,
+		Got: m.value,
+		Err: err,
+	}
+}
+
+func (ms mutations) Len() int {
+	return len(ms)
+}
+
+func (ms mutations) Less(i, j int) bool {
+	if ms[i].priority != ms[j].priority {
+		return ms[i].priority > ms[j].priority
+	}
+	return ms[i].seq < ms[j].seq
+}
+
+func (ms mutations) Swap(i, j int) {
+	ms[i], ms[j] = ms[j], ms[i]
+}
+
+func (ms *mutations) Push(x any) {
+	*ms = append(*ms, x.(*mutation))
+}
+
+func (ms *mutations) Pop() any {
+	var (
+		n = len(*ms) - 1
+		m = (*ms)[n]
+	)
+
+	*ms = (*ms)[:n]
+
+	return m
+}
+
+func (ms mutations) lowest() int {
+	var j int
+
+	for i := range ms {
+		if ms.Less(j, i) {
+			j = i
+		}
+	}
+
+	return j
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queued) Ping(ctx context.Context) error {
+	return Ping(ctx, q.w)
+}
+
+func (q *Queued) forwards() Capability {
+	return CapPinger
+}
+
+func (q *Queued) Unwrap() any {
+	return q.w
+}
@@ -0,0 +1,155 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type gateWriter struct {
+	started chan struct{}
+	gate    chan struct{}
+	mu      sync.Mutex
+	keys    []string
+}
+
+func (gw *gateWriter) Del(context.Context, string) bool { return true }
+
+func (gw *gateWriter) Set(_ context.Context, key string, _ any) bool {
+	gw.started <- struct{}{}
+	<-gw.gate
+	gw.mu.Lock()
+	gw.keys = append(gw.keys, key)
+	gw.mu.Unlock()
+	return false
+}
+
+func (gw *gateWriter) Put(context.Context, string, types.Type) types.Writer { return gw }
+
+func TestQueue(t *testing.T) {
+	var (
+		gw  = &gateWriter{started: make(chan struct{}, 8), gate: make(chan struct{})}
+		q   = types.Queue(gw, &types.QueueOptions{Size: 3, Policy: types.QueueDrop})
+		ctx = context.Background()
+	)
+
+	if _, err := q.SafeSet(ctx, "first", nil); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	<-gw.started // the first mutation keeps the queue busy
+
+	for _, key := range []string{"bulk-1", "bulk-2", "bulk-3"} {
+		if _, err := q.SafeSet(ctx, key, nil); err != nil {
+			t.Fatalf("SafeSet()=%+v", err)
+		}
+	}
+
+	if _, err := q.SafeSet(ctx, "bulk-4", nil); !errors.Is(err, types.ErrQueueFull) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrQueueFull)
+	}
+
+	if _, err := q.SafeSet(types.WithPriority(ctx, 10), "critical", nil); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	close(gw.gate)
+
+	err := q.Close(ctx)
+	if !errors.Is(err, types.ErrQueueFull) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrQueueFull)
+	}
+
+	want := []string{"first", "critical", "bulk-1", "bulk-2"}
+
+	if !cmp.Equal(gw.keys, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(gw.keys, want))
+	}
+
+	if _, err := q.SafeSet(ctx, "late", nil); !errors.Is(err, types.ErrClosed) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrClosed)
+	}
+}
+
+func TestQueueZeroSize(t *testing.T) {
+	ctx := context.Background()
+
+	for _, policy := range []types.QueuePolicy{types.QueueBlock, types.QueueDrop} {
+		var (
+			m = make(types.Map)
+			q = types.Queue(m, &types.QueueOptions{Policy: policy})
+		)
+
+		if _, err := q.SafeSet(ctx, "key", "value"); err != nil {
+			t.Fatalf("SafeSet()=%+v", err)
+		}
+
+		if err := q.Close(ctx); err != nil {
+			t.Fatalf("Close()=%+v", err)
+		}
+
+		if got := m["key"]; got != "value" {
+			t.Fatalf("got %#v, want %#v", got, "value")
+		}
+	}
+}
+
+type ctxWriter struct {
+	started chan struct{}
+	mu      sync.Mutex
+	events  []string
+}
+
+func (cw *ctxWriter) Del(context.Context, string) bool { return true }
+
+func (cw *ctxWriter) Set(ctx context.Context, key string, _ any) bool {
+	cw.started <- struct{}{}
+	<-ctx.Done()
+	cw.mu.Lock()
+	cw.events = append(cw.events, "cancel "+key)
+	cw.mu.Unlock()
+	return false
+}
+
+func (cw *ctxWriter) Put(context.Context, string, types.Type) types.Writer { return cw }
+
+func (cw *ctxWriter) Close(context.Context) error {
+	cw.mu.Lock()
+	cw.events = append(cw.events, "close")
+	cw.mu.Unlock()
+	return nil
+}
+
+func TestQueueCloseTimeout(t *testing.T) {
+	var (
+		cw  = &ctxWriter{started: make(chan struct{}, 1)}
+		q   = types.Queue(cw, nil)
+		ctx = context.Background()
+	)
+
+	for _, key := range []string{"inflight", "queued"} {
+		if _, err := q.SafeSet(ctx, key, nil); err != nil {
+			t.Fatalf("SafeSet()=%+v", err)
+		}
+	}
+
+	<-cw.started
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if err := q.Close(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %+v, want %+v", err, context.Canceled)
+	}
+
+	want := []string{"cancel inflight", "close"}
+
+	if diff := cmp.Diff(want, cw.events); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,333 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// QuotaLimit limits the number of leaves under a prefix and their
+// total size in bytes. Zero values are not limited.
+type QuotaLimit struct {
+	MaxKeys  int
+	MaxBytes int64
+}
+
+// QuotaUsage is the number of leaves under a prefix and their
+// total size in bytes.
+type QuotaUsage struct {
+	Keys  int
+	Bytes int64
+}
+
+type QuotaOptions struct {
+	// Limits maps dot-separated prefixes to their quotas; the empty
+	// prefix limits the whole tree.
+	Limits map[string]QuotaLimit
+}
+
+// Quotas enforces quotas on prefixes of an Interface. Writes which
+// would exceed a quota fail with ErrQuotaExceeded.
+//
+// Usage of a prefix is computed on its first write and tracked
+// afterwards, so the tree must not be modified other than through
+// the wrapper.
+type Quotas struct {
+	iface Interface
+	key   Key
+	q     *quotas
+}
+
+type quotas struct {
+	opts  *QuotaOptions
+	root  Interface
+	mu    sync.Mutex
+	usage map[string]QuotaUsage
+}
+
+var (
+	_ Interface  = (*Quotas)(nil)
+	_ SafeReader = (*Quotas)(nil)
+	_ SafeLister = (*Quotas)(nil)
+	_ SafeWriter = (*Quotas)(nil)
+)
+
+func Quota(iface Interface, opts *QuotaOptions) *Quotas {
+	return &Quotas{
+		iface: iface,
+		q: &quotas{
+			opts:  opts,
+			root:  iface,
+			usage: make(map[string]QuotaUsage),
+		},
+	}
+}
+
+// Usage gives the usage of the prefix.
+func (q *Quotas) Usage(ctx context.Context, prefix string) QuotaUsage {
+	q.q.mu.Lock()
+	defer q.q.mu.Unlock()
+
+	return q.q.get(ctx, prefix)
+}
+
+func (q *Quotas) Type() Type {
+	return q.iface.Type()
+}
+
+func (q *Quotas) Get(ctx context.Context, key string) (any, bool) {
+	v, err := q.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (q *Quotas) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: q.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := v.(Interface); ok {
+		return q.child(iface, key), nil
+	}
+
+	return v, nil
+}
+
+func (q *Quotas) List(ctx context.Context) []string {
+	keys, _ := q.SafeList(ctx)
+	return keys
+}
+
+func (q *Quotas) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, q.iface)
+}
+
+func (q *Quotas) Del(ctx context.Context, key string) bool {
+	return q.SafeDel(ctx, key) == nil
+}
+
+func (q *Quotas) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := q.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (q *Quotas) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := q.SafePut(ctx, key, hint)
+	return w
+}
+
+func (q *Quotas) SafeDel(ctx context.Context, key string) error {
+	q.q.mu.Lock()
+	defer q.q.mu.Unlock()
+
+	var (
+		full   = append(q.key.Copy(), key)
+		old, _ = PrefixedReader{R: q.iface}.SafeGet(ctx, key)
+		ps     = q.q.affected(ctx, full, old, nil)
+	)
+
+	if err := (PrefixedWriter{W: q.iface}).SafeDel(ctx, key); err != nil {
+		return err
+	}
+
+	for _, p := range ps {
+		q.q.usage[p.prefix] = p.usage
+	}
+
+	return nil
+}
+
+func (q *Quotas) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	q.q.mu.Lock()
+	defer q.q.mu.Unlock()
+
+	var (
+		full   = append(q.key.Copy(), key)
+		old, _ = PrefixedReader{R: q.iface}.SafeGet(ctx, key)
+		ps     = q.q.affected(ctx, full, old, value)
+	)
+
+	for _, p := range ps {
+		if limit := q.q.opts.Limits[p.prefix]; exceeds(p.usage, limit) {
+			return false, &Error{
+				Op:   "Set",
+				Key:  full,
+				Got:  p.usage,
+				Want: limit,
+				Err:  ErrQuotaExceeded,
+			}
+		}
+	}
+
+	ok, err := PrefixedWriter{W: q.iface}.SafeSet(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range ps {
+		q.q.usage[p.prefix] = p.usage
+	}
+
+	return ok, nil
+}
+
+func (q *Quotas) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	w, err := PrefixedWriter{W: q.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return q.child(iface, key), nil
+	}
+
+	return w, nil
+}
+
+func (q *Quotas) Ping(ctx context.Context) error {
+	return Ping(ctx, q.iface)
+}
+
+func (q *Quotas) Close(ctx context.Context) error {
+	return Close(ctx, q.iface)
+}
+
+func (q *Quotas) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (q *Quotas) Unwrap() any {
+	return q.iface
+}
+
+func (q *Quotas) child(iface Interface, key string) *Quotas {
+	return &Quotas{
+		iface: iface,
+		key:   append(q.key.Copy(), key),
+		q:     q.q,
+	}
+}
+
+// prefixUsage is the usage of a limited prefix after a write.
+type prefixUsage struct {
+	prefix string
+	usage  QuotaUsage
+}
+
+// affected gives the usage of the limited prefixes after replacing
+// the old value under key with value, where a nil value is a delete.
+// The prefixes are those of key and those under key, for which only
+// the replaced part of the subtree is measured.
+func (q *quotas) affected(ctx context.Context, key Key, old, value any) []prefixUsage {
+	var ps []prefixUsage
+
+	for p := range q.opts.Limits {
+		var (
+			k          = splitKey(p)
+			prev, next QuotaUsage
+		)
+
+		switch {
+		case key.HasPrefix(k):
+			prev, next = measure(ctx, old), measure(ctx, value)
+		case k.HasPrefix(key):
+			rest := k[len(key):]
+			prev, next = measure(ctx, at(ctx, old, rest)), measure(ctx, at(ctx, value, rest))
+		default:
+			continue
+		}
+
+		u := q.get(ctx, p)
+		u.Keys += next.Keys - prev.Keys
+		u.Bytes += next.Bytes - prev.Bytes
+
+		ps = append(ps, prefixUsage{prefix: p, usage: u})
+	}
+
+	return ps
+}
+
+// at gives the value under the key of the tree v, or nil if there
+// is none.
+func at(ctx context.Context, v any, key Key) any {
+	if v == nil || len(key) == 0 {
+		return v
+	}
+
+	r, ok := v.(Reader)
+	if !ok {
+		iface := Make(v)
+		if iface == nil {
+			return nil
+		}
+		r = iface
+	}
+
+	x, err := PrefixedReader{Key: key.Dir(), R: r}.SafeGet(ctx, key.Base())
+	if err != nil {
+		return nil
+	}
+
+	return x
+}
+
+func (q *quotas) get(ctx context.Context, prefix string) QuotaUsage {
+	u, ok := q.usage[prefix]
+	if !ok {
+		if k := splitKey(prefix); len(k) == 0 {
+			u = measure(ctx, q.root)
+		} else if v, err := (PrefixedReader{Key: k.Dir(), R: q.root}).SafeGet(ctx, k.Base()); err == nil {
+			u = measure(ctx, v)
+		}
+		q.usage[prefix] = u
+	}
+	return u
+}
+
+// measure gives the usage of v, which is a leaf or a tree.
+func measure(ctx context.Context, v any) QuotaUsage {
+	switch v := v.(type) {
+	case nil:
+		return QuotaUsage{}
+	case Reader:
+		var (
+			u       QuotaUsage
+			keys, _ = List(ctx, v)
+		)
+
+		for _, k := range keys {
+			x, err := PrefixedReader{R: v}.SafeGet(ctx, k)
+			if err != nil {
+				continue
+			}
+
+			n := measure(ctx, x)
+			u.Keys += n.Keys
+			u.Bytes += n.Bytes
+		}
+
+		return u
+	case string:
+		return QuotaUsage{Keys: 1, Bytes: int64(len(v))}
+	case []byte:
+		return QuotaUsage{Keys: 1, Bytes: int64(len(v))}
+	default:
+		if r := Make(v); r != nil {
+			return measure(ctx, r)
+		}
+		return QuotaUsage{Keys: 1, Bytes: int64(len(fmt.Sprint(v)))}
+	}
+}
+
+func exceeds(u QuotaUsage, limit QuotaLimit) bool {
+	return (limit.MaxKeys != 0 && u.Keys > limit.MaxKeys) ||
+		(limit.MaxBytes != 0 && u.Bytes > limit.MaxBytes)
+}
+
+func splitKey(s string) Key {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ".")
+}
@@ -0,0 +1,103 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects/types"
+)
+
+func TestQuota(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"team-a": types.Map{
+				"flag": "on",
+			},
+			"team-b": types.Map{},
+		}
+		q = types.Quota(m, &types.QuotaOptions{
+			Limits: map[string]types.QuotaLimit{
+				"team-a": {MaxKeys: 2},
+				"team-b": {MaxBytes: 8},
+				"":       {MaxKeys: 4},
+			},
+		})
+		a = types.PrefixWriter(q, "team-a")
+		b = types.PrefixWriter(q, "team-b")
+	)
+
+	if _, err := a.SafeSet(ctx, "other", "off"); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if _, err := a.SafeSet(ctx, "third", "on"); !errors.Is(err, types.ErrQuotaExceeded) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrQuotaExceeded)
+	}
+
+	if _, err := a.SafeSet(ctx, "flag", "off"); err != nil {
+		t.Fatalf("overwrite: SafeSet()=%+v", err)
+	}
+
+	if _, err := b.SafeSet(ctx, "name", "12345678"); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if _, err := b.SafeSet(ctx, "more", "x"); !errors.Is(err, types.ErrQuotaExceeded) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrQuotaExceeded)
+	}
+
+	if got, want := q.Usage(ctx, "team-b"), (types.QuotaUsage{Keys: 1, Bytes: 8}); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := q.SafeSet(ctx, "team-c", types.Map{"x": 1, "y": 2}); !errors.Is(err, types.ErrQuotaExceeded) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrQuotaExceeded)
+	}
+
+	if err := a.SafeDel(ctx, "other"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if got, want := q.Usage(ctx, ""), (types.QuotaUsage{Keys: 2, Bytes: 11}); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := a.SafeSet(ctx, "third", "on"); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+}
+
+func TestQuotaAncestorWrites(t *testing.T) {
+	var (
+		ctx = context.Background()
+		q   = types.Quota(types.Map{}, &types.QuotaOptions{
+			Limits: map[string]types.QuotaLimit{
+				"a.b": {MaxKeys: 2},
+			},
+		})
+	)
+
+	huge := types.Map{"b": types.Map{"x": 1, "y": 2, "z": 3}}
+
+	if _, err := q.SafeSet(ctx, "a", huge); !errors.Is(err, types.ErrQuotaExceeded) {
+		t.Fatalf("got %+v, want %+v", err, types.ErrQuotaExceeded)
+	}
+
+	if _, err := q.SafeSet(ctx, "a", types.Map{"b": types.Map{"x": 1}, "c": 2}); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if u := q.Usage(ctx, "a.b"); u.Keys != 1 {
+		t.Fatalf("got %+v, want 1 key", u)
+	}
+
+	if err := q.SafeDel(ctx, "a"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if u := q.Usage(ctx, "a.b"); u != (types.QuotaUsage{}) {
+		t.Fatalf("got %+v, want no usage", u)
+	}
+}
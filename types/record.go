@@ -0,0 +1,209 @@
+package types
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Operation is an operation recorded by Recorded.
+type Operation struct {
+	Time     time.Time
+	Op       string
+	Key      Key
+	Duration time.Duration
+	Err      error
+}
+
+// Recorded keeps the last operations on an Interface in a ring
+// buffer, so they can be inspected when diagnosing issues.
+type Recorded struct {
+	iface Interface
+	key   Key
+	r     *recorder
+}
+
+type recorder struct {
+	mu   sync.Mutex
+	ops  []Operation
+	next int
+	full bool
+}
+
+var (
+	_ Interface  = (*Recorded)(nil)
+	_ SafeReader = (*Recorded)(nil)
+	_ SafeLister = (*Recorded)(nil)
+	_ SafeWriter = (*Recorded)(nil)
+)
+
+// Record records the last size operations on iface.
+func Record(iface Interface, size int) *Recorded {
+	return &Recorded{
+		iface: iface,
+		r: &recorder{
+			ops: make([]Operation, size),
+		},
+	}
+}
+
+// Operations gives the recorded operations, oldest first.
+func (r *Recorded) Operations() []Operation {
+	r.r.mu.Lock()
+	defer r.r.mu.Unlock()
+
+	if !r.r.full {
+		return append([]Operation(nil), r.r.ops[:r.r.next]...)
+	}
+
+	return append(append([]Operation(nil), r.r.ops[r.r.next:]...), r.r.ops[:r.r.next]...)
+}
+
+// Log gives the recorded operations as a tree, e.g. for Dump.
+func (r *Recorded) Log() Reader {
+	var s Slice
+
+	for _, op := range r.Operations() {
+		m := Map{
+			"time":     op.Time.Format(time.RFC3339Nano),
+			"op":       op.Op,
+			"key":      op.Key.String(),
+			"duration": op.Duration.String(),
+		}
+
+		if op.Err != nil {
+			m["error"] = op.Err.Error()
+		}
+
+		s = append(s, m)
+	}
+
+	return &s
+}
+
+func (r *Recorded) Type() Type {
+	return r.iface.Type()
+}
+
+func (r *Recorded) Get(ctx context.Context, key string) (any, bool) {
+	v, err := r.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (r *Recorded) SafeGet(ctx context.Context, key string) (v any, err error) {
+	defer r.record("Get", key, time.Now(), &err)
+
+	if v, err = (PrefixedReader{R: r.iface}).SafeGet(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return r.child(v, key), nil
+}
+
+func (r *Recorded) List(ctx context.Context) []string {
+	keys, _ := r.SafeList(ctx)
+	return keys
+}
+
+func (r *Recorded) SafeList(ctx context.Context) (keys []string, err error) {
+	defer r.record("List", "", time.Now(), &err)
+
+	return List(ctx, r.iface)
+}
+
+func (r *Recorded) Del(ctx context.Context, key string) bool {
+	return r.SafeDel(ctx, key) == nil
+}
+
+func (r *Recorded) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := r.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (r *Recorded) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := r.SafePut(ctx, key, hint)
+	return w
+}
+
+func (r *Recorded) SafeDel(ctx context.Context, key string) (err error) {
+	defer r.record("Del", key, time.Now(), &err)
+
+	return PrefixedWriter{W: r.iface}.SafeDel(ctx, key)
+}
+
+func (r *Recorded) SafeSet(ctx context.Context, key string, value any) (ok bool, err error) {
+	defer r.record("Set", key, time.Now(), &err)
+
+	return PrefixedWriter{W: r.iface}.SafeSet(ctx, key, value)
+}
+
+func (r *Recorded) SafePut(ctx context.Context, key string, hint Type) (w Writer, err error) {
+	defer r.record("Put", key, time.Now(), &err)
+
+	if w, err = (PrefixedWriter{W: r.iface}).SafePut(ctx, key, hint); err != nil {
+		return nil, err
+	}
+
+	if v, ok := r.child(w, key).(Writer); ok {
+		return v, nil
+	}
+
+	return w, nil
+}
+
+func (r *Recorded) Ping(ctx context.Context) error {
+	return Ping(ctx, r.iface)
+}
+
+func (r *Recorded) Close(ctx context.Context) error {
+	return Close(ctx, r.iface)
+}
+
+func (r *Recorded) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (r *Recorded) Unwrap() any {
+	return r.iface
+}
+
+func (r *Recorded) child(v any, key string) any {
+	if iface, ok := v.(Interface); ok {
+		return &Recorded{
+			iface: iface,
+			key:   append(r.key.Copy(), key),
+			r:     r.r,
+		}
+	}
+	return v
+}
+
+func (r *Recorded) record(op, key string, start time.Time, err *error) {
+	full := r.key.Copy()
+	if key != "" {
+		full = append(full, key)
+	}
+
+	r.r.add(Operation{
+		Time:     start,
+		Op:       op,
+		Key:      full,
+		Duration: time.Since(start),
+		Err:      *err,
+	})
+}
+
+func (r *recorder) add(op Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.ops) == 0 {
+		return
+	}
+
+	r.ops[r.next] = op
+
+	if r.next++; r.next == len(r.ops) {
+		r.next, r.full = 0, true
+	}
+}
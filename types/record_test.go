@@ -0,0 +1,48 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecord(t *testing.T) {
+	var (
+		ctx = context.Background()
+		r   = types.Record(types.Map{"server": types.Map{"host": "localhost"}}, 3)
+		pr  = types.PrefixReader(r, "server")
+	)
+
+	pr.SafeGet(ctx, "host")
+	pr.SafeGet(ctx, "port")
+	r.SafeSet(ctx, "version", "v1")
+
+	type op struct {
+		Op, Key string
+		Err     bool
+	}
+
+	var got []op
+
+	for _, o := range r.Operations() {
+		got = append(got, op{o.Op, o.Key.String(), o.Err != nil})
+	}
+
+	// Only the last three of five operations are kept.
+	want := []op{
+		{"Get", "server", false},
+		{"Get", "server.port", true},
+		{"Set", "version", false},
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if n := len(r.Log().List(ctx)); n != 3 {
+		t.Fatalf("got %d log entries, want 3", n)
+	}
+}
@@ -0,0 +1,476 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a period of time in which a scheduled value is active.
+//
+// The window is either recurring, starting at times matching Cron
+// and lasting Duration, or fixed, between From and Until. Zero From
+// or Until leaves the window open on that side.
+type Window struct {
+	Cron     string
+	Duration time.Duration
+	From     time.Time
+	Until    time.Time
+	Value    any
+
+	cron *cron // parsed Cron
+}
+
+// Schedule is a leaf, which value depends on time: it is the value
+// of the first active window, or Default if none is active.
+//
+// In a tree, a schedule is a map with the ScheduleKey key holding
+// windows and an optional DefaultKey key, e.g.
+//
+//	maintenance:
+//	  "@schedule":
+//	    - cron: "0 2 * * 0"
+//	      duration: 2h
+//	      value: true
+//	    - from: 2026-12-24T00:00:00Z
+//	      until: 2026-12-27T00:00:00Z
+//	      value: true
+//	  "@default": false
+//
+// Times are RFC 3339 strings and durations are time.ParseDuration
+// strings. Cron expressions have the standard five fields: minute,
+// hour, day of month, month and day of week, matched in UTC.
+type Schedule struct {
+	Windows []Window
+	Default any
+}
+
+const (
+	ScheduleKey = "@schedule"
+	DefaultKey  = "@default"
+)
+
+// Value gives the value of the schedule at t. It is false if no
+// window is active and the schedule has no default.
+func (s *Schedule) Value(t time.Time) (any, bool, error) {
+	for _, w := range s.Windows {
+		ok, err := w.Active(t)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if ok {
+			return w.Value, true, nil
+		}
+	}
+
+	return s.Default, s.Default != nil, nil
+}
+
+// Active reports whether the window is active at t.
+func (w *Window) Active(t time.Time) (bool, error) {
+	if !w.From.IsZero() && t.Before(w.From) {
+		return false, nil
+	}
+
+	if !w.Until.IsZero() && !t.Before(w.Until) {
+		return false, nil
+	}
+
+	if w.Cron == "" {
+		return true, nil
+	}
+
+	c := w.cron
+
+	if c == nil || c.expr != w.Cron {
+		var err error
+
+		if c, err = parseCron(w.Cron); err != nil {
+			return false, err
+		}
+	}
+
+	// Look for a start of the window within Duration before t.
+	return c.prev(t.UTC(), t.UTC().Add(-w.Duration)), nil
+}
+
+// Scheduled is a reader, which resolves schedules to their values
+// at the time of a read.
+type Scheduled struct {
+	r     Reader
+	clock Clock
+}
+
+var (
+	_ Reader     = (*Scheduled)(nil)
+	_ SafeReader = (*Scheduled)(nil)
+	_ SafeLister = (*Scheduled)(nil)
+)
+
+// ResolveSchedules wraps r, telling the time with the clock, which
+// defaults to SystemClock when nil.
+func ResolveSchedules(r Reader, clock Clock) *Scheduled {
+	return &Scheduled{
+		r:     r,
+		clock: clockOr(clock),
+	}
+}
+
+func (s *Scheduled) Type() Type {
+	return s.r.Type()
+}
+
+func (s *Scheduled) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Scheduled) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: s.r}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	sched, err := scheduleOf(ctx, v)
+	if err != nil {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Got: v,
+			Err: err,
+		}
+	}
+
+	if sched == nil {
+		if r, ok := v.(Reader); ok {
+			return &Scheduled{r: r, clock: s.clock}, nil
+		}
+
+		return v, nil
+	}
+
+	v, ok, err := sched.Value(s.clock.Now())
+	if err == nil && !ok {
+		err = ErrNotFound
+	}
+
+	if err != nil {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: err,
+		}
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Scheduled{r: r, clock: s.clock}, nil
+	}
+
+	return v, nil
+}
+
+func (s *Scheduled) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Scheduled) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, s.r)
+}
+
+func (s *Scheduled) Unwrap() any {
+	return s.r
+}
+
+// scheduleOf gives the schedule of v, or nil if v is not one.
+func scheduleOf(ctx context.Context, v any) (*Schedule, error) {
+	switch v := v.(type) {
+	case Schedule:
+		return &v, nil
+	case *Schedule:
+		return v, nil
+	case Reader:
+		if v.Type() != TypeMap {
+			return nil, nil
+		}
+
+		windows, err := PrefixedReader{R: v}.SafeGet(ctx, ScheduleKey)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return readSchedule(ctx, v, windows)
+	default:
+		return nil, nil
+	}
+}
+
+func readSchedule(ctx context.Context, r Reader, windows any) (*Schedule, error) {
+	wr, ok := windows.(Reader)
+	if !ok {
+		return nil, ErrUnexpectedType
+	}
+
+	var s Schedule
+
+	if v, err := (PrefixedReader{R: r}).SafeGet(ctx, DefaultKey); err == nil {
+		s.Default = v
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	keys, err := List(ctx, wr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: wr}.SafeGet(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+
+		wm, ok := v.(Reader)
+		if !ok {
+			return nil, ErrUnexpectedType
+		}
+
+		w, err := readWindow(ctx, wm)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Windows = append(s.Windows, w)
+	}
+
+	return &s, nil
+}
+
+func readWindow(ctx context.Context, r Reader) (Window, error) {
+	var w Window
+
+	keys, err := List(ctx, r)
+	if err != nil {
+		return w, err
+	}
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return w, err
+		}
+
+		switch k {
+		case "value":
+			w.Value = v
+		case "cron":
+			if w.Cron, err = str(v); err == nil {
+				w.cron, err = parseCron(w.Cron)
+			}
+		case "duration":
+			w.Duration, err = duration(v)
+		case "from":
+			w.From, err = instant(v)
+		case "until":
+			w.Until, err = instant(v)
+		default:
+			err = fmt.Errorf("unexpected %q key of a window: %w", k, ErrInvalid)
+		}
+
+		if err != nil {
+			return w, err
+		}
+	}
+
+	if w.Cron != "" && w.Duration <= 0 {
+		return w, fmt.Errorf("cron window without duration: %w", ErrInvalid)
+	}
+
+	return w, nil
+}
+
+func str(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", ErrUnexpectedType
+	}
+}
+
+func duration(v any) (time.Duration, error) {
+	if d, ok := v.(time.Duration); ok {
+		return d, nil
+	}
+
+	s, err := str(v)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.ParseDuration(s)
+}
+
+func instant(v any) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+
+	s, err := str(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// cron holds sets of matching values of the fields of a cron
+// expression.
+type cron struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	anyDom, anyDow                bool
+}
+
+var cronBounds = [5][2]int{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, 0 and 7 are Sunday
+}
+
+func parseCron(expr string) (*cron, error) {
+	fields := strings.Fields(expr)
+
+	if len(fields) != len(cronBounds) {
+		return nil, fmt.Errorf("cron %q: want 5 fields: %w", expr, ErrInvalid)
+	}
+
+	var sets [5]uint64
+
+	for i, f := range fields {
+		set, err := parseCronField(f, cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+
+		sets[i] = set
+	}
+
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cron{
+		expr:   expr,
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: fields[2] == "*",
+		anyDow: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(f string, lo, hi int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(f, ",") {
+		var (
+			rng, stepStr, hasStep = strings.Cut(part, "/")
+			from, to              = lo, hi
+			step                  = 1
+			err                   error
+		)
+
+		if hasStep {
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("step %q: %w", stepStr, ErrInvalid)
+			}
+		}
+
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+
+			if from, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("value %q: %w", a, ErrInvalid)
+			}
+
+			to = from
+
+			if isRange {
+				if to, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("value %q: %w", b, ErrInvalid)
+				}
+			} else if hasStep {
+				to = hi
+			}
+		}
+
+		if from < lo || to > hi || from > to {
+			return 0, fmt.Errorf("range %q: %w", rng, ErrOutOfBounds)
+		}
+
+		for n := from; n <= to; n += step {
+			set |= 1 << uint(n)
+		}
+	}
+
+	return set, nil
+}
+
+// prev reports whether the cron matches a minute in (end, t]. Minutes
+// are searched backwards, skipping whole months, days and hours, which
+// do not match.
+func (c *cron) prev(t, end time.Time) bool {
+	t = t.Truncate(time.Minute)
+
+	for t.After(end) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Add(-time.Minute)
+		case !c.day(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(-time.Minute)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(-time.Minute)
+		default:
+			// The latest matching minute of the hour, which is not after t.
+			m := c.minute & (1<<uint(t.Minute()+1) - 1)
+			if m == 0 {
+				t = t.Truncate(time.Hour).Add(-time.Minute)
+				continue
+			}
+
+			return t.Truncate(time.Hour).Add(time.Duration(bits.Len64(m)-1) * time.Minute).After(end)
+		}
+	}
+
+	return false
+}
+
+func (c *cron) day(t time.Time) bool {
+	var (
+		dom = c.dom&(1<<uint(t.Day())) != 0
+		dow = c.dow&(1<<uint(t.Weekday())) != 0
+	)
+
+	// As in cron, either day matches if both are restricted.
+	if c.anyDom || c.anyDow {
+		return dom && dow
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,119 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+func TestScheduled(t *testing.T) {
+	var (
+		m = types.Map{
+			"maintenance": types.Map{
+				types.ScheduleKey: types.Slice{
+					types.Map{"cron": "0 2 * * 0", "duration": "2h", "value": true},
+					types.Map{"from": "2026-12-24T00:00:00Z", "until": "2026-12-27T00:00:00Z", "value": true},
+				},
+				types.DefaultKey: false,
+			},
+			"rollout": types.Schedule{
+				Windows: []types.Window{{
+					From:  time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+					Value: types.Map{"percent": 100},
+				}},
+			},
+		}
+		clock = objectstest.NewClock(time.Date(2026, 10, 18, 1, 59, 0, 0, time.UTC)) // Sunday
+		s     = types.ResolveSchedules(m, clock)
+		ctx   = context.Background()
+	)
+
+	cases := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 18, 1, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 10, 18, 3, 59, 0, 0, time.UTC), true},
+		{time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 10, 19, 2, 30, 0, 0, time.UTC), false},
+		{time.Date(2026, 12, 25, 12, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, cas := range cases {
+		clock.Set(cas.now)
+
+		got, err := objects.Get(ctx, s, "maintenance")
+		if err != nil {
+			t.Fatalf("Get()=%+v", err)
+		}
+
+		if got != cas.want {
+			t.Errorf("%s: got %v, want %v", cas.now, got, cas.want)
+		}
+	}
+
+	clock.Set(time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC))
+
+	if _, err := objects.Get(ctx, s, "rollout", "percent"); err == nil {
+		t.Fatal("expected Get() to fail before the rollout")
+	}
+
+	clock.Set(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+
+	percent, err := objects.Get(ctx, s, "rollout", "percent")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if percent != 100 {
+		t.Fatalf("got %v, want 100", percent)
+	}
+}
+
+func TestScheduledInvalid(t *testing.T) {
+	s := types.ResolveSchedules(types.Map{
+		"bad": types.Map{
+			types.ScheduleKey: types.Slice{types.Map{"cron": "61 * * * *", "duration": "1h"}},
+		},
+	}, nil)
+
+	if _, err := s.SafeGet(context.Background(), "bad"); err == nil {
+		t.Fatal("expected SafeGet() to fail")
+	}
+}
+
+func TestWindowActive(t *testing.T) {
+	cases := []struct {
+		w    types.Window
+		now  time.Time
+		want bool
+	}{
+		{types.Window{Cron: "30 4 1 1 *", Duration: 48 * time.Hour}, time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC), false},
+		{types.Window{Cron: "30 4 1 1 *", Duration: 48 * time.Hour}, time.Date(2027, 1, 1, 4, 30, 0, 0, time.UTC), true},
+		{types.Window{Cron: "30 4 1 1 *", Duration: 48 * time.Hour}, time.Date(2027, 1, 3, 4, 29, 0, 0, time.UTC), true},
+		{types.Window{Cron: "30 4 1 1 *", Duration: 48 * time.Hour}, time.Date(2027, 1, 3, 4, 30, 0, 0, time.UTC), false},
+		{types.Window{Cron: "*/15 9-17 * * 1-5", Duration: 10 * time.Minute}, time.Date(2026, 10, 19, 9, 35, 0, 0, time.UTC), true},
+		{types.Window{Cron: "*/15 9-17 * * 1-5", Duration: 10 * time.Minute}, time.Date(2026, 10, 19, 9, 44, 0, 0, time.UTC), false},
+		{types.Window{Cron: "*/15 9-17 * * 1-5", Duration: 10 * time.Minute}, time.Date(2026, 10, 19, 8, 59, 0, 0, time.UTC), false},
+		{types.Window{Cron: "*/15 9-17 * * 1-5", Duration: 10 * time.Minute}, time.Date(2026, 10, 17, 9, 35, 0, 0, time.UTC), false},
+		{types.Window{Cron: "0 0 13 * 5", Duration: time.Hour}, time.Date(2026, 11, 13, 0, 59, 0, 0, time.UTC), true},
+		{types.Window{Cron: "0 0 13 * 5", Duration: time.Hour}, time.Date(2026, 11, 6, 0, 30, 0, 0, time.UTC), true},
+		{types.Window{Cron: "0 0 13 * 5", Duration: time.Hour}, time.Date(2026, 11, 7, 0, 30, 0, 0, time.UTC), false},
+	}
+
+	for _, cas := range cases {
+		got, err := cas.w.Active(cas.now)
+		if err != nil {
+			t.Fatalf("Active()=%+v", err)
+		}
+
+		if got != cas.want {
+			t.Errorf("%q at %s: got %v, want %v", cas.w.Cron, cas.now, got, cas.want)
+		}
+	}
+}
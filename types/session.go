@@ -0,0 +1,181 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session gives read-your-writes consistency over a replicated
+// backend. Writes go to the primary and reads of keys written
+// within the session window are routed to the primary too, while
+// other reads are served by the replica.
+type Session struct {
+	key Key
+	typ Type
+	s   *session
+}
+
+type session struct {
+	primary Interface
+	replica Reader
+	window  time.Duration
+	mu      sync.Mutex
+	written map[string]time.Time
+	clock   Clock
+}
+
+var (
+	_ Interface  = (*Session)(nil)
+	_ SafeReader = (*Session)(nil)
+	_ SafeLister = (*Session)(nil)
+	_ ListerTo   = (*Session)(nil)
+	_ SafeWriter = (*Session)(nil)
+)
+
+// NewSession creates a session, which routes reads of written keys
+// to the primary for the duration of window, by which the replica
+// is expected to catch up. The window is measured with the clock,
+// which defaults to SystemClock when nil.
+func NewSession(primary Interface, replica Reader, window time.Duration, clock Clock) *Session {
+	return &Session{
+		typ: primary.Type(),
+		s: &session{
+			primary: primary,
+			replica: replica,
+			window:  window,
+			written: make(map[string]time.Time),
+			clock:   clockOr(clock),
+		},
+	}
+}
+
+func (s *Session) Type() Type {
+	return s.typ
+}
+
+func (s *Session) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Session) SafeGet(ctx context.Context, key string) (any, error) {
+	full := append(s.key.Copy(), key)
+
+	v, err := PrefixedReader{Key: s.key, R: s.s.reader(full)}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Session{key: full, typ: r.Type(), s: s.s}, nil
+	}
+
+	return v, nil
+}
+
+func (s *Session) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Session) SafeList(ctx context.Context) ([]string, error) {
+	return List(ctx, PrefixedReader{Key: s.key, R: s.s.reader(s.key)})
+}
+
+func (s *Session) ListTo(ctx context.Context, keys *[]string) {
+	ListTo(ctx, PrefixedReader{Key: s.key, R: s.s.reader(s.key)}, keys)
+}
+
+func (s *Session) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Session) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Session) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *Session) SafeDel(ctx context.Context, key string) error {
+	full := append(s.key.Copy(), key)
+
+	if err := (PrefixedWriter{Key: s.key, W: s.s.primary}).SafeDel(ctx, key); err != nil {
+		return err
+	}
+
+	s.s.mark(full)
+
+	return nil
+}
+
+func (s *Session) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	full := append(s.key.Copy(), key)
+
+	ok, err := PrefixedWriter{Key: s.key, W: s.s.primary}.SafeSet(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+
+	s.s.mark(full)
+
+	return ok, nil
+}
+
+func (s *Session) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	full := append(s.key.Copy(), key)
+
+	w, err := PrefixedWriter{Key: s.key, W: s.s.primary}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	s.s.mark(full)
+
+	typ := makeOr(hint, make(Map)).Type()
+	if r, ok := w.(Reader); ok {
+		typ = r.Type()
+	}
+
+	return &Session{key: full, typ: typ, s: s.s}, nil
+}
+
+func (s *Session) Unwrap() any {
+	return s.s.primary
+}
+
+func (s *session) mark(key Key) {
+	s.mu.Lock()
+	s.written[key.ID()] = s.clock.Now()
+	s.mu.Unlock()
+}
+
+// reader gives the primary if key, any of its parents or children
+// was written within the session window, and the replica otherwise.
+func (s *session) reader(key Key) Reader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		now = s.clock.Now()
+		k   = key.ID()
+	)
+
+	for w, t := range s.written {
+		if now.Sub(t) > s.window {
+			delete(s.written, w)
+			continue
+		}
+
+		if strings.HasPrefix(w, k) || strings.HasPrefix(k, w) {
+			return s.primary
+		}
+	}
+
+	return s.replica
+}
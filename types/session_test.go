@@ -0,0 +1,72 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+)
+
+func TestSession(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		primary = types.Map{
+			"server": types.Map{"port": 8080},
+			"debug":  false,
+		}
+		replica = types.Map{
+			"server": types.Map{"port": 8080},
+			"debug":  false,
+		}
+		clock = objectstest.NewClock(time.Now())
+		s     = types.NewSession(primary, replica, 50*time.Millisecond, clock)
+		pr    = types.PrefixReader(s, "server")
+	)
+
+	if _, err := types.PrefixWriter(s, "server").SafeSet(ctx, "port", 9090); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	if err := s.SafeDel(ctx, "debug"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if v, _ := pr.Get(ctx, "port"); v != 9090 {
+		t.Fatalf("got %#v, want %#v", v, 9090)
+	}
+
+	if _, ok := s.Get(ctx, "debug"); ok {
+		t.Fatal("expected deleted key to not be found")
+	}
+
+	clock.Add(100 * time.Millisecond)
+
+	if v, _ := pr.Get(ctx, "port"); v != 8080 {
+		t.Fatalf("got %#v, want %#v (replica)", v, 8080)
+	}
+}
+
+func TestSessionDottedKeys(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		primary = types.Map{"a.b": 1, "a": types.Map{"b": 2}}
+		replica = types.Map{"a.b": 1, "a": types.Map{"b": 2}}
+		s       = types.NewSession(primary, replica, time.Hour, nil)
+	)
+
+	if _, err := s.SafeSet(ctx, "a.b", 3); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	replica["a"].(types.Map)["b"] = 4
+
+	if v, _ := types.PrefixReader(s, "a").Get(ctx, "b"); v != 4 {
+		t.Fatalf("got %#v, want %#v (replica)", v, 4)
+	}
+
+	if v, _ := s.Get(ctx, "a.b"); v != 3 {
+		t.Fatalf("got %#v, want %#v (primary)", v, 3)
+	}
+}
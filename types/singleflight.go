@@ -0,0 +1,150 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// Singleflighted collapses concurrent identical Get and List calls
+// into a single call to the underlying reader, sharing its result.
+// Callers stop waiting for a shared call once their context is done.
+type Singleflighted struct {
+	r   Reader
+	key Key
+	g   *group
+}
+
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	dups  int // callers waiting for the call
+	value any
+	err   error
+}
+
+var (
+	_ Reader     = (*Singleflighted)(nil)
+	_ SafeReader = (*Singleflighted)(nil)
+	_ SafeLister = (*Singleflighted)(nil)
+	_ ListerTo   = (*Singleflighted)(nil)
+)
+
+func Singleflight(r Reader) *Singleflighted {
+	return &Singleflighted{
+		r: r,
+		g: &group{calls: make(map[string]*call)},
+	}
+}
+
+func (s *Singleflighted) Type() Type {
+	return s.r.Type()
+}
+
+func (s *Singleflighted) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Singleflighted) SafeGet(ctx context.Context, key string) (any, error) {
+	full := append(s.key.Copy(), key)
+
+	v, err := s.g.do(ctx, "Get:"+full.ID(), func() (any, error) {
+		return PrefixedReader{R: s.r}.SafeGet(ctx, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := v.(Reader); ok {
+		return &Singleflighted{r: r, key: full, g: s.g}, nil
+	}
+
+	return v, nil
+}
+
+func (s *Singleflighted) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Singleflighted) SafeList(ctx context.Context) ([]string, error) {
+	v, err := s.g.do(ctx, "List:"+s.key.ID(), func() (any, error) {
+		return List(ctx, s.r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := v.([]string)
+
+	return append(make([]string, 0, len(keys)), keys...), nil
+}
+
+// ListTo appends keys of a List call shared with concurrent callers,
+// which lists the underlying reader with ListTo.
+func (s *Singleflighted) ListTo(ctx context.Context, keys *[]string) {
+	v, err := s.g.do(ctx, "ListTo:"+s.key.ID(), func() (any, error) {
+		var keys []string
+		ListTo(ctx, s.r, &keys)
+		return keys, nil
+	})
+	if err != nil {
+		return
+	}
+
+	*keys = append(*keys, v.([]string)...)
+}
+
+func (s *Singleflighted) Ping(ctx context.Context) error {
+	return Ping(ctx, s.r)
+}
+
+func (s *Singleflighted) Close(ctx context.Context) error {
+	return Close(ctx, s.r)
+}
+
+func (s *Singleflighted) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (s *Singleflighted) Unwrap() any {
+	return s.r
+}
+
+func (g *group) do(ctx context.Context, key string, fn func() (any, error)) (any, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+
+		select {
+		case <-c.done:
+			return c.value, c.err
+		case <-ctx.Done():
+			g.mu.Lock()
+			c.dups--
+			g.mu.Unlock()
+
+			return nil, ctx.Err()
+		}
+	}
+
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.value, c.err = fn()
+
+	return c.value, c.err
+}
@@ -0,0 +1,171 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rafal.dev/objects/types"
+)
+
+type slowMap struct {
+	types.Map
+	n       int32
+	release chan struct{}
+}
+
+func (sm *slowMap) Get(ctx context.Context, key string) (any, bool) {
+	atomic.AddInt32(&sm.n, 1)
+	<-sm.release
+	return sm.Map.Get(ctx, key)
+}
+
+func TestSingleflight(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = &slowMap{
+			Map:     types.Map{"host": "localhost"},
+			release: make(chan struct{}),
+		}
+		s  = types.Singleflight(m)
+		wg sync.WaitGroup
+	)
+
+	const n = 10
+
+	var values = make([]any, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _ = s.Get(ctx, "host")
+		}(i)
+	}
+
+	for types.SingleflightWaiters(s, "Get", types.Key{"host"}) != n-1 {
+		runtime.Gosched()
+	}
+
+	close(m.release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&m.n); got != 1 {
+		t.Fatalf("got %d backend reads, want 1", got)
+	}
+
+	for i, v := range values {
+		if v != "localhost" {
+			t.Fatalf("%d: got %#v, want %#v", i, v, "localhost")
+		}
+	}
+}
+
+func (sm *slowMap) count() int {
+	return int(atomic.LoadInt32(&sm.n))
+}
+
+// leafGate blocks reads of leaves until release is closed, signalling
+// started when a read blocks.
+type leafGate struct {
+	types.Map
+	started chan struct{}
+	release chan struct{}
+}
+
+func (lg leafGate) Get(ctx context.Context, key string) (any, bool) {
+	v, ok := lg.Map.Get(ctx, key)
+	if _, isReader := v.(types.Reader); !isReader {
+		lg.started <- struct{}{}
+		<-lg.release
+	}
+	return v, ok
+}
+
+func TestSingleflightDottedKeys(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		started = make(chan struct{}, 2)
+		release = make(chan struct{})
+		m       = leafGate{
+			Map: types.Map{
+				"a.b": 1,
+				"a":   leafGate{Map: types.Map{"b": 2}, started: started, release: release},
+			},
+			started: started,
+			release: release,
+		}
+		s        = types.Singleflight(m)
+		wg       sync.WaitGroup
+		dot, sub any
+	)
+
+	a, err := s.SafeGet(ctx, "a")
+	if err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dot, _ = s.Get(ctx, "a.b")
+	}()
+	go func() {
+		defer wg.Done()
+		sub, _ = a.(types.Reader).Get(ctx, "b")
+	}()
+
+	// Both reads reach the backend, unless they were collapsed.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("reads of different keys were collapsed")
+		}
+	}
+
+	close(release)
+	wg.Wait()
+
+	if dot != 1 || sub != 2 {
+		t.Fatalf("got %#v and %#v, want 1 and 2", dot, sub)
+	}
+}
+
+func TestSingleflightWaiterContext(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = &slowMap{
+			Map:     types.Map{"host": "localhost"},
+			release: make(chan struct{}),
+		}
+		s    = types.Singleflight(m)
+		done = make(chan any)
+	)
+
+	go func() {
+		v, _ := s.Get(ctx, "host")
+		done <- v
+	}()
+
+	for m.count() == 0 {
+		runtime.Gosched()
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := s.SafeGet(wctx, "host"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %+v, want %+v", err, context.Canceled)
+	}
+
+	close(m.release)
+
+	if v := <-done; v != "localhost" {
+		t.Fatalf("got %#v, want %#v", v, "localhost")
+	}
+}
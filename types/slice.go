@@ -14,6 +14,7 @@ var (
 	_ Interface  = (*Slice)(nil)
 	_ ListerTo   = Slice(nil)
 	_ SafeReader = Slice(nil)
+	_ SafeLister = Slice(nil)
 	_ SafeWriter = (*Slice)(nil)
 )
 
@@ -32,6 +33,10 @@ func (s Slice) List(ctx context.Context) []string {
 	return keys
 }
 
+func (s Slice) SafeList(ctx context.Context) ([]string, error) {
+	return s.List(ctx), nil
+}
+
 func (s Slice) ListTo(ctx context.Context, keys *[]string) {
 	for i := range s {
 		*keys = append(*keys, strconv.Itoa(i))
@@ -105,7 +110,19 @@ func (s *Slice) SafePut(ctx context.Context, key string, hint Type) (Writer, err
 	return w, nil
 }
 
+// index parses key into an index, where "-" stands for the index
+// past the last element.
 func (s Slice) index(key, op string) (int, error) {
+	if key == "-" {
+		return len(s), &Error{
+			Op:   op,
+			Key:  []string{key},
+			Got:  len(s),
+			Want: len(s),
+			Err:  ErrOutOfBounds,
+		}
+	}
+
 	n, err := strconv.Atoi(key)
 	if err != nil {
 		return 0, &Error{
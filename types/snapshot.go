@@ -0,0 +1,335 @@
+package types
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// SnapshotStore is an in-memory tree with cheap immutable snapshots.
+//
+// Nodes of the tree are never modified once written: each write
+// copies the nodes on the path from the root to the written key,
+// so a snapshot is a reference to the root it was taken at.
+type SnapshotStore struct {
+	s   *snapshotState
+	key Key
+}
+
+type snapshotState struct {
+	mu   sync.RWMutex
+	root *snapshotNode
+}
+
+type snapshotNode struct {
+	typ      Type
+	children map[string]any // leaves or *snapshotNode
+}
+
+var (
+	_ Interface  = (*SnapshotStore)(nil)
+	_ SafeReader = (*SnapshotStore)(nil)
+	_ SafeLister = (*SnapshotStore)(nil)
+	_ SafeWriter = (*SnapshotStore)(nil)
+
+	_ SafeReader = (*snapshotNode)(nil)
+	_ SafeLister = (*snapshotNode)(nil)
+)
+
+func NewSnapshotStore() *SnapshotStore {
+	return &SnapshotStore{
+		s: &snapshotState{
+			root: &snapshotNode{typ: TypeMap},
+		},
+	}
+}
+
+// Snapshot gives the tree of s as of now. Reads of the snapshot are
+// consistent regardless of writes to s made in the meantime.
+func (s *SnapshotStore) Snapshot() Reader {
+	s.s.mu.RLock()
+	defer s.s.mu.RUnlock()
+
+	if n, ok := s.s.root.lookup(s.key); ok {
+		return n
+	}
+
+	return &snapshotNode{typ: TypeMap}
+}
+
+func (s *SnapshotStore) Type() Type {
+	s.s.mu.RLock()
+	defer s.s.mu.RUnlock()
+
+	if n, ok := s.s.root.lookup(s.key); ok {
+		return n.typ
+	}
+
+	return TypeMap
+}
+
+func (s *SnapshotStore) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *SnapshotStore) SafeGet(ctx context.Context, key string) (any, error) {
+	s.s.mu.RLock()
+	defer s.s.mu.RUnlock()
+
+	n, ok := s.s.root.lookup(s.key)
+	if !ok {
+		return nil, s.error("Get", key, ErrNotFound)
+	}
+
+	switch v, ok := n.children[key]; {
+	case !ok:
+		return nil, s.error("Get", key, ErrNotFound)
+	case isSnapshotNode(v):
+		return s.child(key), nil
+	default:
+		return v, nil
+	}
+}
+
+func (s *SnapshotStore) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *SnapshotStore) SafeList(ctx context.Context) ([]string, error) {
+	s.s.mu.RLock()
+	defer s.s.mu.RUnlock()
+
+	n, ok := s.s.root.lookup(s.key)
+	if !ok {
+		return nil, nil
+	}
+
+	return n.SafeList(ctx)
+}
+
+func (s *SnapshotStore) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *SnapshotStore) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *SnapshotStore) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *SnapshotStore) SafeDel(ctx context.Context, key string) error {
+	return s.update("Del", key, func(n *snapshotNode) error {
+		if _, ok := n.children[key]; !ok {
+			return ErrNotFound
+		}
+
+		delete(n.children, key)
+
+		return nil
+	})
+}
+
+// SafeSet sets the key to the value, or to a copy of the tree of
+// the value, if it is a Reader.
+func (s *SnapshotStore) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if r, ok := value.(Reader); ok {
+		n, err := freeze(ctx, r)
+		if err != nil {
+			return false, s.error("Set", key, err)
+		}
+
+		value = n
+	}
+
+	var previous bool
+
+	err := s.update("Set", key, func(n *snapshotNode) error {
+		_, previous = n.children[key]
+		n.children[key] = value
+		return nil
+	})
+
+	return previous, err
+}
+
+func (s *SnapshotStore) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	err := s.update("Put", key, func(n *snapshotNode) error {
+		if !isSnapshotNode(n.children[key]) {
+			n.children[key] = &snapshotNode{typ: hint}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.child(key), nil
+}
+
+// update calls fn with a copy of the node of s and makes it part
+// of a new root, with the nodes of the path to it copied as well.
+func (s *SnapshotStore) update(op, key string, fn func(*snapshotNode) error) error {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+
+	root, err := s.s.root.with(s.key, fn)
+	if err != nil {
+		return s.error(op, key, err)
+	}
+
+	s.s.root = root
+
+	return nil
+}
+
+func (s *SnapshotStore) child(key string) *SnapshotStore {
+	return &SnapshotStore{
+		s:   s.s,
+		key: append(s.key.Copy(), key),
+	}
+}
+
+func (s *SnapshotStore) error(op, key string, err error) error {
+	return &Error{
+		Op:  op,
+		Key: append(s.key.Copy(), key),
+		Err: err,
+	}
+}
+
+func (n *snapshotNode) Type() Type {
+	return n.typ
+}
+
+func (n *snapshotNode) Get(ctx context.Context, key string) (any, bool) {
+	v, ok := n.children[key]
+	return v, ok
+}
+
+func (n *snapshotNode) SafeGet(ctx context.Context, key string) (any, error) {
+	v, ok := n.children[key]
+	if !ok {
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	}
+
+	return v, nil
+}
+
+func (n *snapshotNode) List(ctx context.Context) []string {
+	keys, _ := n.SafeList(ctx)
+	return keys
+}
+
+func (n *snapshotNode) SafeList(ctx context.Context) ([]string, error) {
+	keys := make([]string, 0, len(n.children))
+
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+
+	if n.typ == TypeSlice {
+		sort.Slice(keys, func(i, j int) bool {
+			m, _ := strconv.Atoi(keys[i])
+			n, _ := strconv.Atoi(keys[j])
+			return m < n
+		})
+	} else {
+		sort.Strings(keys)
+	}
+
+	return keys, nil
+}
+
+func (n *snapshotNode) lookup(key Key) (*snapshotNode, bool) {
+	for _, k := range key {
+		child, ok := n.children[k].(*snapshotNode)
+		if !ok {
+			return nil, false
+		}
+
+		n = child
+	}
+
+	return n, true
+}
+
+// with gives a copy of n with fn applied to the copy of the node
+// under the key.
+func (n *snapshotNode) with(key Key, fn func(*snapshotNode) error) (*snapshotNode, error) {
+	c := &snapshotNode{
+		typ:      n.typ,
+		children: make(map[string]any, len(n.children)+1),
+	}
+
+	for k, v := range n.children {
+		c.children[k] = v
+	}
+
+	if len(key) == 0 {
+		if err := fn(c); err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+
+	child, ok := n.children[key[0]].(*snapshotNode)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	child, err := child.with(key[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	c.children[key[0]] = child
+
+	return c, nil
+}
+
+// freeze copies the tree of r into nodes.
+func freeze(ctx context.Context, r Reader) (*snapshotNode, error) {
+	keys, err := List(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &snapshotNode{
+		typ:      r.Type(),
+		children: make(map[string]any, len(keys)),
+	}
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+
+		if r, ok := v.(Reader); ok {
+			if v, err = freeze(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+
+		n.children[k] = v
+	}
+
+	return n, nil
+}
+
+func isSnapshotNode(v any) bool {
+	_, ok := v.(*snapshotNode)
+	return ok
+}
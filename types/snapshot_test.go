@@ -0,0 +1,65 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshotStore(t *testing.T) {
+	var (
+		s   = types.NewSnapshotStore()
+		ctx = context.Background()
+	)
+
+	if _, err := s.SafeSet(ctx, "db", types.Map{"host": "localhost", "port": "5432"}); err != nil {
+		t.Fatalf("SafeSet()=%+v", err)
+	}
+
+	snap := s.Snapshot()
+
+	if _, err := objects.Set(ctx, s, "db.prod", "db", "host"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if err := objects.Del(ctx, s, "db", "port"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	host, err := objects.Get(ctx, snap, "db", "host")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if host != "localhost" {
+		t.Fatalf("got %v, want localhost", host)
+	}
+
+	keys, err := objects.List(ctx, snap, "db")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if want := []string{"host", "port"}; !cmp.Equal(keys, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(keys, want))
+	}
+
+	if host, err = objects.Get(ctx, s, "db", "host"); err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if host != "db.prod" {
+		t.Fatalf("got %v, want db.prod", host)
+	}
+}
+
+func TestSnapshotStoreConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(t *testing.T) types.Interface {
+		return types.NewSnapshotStore()
+	})
+}
@@ -0,0 +1,277 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+type Tombstone struct {
+	Value     any
+	DeletedAt time.Time
+}
+
+// SoftDeleted replaces values removed with Del by a Tombstone,
+// which hides them from reads until they are restored or purged.
+type SoftDeleted struct {
+	iface  Interface
+	maxAge time.Duration
+	clock  Clock
+}
+
+var (
+	_ Interface  = (*SoftDeleted)(nil)
+	_ SafeReader = (*SoftDeleted)(nil)
+	_ SafeLister = (*SoftDeleted)(nil)
+	_ ListerTo   = (*SoftDeleted)(nil)
+	_ SafeWriter = (*SoftDeleted)(nil)
+)
+
+// SoftDelete wraps iface with soft deletion. Purge removes tombstones
+// older than maxAge, or all of them if maxAge is 0. Tombstones are
+// timestamped with the clock, which defaults to SystemClock when nil.
+func SoftDelete(iface Interface, maxAge time.Duration, clock Clock) *SoftDeleted {
+	return &SoftDeleted{
+		iface:  iface,
+		maxAge: maxAge,
+		clock:  clockOr(clock),
+	}
+}
+
+func (s *SoftDeleted) Type() Type {
+	return s.iface.Type()
+}
+
+func (s *SoftDeleted) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *SoftDeleted) SafeGet(ctx context.Context, key string) (any, error) {
+	v, err := PrefixedReader{R: s.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case *Tombstone:
+		return nil, &Error{
+			Op:  "Get",
+			Key: []string{key},
+			Err: ErrNotFound,
+		}
+	case Interface:
+		return s.child(v), nil
+	default:
+		return v, nil
+	}
+}
+
+func (s *SoftDeleted) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *SoftDeleted) ListTo(ctx context.Context, keys *[]string) {
+	var n = len(*keys)
+
+	ListTo(ctx, s.iface, keys)
+
+	for i := n; i < len(*keys); i++ {
+		if v, _ := s.iface.Get(ctx, (*keys)[i]); !isTombstone(v) {
+			(*keys)[n] = (*keys)[i]
+			n++
+		}
+	}
+
+	*keys = (*keys)[:n]
+}
+
+func (s *SoftDeleted) SafeList(ctx context.Context) ([]string, error) {
+	all, err := List(ctx, s.iface)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, k := range all {
+		if v, _ := s.iface.Get(ctx, k); !isTombstone(v) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *SoftDeleted) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *SoftDeleted) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *SoftDeleted) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *SoftDeleted) SafeDel(ctx context.Context, key string) error {
+	v, err := s.SafeGet(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := v.(*SoftDeleted); ok {
+		v = c.iface
+	}
+
+	t := &Tombstone{
+		Value:     v,
+		DeletedAt: s.clock.Now(),
+	}
+
+	_, err = PrefixedWriter{W: s.iface}.SafeSet(ctx, key, t)
+
+	return err
+}
+
+func (s *SoftDeleted) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	v, _ := s.iface.Get(ctx, key)
+
+	ok, err := PrefixedWriter{W: s.iface}.SafeSet(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+
+	return ok && !isTombstone(v), nil
+}
+
+func (s *SoftDeleted) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	if v, _ := s.iface.Get(ctx, key); isTombstone(v) {
+		if err := (PrefixedWriter{W: s.iface}).SafeDel(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := PrefixedWriter{W: s.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return s.child(iface), nil
+	}
+
+	return w, nil
+}
+
+// Restore brings back the value deleted under the given path.
+func (s *SoftDeleted) Restore(ctx context.Context, keys ...string) error {
+	var (
+		key = Key(keys)
+		pr  = PrefixedReader{Key: key.Dir(), R: s.iface}
+		pw  = PrefixedWriter{Key: key.Dir(), W: s.iface}
+	)
+
+	v, err := pr.SafeGet(ctx, key.Base())
+	if err != nil {
+		return err
+	}
+
+	t, ok := v.(*Tombstone)
+	if !ok {
+		return &Error{
+			Op:   "Restore",
+			Key:  key,
+			Got:  v,
+			Want: t,
+			Err:  ErrUnexpectedType,
+		}
+	}
+
+	_, err = pw.SafeSet(ctx, key.Base(), t.Value)
+
+	return err
+}
+
+// ListDeleted gives paths of all the tombstones.
+func (s *SoftDeleted) ListDeleted(ctx context.Context) ([]Key, error) {
+	var keys []Key
+
+	err := s.tombstones(ctx, s.iface, nil, func(key Key, _ *Tombstone) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	return keys, err
+}
+
+func (s *SoftDeleted) Purge(ctx context.Context) error {
+	var deadline = s.clock.Now().Add(-s.maxAge)
+
+	return s.tombstones(ctx, s.iface, nil, func(key Key, t *Tombstone) error {
+		if s.maxAge != 0 && t.DeletedAt.After(deadline) {
+			return nil
+		}
+
+		return PrefixedWriter{Key: key.Dir(), W: s.iface}.SafeDel(ctx, key.Base())
+	})
+}
+
+func (s *SoftDeleted) tombstones(ctx context.Context, r Reader, key Key, fn func(Key, *Tombstone) error) error {
+	keys, err := List(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		switch v := v.(type) {
+		case *Tombstone:
+			if err := fn(append(key.Copy(), k), v); err != nil {
+				return err
+			}
+		case Reader:
+			if err := s.tombstones(ctx, v, append(key.Copy(), k), fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *SoftDeleted) child(iface Interface) *SoftDeleted {
+	return &SoftDeleted{
+		iface:  iface,
+		maxAge: s.maxAge,
+		clock:  s.clock,
+	}
+}
+
+func isTombstone(v any) bool {
+	_, ok := v.(*Tombstone)
+	return ok
+}
+
+func (s *SoftDeleted) Ping(ctx context.Context) error {
+	return Ping(ctx, s.iface)
+}
+
+func (s *SoftDeleted) Close(ctx context.Context) error {
+	return Close(ctx, s.iface)
+}
+
+func (s *SoftDeleted) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (s *SoftDeleted) Unwrap() any {
+	return s.iface
+}
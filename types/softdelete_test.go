@@ -0,0 +1,64 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSoftDelete(t *testing.T) {
+	var (
+		m   = newM()
+		s   = types.SoftDelete(m, 0, nil)
+		pr  = types.PrefixReader(s, "foo", "bar")
+		ctx = context.Background()
+	)
+
+	if err := types.PrefixWriter(s, "foo", "bar").SafeDel(ctx, "dir"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if got, want := pr.List(ctx), []string{"file"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if _, err := pr.SafeGet(ctx, "dir"); err == nil {
+		t.Fatal("expected SafeGet() to fail")
+	}
+
+	deleted, err := s.ListDeleted(ctx)
+	if err != nil {
+		t.Fatalf("ListDeleted()=%+v", err)
+	}
+
+	if want := []types.Key{{"foo", "bar", "dir"}}; !cmp.Equal(deleted, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(deleted, want))
+	}
+
+	if err := s.Restore(ctx, "foo", "bar", "dir"); err != nil {
+		t.Fatalf("Restore()=%+v", err)
+	}
+
+	if got, want := pr.List(ctx), []string{"dir", "file"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if err := types.PrefixWriter(s, "foo", "bar").SafeDel(ctx, "dir"); err != nil {
+		t.Fatalf("SafeDel()=%+v", err)
+	}
+
+	if err := s.Purge(ctx); err != nil {
+		t.Fatalf("Purge()=%+v", err)
+	}
+
+	if got, want := types.PrefixReader(m, "foo", "bar").List(ctx), []string{"file"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if err := s.Restore(ctx, "foo", "bar", "dir"); err == nil {
+		t.Fatal("expected Restore() to fail")
+	}
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// Synced guards an Interface with a read-write mutex, making it safe
+// for concurrent use. Subtrees it gives share the mutex of the root.
+type Synced struct {
+	iface Interface
+	mu    *sync.RWMutex
+}
+
+var (
+	_ Interface  = (*Synced)(nil)
+	_ SafeReader = (*Synced)(nil)
+	_ SafeLister = (*Synced)(nil)
+	_ ListerTo   = (*Synced)(nil)
+	_ SafeWriter = (*Synced)(nil)
+)
+
+// Sync wraps the iface, e.g. a Map or a Slice, with a mutex.
+func Sync(iface Interface) *Synced {
+	return &Synced{
+		iface: iface,
+		mu:    new(sync.RWMutex),
+	}
+}
+
+func (s *Synced) Type() Type {
+	return s.iface.Type()
+}
+
+func (s *Synced) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Synced) SafeGet(ctx context.Context, key string) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, err := PrefixedReader{R: s.iface}.SafeGet(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.child(v), nil
+}
+
+func (s *Synced) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Synced) SafeList(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return List(ctx, s.iface)
+}
+
+func (s *Synced) ListTo(ctx context.Context, keys *[]string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ListTo(ctx, s.iface, keys)
+}
+
+func (s *Synced) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Synced) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Synced) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *Synced) SafeDel(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return PrefixedWriter{W: s.iface}.SafeDel(ctx, key)
+}
+
+func (s *Synced) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return PrefixedWriter{W: s.iface}.SafeSet(ctx, key, value)
+}
+
+func (s *Synced) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := PrefixedWriter{W: s.iface}.SafePut(ctx, key, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if iface, ok := w.(Interface); ok {
+		return &Synced{iface: iface, mu: s.mu}, nil
+	}
+
+	return w, nil
+}
+
+func (s *Synced) Ping(ctx context.Context) error {
+	return Ping(ctx, s.iface)
+}
+
+func (s *Synced) Close(ctx context.Context) error {
+	return Close(ctx, s.iface)
+}
+
+func (s *Synced) forwards() Capability {
+	return CapPinger | CapCloser
+}
+
+func (s *Synced) Unwrap() any {
+	return s.iface
+}
+
+func (s *Synced) child(v any) any {
+	if iface, ok := v.(Interface); ok {
+		return &Synced{iface: iface, mu: s.mu}
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package types_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"rafal.dev/objects/types"
+)
+
+func TestSynced(t *testing.T) {
+	var (
+		s   = types.Sync(types.Map{"dir": types.Map{}})
+		ctx = context.Background()
+		wg  sync.WaitGroup
+	)
+
+	v, err := s.SafeGet(ctx, "dir")
+	if err != nil {
+		t.Fatalf("SafeGet()=%+v", err)
+	}
+
+	dir := v.(*types.Synced)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if _, err := dir.SafeSet(ctx, strconv.Itoa(i), i); err != nil {
+				t.Errorf("SafeSet()=%+v", err)
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := s.SafeList(ctx); err != nil {
+				t.Errorf("SafeList()=%+v", err)
+			}
+
+			dir.List(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	if got, want := len(dir.List(ctx)), 16; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+}
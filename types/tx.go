@@ -0,0 +1,266 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Tx is a transaction of writes, which take effect on Commit.
+type Tx interface {
+	Writer
+	SafeWriter
+
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// Transactor is implemented by writers with native transactions.
+type Transactor interface {
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// Begin starts a transaction over w, native one if w is a Transactor,
+// or otherwise a buffered one, see Buffered.
+func Begin(ctx context.Context, w Writer) (Tx, error) {
+	if t, ok := w.(Transactor); ok {
+		return t.Begin(ctx)
+	}
+
+	return Buffer(w), nil
+}
+
+// Buffered is a transaction, which buffers writes and applies them
+// on Commit in order. If any of them fails, the applied ones are
+// reverted, which requires the writer to be a Reader as well.
+//
+// Writes are not validated until Commit, so Set and Del report
+// neither previous values nor missing keys. Buffered is not a Reader,
+// so nested keys are written through writers given by Put.
+type Buffered struct {
+	key Key
+	b   *buffer
+}
+
+type buffer struct {
+	w    Writer
+	mu   sync.Mutex
+	ops  []op
+	done bool
+}
+
+var (
+	_ Tx         = (*Buffered)(nil)
+	_ SafeWriter = (*Buffered)(nil)
+)
+
+func Buffer(w Writer) *Buffered {
+	return &Buffered{
+		b: &buffer{w: w},
+	}
+}
+
+func (b *Buffered) Del(ctx context.Context, key string) bool {
+	return b.SafeDel(ctx, key) == nil
+}
+
+func (b *Buffered) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := b.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (b *Buffered) Put(ctx context.Context, key string, hint Type) Writer {
+	w, _ := b.SafePut(ctx, key, hint)
+	return w
+}
+
+func (b *Buffered) SafeDel(ctx context.Context, key string) error {
+	return b.b.add(op{op: "Del", key: b.path(key)})
+}
+
+func (b *Buffered) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	return false, b.b.add(op{op: "Set", key: b.path(key), value: value})
+}
+
+func (b *Buffered) SafePut(ctx context.Context, key string, hint Type) (Writer, error) {
+	if err := b.b.add(op{op: "Put", key: b.path(key), hint: hint}); err != nil {
+		return nil, err
+	}
+
+	return &Buffered{key: b.path(key), b: b.b}, nil
+}
+
+// Commit applies the buffered writes. The transaction is done
+// afterwards, whether it succeeded or not.
+func (b *Buffered) Commit(ctx context.Context) error {
+	b.b.mu.Lock()
+	defer b.b.mu.Unlock()
+
+	if b.b.done {
+		return &Error{
+			Op:  "Commit",
+			Err: ErrClosed,
+		}
+	}
+
+	b.b.done = true
+
+	var undo []op
+
+	for _, o := range b.b.ops {
+		inv, err := b.b.inverse(ctx, o)
+		if err == nil {
+			err = o.apply(ctx, b.b.w)
+		}
+
+		if err != nil {
+			if e := b.b.revert(ctx, undo); e != nil {
+				err = Errors{err, e}
+			}
+
+			return &Error{
+				Op:  "Commit",
+				Key: o.key,
+				Err: err,
+			}
+		}
+
+		undo = append(undo, inv...)
+	}
+
+	return nil
+}
+
+// Rollback drops the buffered writes.
+func (b *Buffered) Rollback(ctx context.Context) error {
+	b.b.mu.Lock()
+	defer b.b.mu.Unlock()
+
+	if b.b.done {
+		return &Error{
+			Op:  "Rollback",
+			Err: ErrClosed,
+		}
+	}
+
+	b.b.done = true
+	b.b.ops = nil
+
+	return nil
+}
+
+func (b *Buffered) Unwrap() any {
+	return b.b.w
+}
+
+func (b *Buffered) path(key string) Key {
+	return append(b.key.Copy(), key)
+}
+
+func (b *buffer) add(o op) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return &Error{
+			Op:  o.op,
+			Key: o.key,
+			Err: ErrClosed,
+		}
+	}
+
+	b.ops = append(b.ops, o)
+
+	return nil
+}
+
+// inverse gives the ops reverting o, with values of subtrees
+// copied, as o may modify them.
+func (b *buffer) inverse(ctx context.Context, o op) ([]op, error) {
+	r, ok := b.w.(Reader)
+	if !ok {
+		return nil, nil
+	}
+
+	v, err := PrefixReader(r, o.key.Dir()...).SafeGet(ctx, o.key.Base())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if o.op == "Del" {
+			return nil, nil
+		}
+
+		return []op{{op: "Del", key: o.key}}, nil
+	case err != nil:
+		return nil, err
+	case o.op == "Put":
+		return nil, nil
+	}
+
+	if vr, ok := v.(Reader); ok {
+		if v, err = clone(ctx, vr); err != nil {
+			return nil, err
+		}
+	}
+
+	return []op{{op: "Set", key: o.key, value: v}}, nil
+}
+
+func (b *buffer) revert(ctx context.Context, undo []op) error {
+	var errs Errors
+
+	for i := len(undo) - 1; i >= 0; i-- {
+		if err := undo[i].apply(ctx, b.w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// clone copies the tree of r into a Map or a *Slice.
+func clone(ctx context.Context, r Reader) (any, error) {
+	keys, err := List(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m Map
+		s Slice
+	)
+
+	if r.Type() == TypeSlice {
+		s = make(Slice, 0, len(keys))
+	} else {
+		m = make(Map, len(keys))
+	}
+
+	for _, k := range keys {
+		v, err := PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+
+		if vr, ok := v.(Reader); ok {
+			if v, err = clone(ctx, vr); err != nil {
+				return nil, err
+			}
+		}
+
+		if m != nil {
+			m[k] = v
+		} else {
+			s = append(s, v)
+		}
+	}
+
+	if m != nil {
+		return m, nil
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,108 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuffered(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"db": types.Map{
+				"host": "localhost",
+				"port": "5432",
+			},
+		}
+	)
+
+	tx, err := types.Begin(ctx, m)
+	if err != nil {
+		t.Fatalf("Begin()=%+v", err)
+	}
+
+	db, err := tx.SafePut(ctx, "db", types.TypeMap)
+	if err != nil {
+		t.Fatalf("SafePut()=%+v", err)
+	}
+
+	db.Set(ctx, "host", "db.prod")
+	db.Del(ctx, "port")
+
+	cache, err := objects.Put(ctx, tx, types.TypeMap, "cache")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	cache.Set(ctx, "version", "v2")
+
+	if host, _ := objects.Get(ctx, m, "db", "host"); host != "localhost" {
+		t.Fatalf("got %v before Commit(), want localhost", host)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit()=%+v", err)
+	}
+
+	want := types.Map{
+		"db":    types.Map{"host": "db.prod"},
+		"cache": types.Map{"version": "v2"},
+	}
+
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if _, err := tx.SafeSet(ctx, "x", 1); !errors.Is(err, types.ErrClosed) {
+		t.Fatalf("SafeSet()=%+v, want %v", err, types.ErrClosed)
+	}
+}
+
+func TestBufferedRevert(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"db": types.Map{
+				"host": "localhost",
+			},
+		}
+		tx = types.Buffer(m)
+	)
+
+	tx.Set(ctx, "name", "app")
+	tx.Del(ctx, "db")
+	tx.Del(ctx, "missing")
+
+	if err := tx.Commit(ctx); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Commit()=%+v, want %v", err, types.ErrNotFound)
+	}
+
+	want := types.Map{
+		"db": types.Map{"host": "localhost"},
+	}
+
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	tx = types.Buffer(m)
+	tx.Set(ctx, "name", "app")
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback()=%+v", err)
+	}
+
+	if err := tx.Commit(ctx); !errors.Is(err, types.ErrClosed) {
+		t.Fatalf("Commit()=%+v, want %v", err, types.ErrClosed)
+	}
+
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,173 @@
+package objects
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by values, which check themselves once
+// decoded. Decode reports errors of Validate under the key of the
+// value; keys of *Error values are taken relative to it.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// validate calls Validate of the decoded dst, if it is a Validator.
+func (d *decoder) validate(dst reflect.Value, key Key) {
+	if dst.Kind() == reflect.Ptr || dst.Kind() == reflect.Interface {
+		return
+	}
+
+	var v Validator
+
+	switch {
+	case dst.CanAddr() && dst.Addr().Type().Implements(validatorType):
+		v = dst.Addr().Interface().(Validator)
+	case dst.Type().Implements(validatorType):
+		v = dst.Interface().(Validator)
+	default:
+		return
+	}
+
+	err := v.Validate()
+	if err == nil {
+		return
+	}
+
+	errs, ok := err.(Errors)
+	if !ok {
+		errs = Errors{err}
+	}
+
+	for _, err := range errs {
+		e, ok := err.(*Error)
+		if !ok {
+			e = &Error{Err: err}
+		}
+
+		k := Key(e.Key)
+		k.Prepend(key)
+		e.Key = k
+
+		if e.Op == "" {
+			e.Op = "Validate"
+		}
+
+		d.errs = append(d.errs, e)
+	}
+}
+
+// rules checks the value of the struct field f against the rules of
+// its `validate` tag, e.g. `validate:"required,min=1,max=65535"`:
+//
+//   - required - the key must be present
+//   - min=n, max=n - bounds of a number, or of the length of a string,
+//     slice or map
+//   - oneof=a b c - the value, formatted with fmt, must be one of the
+//     space-separated values
+//
+// Rules other than required apply to keys present in the reader.
+func (d *decoder) rules(f reflect.StructField, dst reflect.Value, key Key, found bool) {
+	tag, ok := f.Tag.Lookup("validate")
+	if !ok {
+		return
+	}
+
+	for dst.Kind() == reflect.Ptr && !dst.IsNil() {
+		dst = dst.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+
+		var valid bool
+
+		switch name {
+		case "required":
+			valid = found
+		case "min", "max", "oneof":
+			if !found || dst.Kind() == reflect.Ptr {
+				continue
+			}
+
+			var err error
+			if valid, err = check(name, arg, dst); err != nil {
+				d.errs = append(d.errs, &Error{
+					Op:  "Validate",
+					Key: key,
+					Got: rule,
+					Err: err,
+				})
+				continue
+			}
+		default:
+			d.errs = append(d.errs, &Error{
+				Op:  "Validate",
+				Key: key,
+				Got: rule,
+				Err: ErrUnexpectedType,
+			})
+			continue
+		}
+
+		if !valid {
+			var got any
+
+			if found {
+				got = dst.Interface()
+			}
+
+			d.errs = append(d.errs, &Error{
+				Op:   "Validate",
+				Key:  key,
+				Got:  got,
+				Want: rule,
+				Err:  ErrInvalid,
+			})
+		}
+	}
+}
+
+func check(name, arg string, v reflect.Value) (bool, error) {
+	if name == "oneof" {
+		s := fmt.Sprint(v.Interface())
+
+		for _, want := range strings.Fields(arg) {
+			if s == want {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return false, err
+	}
+
+	var x float64
+
+	switch k := v.Kind(); {
+	case k == reflect.String, k == reflect.Slice, k == reflect.Map, k == reflect.Array:
+		x = float64(v.Len())
+	case k >= reflect.Int && k <= reflect.Int64:
+		x = float64(v.Int())
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		x = float64(v.Uint())
+	case k == reflect.Float32 || k == reflect.Float64:
+		x = v.Float()
+	default:
+		return false, ErrUnexpectedType
+	}
+
+	if name == "min" {
+		return x >= n, nil
+	}
+
+	return x <= n, nil
+}
@@ -0,0 +1,82 @@
+package objects_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Listener struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" validate:"min=1,max=65535"`
+	Mode string `json:"mode" validate:"oneof=tcp udp"`
+	TLS  *TLS   `json:"tls"`
+}
+
+type TLS struct {
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+}
+
+func (t TLS) Validate() error {
+	if (t.Cert == "") != (t.Key == "") {
+		return &objects.Error{
+			Key:  []string{"key"},
+			Want: "both cert and key",
+			Err:  objects.ErrInvalid,
+		}
+	}
+	return nil
+}
+
+func TestDecodeValidate(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = types.Map{
+			"listeners": &types.Slice{
+				types.Map{
+					"host": "localhost",
+					"port": 443,
+					"mode": "tcp",
+					"tls":  types.Map{"cert": "a.pem", "key": "a.key"},
+				},
+				types.Map{
+					"port": 70000,
+					"mode": "sctp",
+					"tls":  types.Map{"cert": "b.pem"},
+				},
+			},
+		}
+		cfg struct {
+			Listeners []Listener `json:"listeners" validate:"min=1"`
+		}
+	)
+
+	err := objects.Decode(ctx, m, &cfg, nil)
+	if !errors.Is(err, objects.ErrInvalid) {
+		t.Fatalf("got %+v, want %+v", err, objects.ErrInvalid)
+	}
+
+	var got []string
+
+	for _, err := range err.(objects.Errors) {
+		e := err.(*objects.Error)
+		got = append(got, e.Op+" "+types.Key(e.Key).String())
+	}
+
+	want := []string{
+		"Validate listeners.1.host",
+		"Validate listeners.1.port",
+		"Validate listeners.1.mode",
+		"Validate listeners.1.tls.key",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,652 @@
+// Package xml exposes XML documents as trees of objects.
+//
+// Child elements are keyed by their names and attributes by their
+// names prefixed with "@". Repeated elements are exposed as slices,
+// and elements with neither attributes nor children as their text.
+// Text of other elements is kept under the "#text" key. Namespace
+// prefixes are kept as part of the names, e.g. "xsi:type".
+package xml
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rafal.dev/objects"
+)
+
+const (
+	// AttrPrefix marks keys of attributes.
+	AttrPrefix = "@"
+
+	// TextKey is the key of the text of an element.
+	TextKey = "#text"
+)
+
+// Element is an XML element.
+type Element struct {
+	Name     string
+	Attrs    []Attr
+	Children []*Element
+	Text     string
+}
+
+// Attr is an attribute of an XML element.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Document is a parsed XML document, which is a tree
+// of its root element.
+type Document struct {
+	*Node
+	Root *Element
+}
+
+// Node is an XML element exposed as a map.
+type Node struct {
+	e *Element
+}
+
+// Elements are the repeated children of an element, which share
+// the same name, exposed as a slice.
+type Elements struct {
+	e    *Element
+	name string
+}
+
+var (
+	_ objects.Interface  = (*Node)(nil)
+	_ objects.SafeReader = (*Node)(nil)
+	_ objects.SafeLister = (*Node)(nil)
+	_ objects.SafeWriter = (*Node)(nil)
+	_ objects.Interface  = (*Elements)(nil)
+	_ objects.SafeReader = (*Elements)(nil)
+	_ objects.SafeLister = (*Elements)(nil)
+	_ objects.SafeWriter = (*Elements)(nil)
+)
+
+// Parse parses the XML document p.
+func Parse(p []byte) (*Document, error) {
+	var (
+		dec   = xml.NewDecoder(bytes.NewReader(p))
+		stack []*Element
+		root  *Element
+	)
+
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &objects.Error{
+				Op:  "Parse",
+				Err: err,
+			}
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e := &Element{Name: name(tok.Name)}
+
+			for _, a := range tok.Attr {
+				e.Attrs = append(e.Attrs, Attr{Name: name(a.Name), Value: a.Value})
+			}
+
+			if n := len(stack); n != 0 {
+				stack[n-1].Children = append(stack[n-1].Children, e)
+			} else if root == nil {
+				root = e
+			}
+
+			stack = append(stack, e)
+		case xml.EndElement:
+			if n := len(stack); n != 0 {
+				stack[n-1].Text = strings.TrimSpace(stack[n-1].Text)
+				stack = stack[:n-1]
+			}
+		case xml.CharData:
+			if n := len(stack); n != 0 {
+				stack[n-1].Text += string(tok)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: objects.ErrEmpty,
+		}
+	}
+
+	return &Document{
+		Node: &Node{e: root},
+		Root: root,
+	}, nil
+}
+
+// Bytes serializes the document back to XML, indented with
+// two spaces.
+func (d *Document) Bytes() ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		enc = xml.NewEncoder(&buf)
+	)
+
+	enc.Indent("", "  ")
+
+	if err := encode(enc, d.Root); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+func encode(enc *xml.Encoder, e *Element) error {
+	start := xml.StartElement{Name: xml.Name{Local: e.Name}}
+
+	for _, a := range e.Attrs {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: a.Name}, Value: a.Value})
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if e.Text != "" {
+		if err := enc.EncodeToken(xml.CharData(e.Text)); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range e.Children {
+		if err := encode(enc, c); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func (n *Node) Type() objects.Type {
+	return objects.TypeMap
+}
+
+func (n *Node) Get(ctx context.Context, key string) (any, bool) {
+	v, err := n.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (n *Node) SafeGet(ctx context.Context, key string) (any, error) {
+	switch {
+	case strings.HasPrefix(key, AttrPrefix):
+		if i := n.e.attr(strings.TrimPrefix(key, AttrPrefix)); i != -1 {
+			return n.e.Attrs[i].Value, nil
+		}
+	case key == TextKey:
+		if n.e.Text != "" {
+			return n.e.Text, nil
+		}
+	default:
+		switch cs := n.e.children(key); len(cs) {
+		case 0:
+		case 1:
+			return value(cs[0]), nil
+		default:
+			return &Elements{e: n.e, name: key}, nil
+		}
+	}
+
+	return nil, &objects.Error{
+		Op:  "Get",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+func (n *Node) List(ctx context.Context) []string {
+	keys, _ := n.SafeList(ctx)
+	return keys
+}
+
+func (n *Node) SafeList(ctx context.Context) ([]string, error) {
+	var (
+		keys []string
+		seen = make(map[string]struct{})
+	)
+
+	for _, a := range n.e.Attrs {
+		keys = append(keys, AttrPrefix+a.Name)
+	}
+
+	if n.e.Text != "" {
+		keys = append(keys, TextKey)
+	}
+
+	for _, c := range n.e.Children {
+		if _, ok := seen[c.Name]; !ok {
+			seen[c.Name] = struct{}{}
+			keys = append(keys, c.Name)
+		}
+	}
+
+	return keys, nil
+}
+
+func (n *Node) Del(ctx context.Context, key string) bool {
+	return n.SafeDel(ctx, key) == nil
+}
+
+func (n *Node) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := n.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (n *Node) Put(ctx context.Context, key string, hint objects.Type) objects.Writer {
+	w, _ := n.SafePut(ctx, key, hint)
+	return w
+}
+
+func (n *Node) SafeDel(ctx context.Context, key string) error {
+	switch {
+	case strings.HasPrefix(key, AttrPrefix):
+		if i := n.e.attr(strings.TrimPrefix(key, AttrPrefix)); i != -1 {
+			n.e.Attrs = append(n.e.Attrs[:i], n.e.Attrs[i+1:]...)
+			return nil
+		}
+	case key == TextKey:
+		if n.e.Text != "" {
+			n.e.Text = ""
+			return nil
+		}
+	default:
+		var cs []*Element
+
+		for _, c := range n.e.Children {
+			if c.Name != key {
+				cs = append(cs, c)
+			}
+		}
+
+		if len(cs) != len(n.e.Children) {
+			n.e.Children = cs
+			return nil
+		}
+	}
+
+	return &objects.Error{
+		Op:  "Del",
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+// SafeSet sets an attribute, the text of the element or replaces
+// children of the given name with a new element. Readers are
+// converted to elements, other values to text.
+func (n *Node) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	switch {
+	case strings.HasPrefix(key, AttrPrefix):
+		s, err := text(value)
+		if err != nil {
+			return false, n.error("Set", key, value, err)
+		}
+
+		name := strings.TrimPrefix(key, AttrPrefix)
+
+		if i := n.e.attr(name); i != -1 {
+			n.e.Attrs[i].Value = s
+			return true, nil
+		}
+
+		n.e.Attrs = append(n.e.Attrs, Attr{Name: name, Value: s})
+
+		return false, nil
+	case key == TextKey:
+		s, err := text(value)
+		if err != nil {
+			return false, n.error("Set", key, value, err)
+		}
+
+		ok := n.e.Text != ""
+		n.e.Text = s
+
+		return ok, nil
+	}
+
+	cs, err := elements(ctx, key, value)
+	if err != nil {
+		return false, n.error("Set", key, value, err)
+	}
+
+	return n.e.replace(key, cs), nil
+}
+
+// SafePut gives a writer of the child element of the given name,
+// creating it if missing. For TypeSlice hint it gives a writer of
+// all children of the given name.
+func (n *Node) SafePut(ctx context.Context, key string, hint objects.Type) (objects.Writer, error) {
+	if strings.HasPrefix(key, AttrPrefix) || key == TextKey {
+		return nil, &objects.Error{
+			Op:   "Put",
+			Key:  []string{key},
+			Got:  key,
+			Want: "element name",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	cs := n.e.children(key)
+
+	if hint == objects.TypeSlice || len(cs) > 1 {
+		return &Elements{e: n.e, name: key}, nil
+	}
+
+	if len(cs) == 1 {
+		return &Node{e: cs[0]}, nil
+	}
+
+	c := &Element{Name: key}
+	n.e.Children = append(n.e.Children, c)
+
+	return &Node{e: c}, nil
+}
+
+func (n *Node) error(op, key string, value any, err error) error {
+	return &objects.Error{
+		Op:  op,
+		Key: []string{key},
+		Got: value,
+		Err: err,
+	}
+}
+
+func (s *Elements) Type() objects.Type {
+	return objects.TypeSlice
+}
+
+func (s *Elements) Get(ctx context.Context, key string) (any, bool) {
+	v, err := s.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (s *Elements) SafeGet(ctx context.Context, key string) (any, error) {
+	c, err := s.index("Get", key)
+	if err != nil {
+		return nil, err
+	}
+
+	return value(c), nil
+}
+
+func (s *Elements) List(ctx context.Context) []string {
+	keys, _ := s.SafeList(ctx)
+	return keys
+}
+
+func (s *Elements) SafeList(ctx context.Context) ([]string, error) {
+	var keys []string
+
+	for i := range s.e.children(s.name) {
+		keys = append(keys, strconv.Itoa(i))
+	}
+
+	return keys, nil
+}
+
+func (s *Elements) Del(ctx context.Context, key string) bool {
+	return s.SafeDel(ctx, key) == nil
+}
+
+func (s *Elements) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := s.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (s *Elements) Put(ctx context.Context, key string, hint objects.Type) objects.Writer {
+	w, _ := s.SafePut(ctx, key, hint)
+	return w
+}
+
+func (s *Elements) SafeDel(ctx context.Context, key string) error {
+	c, err := s.index("Del", key)
+	if err != nil {
+		return err
+	}
+
+	for i, x := range s.e.Children {
+		if x == c {
+			s.e.Children = append(s.e.Children[:i], s.e.Children[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// SafeSet replaces the element under the index, or appends a new
+// one if key is "-".
+func (s *Elements) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	cs, err := elements(ctx, s.name, value)
+	if err != nil {
+		return false, &objects.Error{
+			Op:  "Set",
+			Key: []string{key},
+			Got: value,
+			Err: err,
+		}
+	}
+
+	if key == "-" {
+		s.e.insert(s.name, cs[0])
+		return false, nil
+	}
+
+	c, err := s.index("Set", key)
+	if err != nil {
+		return false, err
+	}
+
+	*c = *cs[0]
+
+	return true, nil
+}
+
+func (s *Elements) SafePut(ctx context.Context, key string, hint objects.Type) (objects.Writer, error) {
+	if key == "-" {
+		c := &Element{Name: s.name}
+		s.e.insert(s.name, c)
+		return &Node{e: c}, nil
+	}
+
+	c, err := s.index("Put", key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{e: c}, nil
+}
+
+func (s *Elements) index(op, key string) (*Element, error) {
+	var (
+		cs     = s.e.children(s.name)
+		i, err = strconv.Atoi(key)
+	)
+
+	if err != nil || i < 0 || i >= len(cs) {
+		return nil, &objects.Error{
+			Op:   op,
+			Key:  []string{key},
+			Got:  key,
+			Want: len(cs),
+			Err:  objects.ErrOutOfBounds,
+		}
+	}
+
+	return cs[i], nil
+}
+
+func (e *Element) attr(name string) int {
+	for i, a := range e.Attrs {
+		if a.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Element) children(name string) []*Element {
+	var cs []*Element
+	for _, c := range e.Children {
+		if c.Name == name {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// replace replaces children of the given name with cs, keeping
+// the position of the first replaced child.
+func (e *Element) replace(name string, cs []*Element) bool {
+	var (
+		children []*Element
+		replaced bool
+	)
+
+	for _, c := range e.Children {
+		switch {
+		case c.Name != name:
+			children = append(children, c)
+		case !replaced:
+			children = append(children, cs...)
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		children = append(children, cs...)
+	}
+
+	e.Children = children
+
+	return replaced
+}
+
+// insert appends c after the last child of the given name.
+func (e *Element) insert(name string, c *Element) {
+	i := len(e.Children)
+
+	for j, x := range e.Children {
+		if x.Name == name {
+			i = j + 1
+		}
+	}
+
+	e.Children = append(e.Children[:i], append([]*Element{c}, e.Children[i:]...)...)
+}
+
+// value gives the text of leaf elements and a Node otherwise.
+func value(e *Element) any {
+	if len(e.Attrs) == 0 && len(e.Children) == 0 {
+		return e.Text
+	}
+	return &Node{e: e}
+}
+
+// elements converts value to elements of the given name; slices
+// give an element for each of their items.
+func elements(ctx context.Context, name string, value any) ([]*Element, error) {
+	if r, ok := value.(objects.Reader); ok {
+		var v any
+		if err := objects.Decode(ctx, r, &v, nil); err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case []any:
+		cs := make([]*Element, 0, len(v))
+		for _, v := range v {
+			c, err := element(name, v)
+			if err != nil {
+				return nil, err
+			}
+			cs = append(cs, c)
+		}
+		return cs, nil
+	default:
+		c, err := element(name, v)
+		if err != nil {
+			return nil, err
+		}
+		return []*Element{c}, nil
+	}
+}
+
+func element(name string, value any) (*Element, error) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		s, err := text(value)
+		if err != nil {
+			return nil, err
+		}
+		return &Element{Name: name, Text: s}, nil
+	}
+
+	var (
+		e    = &Element{Name: name}
+		n    = &Node{e: e}
+		keys = make([]string, 0, len(m))
+	)
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := n.SafeSet(context.Background(), k, m[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+func text(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case map[string]any, []any, objects.Reader:
+		return "", objects.ErrUnexpectedType
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func name(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
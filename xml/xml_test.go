@@ -0,0 +1,96 @@
+package xml_test
+
+import (
+	"context"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/types"
+	"rafal.dev/objects/xml"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const config = `<config version="1">
+  <server host="localhost">
+    <port>8080</port>
+  </server>
+  <backend>a</backend>
+  <backend>b</backend>
+  <title lang="en">Example</title>
+</config>`
+
+func TestDocument(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := xml.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if got, want := doc.List(ctx), []string{"@version", "server", "backend", "title"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	gets := []struct {
+		keys []string
+		want any
+	}{
+		{[]string{"@version"}, "1"},
+		{[]string{"server", "@host"}, "localhost"},
+		{[]string{"server", "port"}, "8080"},
+		{[]string{"backend", "1"}, "b"},
+		{[]string{"title", "#text"}, "Example"},
+	}
+
+	for _, get := range gets {
+		v, err := objects.Get(ctx, doc, get.keys...)
+		if err != nil {
+			t.Fatalf("Get(%v)=%+v", get.keys, err)
+		}
+
+		if !cmp.Equal(v, get.want) {
+			t.Fatalf("Get(%v): got %#v, want %#v", get.keys, v, get.want)
+		}
+	}
+
+	sets := []struct {
+		value any
+		keys  []string
+	}{
+		{9090, []string{"server", "port"}},
+		{true, []string{"server", "@tls"}},
+		{"c", []string{"backend", "-"}},
+		{types.Map{"@id": "x", "#text": "y"}, []string{"extra"}},
+	}
+
+	for _, set := range sets {
+		if _, err := objects.Set(ctx, doc, set.value, set.keys...); err != nil {
+			t.Fatalf("Set(%v)=%+v", set.keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, doc, "title"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	p, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes()=%+v", err)
+	}
+
+	want := `<config version="1">
+  <server host="localhost" tls="true">
+    <port>9090</port>
+  </server>
+  <backend>a</backend>
+  <backend>b</backend>
+  <backend>c</backend>
+  <extra id="x">y</extra>
+</config>
+`
+
+	if got := string(p); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,441 @@
+// Package yaml exposes YAML documents as trees of objects. Writes
+// modify the parsed yaml.Node tree in place, so comments, anchors,
+// key order, quoting and blank lines separating entries are preserved
+// when the document is serialized back.
+package yaml
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"rafal.dev/objects"
+)
+
+const (
+	binaryTag = "!!binary"
+
+	// blankMarker marks blank lines in the encoded document.
+	blankMarker = "#objects:blank-line"
+)
+
+// Document is a parsed YAML document.
+type Document struct {
+	*Node
+
+	// File is the name of the source file reported in positions.
+	File string
+
+	doc   *yaml.Node
+	blank []*yaml.Node // nodes preceded by a blank line
+}
+
+// Node is a mapping or a sequence node of a YAML document.
+type Node struct {
+	n *yaml.Node
+	d *Document
+}
+
+var (
+	_ objects.Interface  = (*Node)(nil)
+	_ objects.SafeReader = (*Node)(nil)
+	_ objects.SafeLister = (*Node)(nil)
+	_ objects.SafeWriter = (*Node)(nil)
+	_ objects.Provenance = (*Node)(nil)
+)
+
+// Parse parses the YAML document p. The top-level value of the
+// document must be a mapping or a sequence.
+func Parse(p []byte) (*Document, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(p, &doc); err != nil {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: err,
+		}
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, &objects.Error{
+			Op:  "Parse",
+			Err: objects.ErrEmpty,
+		}
+	}
+
+	n := resolve(doc.Content[0])
+
+	if n.Kind != yaml.MappingNode && n.Kind != yaml.SequenceNode {
+		return nil, &objects.Error{
+			Op:   "Parse",
+			Got:  n.Tag,
+			Want: "mapping or sequence",
+			Err:  objects.ErrUnexpectedType,
+		}
+	}
+
+	d := &Document{
+		doc:   &doc,
+		blank: blankLines(&doc, bytes.Split(p, []byte("\n"))),
+	}
+
+	d.Node = &Node{n: n, d: d}
+
+	return d, nil
+}
+
+// Bytes serializes the document back to YAML, indented with
+// two spaces.
+func (d *Document) Bytes() ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		enc = yaml.NewEncoder(&buf)
+	)
+
+	enc.SetIndent(2)
+
+	comments := make([]string, len(d.blank))
+
+	for i, n := range d.blank {
+		comments[i] = n.HeadComment
+
+		if n.HeadComment == "" {
+			n.HeadComment = blankMarker
+		} else {
+			n.HeadComment = blankMarker + "\n" + n.HeadComment
+		}
+	}
+
+	err := enc.Encode(d.doc)
+
+	for i, n := range d.blank {
+		n.HeadComment = comments[i]
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	lines := bytes.SplitAfter(buf.Bytes(), []byte("\n"))
+
+	for i, line := range lines {
+		if string(bytes.TrimSpace(line)) == blankMarker {
+			lines[i] = []byte("\n")
+		}
+	}
+
+	return bytes.Join(lines, nil), nil
+}
+
+// blankLines gives key nodes of mappings and items of sequences,
+// which are preceded by a blank line in the source lines.
+func blankLines(n *yaml.Node, lines [][]byte) []*yaml.Node {
+	var nodes []*yaml.Node
+
+	preceded := func(n *yaml.Node) bool {
+		line := n.Line - 1
+
+		if n.HeadComment != "" {
+			line -= strings.Count(n.HeadComment, "\n") + 1
+		}
+
+		return line > 0 && line <= len(lines) && len(bytes.TrimSpace(lines[line-1])) == 0
+	}
+
+	for i, c := range n.Content {
+		if (n.Kind == yaml.SequenceNode || n.Kind == yaml.MappingNode && i%2 == 0) && preceded(c) {
+			nodes = append(nodes, c)
+		}
+
+		nodes = append(nodes, blankLines(c, lines)...)
+	}
+
+	return nodes
+}
+
+func (n *Node) Type() objects.Type {
+	if n.n.Kind == yaml.SequenceNode {
+		return objects.TypeSlice
+	}
+	return objects.TypeMap
+}
+
+func (n *Node) Get(ctx context.Context, key string) (any, bool) {
+	v, err := n.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (n *Node) SafeGet(ctx context.Context, key string) (any, error) {
+	i, err := n.index("Get", key)
+	if err != nil {
+		return nil, err
+	}
+
+	v := resolve(n.value(i))
+
+	switch v.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return &Node{n: v, d: n.d}, nil
+	}
+
+	var x any
+
+	if v.Tag == binaryTag {
+		var p []byte
+		p, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(v.Value), ""))
+		x = p
+	} else {
+		err = v.Decode(&x)
+	}
+
+	if err != nil {
+		return nil, &objects.Error{
+			Op:  "Get",
+			Key: []string{key},
+			Got: v.Value,
+			Err: err,
+		}
+	}
+
+	return x, nil
+}
+
+func (n *Node) List(ctx context.Context) []string {
+	keys, _ := n.SafeList(ctx)
+	return keys
+}
+
+func (n *Node) SafeList(ctx context.Context) ([]string, error) {
+	var keys []string
+
+	if n.n.Kind == yaml.SequenceNode {
+		for i := range n.n.Content {
+			keys = append(keys, strconv.Itoa(i))
+		}
+		return keys, nil
+	}
+
+	for i := 0; i+1 < len(n.n.Content); i += 2 {
+		keys = append(keys, n.n.Content[i].Value)
+	}
+
+	return keys, nil
+}
+
+func (n *Node) Del(ctx context.Context, key string) bool {
+	return n.SafeDel(ctx, key) == nil
+}
+
+func (n *Node) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := n.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (n *Node) Put(ctx context.Context, key string, hint objects.Type) objects.Writer {
+	w, _ := n.SafePut(ctx, key, hint)
+	return w
+}
+
+func (n *Node) SafeDel(ctx context.Context, key string) error {
+	i, err := n.index("Del", key)
+	if err != nil {
+		return err
+	}
+
+	if n.n.Kind == yaml.SequenceNode {
+		n.n.Content = append(n.n.Content[:i], n.n.Content[i+1:]...)
+	} else {
+		n.n.Content = append(n.n.Content[:i], n.n.Content[i+2:]...)
+	}
+
+	return nil
+}
+
+func (n *Node) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	v, err := encode(ctx, value)
+	if err != nil {
+		return false, &objects.Error{
+			Op:  "Set",
+			Key: []string{key},
+			Got: value,
+			Err: err,
+		}
+	}
+
+	return n.set(key, v)
+}
+
+func (n *Node) SafePut(ctx context.Context, key string, hint objects.Type) (objects.Writer, error) {
+	if i, err := n.index("Put", key); err == nil {
+		if v := resolve(n.value(i)); v.Kind == yaml.MappingNode || v.Kind == yaml.SequenceNode {
+			return &Node{n: v, d: n.d}, nil
+		}
+	}
+
+	v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if hint == objects.TypeSlice {
+		v = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	if _, err := n.set(key, v); err != nil {
+		return nil, err
+	}
+
+	return &Node{n: v, d: n.d}, nil
+}
+
+// Position gives the position of the key of a mapping entry or of
+// a sequence item. Values written after parsing have no position.
+func (n *Node) Position(key objects.Key) (objects.Position, bool) {
+	var (
+		cur = n
+		pos *yaml.Node
+	)
+
+	for _, k := range key {
+		if cur == nil {
+			return objects.Position{}, false
+		}
+
+		i, err := cur.index("Get", k)
+		if err != nil {
+			return objects.Position{}, false
+		}
+
+		pos = cur.n.Content[i]
+
+		if v := resolve(cur.value(i)); v.Kind == yaml.MappingNode || v.Kind == yaml.SequenceNode {
+			cur = &Node{n: v, d: n.d}
+		} else {
+			cur = nil
+		}
+	}
+
+	if pos == nil || pos.Line == 0 {
+		return objects.Position{}, false
+	}
+
+	return objects.Position{
+		File:   n.d.File,
+		Line:   pos.Line,
+		Column: pos.Column,
+	}, true
+}
+
+// set replaces the value node under key, keeping comments of the
+// replaced node.
+func (n *Node) set(key string, v *yaml.Node) (bool, error) {
+	i, err := n.index("Set", key)
+	if err != nil {
+		switch {
+		case n.n.Kind == yaml.MappingNode:
+			n.n.Content = append(n.n.Content, &yaml.Node{
+				Kind:  yaml.ScalarNode,
+				Tag:   "!!str",
+				Value: key,
+			}, v)
+			return false, nil
+		case key == "-" || key == strconv.Itoa(len(n.n.Content)):
+			n.n.Content = append(n.n.Content, v)
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	old := n.value(i)
+
+	v.HeadComment = old.HeadComment
+	v.LineComment = old.LineComment
+	v.FootComment = old.FootComment
+
+	if old.Kind == yaml.ScalarNode && v.Kind == yaml.ScalarNode && old.Tag == v.Tag {
+		v.Style = old.Style
+	}
+
+	if n.n.Kind == yaml.SequenceNode {
+		n.n.Content[i] = v
+	} else {
+		n.n.Content[i+1] = v
+	}
+
+	return true, nil
+}
+
+// index gives the position of key in the node content; for mappings
+// it is the position of the key node.
+func (n *Node) index(op, key string) (int, error) {
+	if n.n.Kind == yaml.SequenceNode {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(n.n.Content) {
+			return 0, &objects.Error{
+				Op:   op,
+				Key:  []string{key},
+				Got:  key,
+				Want: len(n.n.Content),
+				Err:  objects.ErrOutOfBounds,
+			}
+		}
+		return i, nil
+	}
+
+	for i := 0; i+1 < len(n.n.Content); i += 2 {
+		if n.n.Content[i].Value == key {
+			return i, nil
+		}
+	}
+
+	return 0, &objects.Error{
+		Op:  op,
+		Key: []string{key},
+		Err: objects.ErrNotFound,
+	}
+}
+
+func (n *Node) value(i int) *yaml.Node {
+	if n.n.Kind == yaml.SequenceNode {
+		return n.n.Content[i]
+	}
+	return n.n.Content[i+1]
+}
+
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func encode(ctx context.Context, value any) (*yaml.Node, error) {
+	if r, ok := value.(objects.Reader); ok {
+		var v any
+		if err := objects.Decode(ctx, r, &v, nil); err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	if p, ok := value.([]byte); ok {
+		return &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   binaryTag,
+			Value: base64.StdEncoding.EncodeToString(p),
+		}, nil
+	}
+
+	var v yaml.Node
+
+	if err := v.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
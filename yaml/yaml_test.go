@@ -0,0 +1,195 @@
+package yaml_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/yaml"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const config = `# Server configuration
+server:
+  host: localhost # listen address
+  port: 8080
+defaults: &defaults
+  timeout: 30s
+primary: *defaults
+backends:
+  - a
+`
+
+func TestDocument(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := yaml.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if got, want := doc.List(ctx), []string{"server", "defaults", "primary", "backends"}; !cmp.Equal(got, want) {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+
+	if v, err := objects.Get(ctx, doc, "primary", "timeout"); err != nil || v != "30s" {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+
+	if v, err := objects.Get(ctx, doc, "server", "port"); err != nil || v != 8080 {
+		t.Fatalf("Get()=%#v, %+v", v, err)
+	}
+
+	sets := []struct {
+		value any
+		keys  []string
+	}{
+		{"0.0.0.0", []string{"server", "host"}},
+		{true, []string{"server", "tls"}},
+		{"b", []string{"backends", "-"}},
+	}
+
+	for _, set := range sets {
+		if _, err := objects.Set(ctx, doc, set.value, set.keys...); err != nil {
+			t.Fatalf("Set(%v)=%+v", set.keys, err)
+		}
+	}
+
+	if err := objects.Del(ctx, doc, "server", "port"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	p, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes()=%+v", err)
+	}
+
+	want := `# Server configuration
+server:
+  host: 0.0.0.0 # listen address
+  tls: true
+defaults: &defaults
+  timeout: 30s
+primary: *defaults
+backends:
+  - a
+  - b
+`
+
+	if got := string(p); got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestBinary(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		want = []byte{0, 1, 2, 255}
+	)
+
+	doc, err := yaml.Parse([]byte("key: !!binary AAEC/w==\n"))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if p, err := objects.Bytes(ctx, doc, "key"); err != nil || !cmp.Equal(p, want) {
+		t.Fatalf("Bytes()=%v, %+v", p, err)
+	}
+
+	if _, err := objects.Set(ctx, doc, []byte("hello"), "other"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	p, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes()=%+v", err)
+	}
+
+	if got, want := string(p), "key: !!binary AAEC/w==\nother: !!binary aGVsbG8=\n"; got != want {
+		t.Fatalf("got != want:\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	var ctx = context.Background()
+
+	const src = `# Service configuration.
+name: 'example'
+
+server:
+  host: "localhost"
+  port: 8080
+
+  # Timeouts of requests.
+  timeout: 30s
+
+backends:
+  - a
+
+  - b
+`
+
+	doc, err := yaml.Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	if p, err := doc.Bytes(); err != nil || string(p) != src {
+		t.Fatalf("Bytes()=%q, %+v", p, err)
+	}
+
+	if _, err := objects.Set(ctx, doc, "updated", "name"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, doc, "0.0.0.0", "server", "host"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	p, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes()=%+v", err)
+	}
+
+	want := strings.NewReplacer("'example'", "'updated'", `"localhost"`, `"0.0.0.0"`).Replace(src)
+
+	if diff := cmp.Diff(want, string(p)); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	var ctx = context.Background()
+
+	doc, err := yaml.Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse()=%+v", err)
+	}
+
+	doc.File = "config.yaml"
+
+	if pos, ok := objects.PositionOf(ctx, doc, "server", "port"); !ok || pos.String() != "config.yaml:4:3" {
+		t.Fatalf("PositionOf()=%v, %t", pos, ok)
+	}
+
+	if _, ok := objects.PositionOf(ctx, doc, "server", "missing"); ok {
+		t.Fatal("expected PositionOf() to fail")
+	}
+
+	var v struct {
+		Server struct {
+			Port bool `objects:"port"`
+		} `objects:"server"`
+	}
+
+	err = objects.Decode(ctx, doc, &v, nil)
+
+	var e *objects.Error
+
+	if !errors.As(err, &e) || e.Position.String() != "config.yaml:4:3" {
+		t.Fatalf("Decode()=%+v", err)
+	}
+}
@@ -0,0 +1,451 @@
+// Package zkkv exposes ZooKeeper znodes as a tree.
+//
+// Znodes with children are read as nested trees and znodes without
+// children as []byte leaves of their data.
+package zkkv
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+
+	"rafal.dev/objects/types"
+)
+
+// Mode is the mode of created znodes, with values of the ZooKeeper
+// create flags.
+type Mode int32
+
+const (
+	ModePersistent          Mode = 0
+	ModeEphemeral           Mode = 1
+	ModeSequential          Mode = 2
+	ModeEphemeralSequential Mode = ModeEphemeral | ModeSequential
+)
+
+type modeKey struct{}
+
+// WithMode makes Set and Put create znodes of the keys, but not of
+// their parents, in the given mode.
+func WithMode(ctx context.Context, mode Mode) context.Context {
+	return context.WithValue(ctx, modeKey{}, mode)
+}
+
+func modeOf(ctx context.Context) Mode {
+	mode, _ := ctx.Value(modeKey{}).(Mode)
+	return mode
+}
+
+type EventType int
+
+const (
+	EventCreated EventType = iota + 1
+	EventDeleted
+	EventDataChanged
+)
+
+type Event struct {
+	Type EventType
+	Path string
+}
+
+// Client is the subset of a ZooKeeper client used by the backend, e.g.
+// an adapter over go-zookeeper. Get, Set, Delete and Children are
+// expected to return an error wrapping types.ErrNotFound for missing
+// znodes, and Create one wrapping types.ErrConflict for existing
+// ones; Create gives the path of the created znode. Watch delivers
+// events of the znode and its descendants, e.g. with a persistent
+// recursive watch, until ctx is done.
+type Client interface {
+	Get(ctx context.Context, path string) ([]byte, error)
+	Set(ctx context.Context, path string, data []byte) error
+	Create(ctx context.Context, path string, data []byte, mode Mode) (string, error)
+	Delete(ctx context.Context, path string) error
+	Children(ctx context.Context, path string) ([]string, error)
+	Watch(ctx context.Context, path string) (<-chan Event, error)
+}
+
+// Tree is a tree of znodes under a root znode.
+type Tree struct {
+	c    Client
+	root string
+	key  types.Key
+}
+
+var (
+	_ types.Interface  = (*Tree)(nil)
+	_ types.SafeReader = (*Tree)(nil)
+	_ types.SafeLister = (*Tree)(nil)
+	_ types.SafeWriter = (*Tree)(nil)
+	_ types.Watcher    = (*Tree)(nil)
+)
+
+// New gives a tree of znodes under the root znode, e.g. "/app", so
+// that the db.host key is the /app/db/host znode.
+func New(c Client, root string) *Tree {
+	return &Tree{
+		c:    c,
+		root: path.Clean("/" + root),
+	}
+}
+
+func (t *Tree) Type() types.Type {
+	return types.TypeMap
+}
+
+func (t *Tree) Get(ctx context.Context, key string) (any, bool) {
+	v, err := t.SafeGet(ctx, key)
+	return v, err == nil
+}
+
+func (t *Tree) SafeGet(ctx context.Context, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	p := t.path(key)
+
+	children, err := t.c.Children(ctx, p)
+	if err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	if len(children) != 0 {
+		return t.child(key), nil
+	}
+
+	data, err := t.c.Get(ctx, p)
+	if err != nil {
+		return nil, keyError("Get", key, err)
+	}
+
+	return data, nil
+}
+
+func (t *Tree) List(ctx context.Context) []string {
+	keys, _ := t.SafeList(ctx)
+	return keys
+}
+
+func (t *Tree) SafeList(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	keys, err := t.c.Children(ctx, t.path(""))
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		return nil, &types.Error{
+			Op:  "List",
+			Err: err,
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (t *Tree) Del(ctx context.Context, key string) bool {
+	return t.SafeDel(ctx, key) == nil
+}
+
+func (t *Tree) Set(ctx context.Context, key string, value any) bool {
+	ok, _ := t.SafeSet(ctx, key, value)
+	return ok
+}
+
+func (t *Tree) Put(ctx context.Context, key string, hint types.Type) types.Writer {
+	w, _ := t.SafePut(ctx, key, hint)
+	return w
+}
+
+// SafeDel deletes the znode of the key with all its descendants.
+func (t *Tree) SafeDel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return keyError("Del", key, err)
+	}
+
+	if err := t.del(ctx, t.path(key)); err != nil {
+		return keyError("Del", key, err)
+	}
+
+	return nil
+}
+
+// SafeSet sets data of the znode of the key to the string or []byte
+// value, or replaces the znode with a tree of znodes of the Reader
+// value.
+func (t *Tree) SafeSet(ctx context.Context, key string, value any) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	if err := t.parents(ctx); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	var (
+		p           = t.path(key)
+		r, isReader = value.(types.Reader)
+	)
+
+	if !isReader {
+		b, err := data(value)
+		if err != nil {
+			return false, keyError("Set", key, err)
+		}
+
+		children, err := t.c.Children(ctx, p)
+		switch {
+		case err == nil && len(children) == 0:
+			if err := t.c.Set(ctx, p, b); err != nil {
+				return false, keyError("Set", key, err)
+			}
+
+			return true, nil
+		case err != nil && !errors.Is(err, types.ErrNotFound):
+			return false, keyError("Set", key, err)
+		}
+
+		previous := err == nil
+
+		if previous {
+			if err := t.del(ctx, p); err != nil {
+				return false, keyError("Set", key, err)
+			}
+		}
+
+		if _, err := t.c.Create(ctx, p, b, modeOf(ctx)); err != nil {
+			return false, keyError("Set", key, err)
+		}
+
+		return previous, nil
+	}
+
+	err := t.del(ctx, p)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		return false, keyError("Set", key, err)
+	}
+
+	previous := err == nil
+
+	created, err := t.c.Create(ctx, p, nil, modeOf(ctx))
+	if err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	if err := t.create(ctx, created, r); err != nil {
+		return false, keyError("Set", key, err)
+	}
+
+	return previous, nil
+}
+
+// SafePut creates the znode of the key, unless it exists, together
+// with missing parents. The Writer it gives is the tree of the created
+// znode, which name has a counter appended for sequential modes.
+func (t *Tree) SafePut(ctx context.Context, key string, hint types.Type) (types.Writer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	if err := t.parents(ctx); err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	created, err := t.c.Create(ctx, t.path(key), nil, modeOf(ctx))
+	if errors.Is(err, types.ErrConflict) {
+		return t.child(key), nil
+	}
+
+	if err != nil {
+		return nil, keyError("Put", key, err)
+	}
+
+	return t.child(path.Base(created)), nil
+}
+
+// Watch delivers a Set event with the data of each created or changed
+// znode under the key, and a Del event for each deleted znode. Data is
+// read once the event arrives, so Set events of znodes deleted in the
+// meantime are dropped.
+func (t *Tree) Watch(ctx context.Context, key string) <-chan types.Event {
+	var (
+		ch     = make(chan types.Event)
+		p      = t.path(key)
+		prefix = t.path("")
+	)
+
+	events, err := t.c.Watch(ctx, p)
+	if err != nil {
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer close(ch)
+
+		for e := range events {
+			rel := strings.TrimPrefix(strings.TrimPrefix(e.Path, prefix), "/")
+
+			if rel == "" {
+				continue
+			}
+
+			ev := types.Event{
+				Op:  "Set",
+				Key: strings.Split(rel, "/"),
+			}
+
+			switch e.Type {
+			case EventDeleted:
+				ev.Op = "Del"
+			case EventCreated, EventDataChanged:
+				data, err := t.c.Get(ctx, e.Path)
+				if err != nil {
+					continue
+				}
+				ev.Value = data
+			default:
+				continue
+			}
+
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+func (t *Tree) Ping(ctx context.Context) error {
+	return types.Ping(ctx, t.c)
+}
+
+func (t *Tree) Close(ctx context.Context) error {
+	return types.Close(ctx, t.c)
+}
+
+func (t *Tree) Unwrap() any {
+	return t.c
+}
+
+// parents creates missing persistent znodes of the tree.
+func (t *Tree) parents(ctx context.Context) error {
+	var (
+		p     = "/"
+		parts = strings.Split(strings.TrimPrefix(t.path(""), "/"), "/")
+	)
+
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		p = path.Join(p, part)
+
+		if _, err := t.c.Create(ctx, p, nil, ModePersistent); err != nil && !errors.Is(err, types.ErrConflict) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) create(ctx context.Context, p string, r types.Reader) error {
+	keys, err := types.List(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		v, err := types.PrefixedReader{R: r}.SafeGet(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		var (
+			child     = path.Join(p, k)
+			nested, _ = v.(types.Reader)
+			b         []byte
+		)
+
+		if nested == nil {
+			if b, err = data(v); err != nil {
+				return err
+			}
+		}
+
+		if _, err := t.c.Create(ctx, child, b, ModePersistent); err != nil {
+			return err
+		}
+
+		if nested != nil {
+			if err := t.create(ctx, child, nested); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// del deletes the znode p after its descendants.
+func (t *Tree) del(ctx context.Context, p string) error {
+	children, err := t.c.Children(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		if err := t.del(ctx, path.Join(p, c)); err != nil {
+			return err
+		}
+	}
+
+	return t.c.Delete(ctx, p)
+}
+
+func (t *Tree) child(key string) *Tree {
+	return &Tree{
+		c:    t.c,
+		root: t.root,
+		key:  append(t.key.Copy(), key),
+	}
+}
+
+func (t *Tree) path(key string) string {
+	return path.Join(append([]string{t.root}, append(t.key.Copy(), key)...)...)
+}
+
+func data(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, &types.Error{
+			Op:   "Set",
+			Got:  value,
+			Want: []byte(nil),
+			Err:  types.ErrUnexpectedType,
+		}
+	}
+}
+
+func keyError(op, key string, err error) error {
+	return &types.Error{
+		Op:  op,
+		Key: []string{key},
+		Err: err,
+	}
+}
@@ -0,0 +1,239 @@
+package zkkv_test
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"rafal.dev/objects"
+	"rafal.dev/objects/objectstest"
+	"rafal.dev/objects/types"
+	"rafal.dev/objects/zkkv"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type znode struct {
+	data []byte
+	mode zkkv.Mode
+}
+
+type client struct {
+	mu       sync.Mutex
+	nodes    map[string]*znode
+	seq      int
+	watchers []chan zkkv.Event
+}
+
+func newClient() *client {
+	return &client{nodes: map[string]*znode{"/": {}}}
+}
+
+func (c *client) Get(_ context.Context, p string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.nodes[p]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+
+	return n.data, nil
+}
+
+func (c *client) Set(_ context.Context, p string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.nodes[p]
+	if !ok {
+		return types.ErrNotFound
+	}
+
+	n.data = data
+	c.notify(zkkv.EventDataChanged, p)
+
+	return nil
+}
+
+func (c *client) Create(_ context.Context, p string, data []byte, mode zkkv.Mode) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if parent, ok := c.nodes[path.Dir(p)]; !ok {
+		return "", fmt.Errorf("no parent of %s: %w", p, types.ErrNotFound)
+	} else if parent.mode&zkkv.ModeEphemeral != 0 {
+		return "", fmt.Errorf("ephemeral parent of %s", p)
+	}
+
+	if mode&zkkv.ModeSequential != 0 {
+		c.seq++
+		p = fmt.Sprintf("%s%010d", p, c.seq)
+	}
+
+	if _, ok := c.nodes[p]; ok {
+		return "", types.ErrConflict
+	}
+
+	c.nodes[p] = &znode{data: data, mode: mode}
+	c.notify(zkkv.EventCreated, p)
+
+	return p, nil
+}
+
+func (c *client) Delete(_ context.Context, p string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.nodes[p]; !ok {
+		return types.ErrNotFound
+	}
+
+	delete(c.nodes, p)
+	c.notify(zkkv.EventDeleted, p)
+
+	return nil
+}
+
+func (c *client) Children(_ context.Context, p string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.nodes[p]; !ok {
+		return nil, types.ErrNotFound
+	}
+
+	var children []string
+
+	for k := range c.nodes {
+		if k != "/" && path.Dir(k) == p {
+			children = append(children, path.Base(k))
+		}
+	}
+
+	return children, nil
+}
+
+func (c *client) Watch(ctx context.Context, p string) (<-chan zkkv.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan zkkv.Event, 64)
+	c.watchers = append(c.watchers, ch)
+
+	return ch, nil
+}
+
+func (c *client) notify(typ zkkv.EventType, p string) {
+	for _, ch := range c.watchers {
+		ch <- zkkv.Event{Type: typ, Path: p}
+	}
+}
+
+func TestTree(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		c    = newClient()
+		tree = zkkv.New(c, "/app")
+	)
+
+	if _, err := objects.Set(ctx, tree, types.Map{"host": "localhost", "port": "5432"}, "db"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	w, err := objects.Put(ctx, tree, types.TypeMap, "members")
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	m, err := w.(types.SafeWriter).SafePut(zkkv.WithMode(ctx, zkkv.ModeEphemeralSequential), "member-", types.TypeMap)
+	if err != nil {
+		t.Fatalf("Put()=%+v", err)
+	}
+
+	if _, err := objects.Set(ctx, m, "node-1", "name"); err == nil {
+		t.Fatal("want Set() under an ephemeral znode to fail")
+	}
+
+	keys, err := objects.List(ctx, tree, "members")
+	if err != nil {
+		t.Fatalf("List()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]string{"member-0000000001"}, keys); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+
+	if mode := c.nodes["/app/members/member-0000000001"].mode; mode != zkkv.ModeEphemeralSequential {
+		t.Fatalf("got %d, want %d", mode, zkkv.ModeEphemeralSequential)
+	}
+
+	port, err := objects.Get(ctx, tree, "db", "port")
+	if err != nil {
+		t.Fatalf("Get()=%+v", err)
+	}
+
+	if diff := cmp.Diff([]byte("5432"), port); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTreeWatch(t *testing.T) {
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		tree        = zkkv.New(newClient(), "/app")
+	)
+	defer cancel()
+
+	if _, err := objects.Set(ctx, tree, types.Map{"host": "localhost"}, "db"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	ch := tree.Watch(ctx, "db")
+
+	next := func() string {
+		t.Helper()
+
+		select {
+		case ev := <-ch:
+			if ev.Value == nil {
+				return ev.Op + " " + ev.Key.String()
+			}
+			return fmt.Sprintf("%s %s %s", ev.Op, ev.Key, ev.Value)
+		case <-ctx.Done():
+			t.Fatalf("no event: %+v", ctx.Err())
+			return ""
+		}
+	}
+
+	if _, err := objects.Set(ctx, tree, "db.local", "db", "host"); err != nil {
+		t.Fatalf("Set()=%+v", err)
+	}
+
+	got := []string{next()}
+
+	if err := objects.Del(ctx, tree, "db"); err != nil {
+		t.Fatalf("Del()=%+v", err)
+	}
+
+	got = append(got, next(), next())
+
+	want := []string{
+		"Set db.host db.local",
+		"Del db.host",
+		"Del db",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got != want (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTreeConformance(t *testing.T) {
+	objectstest.TestInterface(t, func(t *testing.T) types.Interface {
+		return zkkv.New(newClient(), "/objects")
+	})
+}